@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apiHandlers
+
+import (
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+)
+
+// GetCostAnomalies listens on /api/anomalies endpoint and returns hours in which cost of namespaces or groups rose well
+// above their baseline along with the workloads contributing the most to it
+func GetCostAnomalies(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
+		anomalies, err := query.RetrieveCostAnomalies(queryParams.Get(query.Kind), queryParams.Get(query.Name), window)
+		if err == query.ErrInvalidAnomalyKind {
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			logrus.Errorf("unable to retrieve cost anomalies from dgraph, %v", err)
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addHeaders(&w, r)
+		encodeAndWrite(w, anomalies)
+	}
+}
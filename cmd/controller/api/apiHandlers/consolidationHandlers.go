@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apiHandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/consolidation"
+)
+
+// GetConsolidation listens on /api/consolidation endpoint and returns the cheapest mix of nodes which fits requests
+// of the pods of the cluster along with its monthly savings over current nodes
+func GetConsolidation(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		var candidates []string
+		if param := queryParams.Get(consolidation.InstanceTypes); param != "" {
+			candidates = strings.Split(param, ",")
+		}
+		simulation, err := consolidation.Simulate(getKubeClient(), candidates)
+		if err != nil {
+			logrus.Errorf("unable to simulate consolidation of nodes, %v", err)
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addHeaders(&w, r)
+		encodeAndWrite(w, simulation)
+	}
+}
@@ -26,22 +26,88 @@ import (
 	"github.com/vmware/purser/pkg/controller/eventprocessor"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
+	"net/url"
 )
 
 // GetGroupsData listens on /api/groups endpoint
 func GetGroupsData(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		if isWindowQuery(queryParams) {
+			// idle and shared cost of groups are allocated to their month to date cost only
+			if queryParams.Get(query.Idle) == query.IdleDistribute {
+				http.Error(w, "idle cost can't be distributed to groups in a window", http.StatusBadRequest)
+				return
+			}
+			if policies := retrieveSharedCostPolicies(); len(policies) > 0 {
+				http.Error(w, "shared cost policies can't be applied to groups in a window", http.StatusBadRequest)
+				return
+			}
+			encodeAndWrite(w, retrieveGroupsCost(window))
+			return
+		}
 
 		groupsData, err := query.RetrieveGroupsData()
 		if err != nil {
 			logrus.Errorf("unable to retrieve groups data from dgraph, %v", err)
 		} else {
+			if queryParams.Get(query.Idle) == query.IdleDistribute {
+				query.DistributeIdleCostToGroups(groupsData)
+			}
+			if policies := retrieveSharedCostPolicies(); len(policies) > 0 {
+				namespaces := query.RetrieveClusterMetrics(query.Logical, query.RequestCostMode, query.Window{}).Data.Children
+				if err := query.AllocateSharedCostToGroups(groupsData, retrieveGroupsPodsUIDs(), namespaces, policies); err != nil {
+					logrus.Errorf("unable to allocate shared cost to groups, %v", err)
+				}
+			}
 			encodeAndWrite(w, groupsData)
 		}
 	}
 }
 
+func isWindowQuery(queryParams url.Values) bool {
+	return queryParams.Get(query.Start) != "" || queryParams.Get(query.End) != "" || queryParams.Get(query.Step) != ""
+}
+
+// retrieveGroupsPodsUIDs returns uids of pods of each group
+func retrieveGroupsPodsUIDs() map[string][]string {
+	groupsPodsUIDs := map[string][]string{}
+	groups, err := getGroupClient().List(meta_v1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("unable to list groups: %v", err)
+		return groupsPodsUIDs
+	}
+	for _, group := range groups.Items {
+		groupsPodsUIDs[group.Name] = eventprocessor.RetrieveGroupPodsUIDs(group)
+	}
+	return groupsPodsUIDs
+}
+
+func retrieveGroupsCost(window query.Window) []query.GroupCost {
+	groupsCost := []query.GroupCost{}
+	groups, err := getGroupClient().List(meta_v1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("unable to list groups: %v", err)
+		return groupsCost
+	}
+	for _, group := range groups.Items {
+		groupCost, err := eventprocessor.RetrieveGroupCost(group, window)
+		if err != nil {
+			logrus.Errorf("unable to retrieve cost of group: %s, err: %v", group.Name, err)
+			continue
+		}
+		groupsCost = append(groupsCost, groupCost)
+	}
+	return groupsCost
+}
+
 // DeleteGroup listens on /api/group/delete
 func DeleteGroup(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apiHandlers
+
+import (
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+	"github.com/vmware/purser/pkg/controller/eventprocessor"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GetEgressCosts listens on /api/egress endpoint and returns egress of pods, namespaces or groups in the window
+// split by class of traffic(intraNode, intraZone, crossZone, crossRegion, internet)
+func GetEgressCosts(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		kind, name := queryParams.Get(query.Kind), queryParams.Get(query.Name)
+		var egressCosts []query.EgressCost
+		var err error
+		switch kind {
+		case query.PodType, query.NamespaceType:
+			egressCosts, err = query.RetrieveEgressCosts(kind, name, window)
+		case query.GroupType:
+			egressCosts, err = retrieveGroupsEgress(name, window)
+		default:
+			addAccessControlHeaders(&w, r)
+			http.Error(w, "kind must be one of pod, namespace or group", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			logrus.Errorf("unable to retrieve egress costs from dgraph, %v", err)
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addHeaders(&w, r)
+		encodeAndWrite(w, egressCosts)
+	}
+}
+
+// retrieveGroupsEgress returns egress of all groups or only the named one
+func retrieveGroupsEgress(name string, window query.Window) ([]query.EgressCost, error) {
+	egressCosts := []query.EgressCost{}
+	groups, err := getGroupClient().List(meta_v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups.Items {
+		if name != query.All && group.Name != name {
+			continue
+		}
+		egressCost, err := eventprocessor.RetrieveGroupEgress(group, window)
+		if err != nil {
+			logrus.Errorf("unable to retrieve egress of group: %s, err: %v", group.Name, err)
+			continue
+		}
+		egressCosts = append(egressCosts, egressCost)
+	}
+	return egressCosts, nil
+}
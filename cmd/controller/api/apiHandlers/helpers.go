@@ -25,12 +25,17 @@ import (
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"net/http"
 	"github.com/vmware/purser/pkg/controller"
+	sharedcostpolicy_api "github.com/vmware/purser/pkg/apis/sharedcostpolicy/v1"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
 	"github.com/vmware/purser/pkg/client/clientset/typed/groups/v1"
+	sharedcostpolicy_v1 "github.com/vmware/purser/pkg/client/clientset/typed/sharedcostpolicy/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 var groupClient *v1.GroupClient
 var kubeClient *kubernetes.Clientset
+var sharedCostPolicyClient *sharedcostpolicy_v1.SharedCostPolicyClient
 
 func addHeaders(w *http.ResponseWriter, r *http.Request) {
 	addAccessControlHeaders(w, r)
@@ -43,6 +48,19 @@ func addAccessControlHeaders(w *http.ResponseWriter, r *http.Request) {
 	(*w).Header().Set("Access-Control-Allow-Credentials", "true")
 }
 
+// getWindow parses start, end and step query params, responds with bad request if they are invalid
+func getWindow(w http.ResponseWriter, r *http.Request) (query.Window, bool) {
+	queryParams := r.URL.Query()
+	window, err := query.ParseWindow(queryParams.Get(query.Start), queryParams.Get(query.End), queryParams.Get(query.Step))
+	if err != nil {
+		logrus.Errorf("invalid time window: (%v)", err)
+		addAccessControlHeaders(&w, r)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return query.Window{}, false
+	}
+	return window, true
+}
+
 func writeBytes(w io.Writer, data []byte) {
 	_, err := w.Write(data)
 	if err != nil {
@@ -66,10 +84,11 @@ func convertRequestBodyToJSON(r *http.Request) ([]byte, error) {
 	return groupData, err
 }
 
-// SetKubeClientAndGroupClient sets groupcrd client
+// SetKubeClientAndGroupClient sets groupcrd client, kube client and sharedcostpolicy crd client
 func SetKubeClientAndGroupClient(conf controller.Config) {
 	groupClient = conf.Groupcrdclient
 	kubeClient = conf.Kubeclient
+	sharedCostPolicyClient = conf.SharedCostPolicyclient
 }
 
 func getGroupClient() *v1.GroupClient {
@@ -79,3 +98,16 @@ func getGroupClient() *v1.GroupClient {
 func getKubeClient() *kubernetes.Clientset {
 	return kubeClient
 }
+
+// retrieveSharedCostPolicies returns shared cost policies of the cluster, none if they can't be listed
+func retrieveSharedCostPolicies() []sharedcostpolicy_api.SharedCostPolicy {
+	if sharedCostPolicyClient == nil {
+		return nil
+	}
+	policies, err := sharedCostPolicyClient.List(meta_v1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("unable to list shared cost policies, err: %v", err)
+		return nil
+	}
+	return policies.Items
+}
@@ -322,15 +322,24 @@ func GetJobHierarchy(w http.ResponseWriter, r *http.Request) {
 // GetClusterMetrics listens on /metrics endpoint with option for view(physical or logical)
 func GetClusterMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if view, isView := queryParams[query.View]; isView && view[0] == query.Physical {
-			jsonData = query.RetrieveClusterMetrics(query.Physical)
+			jsonData = query.RetrieveClusterMetrics(query.Physical, costMode, window)
+			// cost of nodes already includes idle cost, it is only reported
+			query.PopulateIdleCost(&jsonData, query.All, window)
 		} else {
-			jsonData = query.RetrieveClusterMetrics(query.Logical)
+			jsonData = query.RetrieveClusterMetrics(query.Logical, costMode, window)
+			query.PopulateIdleCost(&jsonData, queryParams.Get(query.Idle), window)
+			query.PopulateSharedCost(&jsonData, retrieveSharedCostPolicies(), costMode, window)
 		}
 		query.PopulateClusterAllocationAndCapacity(&jsonData)
 		encodeAndWrite(w, jsonData)
@@ -340,21 +349,29 @@ func GetClusterMetrics(w http.ResponseWriter, r *http.Request) {
 // GetNamespaceMetrics listens on /metrics/namespace
 func GetNamespaceMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.NamespaceCheck,
-				Type:  query.NamespaceType,
-				Name:  name[0],
+				Check:    query.NamespaceCheck,
+				Type:     query.NamespaceType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 		} else {
-			jsonData = query.RetrieveClusterMetrics(query.Logical)
+			jsonData = query.RetrieveClusterMetrics(query.Logical, costMode, window)
 		}
+		query.PopulateSharedCost(&jsonData, retrieveSharedCostPolicies(), costMode, window)
 		query.PopulateClusterAllocationAndCapacity(&jsonData)
 		encodeAndWrite(w, jsonData)
 	}
@@ -363,16 +380,23 @@ func GetNamespaceMetrics(w http.ResponseWriter, r *http.Request) {
 // GetDeploymentMetrics listens on /metrics/deployment
 func GetDeploymentMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.DeploymentCheck,
-				Type:  query.DeploymentType,
-				Name:  name[0],
+				Check:    query.DeploymentCheck,
+				Type:     query.DeploymentType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -386,16 +410,23 @@ func GetDeploymentMetrics(w http.ResponseWriter, r *http.Request) {
 // GetDaemonsetMetrics listens on /metrics/daemonset
 func GetDaemonsetMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.DaemonsetCheck,
-				Type:  query.DaemonsetType,
-				Name:  name[0],
+				Check:    query.DaemonsetCheck,
+				Type:     query.DaemonsetType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -409,16 +440,23 @@ func GetDaemonsetMetrics(w http.ResponseWriter, r *http.Request) {
 // GetJobMetrics listens on /metrics/job
 func GetJobMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.JobCheck,
-				Type:  query.JobType,
-				Name:  name[0],
+				Check:    query.JobCheck,
+				Type:     query.JobType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -429,19 +467,49 @@ func GetJobMetrics(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetCronJobMetrics listens on /metrics/cronjob and returns cost of every run of cronjobs(or only the named one)
+// which ended in the window along with their average run cost and projected monthly cost
+func GetCronJobMetrics(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		cronJobsCost, err := query.RetrieveCronJobsCost(queryParams.Get(query.Name), window)
+		if err != nil {
+			logrus.Errorf("unable to retrieve cost of cronjobs from dgraph, %v", err)
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addHeaders(&w, r)
+		encodeAndWrite(w, cronJobsCost)
+	}
+}
+
 // GetStatefulsetMetrics listens on /metrics/statefulset
 func GetStatefulsetMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.StatefulsetCheck,
-				Type:  query.StatefulsetType,
-				Name:  name[0],
+				Check:    query.StatefulsetCheck,
+				Type:     query.StatefulsetType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -455,16 +523,23 @@ func GetStatefulsetMetrics(w http.ResponseWriter, r *http.Request) {
 // GetReplicasetMetrics listens on /metrics/replicaset
 func GetReplicasetMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.ReplicasetCheck,
-				Type:  query.ReplicasetType,
-				Name:  name[0],
+				Check:    query.ReplicasetCheck,
+				Type:     query.ReplicasetType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -478,16 +553,23 @@ func GetReplicasetMetrics(w http.ResponseWriter, r *http.Request) {
 // GetNodeMetrics listens on /metrics/node
 func GetNodeMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.NodeCheck,
-				Type:  query.NodeType,
-				Name:  name[0],
+				Check:    query.NodeCheck,
+				Type:     query.NodeType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			resourceQuery.PopulateNodeOrPVAllocationAndCapacity(&jsonData)
@@ -501,16 +583,23 @@ func GetNodeMetrics(w http.ResponseWriter, r *http.Request) {
 // GetPodMetrics listens on /metrics/pod
 func GetPodMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.PodCheck,
-				Type:  query.PodType,
-				Name:  name[0],
+				Check:    query.PodCheck,
+				Type:     query.PodType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -524,16 +613,23 @@ func GetPodMetrics(w http.ResponseWriter, r *http.Request) {
 // GetContainerMetrics listens on /metrics/container
 func GetContainerMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.ContainerCheck,
-				Type:  query.ContainerType,
-				Name:  name[0],
+				Check:    query.ContainerCheck,
+				Type:     query.ContainerType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
@@ -547,16 +643,23 @@ func GetContainerMetrics(w http.ResponseWriter, r *http.Request) {
 // GetPVMetrics listens on /metrics/pv
 func GetPVMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.PVCheck,
-				Type:  query.PVType,
-				Name:  name[0],
+				Check:    query.PVCheck,
+				Type:     query.PVType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			resourceQuery.PopulateNodeOrPVAllocationAndCapacity(&jsonData)
@@ -570,16 +673,23 @@ func GetPVMetrics(w http.ResponseWriter, r *http.Request) {
 // GetPVCMetrics listens on /metrics/pvc
 func GetPVCMetrics(w http.ResponseWriter, r *http.Request) {
 	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
 		addHeaders(&w, r)
 		queryParams := r.URL.Query()
 		logrus.Debugf("Query params: (%v)", queryParams)
+		costMode := query.GetCostMode(queryParams.Get(query.CostMode))
 
 		var jsonData query.JSONDataWrapper
 		if name, isName := queryParams[query.Name]; isName {
 			resourceQuery := query.Resource{
-				Check: query.PVCCheck,
-				Type:  query.PVCType,
-				Name:  name[0],
+				Check:    query.PVCCheck,
+				Type:     query.PVCType,
+				Name:     name[0],
+				CostMode: costMode,
+				Window:   window,
 			}
 			jsonData = resourceQuery.RetrieveResourceMetrics()
 			query.PopulateClusterAllocationAndCapacity(&jsonData)
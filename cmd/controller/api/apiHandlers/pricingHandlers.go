@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apiHandlers
+
+import (
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+)
+
+// GetRateCard listens on /api/ratecard endpoint and returns the cloud provider, region and zone used for pricing
+func GetRateCard(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		addHeaders(&w, r)
+
+		rateCard, err := query.RetrieveRateCard()
+		if err != nil {
+			logrus.Errorf("unable to retrieve rate card from dgraph, %v", err)
+		} else {
+			encodeAndWrite(w, rateCard)
+		}
+	}
+}
+
+// GetRateCardHistory listens on /api/ratecard/history endpoint and returns the rate cards used for pricing, latest first.
+// Version effective from the time in effectiveFrom query param is returned along with its prices when it is given.
+func GetRateCardHistory(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		if effectiveFrom := r.URL.Query().Get(query.EffectiveFrom); effectiveFrom != "" {
+			rateCard, err := query.RetrieveRateCardVersion(effectiveFrom)
+			if err != nil {
+				logrus.Errorf("unable to retrieve rate card version from dgraph, %v", err)
+				addAccessControlHeaders(&w, r)
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			addHeaders(&w, r)
+			encodeAndWrite(w, rateCard)
+			return
+		}
+
+		addHeaders(&w, r)
+		rateCards, err := query.RetrieveRateCardHistory()
+		if err != nil {
+			logrus.Errorf("unable to retrieve rate card history from dgraph, %v", err)
+		} else {
+			encodeAndWrite(w, rateCards)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apiHandlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+)
+
+// GetRecommendations listens on /api/recommendations endpoint and returns rightsizing recommendations of requests
+// and limits of containers of deployments, statefulsets and daemonsets from their usage in the window
+func GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		kind, name := queryParams.Get(query.Kind), queryParams.Get(query.Name)
+		switch kind {
+		case query.All, query.DeploymentType, query.StatefulsetType, query.DaemonsetType:
+		default:
+			addAccessControlHeaders(&w, r)
+			http.Error(w, "kind must be one of deployment, statefulset or daemonset", http.StatusBadRequest)
+			return
+		}
+		margin := query.DefaultMargin
+		if marginParam := queryParams.Get(query.Margin); marginParam != "" {
+			var err error
+			margin, err = strconv.ParseFloat(marginParam, 64)
+			if err != nil || margin < 0 {
+				addAccessControlHeaders(&w, r)
+				http.Error(w, "margin must be a non negative fraction(ex: 0.2)", http.StatusBadRequest)
+				return
+			}
+		}
+
+		recommendations, err := query.RetrieveRecommendations(kind, name, margin, window)
+		if err != nil {
+			logrus.Errorf("unable to retrieve recommendations from dgraph, %v", err)
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addHeaders(&w, r)
+		encodeAndWrite(w, recommendations)
+	}
+}
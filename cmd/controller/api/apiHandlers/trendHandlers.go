@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apiHandlers
+
+import (
+	"net/http"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+)
+
+// GetCostTrends listens on /api/trends endpoint and returns trends of cost of resources of the kind computed from
+// their hourly snapshots
+func GetCostTrends(w http.ResponseWriter, r *http.Request) {
+	if isUserAuthenticated(w, r) {
+		window, isValid := getWindow(w, r)
+		if !isValid {
+			return
+		}
+		queryParams := r.URL.Query()
+		logrus.Debugf("Query params: (%v)", queryParams)
+
+		kind := queryParams.Get(query.Kind)
+		if kind == "" {
+			addAccessControlHeaders(&w, r)
+			http.Error(w, "kind of resources is not given", http.StatusBadRequest)
+			return
+		}
+		trends, err := query.RetrieveCostTrends(kind, queryParams.Get(query.Name), window)
+		if err == query.ErrTrendWindowTooLong {
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		} else if err != nil {
+			logrus.Errorf("unable to retrieve cost trends from dgraph, %v", err)
+			addAccessControlHeaders(&w, r)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		addHeaders(&w, r)
+		encodeAndWrite(w, trends)
+	}
+}
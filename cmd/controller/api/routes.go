@@ -154,6 +154,12 @@ var routes = Routes{
 		"/api/metrics/job",
 		apiHandlers.GetJobMetrics,
 	},
+	Route{
+		"GetCronJobMetrics",
+		"GET",
+		"/api/metrics/cronjob",
+		apiHandlers.GetCronJobMetrics,
+	},
 	Route{
 		"GetStatefulsetMetrics",
 		"GET",
@@ -214,6 +220,54 @@ var routes = Routes{
 		"/api/groups",
 		apiHandlers.GetGroupsData,
 	},
+	Route{
+		"GetRateCard",
+		"GET",
+		"/api/ratecard",
+		apiHandlers.GetRateCard,
+	},
+	Route{
+		"GetRateCardHistory",
+		"GET",
+		"/api/ratecard/history",
+		apiHandlers.GetRateCardHistory,
+	},
+	Route{
+		"GetCostTrends",
+		"GET",
+		"/api/trends",
+		apiHandlers.GetCostTrends,
+	},
+	Route{
+		"GetCostForecasts",
+		"GET",
+		"/api/forecast",
+		apiHandlers.GetCostForecasts,
+	},
+	Route{
+		"GetCostAnomalies",
+		"GET",
+		"/api/anomalies",
+		apiHandlers.GetCostAnomalies,
+	},
+	Route{
+		"GetEgressCosts",
+		"GET",
+		"/api/egress",
+		apiHandlers.GetEgressCosts,
+	},
+	Route{
+		"GetRecommendations",
+		"GET",
+		"/api/recommendations",
+		apiHandlers.GetRecommendations,
+	},
+	Route{
+		"GetConsolidation",
+		"GET",
+		"/api/consolidation",
+		apiHandlers.GetConsolidation,
+	},
 	Route{
 		"Login",
 		"POST",
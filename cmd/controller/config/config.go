@@ -23,7 +23,10 @@ import (
 	log "github.com/Sirupsen/logrus"
 
 	"github.com/vmware/purser/pkg/client"
+	budget_client "github.com/vmware/purser/pkg/client/clientset/typed/budget/v1"
 	group_client "github.com/vmware/purser/pkg/client/clientset/typed/groups/v1"
+	ratecard_client "github.com/vmware/purser/pkg/client/clientset/typed/ratecard/v1"
+	sharedcostpolicy_client "github.com/vmware/purser/pkg/client/clientset/typed/sharedcostpolicy/v1"
 	subscriber_client "github.com/vmware/purser/pkg/client/clientset/typed/subscriber/v1"
 	"github.com/vmware/purser/pkg/controller"
 	"github.com/vmware/purser/pkg/controller/buffering"
@@ -49,13 +52,18 @@ func Setup(conf *controller.Config, kubeconfig string) {
 		StatefulSet:           true,
 		DaemonSet:             true,
 		Job:                   true,
+		CronJob:               true,
 		Service:               true,
 		Namespace:             true,
 		Group:                 true,
 		Subscriber:            true,
+		RateCard:              true,
 	}
 	conf.RingBuffer = &buffering.RingBuffer{Size: buffering.BufferSize, Mutex: &sync.Mutex{}}
 	clientset, clusterConfig := client.GetAPIExtensionClient(kubeconfig)
 	conf.Groupcrdclient = group_client.NewGroupClient(clientset, clusterConfig)
 	conf.Subscriberclient = subscriber_client.NewSubscriberClient(clientset, clusterConfig)
+	conf.RateCardclient = ratecard_client.NewRateCardClient(clientset, clusterConfig)
+	conf.SharedCostPolicyclient = sharedcostpolicy_client.NewSharedCostPolicyClient(clientset, clusterConfig)
+	conf.Budgetclient = budget_client.NewBudgetClient(clientset, clusterConfig)
 }
@@ -31,9 +31,11 @@ import (
 	"github.com/vmware/purser/cmd/controller/api"
 	"github.com/vmware/purser/cmd/controller/config"
 	"github.com/vmware/purser/pkg/controller"
+	"github.com/vmware/purser/pkg/controller/budget"
 	"github.com/vmware/purser/pkg/controller/dgraph"
 	"github.com/vmware/purser/pkg/controller/discovery/processor"
 	"github.com/vmware/purser/pkg/controller/eventprocessor"
+	"github.com/vmware/purser/pkg/controller/usage"
 	"github.com/vmware/purser/pkg/utils"
 )
 
@@ -50,10 +52,21 @@ func init() {
 	dgraphPort := flag.String("dgraphPort", "9080", "dgraph zero port")
 	interactions = flag.String("interactions", "disable", "enable discovery of interactions")
 	kubeconfig := flag.String("kubeconfig", InClusterConfigPath, "path to the kubeconfig file")
+	cloudProvider := flag.String("cloudProvider", "", "cloud provider(aws, gcp, azure, vsphere) used when it can't be detected from nodes")
+	region := flag.String("region", "", "region used when it can't be detected from nodes")
+	zone := flag.String("zone", "", "zone used when it can't be detected from nodes")
+	priceFile := flag.String("priceFile", "", "path to a downloaded price list of the cloud provider, read instead of fetching prices over network. Required for gcp")
+	spotDiscounts := flag.String("spotDiscounts", "", "comma separated instanceFamily=discount(ex: m5=0.7,*=0.65), fraction of on-demand price saved by spot nodes of price lists without spot prices(aws), * applies to other families")
+	usageSource := flag.String("usageSource", usage.DisabledSource, "source(metrics-server, prometheus) of cpu and memory usage of containers used for usage based costs")
+	prometheusURL := flag.String("prometheusURL", "", "url of the prometheus server used when usageSource is prometheus")
+	ipRanges := flag.String("ipRanges", "", "comma separated cidr=trafficClass(intraZone, crossZone, crossRegion, internet) used to classify egress to addresses outside the cluster")
 	flag.Parse()
 
 	utils.InitializeLogger(*logLevel)
 	config.Setup(&conf, *kubeconfig)
+	conf.Cloud = controller.CloudConfig{CloudProvider: *cloudProvider, Region: *region, Zone: *zone, PriceFile: *priceFile, SpotDiscounts: *spotDiscounts}
+	conf.Usage = controller.UsageConfig{Source: *usageSource, PrometheusURL: *prometheusURL}
+	conf.Discovery = controller.DiscoveryConfig{IPRanges: *ipRanges}
 
 	// start dgraph and create login if not exists
 	dgraph.Start(*dgraphURL, *dgraphPort)
@@ -70,6 +83,10 @@ func main() {
 		go startInteractionsDiscovery()
 	}
 	go startCronJobForUpdatingCustomGroups()
+	go startCronJobForTakingCostSnapshots()
+	if conf.Usage.Source != usage.DisabledSource {
+		go startCronJobForCollectingUsage()
+	}
 	controller.Start(&conf)
 }
 
@@ -111,14 +128,31 @@ func startCronJobForUpdatingCustomGroups() {
 	c.Start()
 }
 
+// takes snapshots of cost of the last hour at the start of every hour
+func startCronJobForTakingCostSnapshots() {
+	c := cron.New()
+	err := c.AddFunc("@hourly", takeCostSnapshots)
+	if err != nil {
+		log.Error(err)
+	}
+	c.Start()
+}
+
+// takes cost snapshots and compares them with the baseline to detect anomalies
+func takeCostSnapshots() {
+	eventprocessor.TakeCostSnapshots(conf.Groupcrdclient)
+	eventprocessor.DetectCostAnomalies(conf.Groupcrdclient)
+}
+
+// updates groups and evaluates budgets against their cost
 func runGroupUpdate() {
 	eventprocessor.UpdateGroups(conf.Groupcrdclient)
+	budget.EvaluateBudgets(conf.Budgetclient, conf.Groupcrdclient)
 }
 
 func startCronJobForPopulatingRateCard() {
-	cloud := &pricing.Cloud{Kubeclient: conf.Kubeclient}
-	// find cloud provider and region
-	cloud.CloudProvider, cloud.Region = pricing.GetClusterProviderAndRegion()
+	cloud := pricing.NewCloud(&conf)
+	// cloud provider and region are detected again on every run as nodes may change
 	cloud.PopulateRateCard()
 
 	c := cron.New()
@@ -129,3 +163,23 @@ func startCronJobForPopulatingRateCard() {
 	}
 	c.Start()
 }
+
+// collects usage of containers in every 5 min
+func startCronJobForCollectingUsage() {
+	source, err := usage.NewSource(conf.Usage.Source, conf.Kubeclient, conf.Usage.PrometheusURL)
+	if err != nil {
+		log.Errorf("unable to start collecting usage: %v", err)
+		return
+	}
+	collect := func() {
+		usage.Collect(source)
+	}
+	collect()
+
+	c := cron.New()
+	err = c.AddFunc("@every 0h5m", collect)
+	if err != nil {
+		log.Error(err)
+	}
+	c.Start()
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// CRD Budget attributes
+const (
+	BudgetPlural   string = "budgets"
+	BudgetGroup    string = "vmware.purser.com"
+	BudgetVersion  string = "v1"
+	BudgetFullName string = BudgetPlural + "." + BudgetGroup
+)
+
+// Bases of thresholds, actual is cost of the scope in current month till now and forecast is its projected cost at the
+// end of the month
+const (
+	ActualBasis   = "actual"
+	ForecastBasis = "forecast"
+)
+
+// States of budgets and status of their conditions
+const (
+	ActiveState  = "active"
+	InvalidState = "invalid"
+
+	ConditionTrue  = "True"
+	ConditionFalse = "False"
+)
+
+// ThresholdReachedEvent is type of the event sent to subscribers when cost of the scope of a budget reaches one of its
+// thresholds
+const ThresholdReachedEvent = "thresholdReached"
+
+// Budget information
+type Budget struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               BudgetSpec   `json:"spec"`
+	Status             BudgetStatus `json:"status,omitempty"`
+}
+
+// BudgetSpec is monthly amount for cost of the scope of the budget, scope is exactly one of a namespace, pods
+// matching a label selector or a group. Thresholds are 50, 80 and 100 percent of actual cost if not given.
+type BudgetSpec struct {
+	Namespace  string            `json:"namespace,omitempty"`
+	Selector   map[string]string `json:"selector,omitempty"`
+	Group      string            `json:"group,omitempty"`
+	Amount     float64           `json:"amount"`
+	Thresholds []Threshold       `json:"thresholds,omitempty"`
+}
+
+// Threshold is percentage of amount of the budget reached by actual or forecast cost, basis is actual if not given
+type Threshold struct {
+	Percent float64 `json:"percent"`
+	Basis   string  `json:"basis,omitempty"`
+}
+
+// BudgetStatus has cost of the scope in the month and a condition for every threshold, conditions are reset at the
+// start of every month
+type BudgetStatus struct {
+	State         string            `json:"state,omitempty"`
+	Message       string            `json:"message,omitempty"`
+	Month         string            `json:"month,omitempty"`
+	ActualCost    float64           `json:"actualCost,omitempty"`
+	ForecastCost  float64           `json:"forecastCost,omitempty"`
+	Conditions    []BudgetCondition `json:"conditions,omitempty"`
+	LastEvaluated meta_v1.Time      `json:"lastEvaluated,omitempty"`
+}
+
+// BudgetCondition is status of a threshold, type is named after the threshold(ex: Forecast80PercentReached)
+type BudgetCondition struct {
+	Type               string       `json:"type"`
+	Status             string       `json:"status"`
+	LastTransitionTime meta_v1.Time `json:"lastTransitionTime,omitempty"`
+	Message            string       `json:"message,omitempty"`
+}
+
+// BudgetList type
+type BudgetList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []Budget `json:"items"`
+}
@@ -49,9 +49,11 @@ type GroupSpec struct {
 	PITMetrics         *GroupMetrics                  `json:"pitMetrics,omitempty"`
 	MTDMetrics         *GroupMetrics                  `json:"mtdMetrics,omitempty"`
 	MTDCost            *Cost                          `json:"mtdCost,omitempty"`
+	MTDIdleCost        *Cost                          `json:"mtdIdleCost,omitempty"`
 	PerHourCost        *Cost                          `json:"perHourCost,omitempty"`
 	LastMonthCost      *Cost                          `json:"lastMonthCost,omitempty"`
 	LastLastMonthCost  *Cost                          `json:"lastLastMonthCost,omitempty"`
+	ProjectedCost      *ProjectedCost                 `json:"projectedCost,omitempty"`
 	LastUpdated        time.Time                      `json:"lastUpdated,omitempty"`
 }
 
@@ -73,6 +75,17 @@ type Cost struct {
 	StorageCost float64
 }
 
+// ProjectedCost is forecast of total cost till the end of month and over the next 30 days along with bounds of their
+// 95% confidence bands
+type ProjectedCost struct {
+	EndOfMonth      float64
+	EndOfMonthLower float64
+	EndOfMonthUpper float64
+	Next30Days      float64
+	Next30DaysLower float64
+	Next30DaysUpper float64
+}
+
 // GroupList is the list of Group resources
 type GroupList struct {
 	meta_v1.TypeMeta `json:",inline"`
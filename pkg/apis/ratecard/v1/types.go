@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// CRD RateCard attributes
+const (
+	RateCardPlural   string = "ratecards"
+	RateCardGroup    string = "vmware.purser.com"
+	RateCardVersion  string = "v1"
+	RateCardFullName string = RateCardPlural + "." + RateCardGroup
+)
+
+// States of rate cards, only the oldest RateCard is applied and the others are rejected
+const (
+	ActiveState   = "active"
+	RejectedState = "rejected"
+)
+
+// RateCard information
+type RateCard struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               RateCardSpec   `json:"spec"`
+	Status             RateCardStatus `json:"status,omitempty"`
+}
+
+// RateCardSpec definition details, unit of every price is USD($)
+type RateCardSpec struct {
+	Region                 string                  `json:"region,omitempty"`
+	CPUPricePerHour        float64                 `json:"cpuPricePerHour"`
+	MemoryPricePerGBHour   float64                 `json:"memoryPricePerGBHour"`
+	GPUPricePerHour        float64                 `json:"gpuPricePerHour,omitempty"`
+	StorageClassPrices     []StorageClassPrice     `json:"storageClassPrices,omitempty"`
+	ProvisionerPrices      []ProvisionerPrice      `json:"provisionerPrices,omitempty"`
+	NodeLabelOverrides     []NodeLabelOverride     `json:"nodeLabelOverrides,omitempty"`
+	ExtendedResourcePrices []ExtendedResourcePrice `json:"extendedResourcePrices,omitempty"`
+	NetworkPrices          []NetworkPrice          `json:"networkPrices,omitempty"`
+}
+
+// StorageClassPrice price of storage provisioned by a storage class
+type StorageClassPrice struct {
+	StorageClass   string  `json:"storageClass"`
+	PricePerGBHour float64 `json:"pricePerGBHour"`
+}
+
+// ProvisionerPrice price of storage provisioned by a provisioner(ex: CSI driver) through storage classes having all of
+// the parameters, first matching price of a provisioner is used for a volume
+type ProvisionerPrice struct {
+	Provisioner    string            `json:"provisioner"`
+	Parameters     map[string]string `json:"parameters,omitempty"`
+	PricePerGBHour float64           `json:"pricePerGBHour"`
+}
+
+// NodeLabelOverride prices for nodes having all of the labels, first matching override is used for a node
+type NodeLabelOverride struct {
+	Labels               map[string]string `json:"labels"`
+	CPUPricePerHour      float64           `json:"cpuPricePerHour"`
+	MemoryPricePerGBHour float64           `json:"memoryPricePerGBHour"`
+	GPUPricePerHour      float64           `json:"gpuPricePerHour,omitempty"`
+}
+
+// ExtendedResourcePrice price per unit of an extended resource(ex: xilinx.com/fpga-xilinx_u200) requested by pods,
+// gpus are priced by gpuPricePerHour of their nodes
+type ExtendedResourcePrice struct {
+	ResourceName string  `json:"resourceName"`
+	PricePerHour float64 `json:"pricePerHour"`
+}
+
+// NetworkPrice price of a network resource(loadBalancer, networkLoadBalancer, publicIP or
+// natGateway), load balancers of LoadBalancer services are priced per hour along with their public IPs. Egress of
+// pods is priced by PricePerGB of the class of its traffic(intraNode, intraZone, crossZone, crossRegion or internet).
+type NetworkPrice struct {
+	Resource     string  `json:"resource"`
+	PricePerHour float64 `json:"pricePerHour"`
+	PricePerGB   float64 `json:"pricePerGB,omitempty"`
+}
+
+// RateCardStatus definition
+type RateCardStatus struct {
+	State   string `json:"state,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// RateCardList type
+type RateCardList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []RateCard `json:"items"`
+}
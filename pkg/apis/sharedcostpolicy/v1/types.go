@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// CRD SharedCostPolicy attributes
+const (
+	SharedCostPolicyPlural   string = "sharedcostpolicies"
+	SharedCostPolicyGroup    string = "vmware.purser.com"
+	SharedCostPolicyVersion  string = "v1"
+	SharedCostPolicyFullName string = SharedCostPolicyPlural + "." + SharedCostPolicyGroup
+)
+
+// Strategies to split shared cost between tenants
+const (
+	EvenSplit       = "even"
+	CPURequestSplit = "cpuRequest"
+	CostSplit       = "cost"
+)
+
+// SharedCostPolicy information
+type SharedCostPolicy struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               SharedCostPolicySpec   `json:"spec"`
+	Status             SharedCostPolicyStatus `json:"status,omitempty"`
+}
+
+// SharedCostPolicySpec marks namespaces and groups as shared, their cost is split between all the other(tenant)
+// namespaces and groups as per the strategy(even, cpuRequest or cost). Cost is split evenly if strategy isn't given.
+type SharedCostPolicySpec struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	Strategy   string   `json:"strategy,omitempty"`
+}
+
+// SharedCostPolicyStatus definition
+type SharedCostPolicyStatus struct {
+	State   string `json:"state,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// SharedCostPolicyList type
+type SharedCostPolicyList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []SharedCostPolicy `json:"items"`
+}
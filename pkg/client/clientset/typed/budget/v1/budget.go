@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1
+
+import (
+	"github.com/vmware/purser/pkg/apis/budget/v1"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+// BudgetInterface has client methods we need to access Budget object
+type BudgetInterface interface {
+	Create(obj *v1.Budget) (*v1.Budget, error)
+	Update(obj *v1.Budget) (*v1.Budget, error)
+	Delete(name string, options *meta_v1.DeleteOptions) error
+	Get(name string) (*v1.Budget, error)
+	List(opts meta_v1.ListOptions) (*v1.BudgetList, error)
+	Watch(opts meta_v1.ListOptions) (watch.Interface, error)
+}
+
+// BudgetClient structure
+type BudgetClient struct {
+	client *rest.RESTClient
+	ns     string
+	plural string
+	codec  runtime.ParameterCodec
+}
+
+// Create creates a CRD budget in its namespace, namespace of the client when it has none.
+func (c *BudgetClient) Create(obj *v1.Budget) (*v1.Budget, error) {
+	result := v1.Budget{}
+	err := c.client.Post().
+		Namespace(c.namespaceOf(obj)).
+		Resource(c.plural).
+		Body(obj).
+		Do().
+		Into(&result)
+	return &result, err
+}
+
+// Update modifies the budget in its namespace, namespace of the client when it has none.
+func (c *BudgetClient) Update(obj *v1.Budget) (*v1.Budget, error) {
+	result := v1.Budget{}
+	err := c.client.Put().
+		Name((obj.Name)).
+		Namespace(c.namespaceOf(obj)).
+		Resource(c.plural).
+		Body(obj).
+		Do().
+		Into(&result)
+	return &result, err
+}
+
+// Delete removes the budget.
+func (c *BudgetClient) Delete(name string, options *meta_v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource(c.plural).
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Get returns the budget
+func (c *BudgetClient) Get(name string) (*v1.Budget, error) {
+	result := v1.Budget{}
+	err := c.client.Get().
+		Namespace(c.ns).
+		Resource(c.plural).
+		Name(name).
+		Do().
+		Into(&result)
+	return &result, err
+}
+
+// List fetches the list of budgets.
+func (c *BudgetClient) List(opts meta_v1.ListOptions) (*v1.BudgetList, error) {
+	result := v1.BudgetList{}
+	err := c.client.Get().
+		Namespace(c.ns).
+		Resource(c.plural).
+		VersionedParams(&opts, c.codec).
+		Do().
+		Into(&result)
+	return &result, err
+}
+
+// Watch watches for the budget CRD
+func (c *BudgetClient) Watch(opts meta_v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.
+		Get().
+		Namespace(c.ns).
+		Resource(c.plural).
+		VersionedParams(&opts, c.codec).
+		Watch()
+}
+
+func (c *BudgetClient) namespaceOf(obj *v1.Budget) string {
+	if obj.Namespace != "" {
+		return obj.Namespace
+	}
+	return c.ns
+}
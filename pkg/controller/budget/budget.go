@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package budget
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	budget_v1 "github.com/vmware/purser/pkg/apis/budget/v1"
+	groups_v1 "github.com/vmware/purser/pkg/apis/groups/v1"
+	budgetClient_v1 "github.com/vmware/purser/pkg/client/clientset/typed/budget/v1"
+	groupsClient_v1 "github.com/vmware/purser/pkg/client/clientset/typed/groups/v1"
+	"github.com/vmware/purser/pkg/controller"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+	"github.com/vmware/purser/pkg/controller/eventprocessor"
+	"github.com/vmware/purser/pkg/controller/utils"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ResourceType of payloads sent to subscribers for budgets
+const ResourceType = "Budget"
+
+// cost of pods matching a label selector is forecasted from their daily cost over selectorHistoryDays days
+const (
+	selectorScope       = "selector"
+	selectorHistoryDays = 28
+)
+
+var defaultThresholds = []budget_v1.Threshold{
+	{Percent: 50, Basis: budget_v1.ActualBasis},
+	{Percent: 80, Basis: budget_v1.ActualBasis},
+	{Percent: 100, Basis: budget_v1.ActualBasis},
+}
+
+// errInvalidScope is returned for budgets without exactly one of namespace, selector or group
+var errInvalidScope = errors.New("budget must be scoped to exactly one of namespace, selector or group")
+
+// ThresholdAlert is sent to subscribers when cost of the scope of a budget reaches one of its thresholds
+type ThresholdAlert struct {
+	Budget    string              `json:"budget"`
+	Namespace string              `json:"namespace,omitempty"`
+	Selector  map[string]string   `json:"selector,omitempty"`
+	Group     string              `json:"group,omitempty"`
+	Month     string              `json:"month"`
+	Amount    float64             `json:"amount"`
+	Threshold budget_v1.Threshold `json:"threshold"`
+	Cost      float64             `json:"cost"`
+}
+
+// EvaluateBudgets updates status of every budget with cost of its scope in current month, subscribers are notified of
+// thresholds reached since the last evaluation
+func EvaluateBudgets(budgetClient *budgetClient_v1.BudgetClient, groupCRDClient *groupsClient_v1.GroupClient) {
+	budgets, err := budgetClient.List(meta_v1.ListOptions{})
+	if err != nil {
+		log.Errorf("unable to list budgets, err: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var payloads []*interface{}
+	for i := range budgets.Items {
+		budget := &budgets.Items[i]
+		var reached []budget_v1.Threshold
+		forecast, err := retrieveScopeForecast(budget, groupCRDClient, now)
+		if err != nil {
+			log.Errorf("unable to evaluate budget: %s/%s, err: %v", budget.Namespace, budget.Name, err)
+			budget.Status.State = budget_v1.InvalidState
+			budget.Status.Message = err.Error()
+		} else {
+			reached = updateStatus(budget, forecast, now)
+		}
+
+		_, err = budgetClient.Update(budget)
+		if err != nil {
+			log.Errorf("unable to update status of budget: %s/%s, err: %v", budget.Namespace, budget.Name, err)
+		}
+		for _, threshold := range reached {
+			payloads = append(payloads, newAlertPayload(budget, threshold, now))
+		}
+	}
+
+	if len(payloads) > 0 {
+		log.Infof("Notifying subscribers of %d budget thresholds reached", len(payloads))
+		eventprocessor.NotifySubscribers(payloads)
+	}
+}
+
+// retrieveScopeForecast returns month to date cost of the scope of the budget along with its forecast
+func retrieveScopeForecast(budget *budget_v1.Budget, groupCRDClient *groupsClient_v1.GroupClient, now time.Time) (query.CostForecast, error) {
+	spec := budget.Spec
+	if spec.Amount <= 0 {
+		return query.CostForecast{}, fmt.Errorf("amount of budget must be positive")
+	}
+
+	var forecasts []query.CostForecast
+	var err error
+	switch getScopeKind(spec) {
+	case query.NamespaceType:
+		forecasts, err = query.RetrieveCostForecasts(query.NamespaceType, spec.Namespace)
+	case query.GroupType:
+		_, err = groupCRDClient.Get(spec.Group)
+		if err != nil {
+			return query.CostForecast{}, fmt.Errorf("unable to get group: %s, %v", spec.Group, err)
+		}
+		forecasts, err = query.RetrieveCostForecasts(query.GroupType, spec.Group)
+	case selectorScope:
+		return retrieveSelectorForecast(budget, now)
+	default:
+		return query.CostForecast{}, errInvalidScope
+	}
+	if err != nil || len(forecasts) == 0 {
+		return query.CostForecast{}, err
+	}
+	return forecasts[0], nil
+}
+
+// retrieveSelectorForecast forecasts cost of pods matching the label selector of the budget from their daily cost
+func retrieveSelectorForecast(budget *budget_v1.Budget, now time.Time) (query.CostForecast, error) {
+	group := &groups_v1.Group{Spec: groups_v1.GroupSpec{Expressions: getSelectorExpressions(budget.Spec.Selector)}}
+	group.Name = budget.Name
+
+	end := utils.GetCurrentHourStartTime()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -selectorHistoryDays)
+	if monthStart := utils.GetCurrentMonthStartTime(); monthStart.Before(start) {
+		start = monthStart
+	}
+	groupCost, err := eventprocessor.RetrieveGroupCost(group, query.Window{Start: start, End: end, Step: 24 * time.Hour})
+	if err != nil {
+		return query.CostForecast{}, err
+	}
+	return query.ForecastGroupCost(groupCost, now), nil
+}
+
+// getSelectorExpressions returns expressions of a group selecting pods having all the labels of the selector
+func getSelectorExpressions(selector map[string]string) map[string]map[string][]string {
+	expressions := map[string]map[string][]string{}
+	for key, value := range selector {
+		expressions[key] = map[string][]string{key: {value}}
+	}
+	return expressions
+}
+
+func getScopeKind(spec budget_v1.BudgetSpec) string {
+	kind, scopes := "", 0
+	if spec.Namespace != "" {
+		kind = query.NamespaceType
+		scopes++
+	}
+	if len(spec.Selector) > 0 {
+		kind = selectorScope
+		scopes++
+	}
+	if spec.Group != "" {
+		kind = query.GroupType
+		scopes++
+	}
+	if scopes != 1 {
+		return ""
+	}
+	return kind
+}
+
+// updateStatus sets cost and conditions of thresholds of the budget, conditions of the previous month are dropped.
+// It returns thresholds which are reached since the last evaluation.
+func updateStatus(budget *budget_v1.Budget, forecast query.CostForecast, now time.Time) []budget_v1.Threshold {
+	status := &budget.Status
+	month := now.Format("2006-01")
+	if status.Month != month {
+		status.Month = month
+		status.Conditions = nil
+	}
+	status.State = budget_v1.ActiveState
+	status.Message = ""
+	status.ActualCost = forecast.MonthToDateCost
+	status.ForecastCost = forecast.EndOfMonth.Cost
+	status.LastEvaluated = meta_v1.NewTime(now)
+
+	thresholds := budget.Spec.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = defaultThresholds
+	}
+	var reached []budget_v1.Threshold
+	var conditions []budget_v1.BudgetCondition
+	for _, threshold := range thresholds {
+		basis, cost := getBasisCost(threshold, *status)
+		limit := budget.Spec.Amount * threshold.Percent / 100
+		condition := budget_v1.BudgetCondition{
+			Type:               getConditionType(threshold),
+			Status:             budget_v1.ConditionFalse,
+			LastTransitionTime: meta_v1.NewTime(now),
+			Message:            fmt.Sprintf("%s cost %.2f is below %.2f", basis, cost, limit),
+		}
+		if cost >= limit {
+			condition.Status = budget_v1.ConditionTrue
+			condition.Message = fmt.Sprintf("%s cost %.2f has reached %.2f", basis, cost, limit)
+		}
+
+		previous := getCondition(budget.Status.Conditions, condition.Type)
+		if previous != nil && previous.Status == condition.Status {
+			condition.LastTransitionTime = previous.LastTransitionTime
+		} else if condition.Status == budget_v1.ConditionTrue {
+			reached = append(reached, threshold)
+		}
+		conditions = append(conditions, condition)
+	}
+	status.Conditions = conditions
+	return reached
+}
+
+// getBasisCost returns basis of the threshold and cost of the budget on that basis
+func getBasisCost(threshold budget_v1.Threshold, status budget_v1.BudgetStatus) (string, float64) {
+	if threshold.Basis == budget_v1.ForecastBasis {
+		return budget_v1.ForecastBasis, status.ForecastCost
+	}
+	return budget_v1.ActualBasis, status.ActualCost
+}
+
+// getConditionType returns type of condition of the threshold(ex: Actual80PercentReached)
+func getConditionType(threshold budget_v1.Threshold) string {
+	basis := budget_v1.ActualBasis
+	if threshold.Basis == budget_v1.ForecastBasis {
+		basis = budget_v1.ForecastBasis
+	}
+	return fmt.Sprintf("%s%gPercentReached", strings.Title(basis), threshold.Percent)
+}
+
+func getCondition(conditions []budget_v1.BudgetCondition, conditionType string) *budget_v1.BudgetCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func newAlertPayload(budget *budget_v1.Budget, threshold budget_v1.Threshold, now time.Time) *interface{} {
+	_, cost := getBasisCost(threshold, budget.Status)
+	alert := ThresholdAlert{
+		Budget:    budget.Name,
+		Namespace: budget.Spec.Namespace,
+		Selector:  budget.Spec.Selector,
+		Group:     budget.Spec.Group,
+		Month:     budget.Status.Month,
+		Amount:    budget.Spec.Amount,
+		Threshold: threshold,
+		Cost:      cost,
+	}
+	data, err := json.Marshal(alert)
+	if err != nil {
+		log.Errorf("unable to marshal alert of budget: %s, err: %v", budget.Name, err)
+	}
+	var payload interface{} = &controller.Payload{
+		Key:          budget.Namespace + "/" + budget.Name,
+		EventType:    budget_v1.ThresholdReachedEvent,
+		ResourceType: ResourceType,
+		Data:         string(data),
+		CaptureTime:  meta_v1.NewTime(now),
+	}
+	return &payload
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	budget_v1 "github.com/vmware/purser/pkg/apis/budget/v1"
+	"github.com/vmware/purser/pkg/controller"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+)
+
+func getTestBudget() *budget_v1.Budget {
+	budget := &budget_v1.Budget{Spec: budget_v1.BudgetSpec{
+		Namespace: "default",
+		Amount:    100,
+		Thresholds: []budget_v1.Threshold{
+			{Percent: 50},
+			{Percent: 100, Basis: budget_v1.ForecastBasis},
+		},
+	}}
+	budget.Name = "team-a"
+	return budget
+}
+
+func getTestForecast(actual, forecast float64) query.CostForecast {
+	return query.CostForecast{
+		MonthToDateCost: actual,
+		EndOfMonth:      query.CostProjection{Cost: forecast},
+	}
+}
+
+// TestUpdateStatus ...
+func TestUpdateStatus(t *testing.T) {
+	budget := getTestBudget()
+	now := time.Date(2019, 6, 10, 10, 0, 0, 0, time.UTC)
+
+	reached := updateStatus(budget, getTestForecast(30, 90), now)
+	assert.Empty(t, reached)
+	assert.Equal(t, budget_v1.ActiveState, budget.Status.State)
+	assert.Equal(t, "2019-06", budget.Status.Month)
+	assert.Equal(t, 30.0, budget.Status.ActualCost)
+	assert.Equal(t, 90.0, budget.Status.ForecastCost)
+	assert.Len(t, budget.Status.Conditions, 2)
+	assert.Equal(t, "Actual50PercentReached", budget.Status.Conditions[0].Type)
+	assert.Equal(t, budget_v1.ConditionFalse, budget.Status.Conditions[0].Status)
+	assert.Equal(t, "Forecast100PercentReached", budget.Status.Conditions[1].Type)
+
+	later := now.Add(5 * time.Minute)
+	reached = updateStatus(budget, getTestForecast(55, 120), later)
+	assert.Equal(t, budget.Spec.Thresholds, reached)
+	assert.Equal(t, budget_v1.ConditionTrue, budget.Status.Conditions[0].Status)
+	assert.Equal(t, later, budget.Status.Conditions[0].LastTransitionTime.Time)
+
+	reached = updateStatus(budget, getTestForecast(56, 121), later.Add(5*time.Minute))
+	assert.Empty(t, reached)
+	assert.Equal(t, later, budget.Status.Conditions[0].LastTransitionTime.Time)
+
+	reached = updateStatus(budget, getTestForecast(56, 95), later.Add(10*time.Minute))
+	assert.Empty(t, reached)
+	assert.Equal(t, budget_v1.ConditionFalse, budget.Status.Conditions[1].Status)
+
+	nextMonth := time.Date(2019, 7, 1, 0, 5, 0, 0, time.UTC)
+	reached = updateStatus(budget, getTestForecast(60, 60), nextMonth)
+	assert.Equal(t, budget.Spec.Thresholds[:1], reached)
+	assert.Equal(t, "2019-07", budget.Status.Month)
+}
+
+// TestUpdateStatusWithDefaultThresholds ...
+func TestUpdateStatusWithDefaultThresholds(t *testing.T) {
+	budget := getTestBudget()
+	budget.Spec.Thresholds = nil
+	reached := updateStatus(budget, getTestForecast(85, 150), time.Date(2019, 6, 25, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, defaultThresholds[:2], reached)
+	assert.Len(t, budget.Status.Conditions, 3)
+	assert.Equal(t, "Actual100PercentReached", budget.Status.Conditions[2].Type)
+}
+
+// TestGetScopeKind ...
+func TestGetScopeKind(t *testing.T) {
+	assert.Equal(t, query.NamespaceType, getScopeKind(budget_v1.BudgetSpec{Namespace: "default"}))
+	assert.Equal(t, query.GroupType, getScopeKind(budget_v1.BudgetSpec{Group: "team-a"}))
+	assert.Equal(t, selectorScope, getScopeKind(budget_v1.BudgetSpec{Selector: map[string]string{"app": "web"}}))
+	assert.Equal(t, "", getScopeKind(budget_v1.BudgetSpec{}))
+	assert.Equal(t, "", getScopeKind(budget_v1.BudgetSpec{Namespace: "default", Group: "team-a"}))
+}
+
+// TestGetSelectorExpressions ...
+func TestGetSelectorExpressions(t *testing.T) {
+	got := getSelectorExpressions(map[string]string{"app": "web", "tier": "frontend"})
+	expected := map[string]map[string][]string{
+		"app":  {"app": {"web"}},
+		"tier": {"tier": {"frontend"}},
+	}
+	assert.Equal(t, expected, got)
+}
+
+// TestNewAlertPayload ...
+func TestNewAlertPayload(t *testing.T) {
+	budget := getTestBudget()
+	budget.Namespace = "default"
+	now := time.Date(2019, 6, 10, 10, 0, 0, 0, time.UTC)
+	updateStatus(budget, getTestForecast(55, 120), now)
+
+	payload := (*newAlertPayload(budget, budget.Spec.Thresholds[1], now)).(*controller.Payload)
+	assert.Equal(t, "default/team-a", payload.Key)
+	assert.Equal(t, budget_v1.ThresholdReachedEvent, payload.EventType)
+	assert.Equal(t, ResourceType, payload.ResourceType)
+	assert.Contains(t, payload.Data, `"cost":120`)
+	assert.Contains(t, payload.Data, `"basis":"forecast"`)
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consolidation
+
+import (
+	"math"
+	"sort"
+
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// resources are cpu(vCPUs), memory(GB), gpus and number of pods
+type resources struct {
+	cpu    float64
+	memory float64
+	gpu    float64
+	pods   float64
+}
+
+func (r resources) add(other resources) resources {
+	return resources{cpu: r.cpu + other.cpu, memory: r.memory + other.memory, gpu: r.gpu + other.gpu, pods: r.pods + other.pods}
+}
+
+func (r resources) subtract(other resources) resources {
+	return resources{cpu: r.cpu - other.cpu, memory: r.memory - other.memory, gpu: r.gpu - other.gpu, pods: r.pods - other.pods}
+}
+
+func (r resources) fits(other resources) bool {
+	return other.cpu <= r.cpu && other.memory <= r.memory && other.gpu <= r.gpu && other.pods <= r.pods
+}
+
+// nodeType is an instance type which nodes of a pool can be made of, allocatable excludes what the system and
+// node bound pods(ex: daemonsets) of the pool take
+type nodeType struct {
+	instanceType string
+	capacity     resources
+	allocatable  resources
+	hourlyPrice  float64
+}
+
+// podRequests is a pod to be placed along with its requests
+type podRequests struct {
+	pod      *api_v1.Pod
+	requests resources
+}
+
+type simulatedNode struct {
+	nodeType *nodeType
+	free     resources
+	pods     []podRequests
+}
+
+// pool is a set of interchangeable nodes along with pods to place on them and the instance types to choose from
+type pool struct {
+	labels    map[string]string
+	taints    []api_v1.Taint
+	pods      []podRequests
+	nodeTypes []*nodeType
+}
+
+// isCompatible tells whether the pod can be scheduled on nodes of the type in the pool
+func (p *pool) isCompatible(pod podRequests, nodeType *nodeType) bool {
+	return nodeType.allocatable.fits(pod.requests) && toleratesTaints(pod.pod, p.taints) &&
+		matchesNodeSelection(pod.pod, getSimulatedNodeLabels(p.labels, nodeType.instanceType))
+}
+
+func (p *pool) canPlace(pod podRequests, node *simulatedNode) bool {
+	if !node.free.fits(pod.requests) || !p.isCompatible(pod, node.nodeType) {
+		return false
+	}
+	for i := range node.pods {
+		if hasAntiAffinityConflict(pod.pod, node.pods[i].pod) {
+			return false
+		}
+	}
+	return true
+}
+
+// pack places pods of the pool first fit decreasing, a new node is of the preferred type when the pod can run on it
+// and of the cheapest type the pod can run on otherwise. Pods which can't run on any type are returned.
+func (p *pool) pack(preferred *nodeType) ([]*simulatedNode, []podRequests) {
+	pods := append([]podRequests{}, p.pods...)
+	sort.SliceStable(pods, func(i, j int) bool {
+		return getDominantShare(pods[i].requests, preferred) > getDominantShare(pods[j].requests, preferred)
+	})
+
+	nodes := []*simulatedNode{}
+	unplaced := []podRequests{}
+	for _, pod := range pods {
+		if node := p.findNode(pod, nodes); node != nil {
+			node.place(pod)
+			continue
+		}
+		nodeType := preferred
+		if !p.isCompatible(pod, preferred) {
+			nodeType = p.getCheapestType(pod)
+		}
+		if nodeType == nil {
+			unplaced = append(unplaced, pod)
+			continue
+		}
+		node := newSimulatedNode(nodeType)
+		node.place(pod)
+		nodes = append(nodes, node)
+	}
+	return nodes, unplaced
+}
+
+func (p *pool) findNode(pod podRequests, nodes []*simulatedNode) *simulatedNode {
+	for _, node := range nodes {
+		if p.canPlace(pod, node) {
+			return node
+		}
+	}
+	return nil
+}
+
+func (p *pool) getCheapestType(pod podRequests) *nodeType {
+	var cheapest *nodeType
+	for _, nodeType := range p.nodeTypes {
+		if p.isCompatible(pod, nodeType) && (cheapest == nil || nodeType.hourlyPrice < cheapest.hourlyPrice) {
+			cheapest = nodeType
+		}
+	}
+	return cheapest
+}
+
+// getCheapestMix returns the cheapest packing of pods of the pool, nodes of the cheapest packing over a single
+// preferred type are then replaced by cheaper types which fit their pods. Pods which fit no type are returned.
+func (p *pool) getCheapestMix() ([]*simulatedNode, []podRequests) {
+	var cheapest []*simulatedNode
+	unplaced := p.pods
+	cheapestPrice := math.Inf(1)
+	for _, preferred := range p.nodeTypes {
+		nodes, podsLeft := p.pack(preferred)
+		if len(podsLeft) > 0 {
+			unplaced = podsLeft
+			continue
+		}
+		if price := getHourlyPrice(nodes); price < cheapestPrice {
+			cheapest, cheapestPrice = nodes, price
+		}
+	}
+	if cheapest == nil {
+		return nil, unplaced
+	}
+	for i, node := range cheapest {
+		cheapest[i] = p.downsize(node)
+	}
+	return cheapest, nil
+}
+
+// downsize returns a node of the cheapest type which fits all pods of the node
+func (p *pool) downsize(node *simulatedNode) *simulatedNode {
+	best := node
+	for _, nodeType := range p.nodeTypes {
+		if nodeType.hourlyPrice >= best.nodeType.hourlyPrice {
+			continue
+		}
+		candidate := newSimulatedNode(nodeType)
+		fitsAll := true
+		for _, pod := range node.pods {
+			if !p.canPlace(pod, candidate) {
+				fitsAll = false
+				break
+			}
+			candidate.place(pod)
+		}
+		if fitsAll {
+			best = candidate
+		}
+	}
+	return best
+}
+
+func newSimulatedNode(nodeType *nodeType) *simulatedNode {
+	return &simulatedNode{nodeType: nodeType, free: nodeType.allocatable}
+}
+
+func (node *simulatedNode) place(pod podRequests) {
+	node.free = node.free.subtract(pod.requests)
+	node.pods = append(node.pods, pod)
+}
+
+// getDominantShare returns the largest share of allocatable resources of the node type the requests take
+func getDominantShare(requests resources, nodeType *nodeType) float64 {
+	share := 0.0
+	if nodeType.allocatable.cpu > 0 {
+		share = math.Max(share, requests.cpu/nodeType.allocatable.cpu)
+	}
+	if nodeType.allocatable.memory > 0 {
+		share = math.Max(share, requests.memory/nodeType.allocatable.memory)
+	}
+	if nodeType.allocatable.gpu > 0 {
+		share = math.Max(share, requests.gpu/nodeType.allocatable.gpu)
+	}
+	return share
+}
+
+func getHourlyPrice(nodes []*simulatedNode) float64 {
+	price := 0.0
+	for _, node := range nodes {
+		price += node.nodeType.hourlyPrice
+	}
+	return price
+}
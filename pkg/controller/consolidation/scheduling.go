@@ -0,0 +1,242 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consolidation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	api_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Labels telling the role and the host of a node
+const (
+	hostnameLabelKey     = "kubernetes.io/hostname"
+	masterRoleLabelKey   = "node-role.kubernetes.io/master"
+	controlPlaneLabelKey = "node-role.kubernetes.io/control-plane"
+)
+
+// nodeSpecificLabels differ from node to node of a pool, simulated nodes carry their own values of these
+var nodeSpecificLabels = map[string]bool{
+	hostnameLabelKey:                  true,
+	models.InstanceTypeLabelKey:       true,
+	models.StableInstanceTypeLabelKey: true,
+}
+
+// getPoolKey returns the key of the pool of a node, nodes with the same os, scheduling labels and taints are
+// interchangeable for pods running on them
+func getPoolKey(node api_v1.Node, os string) string {
+	return os + "|" + strings.Join(getSchedulingLabels(node), ",") + "|" + strings.Join(getTaints(node), ",")
+}
+
+// getPoolLabels returns labels of the node except those specific to it
+func getPoolLabels(node api_v1.Node) map[string]string {
+	poolLabels := map[string]string{}
+	for key, value := range node.Labels {
+		if !nodeSpecificLabels[key] && value != node.Name {
+			poolLabels[key] = value
+		}
+	}
+	return poolLabels
+}
+
+func getSchedulingLabels(node api_v1.Node) []string {
+	nodeLabels := []string{}
+	for key, value := range getPoolLabels(node) {
+		nodeLabels = append(nodeLabels, key+"="+value)
+	}
+	sort.Strings(nodeLabels)
+	return nodeLabels
+}
+
+func getTaints(node api_v1.Node) []string {
+	taints := []string{}
+	for _, taint := range node.Spec.Taints {
+		taints = append(taints, taint.ToString())
+	}
+	sort.Strings(taints)
+	return taints
+}
+
+// isControlPlaneNode tells whether the node runs the control plane, such nodes are left out of the simulation
+func isControlPlaneNode(node api_v1.Node) bool {
+	_, isMaster := node.Labels[masterRoleLabelKey]
+	_, isControlPlane := node.Labels[controlPlaneLabelKey]
+	return isMaster || isControlPlane
+}
+
+// isNodeBound tells whether the pod runs on every node(daemonset) or belongs to its node(static pods), these pods
+// aren't moved and their requests are reserved on every node of their pool
+func isNodeBound(pod api_v1.Pod) bool {
+	if _, isMirror := pod.Annotations[api_v1.MirrorPodAnnotationKey]; isMirror {
+		return true
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" || owner.Kind == "Node" {
+			return true
+		}
+	}
+	return false
+}
+
+// getSimulatedNodeLabels returns labels of a node of the instance type in the pool
+func getSimulatedNodeLabels(poolLabels map[string]string, instanceType string) map[string]string {
+	nodeLabels := map[string]string{}
+	for key, value := range poolLabels {
+		nodeLabels[key] = value
+	}
+	nodeLabels[models.InstanceTypeLabelKey] = instanceType
+	nodeLabels[models.StableInstanceTypeLabelKey] = instanceType
+	return nodeLabels
+}
+
+// matchesNodeSelection tells whether node selector and required node affinity of the pod select the node labels
+func matchesNodeSelection(pod *api_v1.Pod, nodeLabels map[string]string) bool {
+	if !labels.SelectorFromSet(labels.Set(pod.Spec.NodeSelector)).Matches(labels.Set(nodeLabels)) {
+		return false
+	}
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	// terms are ORed and expressions of a term are ANDed
+	for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		if matchesNodeSelectorTerm(term, nodeLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesNodeSelectorTerm(term api_v1.NodeSelectorTerm, nodeLabels map[string]string) bool {
+	if len(term.MatchExpressions) == 0 {
+		return false
+	}
+	for _, requirement := range term.MatchExpressions {
+		if !matchesNodeSelectorRequirement(requirement, nodeLabels) {
+			return false
+		}
+	}
+	return true
+}
+
+// nolint: gocyclo
+func matchesNodeSelectorRequirement(requirement api_v1.NodeSelectorRequirement, nodeLabels map[string]string) bool {
+	value, isPresent := nodeLabels[requirement.Key]
+	switch requirement.Operator {
+	case api_v1.NodeSelectorOpIn:
+		return isPresent && containsString(requirement.Values, value)
+	case api_v1.NodeSelectorOpNotIn:
+		return !isPresent || !containsString(requirement.Values, value)
+	case api_v1.NodeSelectorOpExists:
+		return isPresent
+	case api_v1.NodeSelectorOpDoesNotExist:
+		return !isPresent
+	case api_v1.NodeSelectorOpGt, api_v1.NodeSelectorOpLt:
+		if !isPresent || len(requirement.Values) != 1 {
+			return false
+		}
+		labelValue, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		requiredValue, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == api_v1.NodeSelectorOpGt {
+			return labelValue > requiredValue
+		}
+		return labelValue < requiredValue
+	}
+	return false
+}
+
+// toleratesTaints tells whether the pod tolerates every taint which keeps pods from scheduling or running on a node
+func toleratesTaints(pod *api_v1.Pod, taints []api_v1.Taint) bool {
+	for i := range taints {
+		if taints[i].Effect == api_v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		isTolerated := false
+		for j := range pod.Spec.Tolerations {
+			if pod.Spec.Tolerations[j].ToleratesTaint(&taints[i]) {
+				isTolerated = true
+				break
+			}
+		}
+		if !isTolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// hasAntiAffinityConflict tells whether required pod anti-affinity of either pod keeps them off the same node
+func hasAntiAffinityConflict(pod, other *api_v1.Pod) bool {
+	return isRepelledBy(pod, other) || isRepelledBy(other, pod)
+}
+
+// isRepelledBy tells whether a required anti-affinity term of pod matches the other pod. Only terms of hostname
+// topology are considered, pods stay in their pool so other topologies(ex: zone) are unchanged by consolidation.
+func isRepelledBy(pod, other *api_v1.Pod) bool {
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return false
+	}
+	for _, term := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey != hostnameLabelKey || term.LabelSelector == nil {
+			continue
+		}
+		namespaces := term.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{pod.Namespace}
+		}
+		if !containsString(namespaces, other.Namespace) {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			log.Debugf("invalid anti-affinity label selector of pod: %s, err: %v", getPodName(pod), err)
+			continue
+		}
+		if selector.Matches(labels.Set(other.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func getPodName(pod *api_v1.Pod) string {
+	return fmt.Sprintf("%s:%s", pod.Namespace, pod.Name)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consolidation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestMatchesNodeSelection ...
+func TestMatchesNodeSelection(t *testing.T) {
+	nodeLabels := map[string]string{"disktype": "ssd", "cores": "8"}
+	pod := &api_v1.Pod{}
+	assert.True(t, matchesNodeSelection(pod, nodeLabels))
+
+	pod.Spec.NodeSelector = map[string]string{"disktype": "hdd"}
+	assert.False(t, matchesNodeSelection(pod, nodeLabels))
+
+	pod.Spec.NodeSelector = map[string]string{"disktype": "ssd"}
+	pod.Spec.Affinity = &api_v1.Affinity{NodeAffinity: &api_v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &api_v1.NodeSelector{NodeSelectorTerms: []api_v1.NodeSelectorTerm{
+			{MatchExpressions: []api_v1.NodeSelectorRequirement{{Key: "gpu", Operator: api_v1.NodeSelectorOpExists}}},
+			{MatchExpressions: []api_v1.NodeSelectorRequirement{
+				{Key: "cores", Operator: api_v1.NodeSelectorOpGt, Values: []string{"4"}},
+				{Key: "zone", Operator: api_v1.NodeSelectorOpNotIn, Values: []string{"us-west-2a"}},
+			}},
+		}},
+	}}
+	assert.True(t, matchesNodeSelection(pod, nodeLabels))
+
+	nodeLabels["cores"] = "2"
+	assert.False(t, matchesNodeSelection(pod, nodeLabels))
+}
+
+// TestToleratesTaints ...
+func TestToleratesTaints(t *testing.T) {
+	taints := []api_v1.Taint{
+		{Key: "dedicated", Value: "db", Effect: api_v1.TaintEffectNoSchedule},
+		{Key: "spot", Effect: api_v1.TaintEffectPreferNoSchedule},
+	}
+	pod := &api_v1.Pod{}
+	assert.False(t, toleratesTaints(pod, taints))
+
+	pod.Spec.Tolerations = []api_v1.Toleration{{Key: "dedicated", Operator: api_v1.TolerationOpExists}}
+	assert.True(t, toleratesTaints(pod, taints))
+}
+
+// TestHasAntiAffinityConflict ...
+func TestHasAntiAffinityConflict(t *testing.T) {
+	web := &api_v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Labels: map[string]string{"app": "web"}}}
+	db := &api_v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Labels: map[string]string{"app": "db"}}}
+	assert.False(t, hasAntiAffinityConflict(web, db))
+
+	db.Spec.Affinity = &api_v1.Affinity{PodAntiAffinity: &api_v1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []api_v1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			TopologyKey:   hostnameLabelKey,
+		}},
+	}}
+	assert.True(t, hasAntiAffinityConflict(web, db))
+
+	// pods in other namespaces aren't repelled
+	web.Namespace = "frontend"
+	assert.False(t, hasAntiAffinityConflict(web, db))
+}
+
+// TestIsNodeBound ...
+func TestIsNodeBound(t *testing.T) {
+	pod := api_v1.Pod{}
+	assert.False(t, isNodeBound(pod))
+
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet"}}
+	assert.True(t, isNodeBound(pod))
+
+	pod.OwnerReferences = nil
+	pod.Annotations = map[string]string{api_v1.MirrorPodAnnotationKey: "hash"}
+	assert.True(t, isNodeBound(pod))
+}
@@ -0,0 +1,411 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consolidation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+	"github.com/vmware/purser/pkg/controller/metrics"
+	"github.com/vmware/purser/pkg/controller/utils"
+	api_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// InstanceTypes is the query parameter which restricts instance types of the rate card to simulate with(ex: m5.large,m5.xlarge)
+const InstanceTypes = "instanceTypes"
+
+const defaultNodeOS = "linux"
+
+// NodeGroup is a number of nodes of an instance type and the hourly price of each of them
+type NodeGroup struct {
+	InstanceType   string  `json:"instanceType"`
+	Count          int     `json:"count"`
+	CPUCapacity    float64 `json:"cpuCapacity"`
+	MemoryCapacity float64 `json:"memoryCapacity"`
+	GPUCapacity    float64 `json:"gpuCapacity,omitempty"`
+	HourlyPrice    float64 `json:"hourlyPrice"`
+}
+
+// PoolSimulation is the current and the cheapest node mix of a pool of nodes sharing os, scheduling labels and taints.
+// Current nodes are kept when some pod fits no instance type or no mix is cheaper.
+type PoolSimulation struct {
+	Labels              []string    `json:"labels"`
+	Taints              []string    `json:"taints,omitempty"`
+	Pods                int         `json:"pods"`
+	CurrentNodes        []NodeGroup `json:"currentNodes"`
+	ProposedNodes       []NodeGroup `json:"proposedNodes"`
+	UnschedulablePods   []string    `json:"unschedulablePods,omitempty"`
+	CurrentMonthlyCost  float64     `json:"currentMonthlyCost"`
+	ProposedMonthlyCost float64     `json:"proposedMonthlyCost"`
+	MonthlySavings      float64     `json:"monthlySavings"`
+}
+
+// Simulation is the result of bin-packing pods of the cluster on the cheapest mix of instance types of each pool
+type Simulation struct {
+	Pools               []PoolSimulation `json:"pools"`
+	CurrentNodes        int              `json:"currentNodes"`
+	ProposedNodes       int              `json:"proposedNodes"`
+	CurrentMonthlyCost  float64          `json:"currentMonthlyCost"`
+	ProposedMonthlyCost float64          `json:"proposedMonthlyCost"`
+	MonthlySavings      float64          `json:"monthlySavings"`
+}
+
+// poolBuilder collects nodes and pods of a pool
+type poolBuilder struct {
+	pool
+	nodes    []api_v1.Node
+	overhead map[string]resources
+	os       string
+	term     string
+}
+
+// Simulate packs requests of scheduled pods on the cheapest mix of instance types of the rate card(or only the given
+// ones) which honors their node selectors, node affinity, tolerations and anti-affinity. Pods of daemonsets and
+// static pods are reserved on every node, control plane nodes are left out.
+func Simulate(kubeClient *kubernetes.Clientset, instanceTypes []string) (Simulation, error) {
+	nodeList := utils.RetrieveNodeList(kubeClient, metav1.ListOptions{})
+	podList := utils.RetrievePodList(kubeClient, metav1.ListOptions{})
+	if nodeList == nil || podList == nil {
+		return Simulation{}, fmt.Errorf("unable to list nodes and pods of the cluster")
+	}
+	liveNodes, err := query.RetrieveLiveNodesPrice()
+	if err != nil {
+		return Simulation{}, err
+	}
+	instanceTypePrices, err := query.RetrieveInstanceTypePrices()
+	if err != nil {
+		return Simulation{}, err
+	}
+	return simulate(nodeList.Items, podList.Items, liveNodes, filterInstanceTypes(instanceTypePrices, instanceTypes)), nil
+}
+
+func simulate(nodes []api_v1.Node, pods []api_v1.Pod, liveNodes []models.Node, instanceTypePrices []models.NodePrice) Simulation {
+	nodesInfo := map[string]models.Node{}
+	for _, node := range liveNodes {
+		nodesInfo[node.Xid] = node
+	}
+	builders := getPoolBuilders(nodes, pods, nodesInfo)
+
+	simulation := Simulation{Pools: []PoolSimulation{}}
+	for _, builder := range builders {
+		builder.nodeTypes = builder.getNodeTypes(nodesInfo, instanceTypePrices)
+		poolSimulation := builder.simulate(nodesInfo)
+		simulation.Pools = append(simulation.Pools, poolSimulation)
+		simulation.CurrentNodes += countNodes(poolSimulation.CurrentNodes)
+		simulation.ProposedNodes += countNodes(poolSimulation.ProposedNodes)
+		simulation.CurrentMonthlyCost += poolSimulation.CurrentMonthlyCost
+		simulation.ProposedMonthlyCost += poolSimulation.ProposedMonthlyCost
+		simulation.MonthlySavings += poolSimulation.MonthlySavings
+	}
+	return simulation
+}
+
+// getPoolBuilders groups nodes into pools and assigns scheduled pods to the pool of their node
+func getPoolBuilders(nodes []api_v1.Node, pods []api_v1.Pod, nodesInfo map[string]models.Node) []*poolBuilder {
+	builders := []*poolBuilder{}
+	poolOfKey := map[string]*poolBuilder{}
+	poolOfNode := map[string]*poolBuilder{}
+	for _, node := range nodes {
+		if isControlPlaneNode(node) {
+			continue
+		}
+		os := getNodeOS(nodesInfo[node.Name])
+		key := getPoolKey(node, os)
+		builder, isPresent := poolOfKey[key]
+		if !isPresent {
+			builder = newPoolBuilder(node, os, nodesInfo[node.Name].PricingTerm)
+			poolOfKey[key] = builder
+			builders = append(builders, builder)
+		}
+		builder.nodes = append(builder.nodes, node)
+		poolOfNode[node.Name] = builder
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		builder, isPresent := poolOfNode[pod.Spec.NodeName]
+		if !isPresent || pod.Status.Phase == api_v1.PodSucceeded || pod.Status.Phase == api_v1.PodFailed {
+			continue
+		}
+		requests := getPodRequests(pod)
+		if isNodeBound(*pod) {
+			builder.overhead[pod.Spec.NodeName] = builder.overhead[pod.Spec.NodeName].add(requests)
+			continue
+		}
+		builder.pods = append(builder.pods, podRequests{pod: pod, requests: requests})
+	}
+	return builders
+}
+
+func newPoolBuilder(node api_v1.Node, os, term string) *poolBuilder {
+	return &poolBuilder{
+		pool: pool{
+			labels: getPoolLabels(node),
+			taints: node.Spec.Taints,
+		},
+		overhead: map[string]resources{},
+		os:       os,
+		term:     term,
+	}
+}
+
+// getNodeTypes returns instance types of current nodes of the pool priced as they are now, along with instance types
+// of the rate card(of the os of the pool) whose shape is known priced by the pricing term of the pool. Allocatable of
+// rate card instance types is taken in the same proportion to capacity as on current nodes.
+func (builder *poolBuilder) getNodeTypes(nodesInfo map[string]models.Node, instanceTypePrices []models.NodePrice) []*nodeType {
+	overhead := builder.getOverhead()
+	nodeTypes := []*nodeType{}
+	isCurrentType := map[string]bool{}
+	var cpuRatio, memoryRatio float64
+	maxPods := 0.0
+	for _, node := range builder.nodes {
+		capacity, allocatable := getNodeResources(node)
+		if capacity.cpu > 0 {
+			cpuRatio += allocatable.cpu / capacity.cpu / float64(len(builder.nodes))
+		}
+		if capacity.memory > 0 {
+			memoryRatio += allocatable.memory / capacity.memory / float64(len(builder.nodes))
+		}
+		if maxPods == 0 || allocatable.pods < maxPods {
+			maxPods = allocatable.pods
+		}
+		instanceType := getNodeInstanceType(node, nodesInfo[node.Name])
+		if isCurrentType[instanceType] {
+			continue
+		}
+		isCurrentType[instanceType] = true
+		nodeTypes = appendNodeType(nodeTypes, &nodeType{
+			instanceType: instanceType,
+			capacity:     capacity,
+			allocatable:  allocatable.subtract(overhead),
+			hourlyPrice:  getNodeHourlyPrice(nodesInfo[node.Name], capacity),
+		})
+	}
+
+	shapes := getInstanceTypeShapes(nodesInfo)
+	for _, nodePrice := range instanceTypePrices {
+		price := getInstanceTypePrice(nodePrice, builder.term)
+		if isCurrentType[nodePrice.InstanceType] || nodePrice.OperatingSystem != builder.os || price <= 0 {
+			continue
+		}
+		capacity := resources{cpu: nodePrice.CPUCapacity, memory: nodePrice.MemoryCapacity, gpu: nodePrice.GPUCount, pods: maxPods}
+		if shape, isPresent := shapes[nodePrice.InstanceType]; isPresent && (capacity.cpu <= 0 || capacity.memory <= 0) {
+			capacity.cpu, capacity.memory = shape.cpu, shape.memory
+		}
+		if capacity.cpu <= 0 || capacity.memory <= 0 {
+			continue
+		}
+		allocatable := resources{cpu: capacity.cpu * cpuRatio, memory: capacity.memory * memoryRatio, gpu: capacity.gpu, pods: maxPods}
+		nodeTypes = appendNodeType(nodeTypes, &nodeType{
+			instanceType: nodePrice.InstanceType,
+			capacity:     capacity,
+			allocatable:  allocatable.subtract(overhead),
+			hourlyPrice:  price,
+		})
+	}
+	return nodeTypes
+}
+
+// appendNodeType appends the node type unless node bound pods take all of it
+func appendNodeType(nodeTypes []*nodeType, nodeType *nodeType) []*nodeType {
+	if nodeType.allocatable.cpu < 0 || nodeType.allocatable.memory < 0 || nodeType.allocatable.gpu < 0 || nodeType.allocatable.pods < 0 {
+		return nodeTypes
+	}
+	return append(nodeTypes, nodeType)
+}
+
+// getOverhead returns the largest requests of node bound pods on a node of the pool
+func (builder *poolBuilder) getOverhead() resources {
+	overhead := resources{}
+	for _, requests := range builder.overhead {
+		overhead.cpu = math.Max(overhead.cpu, requests.cpu)
+		overhead.memory = math.Max(overhead.memory, requests.memory)
+		overhead.gpu = math.Max(overhead.gpu, requests.gpu)
+		overhead.pods = math.Max(overhead.pods, requests.pods)
+	}
+	return overhead
+}
+
+func (builder *poolBuilder) simulate(nodesInfo map[string]models.Node) PoolSimulation {
+	poolSimulation := PoolSimulation{
+		Labels: getSchedulingLabels(builder.nodes[0]),
+		Taints: getTaints(builder.nodes[0]),
+		Pods:   len(builder.pods),
+	}
+	currentNodes := []*simulatedNode{}
+	for _, node := range builder.nodes {
+		capacity, _ := getNodeResources(node)
+		currentNodes = append(currentNodes, newSimulatedNode(&nodeType{
+			instanceType: getNodeInstanceType(node, nodesInfo[node.Name]),
+			capacity:     capacity,
+			hourlyPrice:  getNodeHourlyPrice(nodesInfo[node.Name], capacity),
+		}))
+	}
+	poolSimulation.CurrentNodes = getNodeGroups(currentNodes)
+	poolSimulation.CurrentMonthlyCost = getHourlyPrice(currentNodes) * models.HoursInMonth
+
+	proposedNodes, unplaced := builder.getCheapestMix()
+	for _, pod := range unplaced {
+		poolSimulation.UnschedulablePods = append(poolSimulation.UnschedulablePods, getPodName(pod.pod))
+	}
+	if len(unplaced) > 0 || getHourlyPrice(proposedNodes) >= getHourlyPrice(currentNodes) {
+		proposedNodes = currentNodes
+	}
+	poolSimulation.ProposedNodes = getNodeGroups(proposedNodes)
+	poolSimulation.ProposedMonthlyCost = getHourlyPrice(proposedNodes) * models.HoursInMonth
+	poolSimulation.MonthlySavings = poolSimulation.CurrentMonthlyCost - poolSimulation.ProposedMonthlyCost
+	return poolSimulation
+}
+
+// getNodeGroups returns nodes grouped by instance type, costliest first
+func getNodeGroups(nodes []*simulatedNode) []NodeGroup {
+	groups := []NodeGroup{}
+	indexOfType := map[string]int{}
+	for _, node := range nodes {
+		index, isPresent := indexOfType[node.nodeType.instanceType]
+		if !isPresent {
+			index = len(groups)
+			indexOfType[node.nodeType.instanceType] = index
+			groups = append(groups, NodeGroup{
+				InstanceType:   node.nodeType.instanceType,
+				CPUCapacity:    node.nodeType.capacity.cpu,
+				MemoryCapacity: node.nodeType.capacity.memory,
+				GPUCapacity:    node.nodeType.capacity.gpu,
+				HourlyPrice:    node.nodeType.hourlyPrice,
+			})
+		}
+		groups[index].Count++
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].HourlyPrice*float64(groups[i].Count) > groups[j].HourlyPrice*float64(groups[j].Count)
+	})
+	return groups
+}
+
+func countNodes(groups []NodeGroup) int {
+	count := 0
+	for _, group := range groups {
+		count += group.Count
+	}
+	return count
+}
+
+// getPodRequests returns cpu(vCPUs), memory(GB) and gpus requested by containers of the pod
+func getPodRequests(pod *api_v1.Pod) resources {
+	podMetrics := metrics.CalculatePodStatsFromContainers(pod)
+	return resources{
+		cpu:    utils.ConvertToFloat64CPU(podMetrics.CPURequest),
+		memory: utils.ConvertToFloat64GB(podMetrics.MemoryRequest),
+		gpu:    utils.ConvertToFloat64CPU(podMetrics.GPURequest),
+		pods:   1,
+	}
+}
+
+// getNodeResources returns capacity and allocatable resources of the node
+func getNodeResources(node api_v1.Node) (resources, resources) {
+	capacity := resources{
+		cpu:    utils.ConvertToFloat64CPU(node.Status.Capacity.Cpu()),
+		memory: utils.ConvertToFloat64GB(node.Status.Capacity.Memory()),
+		gpu:    utils.GetGPUCount(node.Status.Capacity),
+		pods:   float64(node.Status.Capacity.Pods().Value()),
+	}
+	allocatable := resources{
+		cpu:    utils.ConvertToFloat64CPU(node.Status.Allocatable.Cpu()),
+		memory: utils.ConvertToFloat64GB(node.Status.Allocatable.Memory()),
+		gpu:    utils.GetGPUCount(node.Status.Allocatable),
+		pods:   float64(node.Status.Allocatable.Pods().Value()),
+	}
+	// allocatable isn't reported by old kubelets
+	if allocatable.cpu <= 0 || allocatable.memory <= 0 || allocatable.pods <= 0 {
+		allocatable = capacity
+	}
+	return capacity, allocatable
+}
+
+// getNodeHourlyPrice returns hourly price of the node's capacity by its per unit resource prices
+func getNodeHourlyPrice(node models.Node, capacity resources) float64 {
+	cpuPrice, memoryPrice := node.CPUPrice, node.MemoryPrice
+	if cpuPrice <= 0 && memoryPrice <= 0 {
+		cpuPrice, memoryPrice = models.DefaultCPUCostInFloat64, models.DefaultMemCostInFloat64
+	}
+	return capacity.cpu*cpuPrice + capacity.memory*memoryPrice + capacity.gpu*node.GPUPrice
+}
+
+// getInstanceTypePrice returns hourly price of the instance type for the pricing term, on-demand price when it has no
+// price for the term
+func getInstanceTypePrice(nodePrice models.NodePrice, term string) float64 {
+	switch {
+	case term == models.ReservedPricingTerm && nodePrice.ReservedPrice > 0:
+		return nodePrice.ReservedPrice
+	case term == models.SpotPricingTerm && nodePrice.SpotPrice > 0:
+		return nodePrice.SpotPrice
+	}
+	return nodePrice.Price
+}
+
+// getInstanceTypeShapes returns capacity of instance types of live nodes, used for instance types whose price list
+// doesn't tell their shape(ex: azure)
+func getInstanceTypeShapes(nodesInfo map[string]models.Node) map[string]resources {
+	shapes := map[string]resources{}
+	for _, node := range nodesInfo {
+		if node.InstanceType != "" && node.CPUCapacity > 0 && node.MemoryCapacity > 0 {
+			shapes[node.InstanceType] = resources{cpu: node.CPUCapacity, memory: node.MemoryCapacity}
+		}
+	}
+	return shapes
+}
+
+func getNodeInstanceType(node api_v1.Node, info models.Node) string {
+	if info.InstanceType != "" {
+		return info.InstanceType
+	}
+	if instanceType, isPresent := node.Labels[models.StableInstanceTypeLabelKey]; isPresent {
+		return instanceType
+	}
+	if instanceType, isPresent := node.Labels[models.InstanceTypeLabelKey]; isPresent {
+		return instanceType
+	}
+	return models.DefaultNodeInstance
+}
+
+func getNodeOS(info models.Node) string {
+	if info.OS == "" || info.OS == models.DefaultNodeOS {
+		return defaultNodeOS
+	}
+	return strings.ToLower(info.OS)
+}
+
+// filterInstanceTypes returns prices of only the given instance types, all of them when none is given
+func filterInstanceTypes(nodePrices []models.NodePrice, instanceTypes []string) []models.NodePrice {
+	if len(instanceTypes) == 0 {
+		return nodePrices
+	}
+	filtered := []models.NodePrice{}
+	for _, nodePrice := range nodePrices {
+		if containsString(instanceTypes, nodePrice.InstanceType) {
+			filtered = append(filtered, nodePrice)
+		}
+	}
+	return filtered
+}
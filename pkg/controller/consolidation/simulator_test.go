@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package consolidation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	api_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestNode(name, instanceType string, cpu, memory string) api_v1.Node {
+	capacity := api_v1.ResourceList{
+		api_v1.ResourceCPU:    resource.MustParse(cpu),
+		api_v1.ResourceMemory: resource.MustParse(memory),
+		api_v1.ResourcePods:   resource.MustParse("110"),
+	}
+	return api_v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				hostnameLabelKey:                         name,
+				models.InstanceTypeLabelKey:              instanceType,
+				"failure-domain.beta.kubernetes.io/zone": "us-west-2a",
+			},
+		},
+		Status: api_v1.NodeStatus{Capacity: capacity, Allocatable: capacity},
+	}
+}
+
+func getTestPod(name, nodeName, cpu, memory string) api_v1.Pod {
+	return api_v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec: api_v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []api_v1.Container{{
+				Name: "web",
+				Resources: api_v1.ResourceRequirements{Requests: api_v1.ResourceList{
+					api_v1.ResourceCPU:    resource.MustParse(cpu),
+					api_v1.ResourceMemory: resource.MustParse(memory),
+				}},
+			}},
+		},
+		Status: api_v1.PodStatus{Phase: api_v1.PodRunning},
+	}
+}
+
+func getTestNodesAndPrices() ([]api_v1.Node, []models.Node, []models.NodePrice) {
+	nodes := []api_v1.Node{
+		getTestNode("node-1", "m5.xlarge", "4", "16Gi"),
+		getTestNode("node-2", "m5.xlarge", "4", "16Gi"),
+		getTestNode("node-3", "m5.xlarge", "4", "16Gi"),
+	}
+	liveNodes := []models.Node{}
+	for _, node := range nodes {
+		liveNodes = append(liveNodes, models.Node{
+			ID:             dgraph.ID{Xid: node.Name},
+			InstanceType:   "m5.xlarge",
+			OS:             "linux",
+			CPUCapacity:    4,
+			MemoryCapacity: 16,
+			CPUPrice:       0.024,
+			MemoryPrice:    0.006,
+		})
+	}
+	instanceTypePrices := []models.NodePrice{
+		{InstanceType: "m5.large", OperatingSystem: "linux", Price: 0.096, CPUCapacity: 2, MemoryCapacity: 8},
+		{InstanceType: "m5.xlarge", OperatingSystem: "linux", Price: 0.192, CPUCapacity: 4, MemoryCapacity: 16},
+		{InstanceType: "m5.large", OperatingSystem: "windows", Price: 0.05, CPUCapacity: 2, MemoryCapacity: 8},
+		{InstanceType: "c5.large", OperatingSystem: "linux", Price: 0.085},
+	}
+	return nodes, liveNodes, instanceTypePrices
+}
+
+// TestSimulateConsolidatesToCheaperType ...
+func TestSimulateConsolidatesToCheaperType(t *testing.T) {
+	nodes, liveNodes, instanceTypePrices := getTestNodesAndPrices()
+	pods := []api_v1.Pod{
+		getTestPod("web-1", "node-1", "500m", "1Gi"),
+		getTestPod("web-2", "node-2", "500m", "1Gi"),
+		getTestPod("web-3", "node-3", "500m", "1Gi"),
+	}
+
+	simulation := simulate(nodes, pods, liveNodes, instanceTypePrices)
+
+	assert.Equal(t, 1, len(simulation.Pools))
+	assert.Equal(t, 3, simulation.Pools[0].Pods)
+	assert.Equal(t, 3, simulation.CurrentNodes)
+	assert.Equal(t, 1, simulation.ProposedNodes)
+	assert.Equal(t, "m5.large", simulation.Pools[0].ProposedNodes[0].InstanceType)
+	assert.InDelta(t, 0.576*models.HoursInMonth, simulation.CurrentMonthlyCost, 1e-9)
+	assert.InDelta(t, 0.096*models.HoursInMonth, simulation.ProposedMonthlyCost, 1e-9)
+	assert.InDelta(t, 0.48*models.HoursInMonth, simulation.MonthlySavings, 1e-9)
+}
+
+// TestSimulateHonorsAntiAffinityAndNodeSelector ...
+func TestSimulateHonorsAntiAffinityAndNodeSelector(t *testing.T) {
+	nodes, liveNodes, instanceTypePrices := getTestNodesAndPrices()
+	antiAffinity := &api_v1.Affinity{PodAntiAffinity: &api_v1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []api_v1.PodAffinityTerm{{
+			LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			TopologyKey:   hostnameLabelKey,
+		}},
+	}}
+	pods := []api_v1.Pod{
+		getTestPod("web-1", "node-1", "500m", "1Gi"),
+		getTestPod("web-2", "node-2", "500m", "1Gi"),
+		getTestPod("batch-1", "node-3", "500m", "1Gi"),
+	}
+	pods[0].Spec.Affinity = antiAffinity
+	pods[1].Spec.Affinity = antiAffinity
+	pods[2].Labels = map[string]string{"app": "batch"}
+	pods[2].Spec.NodeSelector = map[string]string{models.InstanceTypeLabelKey: "m5.xlarge"}
+
+	simulation := simulate(nodes, pods, liveNodes, instanceTypePrices)
+
+	// web pods repel each other and batch pod selects m5.xlarge, it can share a node with a web pod
+	assert.Equal(t, 2, simulation.ProposedNodes)
+	assert.InDelta(t, (0.192+0.096)*models.HoursInMonth, simulation.ProposedMonthlyCost, 1e-9)
+}
+
+// TestSimulateKeepsPoolOfUnschedulablePods ...
+func TestSimulateKeepsPoolOfUnschedulablePods(t *testing.T) {
+	nodes, liveNodes, instanceTypePrices := getTestNodesAndPrices()
+	taint := api_v1.Taint{Key: "dedicated", Value: "gpu", Effect: api_v1.TaintEffectNoSchedule}
+	for i := range nodes {
+		nodes[i].Spec.Taints = []api_v1.Taint{taint}
+	}
+	pods := []api_v1.Pod{getTestPod("web-1", "node-1", "500m", "1Gi")}
+
+	simulation := simulate(nodes, pods, liveNodes, instanceTypePrices)
+
+	assert.Equal(t, []string{"default:web-1"}, simulation.Pools[0].UnschedulablePods)
+	assert.Equal(t, 3, simulation.ProposedNodes)
+	assert.Equal(t, 0.0, simulation.MonthlySavings)
+
+	pods[0].Spec.Tolerations = []api_v1.Toleration{{Key: "dedicated", Operator: api_v1.TolerationOpEqual, Value: "gpu"}}
+	simulation = simulate(nodes, pods, liveNodes, instanceTypePrices)
+	assert.Equal(t, 0, len(simulation.Pools[0].UnschedulablePods))
+	assert.Equal(t, 1, simulation.ProposedNodes)
+}
+
+// TestSimulateReservesNodeBoundPods ...
+func TestSimulateReservesNodeBoundPods(t *testing.T) {
+	nodes, liveNodes, instanceTypePrices := getTestNodesAndPrices()
+	pods := []api_v1.Pod{
+		getTestPod("web-1", "node-1", "1500m", "1Gi"),
+		getTestPod("logging-1", "node-1", "1", "1Gi"),
+	}
+	pods[1].OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "logging"}}
+
+	simulation := simulate(nodes, pods, liveNodes, instanceTypePrices)
+
+	// 1.5 cpus don't fit on m5.large along with the daemonset pod
+	assert.Equal(t, 1, simulation.Pools[0].Pods)
+	assert.Equal(t, "m5.xlarge", simulation.Pools[0].ProposedNodes[0].InstanceType)
+	assert.Equal(t, 1, simulation.ProposedNodes)
+}
+
+// TestFilterInstanceTypes ...
+func TestFilterInstanceTypes(t *testing.T) {
+	_, _, instanceTypePrices := getTestNodesAndPrices()
+	assert.Equal(t, 4, len(filterInstanceTypes(instanceTypePrices, nil)))
+	assert.Equal(t, 2, len(filterInstanceTypes(instanceTypePrices, []string{"m5.large"})))
+}
@@ -28,10 +28,12 @@ import (
 	log "github.com/Sirupsen/logrus"
 
 	groups_v1 "github.com/vmware/purser/pkg/apis/groups/v1"
+	ratecard_v1 "github.com/vmware/purser/pkg/apis/ratecard/v1"
 	subscriber_v1 "github.com/vmware/purser/pkg/apis/subscriber/v1"
 
 	apps_v1beta1 "k8s.io/api/apps/v1beta1"
 	batch_v1 "k8s.io/api/batch/v1"
+	batch_v1beta1 "k8s.io/api/batch/v1beta1"
 	api_v1 "k8s.io/api/core/v1"
 	ext_v1beta1 "k8s.io/api/extensions/v1beta1"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -47,6 +49,15 @@ import (
 // Kubeclient is kubernetes Clientset
 var Kubeclient *kubernetes.Clientset
 
+// updatableResources are the resource types whose update events are processed, jobs are updated to record their
+// runs when they finish and services to price their load balancers once provisioned
+var updatableResources = map[string]bool{
+	"RateCard": true,
+	"Job":      true,
+	"CronJob":  true,
+	"Service":  true,
+}
+
 // Controller holds Kubernetes controller components
 type Controller struct {
 	clientset kubernetes.Interface
@@ -299,6 +310,29 @@ func Start(conf *Config) {
 		go c.Run(stopCh)
 	}
 
+	if conf.Resource.CronJob {
+		informer := cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options meta_v1.ListOptions) (runtime.Object, error) {
+					return Kubeclient.BatchV1beta1().CronJobs(meta_v1.NamespaceAll).List(options)
+				},
+				WatchFunc: func(options meta_v1.ListOptions) (watch.Interface, error) {
+					return Kubeclient.BatchV1beta1().CronJobs(meta_v1.NamespaceAll).Watch(options)
+				},
+			},
+			&batch_v1beta1.CronJob{},
+			0,
+			cache.Indexers{},
+		)
+
+		c := newResourceController(Kubeclient, informer, "CronJob")
+		c.conf = conf
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+
+		go c.Run(stopCh)
+	}
+
 	if conf.Resource.Namespace {
 		informer := cache.NewSharedIndexInformer(
 			&cache.ListWatch{
@@ -368,6 +402,29 @@ func Start(conf *Config) {
 		go c.Run(stopCh)
 	}
 
+	if conf.Resource.RateCard {
+		informer := cache.NewSharedIndexInformer(
+			&cache.ListWatch{
+				ListFunc: func(options meta_v1.ListOptions) (runtime.Object, error) {
+					return conf.RateCardclient.List(options)
+				},
+				WatchFunc: func(options meta_v1.ListOptions) (watch.Interface, error) {
+					return conf.RateCardclient.Watch(options)
+				},
+			},
+			&ratecard_v1.RateCard{},
+			0,
+			cache.Indexers{},
+		)
+
+		c := newResourceController(Kubeclient, informer, "RateCard")
+		c.conf = conf
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+
+		go c.Run(stopCh)
+	}
+
 	sigterm := make(chan os.Signal, 1)
 	signal.Notify(sigterm, syscall.SIGTERM)
 	signal.Notify(sigterm, syscall.SIGINT)
@@ -391,6 +448,16 @@ func newResourceController(client kubernetes.Interface, informer cache.SharedInd
 		},
 		// TODO: Fixme
 		UpdateFunc: func(old, new interface{}) {
+			if updatableResources[resourceType] {
+				newEvent.key, err = cache.MetaNamespaceKeyFunc(new)
+				newEvent.eventType = Update
+				newEvent.resourceType = resourceType
+				newEvent.captureTime = meta_v1.Now()
+				log.Printf("Processing update to %v: %s", resourceType, newEvent.key)
+				if err == nil {
+					queue.Add(newEvent)
+				}
+			}
 			/*newEvent.key, err = cache.MetaNamespaceKeyFunc(old)
 			newEvent.eventType = "update"
 			newEvent.resourceType = resourceType
@@ -488,7 +555,17 @@ func (c *Controller) processItem(newEvent Event) error {
 		c.conf.RingBuffer.Put(payload)
 		return nil
 	case Update:
-		// TODO: Decide on what needs to be propagated.
+		// TODO: Decide on what needs to be propagated for other resources.
+		if !updatableResources[newEvent.resourceType] {
+			return nil
+		}
+		str, err := json.Marshal(obj)
+		if err != nil {
+			log.Errorf("Error marshalling object %s", obj)
+		}
+		payload := &Payload{Key: newEvent.key, EventType: newEvent.eventType, ResourceType: newEvent.resourceType,
+			CloudType: "aws", Data: string(str), CaptureTime: newEvent.captureTime}
+		c.conf.RingBuffer.Put(payload)
 		return nil
 	case Delete:
 		str, err := json.Marshal(newEvent.data)
@@ -101,6 +101,16 @@ func CreateSchema() error {
 		isNodePrice: bool .
 		isStoragePrice: bool .
 		isRateCard: bool .
+		isRateCardHistory: bool .
+		isPriceSegment: bool .
+		isUsageSample: bool .
+		isCostSnapshot: bool .
+		isExtendedResource: bool .
+		isExtendedResourcePrice: bool .
+		isNetworkPrice: bool .
+		isEgressSample: bool .
+		isJobRun: bool .
+		isCostAnomaly: bool .
         isLogin: bool .
 		pod: uid @reverse .
 		namespace: uid @reverse .
@@ -113,7 +123,35 @@ func CreateSchema() error {
 		pv: uid @reverse .
 		daemonset: uid @reverse .
 		job: uid @reverse .
+		cronJob: uid @reverse .
 		label: uid @reverse .
+		priceSegments: uid .
+		usageSamples: uid .
+		extendedResources: uid .
+		extendedResourcePrices: uid .
+		networkPrices: uid .
+		egressSamples: uid .
+		contributors: uid .
+		sampleTime: dateTime @index(hour) .
+		runStartTime: dateTime @index(hour) .
+		runEndTime: dateTime @index(hour) .
+		schedule: string .
+		suspend: bool .
+		jobName: string @index(exact) .
+		succeeded: bool .
+		runHours: float .
+		nodePrice: float .
+		snapshotKind: string @index(exact) .
+		snapshotName: string @index(exact) .
+		snapshotTime: dateTime @index(hour) .
+		anomalyKind: string @index(exact) .
+		anomalyName: string @index(exact) .
+		anomalyTime: dateTime @index(hour) .
+		workloadKind: string .
+		workloadName: string .
+		baselineCost: float .
+		baselineStdDev: float .
+		score: float .
 		key: string @index(term) .
 		value: string @index(term) .
 		cpu: float .
@@ -126,6 +164,28 @@ func CreateSchema() error {
 		memoryLimit: float .
 		memoryCapacity: float .
 		memoryPrice: float .
+		gpu: float .
+		gpuRequest: float .
+		gpuLimit: float .
+		gpuCapacity: float .
+		gpuPrice: float .
+		gpuCount: float .
+		resourceName: string @index(exact) .
+		request: float .
+		limit: float .
+		capacity: float .
+		extendedResourcePrice: float .
+		networkResource: string @index(exact) .
+		pricePerGB: float .
+		serviceType: string @index(exact) .
+		publicIPs: int .
+		trafficClass: string @index(exact) .
+		connections: int .
+		egressGB: float .
+		egressCost: float .
+		cpuUsage: float .
+		memoryUsage: float .
+		usageSampleCount: int .
 		storage: float .
 		storageRequest: float .
 		storageLimit: float .
@@ -137,6 +197,13 @@ func CreateSchema() error {
 		mtdMemory: float .
 		mtdMemoryCost: float .
 		price: float .
+		cpuCost: float .
+		memoryCost: float .
+		storageCost: float .
+		gpuCost: float .
+		extendedResourceCost: float .
+		serviceCost: float .
+		cost: float .
 		podsCount: int .
 	`
 	ctx := context.Background()
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// Dgraph Model Constants
+const (
+	IsCostAnomaly = "isCostAnomaly"
+)
+
+// CostAnomaly is an hour in which cost of a namespace or group rose well above its baseline, the workloads whose cost
+// rose the most are kept as its contributors
+type CostAnomaly struct {
+	dgraph.ID
+	IsCostAnomaly  bool                  `json:"isCostAnomaly,omitempty"`
+	AnomalyKind    string                `json:"anomalyKind,omitempty"`
+	AnomalyName    string                `json:"anomalyName,omitempty"`
+	AnomalyTime    string                `json:"anomalyTime,omitempty"`
+	Cost           float64               `json:"cost,omitempty"`
+	BaselineCost   float64               `json:"baselineCost"`
+	BaselineStdDev float64               `json:"baselineStdDev"`
+	Score          float64               `json:"score,omitempty"`
+	Contributors   []*AnomalyContributor `json:"contributors,omitempty"`
+}
+
+// AnomalyContributor is cost of a workload in the hour of an anomaly along with its baseline
+type AnomalyContributor struct {
+	dgraph.ID
+	WorkloadKind string  `json:"workloadKind,omitempty"`
+	WorkloadName string  `json:"workloadName,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+	BaselineCost float64 `json:"baselineCost"`
+}
+
+// NewCostAnomaly returns anomaly of the resource of given kind in the hour starting at anomalyTime
+func NewCostAnomaly(kind, name string, anomalyTime time.Time) CostAnomaly {
+	timestamp := anomalyTime.Format(time.RFC3339)
+	return CostAnomaly{
+		ID:            dgraph.ID{Xid: "anomaly-" + kind + "-" + name + "-" + timestamp},
+		IsCostAnomaly: true,
+		AnomalyKind:   kind,
+		AnomalyName:   name,
+		AnomalyTime:   timestamp,
+	}
+}
+
+// StoreCostAnomaly creates the anomaly in dgraph, it returns false if the anomaly is already stored
+func StoreCostAnomaly(anomaly CostAnomaly) (bool, error) {
+	if dgraph.GetUID(anomaly.Xid, IsCostAnomaly) != "" {
+		return false, nil
+	}
+	for _, contributor := range anomaly.Contributors {
+		contributor.Xid = anomaly.Xid + "-" + contributor.WorkloadKind + "-" + contributor.WorkloadName
+	}
+	_, err := dgraph.MutateNode(anomaly, dgraph.CREATE)
+	return err == nil, err
+}
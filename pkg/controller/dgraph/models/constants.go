@@ -3,15 +3,21 @@ package models
 // Cost and other cloud constants
 const (
 	// Cost constants
-	DefaultCPUCostPerCPUPerHour    = "0.024"
-	DefaultMemCostPerGBPerHour     = "0.01"
-	DefaultStorageCostPerGBPerHour = "0.00013888888"
-	DefaultCPUCostInFloat64        = 0.024
-	DefaultMemCostInFloat64        = 0.01
-	DefaultStorageCostInFloat64    = 0.00013888888
+	DefaultCPUCostPerCPUPerHour      = "0.024"
+	DefaultMemCostPerGBPerHour       = "0.01"
+	DefaultStorageCostPerGBPerHour   = "0.00013888888"
+	DefaultCPUCostInFloat64          = 0.024
+	DefaultMemCostInFloat64          = 0.01
+	DefaultStorageCostInFloat64      = 0.00013888888
+	DefaultGPUCostInFloat64          = 0.9
+	DefaultLoadBalancerCostInFloat64 = 0.025
 
 	// Cloud provider constants
-	AWS = "aws"
+	AWS     = "aws"
+	GCP     = "gcp"
+	Azure   = "azure"
+	VSphere = "vsphere"
+	OnPrem  = "onprem"
 
 	// Time constants
 	HoursInMonth = 720
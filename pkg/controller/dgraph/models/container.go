@@ -18,6 +18,7 @@
 package models
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -34,6 +35,8 @@ const (
 	IsContainer = "isContainer"
 )
 
+var errContainerNotPersisted = errors.New("container is not persisted yet")
+
 // Container schema in dgraph
 type Container struct {
 	dgraph.ID
@@ -49,6 +52,17 @@ type Container struct {
 	MemoryRequest float64    `json:"memoryRequest,omitempty"`
 	MemoryLimit   float64    `json:"memoryLimit,omitempty"`
 	Type          string     `json:"type,omitempty"`
+
+	// gpus(of every vendor) requested by the container, they are also part of its extended resources
+	GPURequest        float64             `json:"gpuRequest,omitempty"`
+	GPULimit          float64             `json:"gpuLimit,omitempty"`
+	ExtendedResources []*ExtendedResource `json:"extendedResources,omitempty"`
+
+	// average usage of cpu and memory over usage samples
+	CPUUsage         float64        `json:"cpuUsage,omitempty"`
+	MemoryUsage      float64        `json:"memoryUsage,omitempty"`
+	UsageSampleCount int            `json:"usageSampleCount,omitempty"`
+	UsageSamples     []*UsageSample `json:"usageSamples,omitempty"`
 }
 
 func newContainer(container api_v1.Container, podUID, namespaceUID string, pod api_v1.Pod) (*api.Assigned, error) {
@@ -66,7 +80,10 @@ func newContainer(container api_v1.Container, podUID, namespaceUID string, pod a
 		CPULimit:      utils.ConvertToFloat64CPU(limits.Cpu()),
 		MemoryRequest: utils.ConvertToFloat64GB(requests.Memory()),
 		MemoryLimit:   utils.ConvertToFloat64GB(limits.Memory()),
+		GPURequest:    utils.GetGPUCount(requests),
+		GPULimit:      utils.GetGPUCount(limits),
 	}
+	c.ExtendedResources = newContainerExtendedResources(containerXid, requests, limits)
 	if namespaceUID != "" {
 		c.Namespace = &Namespace{ID: dgraph.ID{UID: namespaceUID, Xid: pod.Namespace}}
 	}
@@ -81,6 +98,8 @@ func StoreAndRetrieveContainersAndMetrics(pod api_v1.Pod, podUID, namespaceUID s
 	memoryRequest := &resource.Quantity{}
 	cpuLimit := &resource.Quantity{}
 	memoryLimit := &resource.Quantity{}
+	gpuRequest, gpuLimit := 0.0, 0.0
+	extendedResourceRequests := make(map[string]float64)
 
 	for _, c := range pod.Spec.Containers {
 		container, err := storeContainerIfNotExist(c, pod, podUID, namespaceUID)
@@ -93,12 +112,20 @@ func StoreAndRetrieveContainersAndMetrics(pod api_v1.Pod, podUID, namespaceUID s
 		utils.AddResourceAToResourceB(requests.Memory(), memoryRequest)
 		utils.AddResourceAToResourceB(limits.Cpu(), cpuLimit)
 		utils.AddResourceAToResourceB(limits.Memory(), memoryLimit)
+		gpuRequest += utils.GetGPUCount(requests)
+		gpuLimit += utils.GetGPUCount(limits)
+		for name, quantity := range utils.GetExtendedResources(requests) {
+			extendedResourceRequests[name] += quantity
+		}
 	}
 	return containers, Metrics{
-		CPURequest:    utils.ConvertToFloat64CPU(cpuRequest),
-		CPULimit:      utils.ConvertToFloat64CPU(cpuLimit),
-		MemoryRequest: utils.ConvertToFloat64GB(memoryRequest),
-		MemoryLimit:   utils.ConvertToFloat64GB(memoryLimit),
+		CPURequest:               utils.ConvertToFloat64CPU(cpuRequest),
+		CPULimit:                 utils.ConvertToFloat64CPU(cpuLimit),
+		MemoryRequest:            utils.ConvertToFloat64GB(memoryRequest),
+		MemoryLimit:              utils.ConvertToFloat64GB(memoryLimit),
+		GPURequest:               gpuRequest,
+		GPULimit:                 gpuLimit,
+		ExtendedResourceRequests: extendedResourceRequests,
 	}
 }
 
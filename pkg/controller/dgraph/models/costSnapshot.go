@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// Dgraph Model Constants
+const (
+	IsCostSnapshot = "isCostSnapshot"
+)
+
+// CostSnapshot is allocation(cpu, memory, storage and gpus) and cost of a namespace, workload, node or group over an hour.
+// Snapshots aren't derived from start and end times of pods, so they outlive the purging of deleted pods.
+type CostSnapshot struct {
+	dgraph.ID
+	IsCostSnapshot bool    `json:"isCostSnapshot,omitempty"`
+	SnapshotKind   string  `json:"snapshotKind,omitempty"`
+	SnapshotName   string  `json:"snapshotName,omitempty"`
+	SnapshotTime   string  `json:"snapshotTime,omitempty"`
+	CPU            float64 `json:"cpu,omitempty"`
+	Memory         float64 `json:"memory,omitempty"`
+	Storage        float64 `json:"storage,omitempty"`
+	CPUCost        float64 `json:"cpuCost,omitempty"`
+	MemoryCost     float64 `json:"memoryCost,omitempty"`
+	StorageCost    float64 `json:"storageCost,omitempty"`
+	GPU            float64 `json:"gpu,omitempty"`
+	GPUCost        float64 `json:"gpuCost,omitempty"`
+	Cost           float64 `json:"cost,omitempty"`
+
+	ExtendedResourceCost float64 `json:"extendedResourceCost,omitempty"`
+	ServiceCost          float64 `json:"serviceCost,omitempty"`
+}
+
+// NewCostSnapshot returns snapshot of the resource of given kind for the hour starting at snapshotTime
+func NewCostSnapshot(kind, name string, snapshotTime time.Time) CostSnapshot {
+	timestamp := snapshotTime.Format(time.RFC3339)
+	return CostSnapshot{
+		ID:             dgraph.ID{Xid: "snapshot-" + kind + "-" + name + "-" + timestamp},
+		IsCostSnapshot: true,
+		SnapshotKind:   kind,
+		SnapshotName:   name,
+		SnapshotTime:   timestamp,
+	}
+}
+
+// StoreCostSnapshots creates the snapshots in dgraph, snapshots taken earlier for the same hour are updated
+func StoreCostSnapshots(snapshots []CostSnapshot) {
+	for _, snapshot := range snapshots {
+		snapshot.Cost = snapshot.CPUCost + snapshot.MemoryCost + snapshot.StorageCost + snapshot.GPUCost +
+			snapshot.ExtendedResourceCost + snapshot.ServiceCost
+		snapshot.UID = dgraph.GetUID(snapshot.Xid, IsCostSnapshot)
+		_, err := dgraph.MutateNode(snapshot, dgraph.CREATE)
+		if err != nil {
+			log.Errorf("unable to store cost snapshot: %s, err: %v", snapshot.Xid, err)
+		}
+	}
+}
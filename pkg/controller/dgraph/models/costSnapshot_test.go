@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// TestNewCostSnapshot ...
+func TestNewCostSnapshot(t *testing.T) {
+	snapshotTime := time.Date(2019, 6, 4, 10, 0, 0, 0, time.UTC)
+	got := NewCostSnapshot("namespace", "default", snapshotTime)
+	expected := CostSnapshot{
+		ID:             dgraph.ID{Xid: "snapshot-namespace-default-2019-06-04T10:00:00Z"},
+		IsCostSnapshot: true,
+		SnapshotKind:   "namespace",
+		SnapshotName:   "default",
+		SnapshotTime:   "2019-06-04T10:00:00Z",
+	}
+	assert.Equal(t, expected, got)
+}
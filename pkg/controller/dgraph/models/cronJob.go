@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	batch_v1beta1 "k8s.io/api/batch/v1beta1"
+)
+
+// Dgraph Model Constants
+const (
+	IsCronJob = "isCronJob"
+)
+
+// CronJob schema in dgraph
+type CronJob struct {
+	dgraph.ID
+	IsCronJob bool       `json:"isCronJob,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	StartTime string     `json:"startTime,omitempty"`
+	EndTime   string     `json:"endTime,omitempty"`
+	Namespace *Namespace `json:"namespace,omitempty"`
+	Type      string     `json:"type,omitempty"`
+	Schedule  string     `json:"schedule,omitempty"`
+	Suspend   bool       `json:"suspend"`
+}
+
+func createCronJobObject(cronJob batch_v1beta1.CronJob) CronJob {
+	newCronJob := CronJob{
+		Name:      "cronjob-" + cronJob.Name,
+		IsCronJob: true,
+		Type:      "cronjob",
+		ID:        dgraph.ID{Xid: cronJob.Namespace + ":" + cronJob.Name},
+		StartTime: cronJob.GetCreationTimestamp().Time.Format(time.RFC3339),
+		Schedule:  cronJob.Spec.Schedule,
+		Suspend:   cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend,
+	}
+	namespaceUID := CreateOrGetNamespaceByID(cronJob.Namespace)
+	if namespaceUID != "" {
+		newCronJob.Namespace = &Namespace{ID: dgraph.ID{UID: namespaceUID, Xid: cronJob.Namespace}}
+	}
+	cronJobDeletionTimestamp := cronJob.GetDeletionTimestamp()
+	if !cronJobDeletionTimestamp.IsZero() {
+		newCronJob.EndTime = cronJobDeletionTimestamp.Time.Format(time.RFC3339)
+		newCronJob.Xid += newCronJob.EndTime
+		newCronJob.Name += "*" + newCronJob.EndTime
+	}
+	return newCronJob
+}
+
+// StoreCronJob create a new cronjob in the Dgraph and updates if already present.
+func StoreCronJob(cronJob batch_v1beta1.CronJob) (string, error) {
+	xid := cronJob.Namespace + ":" + cronJob.Name
+	uid := dgraph.GetUID(xid, IsCronJob)
+
+	newCronJob := createCronJobObject(cronJob)
+	if uid != "" {
+		newCronJob.UID = uid
+	}
+	assigned, err := dgraph.MutateNode(newCronJob, dgraph.CREATE)
+	if err != nil {
+		return "", err
+	}
+	return assigned.Uids["blank-0"], nil
+}
+
+// CreateOrGetCronJobByID returns the uid of cronjob if exists,
+// otherwise creates the cronjob and returns uid.
+func CreateOrGetCronJobByID(xid string) string {
+	if xid == "" {
+		return ""
+	}
+	uid := dgraph.GetUID(xid, IsCronJob)
+
+	if uid != "" {
+		return uid
+	}
+
+	c := CronJob{
+		ID:        dgraph.ID{Xid: xid},
+		Name:      xid,
+		IsCronJob: true,
+	}
+	assigned, err := dgraph.MutateNode(c, dgraph.CREATE)
+	if err != nil {
+		log.Fatal(err)
+		return ""
+	}
+	return assigned.Uids["blank-0"]
+}
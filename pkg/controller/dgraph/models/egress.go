@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// Dgraph Model Constants
+const (
+	IsEgressSample = "isEgressSample"
+
+	// Classes of traffic sent by pods, traffic of a class is priced by pricePerGB of the network price of the same
+	// resource(ex: networkPrices: [{resource: crossZone, pricePerGB: 0.01}])
+	IntraNodeTraffic   = "intraNode"
+	IntraZoneTraffic   = "intraZone"
+	CrossZoneTraffic   = "crossZone"
+	CrossRegionTraffic = "crossRegion"
+	InternetTraffic    = "internet"
+
+	bytesInGB = 1024 * 1024 * 1024
+)
+
+// EgressSample is traffic of a class sent by a pod since the previous discovery of interactions, EgressGB is estimated
+// from socket statistics of the pod and is zero when they aren't available. EgressCost is priced at sampling time.
+type EgressSample struct {
+	dgraph.ID
+	IsEgressSample bool    `json:"isEgressSample,omitempty"`
+	SampleTime     string  `json:"sampleTime,omitempty"`
+	TrafficClass   string  `json:"trafficClass,omitempty"`
+	Connections    int     `json:"connections,omitempty"`
+	EgressGB       float64 `json:"egressGB,omitempty"`
+	EgressCost     float64 `json:"egressCost,omitempty"`
+}
+
+// PodTraffic is traffic of a class sent by a pod over its connections
+type PodTraffic struct {
+	TrafficClass string
+	Connections  int
+	Bytes        float64
+}
+
+// StorePodsEgress stores egress samples of pods(mapped by their xid) taken at sampleTime
+func StorePodsEgress(podsTraffic map[string][]PodTraffic, sampleTime time.Time) {
+	networkPrices, err := retrieveNetworkPrices()
+	if err != nil {
+		log.Errorf("unable to retrieve network prices, egress is stored without cost: %v", err)
+	}
+	for podXID, traffic := range podsTraffic {
+		uid := dgraph.GetUID(podXID, IsPod)
+		if uid == "" {
+			log.Debugf("unable to store egress of pod: %s, pod is not persisted yet", podXID)
+			continue
+		}
+		pod := Pod{
+			ID:            dgraph.ID{UID: uid, Xid: podXID},
+			EgressSamples: newEgressSamples(podXID, traffic, networkPrices, sampleTime),
+		}
+		_, err = dgraph.MutateNode(pod, dgraph.UPDATE)
+		if err != nil {
+			log.Errorf("unable to store egress of pod: %s, err: %v", podXID, err)
+		}
+	}
+}
+
+func newEgressSamples(podXID string, traffic []PodTraffic, networkPrices []NetworkPrice, sampleTime time.Time) []*EgressSample {
+	timestamp := sampleTime.Format(time.RFC3339)
+	var samples []*EgressSample
+	for _, classTraffic := range traffic {
+		egressGB := classTraffic.Bytes / bytesInGB
+		samples = append(samples, &EgressSample{
+			ID:             dgraph.ID{Xid: podXID + "-egress-" + classTraffic.TrafficClass + "-" + timestamp},
+			IsEgressSample: true,
+			SampleTime:     timestamp,
+			TrafficClass:   classTraffic.TrafficClass,
+			Connections:    classTraffic.Connections,
+			EgressGB:       egressGB,
+			EgressCost:     egressGB * getEgressPricePerGB(networkPrices, classTraffic.TrafficClass),
+		})
+	}
+	return samples
+}
+
+// getEgressPricePerGB returns price per GB of the traffic class, traffic of classes without a price costs nothing
+func getEgressPricePerGB(networkPrices []NetworkPrice, trafficClass string) float64 {
+	for _, networkPrice := range networkPrices {
+		if networkPrice.NetworkResource == trafficClass {
+			return networkPrice.PricePerGB
+		}
+	}
+	return 0
+}
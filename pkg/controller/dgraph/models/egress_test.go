@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewEgressSamples ...
+func TestNewEgressSamples(t *testing.T) {
+	networkPrices := []NetworkPrice{
+		{NetworkResource: CrossZoneTraffic, PricePerGB: 0.01},
+		{NetworkResource: InternetTraffic, PricePerGB: 0.09},
+	}
+	traffic := []PodTraffic{
+		{TrafficClass: CrossZoneTraffic, Connections: 2, Bytes: 2 * bytesInGB},
+		{TrafficClass: IntraNodeTraffic, Connections: 1, Bytes: bytesInGB},
+		{TrafficClass: InternetTraffic, Connections: 3},
+	}
+	sampleTime := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	samples := newEgressSamples("default:web", traffic, networkPrices, sampleTime)
+
+	assert.Len(t, samples, 3)
+	assert.Equal(t, "default:web-egress-crossZone-2019-06-01T10:00:00Z", samples[0].Xid)
+	assert.Equal(t, "2019-06-01T10:00:00Z", samples[0].SampleTime)
+	assert.Equal(t, 2, samples[0].Connections)
+	assert.Equal(t, 2.0, samples[0].EgressGB)
+	assert.InDelta(t, 0.02, samples[0].EgressCost, 1e-12)
+
+	// traffic of classes without a price costs nothing
+	assert.Equal(t, 1.0, samples[1].EgressGB)
+	assert.Equal(t, 0.0, samples[1].EgressCost)
+
+	// connections without socket statistics are counted but not sized
+	assert.Equal(t, 3, samples[2].Connections)
+	assert.Equal(t, 0.0, samples[2].EgressCost)
+}
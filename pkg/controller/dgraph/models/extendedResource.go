@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/metrics"
+	"github.com/vmware/purser/pkg/controller/utils"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// Dgraph Model Constants
+const (
+	IsExtendedResource      = "isExtendedResource"
+	IsExtendedResourcePrice = "isExtendedResourcePrice"
+)
+
+// ExtendedResource is an extended resource(ex: nvidia.com/gpu) requested by a container or advertised by a node,
+// Request and Limit are set for containers and Capacity for nodes
+type ExtendedResource struct {
+	dgraph.ID
+	IsExtendedResource bool    `json:"isExtendedResource,omitempty"`
+	ResourceName       string  `json:"resourceName,omitempty"`
+	Request            float64 `json:"request,omitempty"`
+	Limit              float64 `json:"limit,omitempty"`
+	Capacity           float64 `json:"capacity,omitempty"`
+}
+
+// ExtendedResourcePrice structure
+// Unit of Extended Resource Price should be USD($)-(per unit of the resource)-(per Hour)
+type ExtendedResourcePrice struct {
+	dgraph.ID
+	IsExtendedResourcePrice bool    `json:"isExtendedResourcePrice,omitempty"`
+	ResourceName            string  `json:"resourceName,omitempty"`
+	Price                   float64 `json:"price,omitempty"`
+}
+
+// newContainerExtendedResources returns extended resources requested or limited by a container
+func newContainerExtendedResources(containerXid string, requests, limits api_v1.ResourceList) []*ExtendedResource {
+	requested := utils.GetExtendedResources(requests)
+	limited := utils.GetExtendedResources(limits)
+	var names []string
+	for name := range requested {
+		names = append(names, name)
+	}
+	for name := range limited {
+		if _, isPresent := requested[name]; !isPresent {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var extendedResources []*ExtendedResource
+	for _, name := range names {
+		extendedResources = append(extendedResources, &ExtendedResource{
+			ID:                 dgraph.ID{Xid: containerXid + ":" + name},
+			IsExtendedResource: true,
+			ResourceName:       name,
+			Request:            requested[name],
+			Limit:              limited[name],
+		})
+	}
+	return extendedResources
+}
+
+// getNodeExtendedResources returns extended resources advertised by a node, already persisted ones keep their uid
+func getNodeExtendedResources(nodeXid string, capacity api_v1.ResourceList) []*ExtendedResource {
+	var extendedResources []*ExtendedResource
+	for name, quantity := range utils.GetExtendedResources(capacity) {
+		xid := nodeXid + ":" + name
+		extendedResources = append(extendedResources, &ExtendedResource{
+			ID:                 dgraph.ID{Xid: xid, UID: dgraph.GetUID(xid, IsExtendedResource)},
+			IsExtendedResource: true,
+			ResourceName:       name,
+			Capacity:           quantity,
+		})
+	}
+	return extendedResources
+}
+
+// StoreExtendedResourcePrices stores(create/update) every extendedResourcePrice in dgraph using its XID, returns the
+// stored ones
+func StoreExtendedResourcePrices(extendedResourcePrices []*ExtendedResourcePrice) []*ExtendedResourcePrice {
+	var storedPrices []*ExtendedResourcePrice
+	for _, extendedResourcePrice := range extendedResourcePrices {
+		uid := dgraph.GetUID(extendedResourcePrice.Xid, IsExtendedResourcePrice)
+		if uid != "" {
+			extendedResourcePrice.UID = uid
+		}
+		assigned, err := dgraph.MutateNode(extendedResourcePrice, dgraph.CREATE)
+		if err != nil {
+			logrus.Errorf("Unable to store extendedResourcePrice: (%v), reason: %v", extendedResourcePrice, err)
+			continue
+		}
+		if uid == "" {
+			extendedResourcePrice.UID = assigned.Uids["blank-0"]
+		}
+		storedPrices = append(storedPrices, extendedResourcePrice)
+	}
+	return storedPrices
+}
+
+// DeleteExtendedResourcePrices deletes prices of extended resources from dgraph
+func DeleteExtendedResourcePrices() {
+	extendedResourcePrices, err := retrieveExtendedResourcePrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve extended resource prices: %v", err)
+		return
+	}
+	var stalePrices []ExtendedResourcePrice
+	for _, extendedResourcePrice := range extendedResourcePrices {
+		stalePrices = append(stalePrices, ExtendedResourcePrice{ID: dgraph.ID{UID: extendedResourcePrice.UID}})
+	}
+	if len(stalePrices) > 0 {
+		_, err = dgraph.MutateNode(stalePrices, dgraph.DELETE)
+		if err != nil {
+			logrus.Errorf("unable to delete extended resource prices: %v", err)
+		}
+	}
+}
+
+// retrieveExtendedResourcePrices returns prices of extended resources of the rate card
+func retrieveExtendedResourcePrices() ([]ExtendedResourcePrice, error) {
+	query := `query {
+		extendedResourcePrices(func: has(isExtendedResourcePrice)) {
+			uid
+			resourceName
+			price
+        }
+    }`
+	type root struct {
+		ExtendedResourcePrices []ExtendedResourcePrice `json:"extendedResourcePrices"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.ExtendedResourcePrices, nil
+}
+
+// getExtendedResourcePriceForRequests returns hourly price of extended resources requested by a pod, gpus are priced
+// by the gpu price of the pod's node and extended resources without a price cost nothing
+func getExtendedResourcePriceForRequests(requests map[string]float64) float64 {
+	if len(requests) == 0 {
+		return 0
+	}
+	extendedResourcePrices, err := retrieveExtendedResourcePrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve extended resource prices: %v", err)
+		return 0
+	}
+	return computeExtendedResourcePrice(requests, extendedResourcePrices)
+}
+
+func computeExtendedResourcePrice(requests map[string]float64, extendedResourcePrices []ExtendedResourcePrice) float64 {
+	price := 0.0
+	for _, extendedResourcePrice := range extendedResourcePrices {
+		if metrics.IsGPUResource(api_v1.ResourceName(extendedResourcePrice.ResourceName)) {
+			continue
+		}
+		price += requests[extendedResourcePrice.ResourceName] * extendedResourcePrice.Price
+	}
+	return price
+}
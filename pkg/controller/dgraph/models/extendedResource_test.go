@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// TestNewContainerExtendedResources ...
+func TestNewContainerExtendedResources(t *testing.T) {
+	requests := api_v1.ResourceList{
+		api_v1.ResourceCPU: resource.MustParse("2"),
+		"nvidia.com/gpu":   resource.MustParse("1"),
+	}
+	limits := api_v1.ResourceList{
+		"nvidia.com/gpu":              resource.MustParse("2"),
+		"xilinx.com/fpga-xilinx_u200": resource.MustParse("1"),
+	}
+
+	extendedResources := newContainerExtendedResources("default:trainer:worker", requests, limits)
+	assert.Len(t, extendedResources, 2)
+	assert.Equal(t, "default:trainer:worker:nvidia.com/gpu", extendedResources[0].Xid)
+	assert.Equal(t, "nvidia.com/gpu", extendedResources[0].ResourceName)
+	assert.Equal(t, 1.0, extendedResources[0].Request)
+	assert.Equal(t, 2.0, extendedResources[0].Limit)
+	assert.Equal(t, "xilinx.com/fpga-xilinx_u200", extendedResources[1].ResourceName)
+	assert.Equal(t, 0.0, extendedResources[1].Request)
+	assert.Equal(t, 1.0, extendedResources[1].Limit)
+}
+
+// TestComputeExtendedResourcePrice ...
+func TestComputeExtendedResourcePrice(t *testing.T) {
+	extendedResourcePrices := []ExtendedResourcePrice{
+		{ResourceName: "xilinx.com/fpga-xilinx_u200", Price: 0.5},
+		{ResourceName: "example.com/dongle", Price: 0.1},
+		// gpus are priced by their nodes
+		{ResourceName: "nvidia.com/gpu", Price: 2},
+	}
+	requests := map[string]float64{
+		"xilinx.com/fpga-xilinx_u200": 2,
+		"nvidia.com/gpu":              1,
+		"example.com/unpriced":        3,
+	}
+	assert.InDelta(t, 1.0, computeExtendedResourcePrice(requests, extendedResourcePrices), 1e-9)
+	assert.Equal(t, 0.0, computeExtendedResourcePrice(nil, extendedResourcePrices))
+}
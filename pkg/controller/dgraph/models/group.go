@@ -47,6 +47,10 @@ type Group struct {
 	MtdMemoryCost            float64 `json:"mtdMemoryCost,omitempty"`
 	MtdStorageCost           float64 `json:"mtdStorageCost,omitempty"`
 	MtdCost                  float64 `json:"mtdCost,omitempty"`
+	MtdIdleCPUCost           float64 `json:"mtdIdleCPUCost,omitempty"`
+	MtdIdleMemoryCost        float64 `json:"mtdIdleMemoryCost,omitempty"`
+	MtdIdleCost              float64 `json:"mtdIdleCost,omitempty"`
+	MtdSharedCost            float64 `json:"mtdSharedCost,omitempty"`
 	ProjectedCPUCost         float64 `json:"projectedCPUCost,omitempty"`
 	ProjectedMemoryCost      float64 `json:"projectedMemoryCost,omitempty"`
 	ProjectedStorageCost     float64 `json:"projectedStorageCost,omitempty"`
@@ -95,6 +99,11 @@ func CreateOrUpdateGroup(group *groups_v1.Group, podsCount int) (*api.Assigned,
 		LastLastMonthStorageCost:       group.Spec.LastLastMonthCost.StorageCost,
 		LastLastMonthCost:              group.Spec.LastLastMonthCost.TotalCost,
 	}
+	if group.Spec.MTDIdleCost != nil {
+		grp.MtdIdleCPUCost = group.Spec.MTDIdleCost.CPUCost
+		grp.MtdIdleMemoryCost = group.Spec.MTDIdleCost.MemoryCost
+		grp.MtdIdleCost = group.Spec.MTDIdleCost.TotalCost
+	}
 	if uid != "" {
 		grp.ID = dgraph.ID{Xid: xid, UID: uid}
 	}
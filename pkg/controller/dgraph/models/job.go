@@ -40,6 +40,7 @@ type Job struct {
 	Namespace *Namespace `json:"namespace,omitempty"`
 	Pods      []*Pod     `json:"pod,omitempty"`
 	Type      string     `json:"type,omitempty"`
+	CronJob   *CronJob   `json:"cronJob,omitempty"`
 }
 
 func createJobObject(job batch_v1.Job) Job {
@@ -54,6 +55,7 @@ func createJobObject(job batch_v1.Job) Job {
 	if namespaceUID != "" {
 		newJob.Namespace = &Namespace{ID: dgraph.ID{UID: namespaceUID, Xid: job.Namespace}}
 	}
+	newJob.CronJob = getJobCronJob(job)
 	jobDeletionTimestamp := job.GetDeletionTimestamp()
 	if !jobDeletionTimestamp.IsZero() {
 		newJob.EndTime = jobDeletionTimestamp.Time.Format(time.RFC3339)
@@ -63,7 +65,8 @@ func createJobObject(job batch_v1.Job) Job {
 	return newJob
 }
 
-// StoreJob create a new daemonset in the Dgraph and updates if already present.
+// StoreJob create a new daemonset in the Dgraph and updates if already present. Run of a finished job is recorded
+// along with its cost.
 func StoreJob(job batch_v1.Job) (string, error) {
 	xid := job.Namespace + ":" + job.Name
 	uid := dgraph.GetUID(xid, IsJob)
@@ -76,9 +79,28 @@ func StoreJob(job batch_v1.Job) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if uid == "" {
+		uid = assigned.Uids["blank-0"]
+	}
+	storeJobRun(job, uid, newJob.CronJob)
 	return assigned.Uids["blank-0"], nil
 }
 
+// getJobCronJob returns the cronjob which created the job, nil if it wasn't created by a cronjob
+func getJobCronJob(job batch_v1.Job) *CronJob {
+	for _, owner := range job.GetObjectMeta().GetOwnerReferences() {
+		if owner.Kind != "CronJob" {
+			continue
+		}
+		ownerXID := job.Namespace + ":" + owner.Name
+		cronJobUID := CreateOrGetCronJobByID(ownerXID)
+		if cronJobUID != "" {
+			return &CronJob{ID: dgraph.ID{UID: cronJobUID, Xid: ownerXID}}
+		}
+	}
+	return nil
+}
+
 // CreateOrGetJobByID returns the uid of namespace if exists,
 // otherwise creates the job and returns uid.
 func CreateOrGetJobByID(xid string) string {
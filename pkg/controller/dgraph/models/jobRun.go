@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	batch_v1 "k8s.io/api/batch/v1"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// Dgraph Model Constants
+const (
+	IsJobRun = "isJobRun"
+)
+
+// JobRun is a finished execution of a job along with its cost, it outlives the job and its pods so that runs of
+// cronjobs can be costed after they are purged. CPU, Memory and GPU are requests of the job's pods.
+type JobRun struct {
+	dgraph.ID
+	IsJobRun     bool       `json:"isJobRun,omitempty"`
+	JobName      string     `json:"jobName,omitempty"`
+	Namespace    *Namespace `json:"namespace,omitempty"`
+	CronJob      *CronJob   `json:"cronJob,omitempty"`
+	RunStartTime string     `json:"runStartTime,omitempty"`
+	RunEndTime   string     `json:"runEndTime,omitempty"`
+	Succeeded    bool       `json:"succeeded"`
+	RunHours     float64    `json:"runHours,omitempty"`
+	CPU          float64    `json:"cpu,omitempty"`
+	Memory       float64    `json:"memory,omitempty"`
+	GPU          float64    `json:"gpu,omitempty"`
+	NodePrice    float64    `json:"nodePrice,omitempty"`
+	CPUCost      float64    `json:"cpuCost,omitempty"`
+	MemoryCost   float64    `json:"memoryCost,omitempty"`
+	GPUCost      float64    `json:"gpuCost,omitempty"`
+	Cost         float64    `json:"cost,omitempty"`
+}
+
+// storeJobRun records the run of the job once it has finished, runs are recorded only once
+func storeJobRun(job batch_v1.Job, jobUID string, cronJob *CronJob) {
+	runEnd, succeeded, isFinished := getJobRunEnd(job)
+	if !isFinished {
+		return
+	}
+	jobXID := job.Namespace + ":" + job.Name
+	xid := "jobrun-" + jobXID + "-" + job.GetCreationTimestamp().Time.Format(time.RFC3339)
+	if dgraph.GetUID(xid, IsJobRun) != "" {
+		return
+	}
+
+	pods, err := retrieveJobPods(jobUID)
+	if err != nil {
+		log.Errorf("unable to retrieve pods of job: %s, err: %v", jobXID, err)
+		return
+	}
+	runStart := job.GetCreationTimestamp().Time
+	if job.Status.StartTime != nil {
+		runStart = job.Status.StartTime.Time
+	}
+	run := newJobRun(jobXID, pods, runStart, runEnd, succeeded)
+	run.ID = dgraph.ID{Xid: xid}
+	run.CronJob = cronJob
+	namespaceUID := CreateOrGetNamespaceByID(job.Namespace)
+	if namespaceUID != "" {
+		run.Namespace = &Namespace{ID: dgraph.ID{UID: namespaceUID, Xid: job.Namespace}}
+	}
+	_, err = dgraph.MutateNode(run, dgraph.CREATE)
+	if err != nil {
+		log.Errorf("unable to store run of job: %s, err: %v", jobXID, err)
+	}
+}
+
+// getJobRunEnd returns the time at which the job completed or failed, false if it is still running
+func getJobRunEnd(job batch_v1.Job) (time.Time, bool, bool) {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.Time, true, true
+	}
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batch_v1.JobFailed && condition.Status == api_v1.ConditionTrue {
+			return condition.LastTransitionTime.Time, false, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// newJobRun returns run of the job costed by requests and prices of its pods, a pod is costed from its start till
+// its end or the end of the run whichever is earlier. NodePrice is the average hourly price of nodes running the pods.
+func newJobRun(jobXID string, pods []Pod, runStart, runEnd time.Time, succeeded bool) JobRun {
+	run := JobRun{
+		IsJobRun:     true,
+		JobName:      jobXID,
+		Succeeded:    succeeded,
+		RunStartTime: runStart.Format(time.RFC3339),
+		RunEndTime:   runEnd.Format(time.RFC3339),
+		RunHours:     getHours(runStart, runEnd),
+	}
+	nodesCount := 0
+	for _, pod := range pods {
+		hours := getHours(getPodRunStart(pod, runStart), getPodRunEnd(pod, runEnd))
+		run.CPU += pod.CPURequest
+		run.Memory += pod.MemoryRequest
+		run.GPU += pod.GPURequest
+		run.CPUCost += pod.CPURequest * pod.CPUPrice * hours
+		run.MemoryCost += pod.MemoryRequest * pod.MemoryPrice * hours
+		run.GPUCost += pod.GPURequest * pod.GPUPrice * hours
+		run.Cost += pod.ExtendedResourcePrice * hours
+		if pod.Node != nil {
+			run.NodePrice += pod.Node.CPUCapacity*pod.Node.CPUPrice + pod.Node.MemoryCapacity*pod.Node.MemoryPrice +
+				pod.Node.GPUCapacity*pod.Node.GPUPrice
+			nodesCount++
+		}
+	}
+	if nodesCount > 0 {
+		run.NodePrice /= float64(nodesCount)
+	}
+	run.Cost += run.CPUCost + run.MemoryCost + run.GPUCost
+	return run
+}
+
+func getPodRunStart(pod Pod, runStart time.Time) time.Time {
+	start, err := time.Parse(time.RFC3339, pod.StartTime)
+	if err != nil || start.Before(runStart) {
+		return runStart
+	}
+	return start
+}
+
+func getPodRunEnd(pod Pod, runEnd time.Time) time.Time {
+	end, err := time.Parse(time.RFC3339, pod.EndTime)
+	if err != nil || end.After(runEnd) {
+		return runEnd
+	}
+	return end
+}
+
+func getHours(start, end time.Time) float64 {
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+func retrieveJobPods(jobUID string) ([]Pod, error) {
+	query := `query {
+		jobs(func: uid(` + jobUID + `)) {
+			pods: ~job @filter(has(isPod)) {
+				startTime
+				endTime
+				cpuRequest
+				memoryRequest
+				gpuRequest
+				cpuPrice
+				memoryPrice
+				gpuPrice
+				extendedResourcePrice
+				node {
+					cpuCapacity
+					memoryCapacity
+					gpuCapacity
+					cpuPrice
+					memoryPrice
+					gpuPrice
+				}
+			}
+		}
+	}`
+	type root struct {
+		Jobs []struct {
+			Pods []Pod `json:"pods"`
+		} `json:"jobs"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil || len(newRoot.Jobs) == 0 {
+		return nil, err
+	}
+	return newRoot.Jobs[0].Pods, nil
+}
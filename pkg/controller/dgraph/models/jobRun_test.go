@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	batch_v1 "k8s.io/api/batch/v1"
+	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNewJobRun ...
+func TestNewJobRun(t *testing.T) {
+	runStart := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	runEnd := runStart.Add(2 * time.Hour)
+	node := &Node{CPUCapacity: 4, MemoryCapacity: 16, CPUPrice: 0.03, MemoryPrice: 0.004}
+	pods := []Pod{
+		// failed attempt which ended after an hour
+		{StartTime: "2019-06-01T10:00:00Z", EndTime: "2019-06-01T11:00:00Z", CPURequest: 1, MemoryRequest: 2,
+			CPUPrice: 0.03, MemoryPrice: 0.004, Node: node},
+		// successful attempt which completed with the job, it is deleted only along with the job
+		{StartTime: "2019-06-01T11:00:00Z", CPURequest: 1, MemoryRequest: 2, CPUPrice: 0.03, MemoryPrice: 0.004,
+			ExtendedResourcePrice: 0.5},
+	}
+	run := newJobRun("default:backup-1559383200", pods, runStart, runEnd, true)
+
+	assert.True(t, run.IsJobRun)
+	assert.True(t, run.Succeeded)
+	assert.Equal(t, "2019-06-01T10:00:00Z", run.RunStartTime)
+	assert.Equal(t, "2019-06-01T12:00:00Z", run.RunEndTime)
+	assert.Equal(t, 2.0, run.RunHours)
+	assert.Equal(t, 2.0, run.CPU)
+	assert.Equal(t, 4.0, run.Memory)
+	assert.InDelta(t, 0.06, run.CPUCost, 1e-12)
+	assert.InDelta(t, 0.016, run.MemoryCost, 1e-12)
+	assert.InDelta(t, 0.184, run.NodePrice, 1e-12)
+	assert.InDelta(t, 0.576, run.Cost, 1e-12)
+}
+
+// TestGetJobRunEnd ...
+func TestGetJobRunEnd(t *testing.T) {
+	end := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	_, _, isFinished := getJobRunEnd(batch_v1.Job{})
+	assert.False(t, isFinished)
+
+	completed := batch_v1.Job{Status: batch_v1.JobStatus{CompletionTime: &meta_v1.Time{Time: end}}}
+	runEnd, succeeded, isFinished := getJobRunEnd(completed)
+	assert.True(t, isFinished)
+	assert.True(t, succeeded)
+	assert.Equal(t, end, runEnd)
+
+	failed := batch_v1.Job{Status: batch_v1.JobStatus{Conditions: []batch_v1.JobCondition{
+		{Type: batch_v1.JobFailed, Status: api_v1.ConditionTrue, LastTransitionTime: meta_v1.Time{Time: end}},
+	}}}
+	runEnd, succeeded, isFinished = getJobRunEnd(failed)
+	assert.True(t, isFinished)
+	assert.False(t, succeeded)
+	assert.Equal(t, end, runEnd)
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// Dgraph Model Constants
+const (
+	IsNetworkPrice        = "isNetworkPrice"
+	NetworkPriceXIDPrefix = "purser-networkPrice-"
+
+	// Network resources provisioned in the cloud for the cluster
+	LoadBalancerNetworkResource        = "loadBalancer"
+	NetworkLoadBalancerNetworkResource = "networkLoadBalancer"
+	PublicIPNetworkResource            = "publicIP"
+	NATGatewayNetworkResource          = "natGateway"
+)
+
+// NetworkPrice structure
+// Unit of Network Price should be USD($)-(per Hour) and unit of PricePerGB should be USD($)-(per GB processed)
+type NetworkPrice struct {
+	dgraph.ID
+	IsNetworkPrice  bool    `json:"isNetworkPrice,omitempty"`
+	NetworkResource string  `json:"networkResource,omitempty"`
+	Price           float64 `json:"price,omitempty"`
+	PricePerGB      float64 `json:"pricePerGB,omitempty"`
+}
+
+// StoreNetworkPrices stores(create/update) every networkPrice in dgraph using its XID, returns the stored ones
+func StoreNetworkPrices(networkPrices []*NetworkPrice) []*NetworkPrice {
+	var storedPrices []*NetworkPrice
+	for _, networkPrice := range networkPrices {
+		uid := dgraph.GetUID(networkPrice.Xid, IsNetworkPrice)
+		if uid != "" {
+			networkPrice.UID = uid
+		}
+		assigned, err := dgraph.MutateNode(networkPrice, dgraph.CREATE)
+		if err != nil {
+			logrus.Errorf("Unable to store networkPrice: (%v), reason: %v", networkPrice, err)
+			continue
+		}
+		if uid == "" {
+			networkPrice.UID = assigned.Uids["blank-0"]
+		}
+		storedPrices = append(storedPrices, networkPrice)
+	}
+	return storedPrices
+}
+
+// DeleteNetworkPrices deletes prices of network resources from dgraph
+func DeleteNetworkPrices() {
+	networkPrices, err := retrieveNetworkPrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve network prices: %v", err)
+		return
+	}
+	var stalePrices []NetworkPrice
+	for _, networkPrice := range networkPrices {
+		stalePrices = append(stalePrices, NetworkPrice{ID: dgraph.ID{UID: networkPrice.UID}})
+	}
+	if len(stalePrices) > 0 {
+		_, err = dgraph.MutateNode(stalePrices, dgraph.DELETE)
+		if err != nil {
+			logrus.Errorf("unable to delete network prices: %v", err)
+		}
+	}
+}
+
+// retrieveNetworkPrices returns prices of network resources of the rate card
+func retrieveNetworkPrices() ([]NetworkPrice, error) {
+	query := `query {
+		networkPrices(func: has(isNetworkPrice)) {
+			uid
+			networkResource
+			price
+			pricePerGB
+        }
+    }`
+	type root struct {
+		NetworkPrices []NetworkPrice `json:"networkPrices"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.NetworkPrices, nil
+}
+
+// getLoadBalancerPrice returns price per hour of a load balancer of the network resource(ex: networkLoadBalancer)
+// along with its public IPs. Load balancers are priced by the price of loadBalancer when the rate card has no price
+// for their resource and by the default price when it has neither.
+func getLoadBalancerPrice(resource string, publicIPs int) float64 {
+	networkPrices, err := retrieveNetworkPrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve network prices: %v", err)
+		return DefaultLoadBalancerCostInFloat64
+	}
+	return computeLoadBalancerPrice(networkPrices, resource, publicIPs)
+}
+
+func computeLoadBalancerPrice(networkPrices []NetworkPrice, resource string, publicIPs int) float64 {
+	loadBalancerPrice, publicIPPrice := DefaultLoadBalancerCostInFloat64, 0.0
+	isResourcePriced := false
+	for _, networkPrice := range networkPrices {
+		switch networkPrice.NetworkResource {
+		case resource:
+			loadBalancerPrice, isResourcePriced = networkPrice.Price, true
+		case LoadBalancerNetworkResource:
+			if !isResourcePriced {
+				loadBalancerPrice = networkPrice.Price
+			}
+		case PublicIPNetworkResource:
+			publicIPPrice = networkPrice.Price
+		}
+	}
+	return loadBalancerPrice + float64(publicIPs)*publicIPPrice
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestComputeLoadBalancerPrice ...
+func TestComputeLoadBalancerPrice(t *testing.T) {
+	// load balancers without a price use the default price, public IPs without a price cost nothing
+	assert.Equal(t, DefaultLoadBalancerCostInFloat64, computeLoadBalancerPrice(nil, LoadBalancerNetworkResource, 2))
+
+	networkPrices := []NetworkPrice{
+		{NetworkResource: LoadBalancerNetworkResource, Price: 0.02, PricePerGB: 0.008},
+		{NetworkResource: PublicIPNetworkResource, Price: 0.005},
+		{NetworkResource: NATGatewayNetworkResource, Price: 0.045},
+	}
+	assert.InDelta(t, 0.02, computeLoadBalancerPrice(networkPrices, LoadBalancerNetworkResource, 0), 1e-12)
+	assert.InDelta(t, 0.03, computeLoadBalancerPrice(networkPrices, LoadBalancerNetworkResource, 2), 1e-12)
+
+	// load balancers priced at zero(ex: on-prem) are free
+	free := []NetworkPrice{{NetworkResource: LoadBalancerNetworkResource}}
+	assert.Equal(t, 0.0, computeLoadBalancerPrice(free, LoadBalancerNetworkResource, 1))
+
+	// network load balancers use price of loadBalancer until the rate card has their own price
+	assert.InDelta(t, 0.02, computeLoadBalancerPrice(networkPrices, NetworkLoadBalancerNetworkResource, 0), 1e-12)
+	networkPrices = append(networkPrices, NetworkPrice{NetworkResource: NetworkLoadBalancerNetworkResource, Price: 0.0225})
+	assert.InDelta(t, 0.0325, computeLoadBalancerPrice(networkPrices, NetworkLoadBalancerNetworkResource, 2), 1e-12)
+	assert.InDelta(t, 0.02, computeLoadBalancerPrice(networkPrices, LoadBalancerNetworkResource, 0), 1e-12)
+}
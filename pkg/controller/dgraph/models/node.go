@@ -18,6 +18,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"fmt"
@@ -35,23 +36,73 @@ const (
 	DefaultNodeOS        = "purser-default"
 	InstanceTypeLabelKey = "beta.kubernetes.io/instance-type"
 	OSLabelKey           = "beta.kubernetes.io/os"
+
+	StableInstanceTypeLabelKey = "node.kubernetes.io/instance-type"
+	StableOSLabelKey           = "kubernetes.io/os"
+)
+
+// Pricing terms of a node
+const (
+	OnDemandPricingTerm = "on-demand"
+	ReservedPricingTerm = "reserved"
+	SpotPricingTerm     = "spot"
 )
 
+// Node labels telling the pricing term of a node
+const (
+	PricingTermLabelKey           = "purser/pricing-term"
+	LifecycleLabelKey             = "node.kubernetes.io/lifecycle"
+	EKSCapacityTypeLabelKey       = "eks.amazonaws.com/capacityType"
+	KarpenterCapacityTypeLabelKey = "karpenter.sh/capacity-type"
+	GKESpotLabelKey               = "cloud.google.com/gke-spot"
+	GKEPreemptibleLabelKey        = "cloud.google.com/gke-preemptible"
+	AKSScaleSetPriorityLabelKey   = "kubernetes.azure.com/scalesetpriority"
+)
+
+// pricingTermLabels maps (lower case) values of node labels to pricing terms, labels are checked in order
+var pricingTermLabels = []struct {
+	key   string
+	terms map[string]string
+}{
+	{PricingTermLabelKey, map[string]string{
+		OnDemandPricingTerm: OnDemandPricingTerm,
+		ReservedPricingTerm: ReservedPricingTerm,
+		// savings plan rates aren't part of the price lists, such nodes are priced on-demand
+		"savings-plan":  OnDemandPricingTerm,
+		SpotPricingTerm: SpotPricingTerm,
+	}},
+	{LifecycleLabelKey, map[string]string{"spot": SpotPricingTerm, "normal": OnDemandPricingTerm}},
+	{EKSCapacityTypeLabelKey, map[string]string{"spot": SpotPricingTerm, "on_demand": OnDemandPricingTerm}},
+	{KarpenterCapacityTypeLabelKey, map[string]string{
+		"spot": SpotPricingTerm, "on-demand": OnDemandPricingTerm, "reserved": ReservedPricingTerm,
+	}},
+	{GKESpotLabelKey, map[string]string{"true": SpotPricingTerm}},
+	{GKEPreemptibleLabelKey, map[string]string{"true": SpotPricingTerm}},
+	{AKSScaleSetPriorityLabelKey, map[string]string{"spot": SpotPricingTerm, "regular": OnDemandPricingTerm}},
+}
+
 // Node schema in dgraph
 type Node struct {
 	dgraph.ID
-	IsNode         bool    `json:"isNode,omitempty"`
-	Name           string  `json:"name,omitempty"`
-	StartTime      string  `json:"startTime,omitempty"`
-	EndTime        string  `json:"endTime,omitempty"`
-	Pods           []*Pod  `json:"pods,omitempty"`
-	CPUCapacity    float64 `json:"cpuCapacity,omitempty"`
-	MemoryCapacity float64 `json:"memoryCapacity,omitempty"`
-	Type           string  `json:"type,omitempty"`
-	InstanceType   string  `json:"instanceType,omitempty"`
-	OS             string  `json:"os,omitempty"`
-	CPUPrice       float64 `json:"cpuPrice,omitempty"`
-	MemoryPrice    float64 `json:"memoryPrice,omitempty"`
+	IsNode         bool            `json:"isNode,omitempty"`
+	Name           string          `json:"name,omitempty"`
+	StartTime      string          `json:"startTime,omitempty"`
+	EndTime        string          `json:"endTime,omitempty"`
+	Pods           []*Pod          `json:"pods,omitempty"`
+	CPUCapacity    float64         `json:"cpuCapacity,omitempty"`
+	MemoryCapacity float64         `json:"memoryCapacity,omitempty"`
+	GPUCapacity    float64         `json:"gpuCapacity,omitempty"`
+	Type           string          `json:"type,omitempty"`
+	InstanceType   string          `json:"instanceType,omitempty"`
+	OS             string          `json:"os,omitempty"`
+	CPUPrice       float64         `json:"cpuPrice,omitempty"`
+	MemoryPrice    float64         `json:"memoryPrice,omitempty"`
+	GPUPrice       float64         `json:"gpuPrice,omitempty"`
+	PricingTerm    string          `json:"pricingTerm,omitempty"`
+	PriceSegments  []*PriceSegment `json:"priceSegments,omitempty"`
+
+	// extended resources(ex: nvidia.com/gpu) advertised by the node
+	ExtendedResources []*ExtendedResource `json:"extendedResources,omitempty"`
 }
 
 func createNodeObject(node api_v1.Node) Node {
@@ -63,12 +114,14 @@ func createNodeObject(node api_v1.Node) Node {
 		StartTime:      node.GetCreationTimestamp().Time.Format(time.RFC3339),
 		CPUCapacity:    utils.ConvertToFloat64CPU(node.Status.Capacity.Cpu()),
 		MemoryCapacity: utils.ConvertToFloat64GB(node.Status.Capacity.Memory()),
+		GPUCapacity:    utils.GetGPUCount(node.Status.Capacity),
 	}
 
 	instanceType, os := getInstanceTypeAndOS(node)
 	newNode.InstanceType = instanceType
 	newNode.OS = os
-	log.Debugf("node: %s, instanceType: %s, os: %s", node.Name, newNode.InstanceType, newNode.OS)
+	newNode.PricingTerm = getPricingTerm(node.GetLabels())
+	log.Debugf("node: %s, instanceType: %s, os: %s, pricingTerm: %s", node.Name, newNode.InstanceType, newNode.OS, newNode.PricingTerm)
 
 	nodeDeletionTimestamp := node.GetDeletionTimestamp()
 	if !nodeDeletionTimestamp.IsZero() {
@@ -111,7 +164,21 @@ func StoreNode(node api_v1.Node) (string, error) {
 		newNode.UID = uid
 	}
 
-	newNode.CPUPrice, newNode.MemoryPrice = getPricePerUnitResourceFromNodePrice(newNode)
+	if newNode.EndTime != "" {
+		// price of the node stays as it was during its lifetime
+		newNode.PricingTerm = ""
+		newNode.PriceSegments = getPriceSegmentsOnEnd(uid, newNode.EndTime)
+	} else {
+		var gpuPrice float64
+		newNode.CPUPrice, newNode.MemoryPrice, gpuPrice, newNode.PricingTerm = getPricePerUnitResourceFromNodePrice(newNode, node.GetLabels())
+		if newNode.GPUCapacity > 0 {
+			newNode.GPUPrice = gpuPrice
+		}
+		newNode.ExtendedResources = getNodeExtendedResources(xid, node.Status.Capacity)
+		newNode.PriceSegments = getPriceSegmentsOnPriceChange(uid, time.Now(), func(prices *ResourcePrices) {
+			prices.CPUPrice, prices.MemoryPrice, prices.GPUPrice = newNode.CPUPrice, newNode.MemoryPrice, newNode.GPUPrice
+		})
+	}
 	assigned, err := dgraph.MutateNode(newNode, dgraph.CREATE)
 	if err != nil {
 		return "", err
@@ -129,12 +196,26 @@ func getInstanceTypeAndOS(node api_v1.Node) (string, string) {
 	instanceType := DefaultNodeInstance
 	os := DefaultNodeOS
 
-	if value, isPresent := nodeLabels[InstanceTypeLabelKey]; isPresent {
+	if value, isPresent := nodeLabels[StableInstanceTypeLabelKey]; isPresent {
+		instanceType = value
+	} else if value, isPresent := nodeLabels[InstanceTypeLabelKey]; isPresent {
 		instanceType = value
 	}
-	if value, isPresent := nodeLabels[OSLabelKey]; isPresent {
+	if value, isPresent := nodeLabels[StableOSLabelKey]; isPresent {
+		os = value
+	} else if value, isPresent := nodeLabels[OSLabelKey]; isPresent {
 		os = value
 	}
 
 	return instanceType, os
 }
+
+// getPricingTerm returns the pricing term(on-demand, reserved or spot) of a node from its labels
+func getPricingTerm(nodeLabels map[string]string) string {
+	for _, pricingTermLabel := range pricingTermLabels {
+		if term, isPresent := pricingTermLabel.terms[strings.ToLower(nodeLabels[pricingTermLabel.key])]; isPresent {
+			return term
+		}
+	}
+	return OnDemandPricingTerm
+}
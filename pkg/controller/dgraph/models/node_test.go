@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetPricingTerm ...
+func TestGetPricingTerm(t *testing.T) {
+	assert.Equal(t, OnDemandPricingTerm, getPricingTerm(nil))
+	assert.Equal(t, OnDemandPricingTerm, getPricingTerm(map[string]string{LifecycleLabelKey: "normal"}))
+	assert.Equal(t, SpotPricingTerm, getPricingTerm(map[string]string{LifecycleLabelKey: "spot"}))
+	assert.Equal(t, SpotPricingTerm, getPricingTerm(map[string]string{EKSCapacityTypeLabelKey: "SPOT"}))
+	assert.Equal(t, ReservedPricingTerm, getPricingTerm(map[string]string{KarpenterCapacityTypeLabelKey: "reserved"}))
+	assert.Equal(t, SpotPricingTerm, getPricingTerm(map[string]string{GKESpotLabelKey: "true"}))
+	assert.Equal(t, SpotPricingTerm, getPricingTerm(map[string]string{AKSScaleSetPriorityLabelKey: "spot"}))
+	assert.Equal(t, OnDemandPricingTerm, getPricingTerm(map[string]string{PricingTermLabelKey: "savings-plan"}))
+
+	// explicit pricing term label wins over capacity type labels
+	assert.Equal(t, ReservedPricingTerm, getPricingTerm(map[string]string{
+		PricingTermLabelKey:     "reserved",
+		EKSCapacityTypeLabelKey: "ON_DEMAND",
+	}))
+}
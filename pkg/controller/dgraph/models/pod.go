@@ -56,20 +56,34 @@ type Pod struct {
 	CPULimit       float64                  `json:"cpuLimit,omitempty"`
 	MemoryRequest  float64                  `json:"memoryRequest,omitempty"`
 	MemoryLimit    float64                  `json:"memoryLimit,omitempty"`
+	GPURequest     float64                  `json:"gpuRequest,omitempty"`
+	GPULimit       float64                  `json:"gpuLimit,omitempty"`
 	StorageRequest float64                  `json:"storageRequest,omitempty"`
 	Type           string                   `json:"type,omitempty"`
 	Cid            []Service                `json:"cid,omitempty"`
 	Labels         []*Label                 `json:"label,omitempty"`
 	CPUPrice       float64                  `json:"cpuPrice,omitempty"`
 	MemoryPrice    float64                  `json:"memoryPrice,omitempty"`
+	StoragePrice   float64                  `json:"storagePrice,omitempty"`
+	GPUPrice       float64                  `json:"gpuPrice,omitempty"`
+	PriceSegments  []*PriceSegment          `json:"priceSegments,omitempty"`
+	CPUUsage       float64                  `json:"cpuUsage,omitempty"`
+	MemoryUsage    float64                  `json:"memoryUsage,omitempty"`
+	EgressSamples  []*EgressSample          `json:"egressSamples,omitempty"`
+
+	// hourly price of extended resources(except gpus) requested by the pod
+	ExtendedResourcePrice float64 `json:"extendedResourcePrice,omitempty"`
 }
 
 // Metrics ...
 type Metrics struct {
-	CPURequest    float64
-	CPULimit      float64
-	MemoryRequest float64
-	MemoryLimit   float64
+	CPURequest               float64
+	CPULimit                 float64
+	MemoryRequest            float64
+	MemoryLimit              float64
+	GPURequest               float64
+	GPULimit                 float64
+	ExtendedResourceRequests map[string]float64
 }
 
 // newPod creates a new node for the pod in the Dgraph
@@ -89,7 +103,7 @@ func newPod(k8sPod api_v1.Pod) (*api.Assigned, error) {
 	if namespaceUID != "" {
 		pod.Namespace = &Namespace{ID: dgraph.ID{UID: namespaceUID, Xid: k8sPod.Namespace}}
 	}
-	pod.Pvcs, pod.StorageRequest = getPodVolumes(k8sPod)
+	pod.Pvcs, pod.StorageRequest, pod.StoragePrice = getPodVolumes(k8sPod)
 	setPodOwners(&pod, k8sPod)
 	return dgraph.MutateNode(pod, dgraph.CREATE)
 }
@@ -124,6 +138,8 @@ func StorePod(k8sPod api_v1.Pod) error {
 		}
 		podData := RetrievePodWithContainers(xid)
 		deleteContainersInTerminatedPod(podData.Containers, podDeletedTimestamp.Time)
+		// price of the pod stays as it was during its lifetime
+		pod.PriceSegments = getPriceSegmentsOnEnd(uid, endTime)
 	} else {
 		namespaceUID := CreateOrGetNamespaceByID(k8sPod.Namespace)
 		containers, metrics := StoreAndRetrieveContainersAndMetrics(k8sPod, uid, namespaceUID)
@@ -135,17 +151,154 @@ func StorePod(k8sPod api_v1.Pod) error {
 			CPULimit:      metrics.CPULimit,
 			MemoryRequest: metrics.MemoryRequest,
 			MemoryLimit:   metrics.MemoryLimit,
+			GPURequest:    metrics.GPURequest,
+			GPULimit:      metrics.GPULimit,
 		}
 		populatePodLabels(&pod, k8sPod.Labels)
-	}
 
-	// store/update CPUPrice, MemoryPrice
-	pod.CPUPrice, pod.MemoryPrice = getPerUnitResourcePriceForNode("node-" + k8sPod.Spec.NodeName)
+		// store/update CPUPrice, MemoryPrice, GPUPrice and ExtendedResourcePrice
+		pod.CPUPrice, pod.MemoryPrice, pod.GPUPrice = getPerUnitResourcePriceForNode("node-" + k8sPod.Spec.NodeName)
+		pod.ExtendedResourcePrice = getExtendedResourcePriceForRequests(metrics.ExtendedResourceRequests)
+		pod.PriceSegments = getPriceSegmentsOnPriceChange(uid, time.Now(), func(prices *ResourcePrices) {
+			prices.CPUPrice, prices.MemoryPrice, prices.GPUPrice = pod.CPUPrice, pod.MemoryPrice, pod.GPUPrice
+			prices.ExtendedResourcePrice = pod.ExtendedResourcePrice
+		})
+	}
 
 	_, err := dgraph.MutateNode(pod, dgraph.UPDATE)
 	return err
 }
 
+// UpdatePodPrices reprices live pods with the price of their nodes and extended resource prices of the rate card, price
+// segments are recorded for pods whose price changed so that their cost before the change is computed with the old price.
+func UpdatePodPrices() {
+	pods, err := retrieveLivePodsWithNode()
+	if err != nil {
+		log.Errorf("unable to retrieve live pods: %v", err)
+		return
+	}
+	extendedResourcePrices, err := retrieveExtendedResourcePrices()
+	if err != nil {
+		log.Errorf("unable to retrieve extended resource prices: %v", err)
+		return
+	}
+	changeTime := time.Now()
+	for _, pod := range pods {
+		if pod.Node == nil {
+			continue
+		}
+		updatedPod := Pod{ID: dgraph.ID{UID: pod.UID, Xid: pod.Xid}}
+		updatedPod.CPUPrice, updatedPod.MemoryPrice, updatedPod.GPUPrice = getPerUnitResourcePriceForNode(pod.Node.Name)
+		updatedPod.ExtendedResourcePrice = computeExtendedResourcePrice(getPodExtendedResourceRequests(pod), extendedResourcePrices)
+		updatedPod.PriceSegments = getPriceSegmentsOnPriceChange(pod.UID, changeTime, func(prices *ResourcePrices) {
+			prices.CPUPrice, prices.MemoryPrice, prices.GPUPrice = updatedPod.CPUPrice, updatedPod.MemoryPrice, updatedPod.GPUPrice
+			prices.ExtendedResourcePrice = updatedPod.ExtendedResourcePrice
+		})
+		if len(updatedPod.PriceSegments) == 0 {
+			continue
+		}
+		_, err = dgraph.MutateNode(updatedPod, dgraph.UPDATE)
+		if err != nil {
+			log.Errorf("unable to update price of pod: %s, err: %v", pod.Name, err)
+		}
+	}
+}
+
+// getPodExtendedResourceRequests returns extended resources requested by containers of the pod by resource name
+func getPodExtendedResourceRequests(pod Pod) map[string]float64 {
+	requests := make(map[string]float64)
+	for _, container := range pod.Containers {
+		for _, extendedResource := range container.ExtendedResources {
+			requests[extendedResource.ResourceName] += extendedResource.Request
+		}
+	}
+	return requests
+}
+
+// UpdatePodStoragePrices reprices storage of live pods with the storage price of their claims, price segments are
+// recorded for pods whose storage price changed
+func UpdatePodStoragePrices() {
+	pods, err := retrieveLivePodsWithClaims()
+	if err != nil {
+		log.Errorf("unable to retrieve live pods: %v", err)
+		return
+	}
+	changeTime := time.Now()
+	for _, pod := range pods {
+		var pvcs []PersistentVolumeClaim
+		for _, pvc := range pod.Pvcs {
+			pvcs = append(pvcs, *pvc)
+		}
+		_, storagePrice := getStorageAndStoragePrice(pvcs)
+		if storagePrice == pod.StoragePrice {
+			continue
+		}
+		updatedPod := Pod{
+			ID:           dgraph.ID{UID: pod.UID, Xid: pod.Xid},
+			StoragePrice: storagePrice,
+		}
+		updatedPod.PriceSegments = getPriceSegmentsOnPriceChange(pod.UID, changeTime, func(prices *ResourcePrices) {
+			prices.StoragePrice = storagePrice
+		})
+		_, err = dgraph.MutateNode(updatedPod, dgraph.UPDATE)
+		if err != nil {
+			log.Errorf("unable to update storage price of pod: %s, err: %v", pod.Name, err)
+		}
+	}
+}
+
+func retrieveLivePodsWithClaims() ([]Pod, error) {
+	query := `query {
+		pods(func: has(isPod)) @filter(NOT has(endTime)) {
+			uid
+			xid
+			name
+			storagePrice
+			pvc {
+				storageCapacity
+				storagePrice
+			}
+		}
+	}`
+	type root struct {
+		Pods []Pod `json:"pods"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Pods, nil
+}
+
+func retrieveLivePodsWithNode() ([]Pod, error) {
+	query := `query {
+		pods(func: has(isPod)) @filter(NOT has(endTime)) {
+			uid
+			xid
+			name
+			node {
+				name
+			}
+			containers @filter(NOT has(endTime)) {
+				extendedResources {
+					resourceName
+					request
+				}
+			}
+		}
+	}`
+	type root struct {
+		Pods []Pod `json:"pods"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Pods, nil
+}
+
 // StorePodsInteraction store the pod interactions in Dgraph
 func StorePodsInteraction(sourcePodXID string, destinationPodsXIDs []string, counts []float64) error {
 	uid := dgraph.GetUID(sourcePodXID, IsPod)
@@ -253,9 +406,11 @@ func updateDaemonsetAsPodOwner(pod *Pod, ownerXID string) {
 	}
 }
 
-func getPodVolumes(k8sPod api_v1.Pod) ([]*PersistentVolumeClaim, float64) {
+// getPodVolumes returns claims of a pod along with their storage capacity and storage price per GB per hour,
+// storage price of a pod is the average price of its claims weighted by their capacity
+func getPodVolumes(k8sPod api_v1.Pod) ([]*PersistentVolumeClaim, float64, float64) {
 	podVolumes := []*PersistentVolumeClaim{}
-	storage := 0.0
+	var pvcs []PersistentVolumeClaim
 	for j := 0; j < len(k8sPod.Spec.Volumes); j++ {
 		vol := k8sPod.Spec.Volumes[j]
 		if vol.PersistentVolumeClaim != nil {
@@ -265,14 +420,32 @@ func getPodVolumes(k8sPod api_v1.Pod) ([]*PersistentVolumeClaim, float64) {
 				podVolumes = append(podVolumes, &PersistentVolumeClaim{ID: dgraph.ID{UID: pvcUID, Xid: pvcXID}})
 				pvc, err := getPVCFromUID(pvcUID)
 				if err == nil {
-					storage += pvc.StorageCapacity
+					pvcs = append(pvcs, pvc)
 				} else {
 					log.Errorf("error while getting pvc from uid: (%v), error: (%v)", pvcUID, err)
 				}
 			}
 		}
 	}
-	return podVolumes, storage
+	storage, storagePrice := getStorageAndStoragePrice(pvcs)
+	return podVolumes, storage, storagePrice
+}
+
+func getStorageAndStoragePrice(pvcs []PersistentVolumeClaim) (float64, float64) {
+	storage := 0.0
+	storageCostPerHour := 0.0
+	for _, pvc := range pvcs {
+		price := pvc.StoragePrice
+		if price <= 0 {
+			price = DefaultStorageCostInFloat64
+		}
+		storage += pvc.StorageCapacity
+		storageCostPerHour += pvc.StorageCapacity * price
+	}
+	if storage <= 0 {
+		return storage, DefaultStorageCostInFloat64
+	}
+	return storage, storageCostPerHour / storage
 }
 
 func populatePodLabels(pod *Pod, podLabels map[string]string) {
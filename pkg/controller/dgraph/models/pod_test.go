@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/vmware/purser/pkg/controller/dgraph"
 )
 
@@ -46,3 +47,27 @@ func TestStorePodsInteraction(t *testing.T) {
 		fmt.Println("Error while building interation graph ", err)
 	}
 }
+
+// TestGetStorageAndStoragePrice ...
+func TestGetStorageAndStoragePrice(t *testing.T) {
+	storage, price := getStorageAndStoragePrice(nil)
+	assert.Equal(t, 0.0, storage)
+	assert.Equal(t, DefaultStorageCostInFloat64, price)
+
+	storage, price = getStorageAndStoragePrice([]PersistentVolumeClaim{
+		{StorageCapacity: 10, StoragePrice: 0.0003},
+		{StorageCapacity: 30, StoragePrice: 0.0001},
+	})
+	assert.Equal(t, 40.0, storage)
+	assert.InDelta(t, 0.00015, price, 1e-12)
+}
+
+// TestGetPodExtendedResourceRequests ...
+func TestGetPodExtendedResourceRequests(t *testing.T) {
+	pod := Pod{Containers: []*Container{
+		{ExtendedResources: []*ExtendedResource{{ResourceName: "xilinx.com/fpga", Request: 1}}},
+		{ExtendedResources: []*ExtendedResource{{ResourceName: "xilinx.com/fpga", Request: 2}, {ResourceName: "nvidia.com/gpu", Request: 1}}},
+	}}
+	expected := map[string]float64{"xilinx.com/fpga": 3, "nvidia.com/gpu": 1}
+	assert.Equal(t, expected, getPodExtendedResourceRequests(pod))
+}
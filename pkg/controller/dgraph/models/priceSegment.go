@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// Dgraph Model Constants
+const (
+	IsPriceSegment = "isPriceSegment"
+)
+
+// PriceSegment is the price of a pod, node, persistent volume, claim or load balancer during an interval. Segments are recorded when price of a resource
+// changes(ex: new rate card) so that costs of past intervals aren't recomputed with the latest price.
+// Resources which were never repriced have no segments, their own price is in effect during their lifetime.
+type PriceSegment struct {
+	dgraph.ID
+	IsPriceSegment bool   `json:"isPriceSegment,omitempty"`
+	StartTime      string `json:"startTime,omitempty"`
+	EndTime        string `json:"endTime,omitempty"`
+	ResourcePrices
+}
+
+// ResourcePrices are the hourly prices of a resource which are recorded in its price segments
+type ResourcePrices struct {
+	CPUPrice              float64 `json:"cpuPrice,omitempty"`
+	MemoryPrice           float64 `json:"memoryPrice,omitempty"`
+	GPUPrice              float64 `json:"gpuPrice,omitempty"`
+	ExtendedResourcePrice float64 `json:"extendedResourcePrice,omitempty"`
+	StoragePrice          float64 `json:"storagePrice,omitempty"`
+	// price of load balancers
+	Price float64 `json:"price,omitempty"`
+}
+
+// pricedResource is a pod, node, persistent volume, claim or service along with its price segments which are in effect,
+// load balancers of services are priced from their provisioned time
+type pricedResource struct {
+	dgraph.ID
+	StartTime       string `json:"startTime,omitempty"`
+	ProvisionedTime string `json:"provisionedTime,omitempty"`
+	ResourcePrices
+	PriceSegments []*PriceSegment `json:"priceSegments,omitempty"`
+	SegmentsCount int             `json:"segmentsCount,omitempty"`
+}
+
+// getPriceSegmentsOnPriceChange returns price segments to be stored with a resource(uid) when its price changes at
+// changeTime. setPrices sets the new prices on the current ones, prices it doesn't set stay as they are.
+func getPriceSegmentsOnPriceChange(uid string, changeTime time.Time, setPrices func(prices *ResourcePrices)) []*PriceSegment {
+	if uid == "" {
+		return nil
+	}
+	resource, err := retrievePricedResource(uid)
+	if err != nil {
+		log.Errorf("unable to retrieve price segments of resource: %s, err: %v", uid, err)
+		return nil
+	}
+	prices := resource.ResourcePrices
+	setPrices(&prices)
+	return getPriceSegmentsOfResource(resource, prices, changeTime)
+}
+
+// getPriceSegmentsOfResource returns price segments of the resource when its prices change at changeTime: the segment
+// in effect ends and a new one starts with the new prices. A resource repriced for the first time gets a segment with
+// its old prices from its start time. Nothing is returned when the prices haven't changed.
+func getPriceSegmentsOfResource(resource *pricedResource, prices ResourcePrices, changeTime time.Time) []*PriceSegment {
+	// resource is new or its prices didn't change
+	if resource.ResourcePrices == (ResourcePrices{}) || resource.getPricesInEffect() == prices {
+		return nil
+	}
+
+	start := changeTime.Format(time.RFC3339)
+	var segments []*PriceSegment
+	if resource.SegmentsCount == 0 && resource.getStartTime() != "" {
+		segments = append(segments, newPriceSegment(resource.Xid, resource.getStartTime(), resource.ResourcePrices))
+		segments[0].EndTime = start
+	}
+	segments = append(segments, endPriceSegments(resource.PriceSegments, start)...)
+	return append(segments, newPriceSegment(resource.Xid, start, prices))
+}
+
+// getStartTime returns the time from which the resource is priced
+func (resource *pricedResource) getStartTime() string {
+	if resource.ProvisionedTime != "" {
+		return resource.ProvisionedTime
+	}
+	return resource.StartTime
+}
+
+// getPricesInEffect returns prices of the segment in effect, own prices of the resource when it has no such segment
+func (resource *pricedResource) getPricesInEffect() ResourcePrices {
+	if len(resource.PriceSegments) > 0 {
+		return resource.PriceSegments[len(resource.PriceSegments)-1].ResourcePrices
+	}
+	return resource.ResourcePrices
+}
+
+// getPriceSegmentsOnEnd returns price segments of a resource(uid) which is deleted at endTime
+func getPriceSegmentsOnEnd(uid, endTime string) []*PriceSegment {
+	if uid == "" {
+		return nil
+	}
+	resource, err := retrievePricedResource(uid)
+	if err != nil {
+		log.Errorf("unable to retrieve price segments of resource: %s, err: %v", uid, err)
+		return nil
+	}
+	return endPriceSegments(resource.PriceSegments, endTime)
+}
+
+func endPriceSegments(segments []*PriceSegment, endTime string) []*PriceSegment {
+	var endedSegments []*PriceSegment
+	for _, segment := range segments {
+		endedSegments = append(endedSegments, &PriceSegment{ID: dgraph.ID{UID: segment.UID}, EndTime: endTime})
+	}
+	return endedSegments
+}
+
+func newPriceSegment(resourceXID, startTime string, prices ResourcePrices) *PriceSegment {
+	return &PriceSegment{
+		ID:             dgraph.ID{Xid: resourceXID + "-priceSegment-" + startTime},
+		IsPriceSegment: true,
+		StartTime:      startTime,
+		ResourcePrices: prices,
+	}
+}
+
+// retrievePricedResource returns prices of a resource along with its price segments which haven't ended
+func retrievePricedResource(uid string) (*pricedResource, error) {
+	query := `query {
+		resources(func: uid(` + uid + `)) {
+			xid
+			startTime
+			provisionedTime
+			cpuPrice
+			memoryPrice
+			gpuPrice
+			extendedResourcePrice
+			storagePrice
+			price
+			priceSegments @filter(NOT has(endTime)) {
+				uid
+				startTime
+				cpuPrice
+				memoryPrice
+				gpuPrice
+				extendedResourcePrice
+				storagePrice
+				price
+			}
+			segmentsCount: count(priceSegments)
+		}
+	}`
+	type root struct {
+		Resources []pricedResource `json:"resources"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	} else if len(newRoot.Resources) < 1 {
+		return &pricedResource{}, nil
+	}
+	return &newRoot.Resources[0], nil
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// TestNewPriceSegment ...
+func TestNewPriceSegment(t *testing.T) {
+	prices := ResourcePrices{CPUPrice: 0.24, MemoryPrice: 0.1, GPUPrice: 0.9, ExtendedResourcePrice: 0.5}
+	got := newPriceSegment("purser:pod-a", "2019-06-04T10:15:00Z", prices)
+	expected := &PriceSegment{
+		ID:             dgraph.ID{Xid: "purser:pod-a-priceSegment-2019-06-04T10:15:00Z"},
+		IsPriceSegment: true,
+		StartTime:      "2019-06-04T10:15:00Z",
+		ResourcePrices: prices,
+	}
+	assert.Equal(t, expected, got)
+}
+
+// TestEndPriceSegments ...
+func TestEndPriceSegments(t *testing.T) {
+	segments := []*PriceSegment{
+		{ID: dgraph.ID{UID: "0x1", Xid: "purser:pod-a-priceSegment-1"}, ResourcePrices: ResourcePrices{CPUPrice: 0.24}},
+		{ID: dgraph.ID{UID: "0x2", Xid: "purser:pod-a-priceSegment-2"}, ResourcePrices: ResourcePrices{CPUPrice: 0.3}},
+	}
+	got := endPriceSegments(segments, "2019-06-04T10:15:00Z")
+	expected := []*PriceSegment{
+		{ID: dgraph.ID{UID: "0x1"}, EndTime: "2019-06-04T10:15:00Z"},
+		{ID: dgraph.ID{UID: "0x2"}, EndTime: "2019-06-04T10:15:00Z"},
+	}
+	assert.Equal(t, expected, got)
+	assert.Nil(t, endPriceSegments(nil, "2019-06-04T10:15:00Z"))
+}
+
+// TestGetPriceSegmentsOfResource ...
+func TestGetPriceSegmentsOfResource(t *testing.T) {
+	changeTime := time.Date(2019, 6, 4, 10, 15, 0, 0, time.UTC)
+	oldPrices := ResourcePrices{CPUPrice: 0.24, MemoryPrice: 0.1, GPUPrice: 0.9}
+	resource := &pricedResource{
+		ID:             dgraph.ID{UID: "0x1", Xid: "purser:pod-a"},
+		StartTime:      "2019-06-01T00:00:00Z",
+		ResourcePrices: oldPrices,
+	}
+
+	// unchanged prices
+	assert.Nil(t, getPriceSegmentsOfResource(resource, oldPrices, changeTime))
+	// new resource
+	assert.Nil(t, getPriceSegmentsOfResource(&pricedResource{StartTime: "2019-06-01T00:00:00Z"}, oldPrices, changeTime))
+
+	// only gpu price changed
+	newPrices := ResourcePrices{CPUPrice: 0.24, MemoryPrice: 0.1, GPUPrice: 0.7, ExtendedResourcePrice: 0.5}
+	got := getPriceSegmentsOfResource(resource, newPrices, changeTime)
+	firstSegment := newPriceSegment("purser:pod-a", "2019-06-01T00:00:00Z", oldPrices)
+	firstSegment.EndTime = "2019-06-04T10:15:00Z"
+	expected := []*PriceSegment{firstSegment, newPriceSegment("purser:pod-a", "2019-06-04T10:15:00Z", newPrices)}
+	assert.Equal(t, expected, got)
+}
+
+// TestGetPriceSegmentsOfRepricedResource ...
+func TestGetPriceSegmentsOfRepricedResource(t *testing.T) {
+	changeTime := time.Date(2019, 6, 4, 10, 15, 0, 0, time.UTC)
+	segmentPrices := ResourcePrices{CPUPrice: 0.3, MemoryPrice: 0.1, ExtendedResourcePrice: 0.5}
+	resource := &pricedResource{
+		ID:             dgraph.ID{UID: "0x1", Xid: "purser:pod-a"},
+		StartTime:      "2019-06-01T00:00:00Z",
+		ResourcePrices: segmentPrices,
+		PriceSegments: []*PriceSegment{
+			{ID: dgraph.ID{UID: "0x2"}, StartTime: "2019-06-02T00:00:00Z", ResourcePrices: segmentPrices},
+		},
+		SegmentsCount: 2,
+	}
+
+	// prices of the segment in effect
+	assert.Nil(t, getPriceSegmentsOfResource(resource, segmentPrices, changeTime))
+
+	newPrices := ResourcePrices{CPUPrice: 0.3, MemoryPrice: 0.1}
+	got := getPriceSegmentsOfResource(resource, newPrices, changeTime)
+	expected := []*PriceSegment{
+		{ID: dgraph.ID{UID: "0x2"}, EndTime: "2019-06-04T10:15:00Z"},
+		newPriceSegment("purser:pod-a", "2019-06-04T10:15:00Z", newPrices),
+	}
+	assert.Equal(t, expected, got)
+}
+
+// TestGetPriceSegmentsOfRepricedStorage ...
+func TestGetPriceSegmentsOfRepricedStorage(t *testing.T) {
+	changeTime := time.Date(2019, 6, 4, 10, 15, 0, 0, time.UTC)
+	resource := &pricedResource{
+		ID:             dgraph.ID{UID: "0x1", Xid: "default:pvc-a"},
+		StartTime:      "2019-06-01T00:00:00Z",
+		ResourcePrices: ResourcePrices{StoragePrice: 0.0001},
+	}
+	got := getPriceSegmentsOfResource(resource, ResourcePrices{StoragePrice: 0.00015}, changeTime)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "2019-06-04T10:15:00Z", got[0].EndTime)
+	assert.Equal(t, 0.0001, got[0].StoragePrice)
+	assert.Equal(t, 0.00015, got[1].StoragePrice)
+}
+
+// TestGetPriceSegmentsOfDeprovisionedLoadBalancer checks that cost of a load balancer ends when its service stops being
+// a LoadBalancer and starts again when it is provisioned again
+func TestGetPriceSegmentsOfDeprovisionedLoadBalancer(t *testing.T) {
+	changeTime := time.Date(2019, 6, 4, 10, 15, 0, 0, time.UTC)
+	resource := &pricedResource{
+		ID:              dgraph.ID{UID: "0x1", Xid: "default:frontend"},
+		StartTime:       "2019-06-01T00:00:00Z",
+		ProvisionedTime: "2019-06-02T00:00:00Z",
+		ResourcePrices:  ResourcePrices{Price: 0.03},
+	}
+	got := getPriceSegmentsOfResource(resource, ResourcePrices{}, changeTime)
+	firstSegment := newPriceSegment("default:frontend", "2019-06-02T00:00:00Z", ResourcePrices{Price: 0.03})
+	firstSegment.EndTime = "2019-06-04T10:15:00Z"
+	assert.Equal(t, []*PriceSegment{firstSegment, newPriceSegment("default:frontend", "2019-06-04T10:15:00Z", ResourcePrices{})}, got)
+
+	// own price of the service stays, the segment without price is in effect
+	resource.PriceSegments = []*PriceSegment{{ID: dgraph.ID{UID: "0x2"}, StartTime: "2019-06-04T10:15:00Z"}}
+	resource.SegmentsCount = 2
+	assert.Nil(t, getPriceSegmentsOfResource(resource, ResourcePrices{}, changeTime.Add(time.Hour)))
+	got = getPriceSegmentsOfResource(resource, ResourcePrices{Price: 0.03}, changeTime.Add(time.Hour))
+	expected := []*PriceSegment{
+		{ID: dgraph.ID{UID: "0x2"}, EndTime: "2019-06-04T11:15:00Z"},
+		newPriceSegment("default:frontend", "2019-06-04T11:15:00Z", ResourcePrices{Price: 0.03}),
+	}
+	assert.Equal(t, expected, got)
+}
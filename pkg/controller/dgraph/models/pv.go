@@ -18,6 +18,8 @@
 package models
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Sirupsen/logrus"
@@ -28,6 +30,8 @@ import (
 	"github.com/vmware/purser/pkg/controller/dgraph"
 	"github.com/vmware/purser/pkg/controller/utils"
 	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // Dgraph Model Constants
@@ -35,16 +39,51 @@ const (
 	IsPersistentVolume = "isPersistentVolume"
 )
 
+// Provisioners of persistent volumes
+const (
+	ProvisionedByAnnotationKey = "pv.kubernetes.io/provisioned-by"
+
+	AWSEBSProvisioner       = "kubernetes.io/aws-ebs"
+	AWSEBSCSIProvisioner    = "ebs.csi.aws.com"
+	GCEPDProvisioner        = "kubernetes.io/gce-pd"
+	GCEPDCSIProvisioner     = "pd.csi.storage.gke.io"
+	AzureDiskProvisioner    = "kubernetes.io/azure-disk"
+	AzureDiskCSIProvisioner = "disk.csi.azure.com"
+)
+
+// volumeTypeParameterKeys storage class parameters holding the volume type(ex: gp2, pd-ssd, Premium_LRS)
+var volumeTypeParameterKeys = []string{"type", "skuName", "skuname", "storageaccounttype", "storageAccountType"}
+
+// defaultVolumeTypes volume types provisioned when storage class parameters don't have one
+var defaultVolumeTypes = map[string]string{
+	AWSEBSProvisioner:       "gp2",
+	AWSEBSCSIProvisioner:    "gp3",
+	GCEPDProvisioner:        "pd-standard",
+	GCEPDCSIProvisioner:     "pd-standard",
+	AzureDiskProvisioner:    "Standard",
+	AzureDiskCSIProvisioner: "StandardSSD",
+}
+
 // PersistentVolume schema in dgraph
 type PersistentVolume struct {
 	dgraph.ID
-	IsPersistentVolume bool    `json:"isPersistentVolume,omitempty"`
-	Name               string  `json:"name,omitempty"`
-	StartTime          string  `json:"startTime,omitempty"`
-	EndTime            string  `json:"endTime,omitempty"`
-	Type               string  `json:"type,omitempty"`
-	StorageCapacity    float64 `json:"storageCapacity,omitempty"`
-	StorageType        string  `json:"storageType,omitempty"`
+	IsPersistentVolume bool            `json:"isPersistentVolume,omitempty"`
+	Name               string          `json:"name,omitempty"`
+	StartTime          string          `json:"startTime,omitempty"`
+	EndTime            string          `json:"endTime,omitempty"`
+	Type               string          `json:"type,omitempty"`
+	StorageCapacity    float64         `json:"storageCapacity,omitempty"`
+	StorageType        string          `json:"storageType,omitempty"`
+	StorageClass       string          `json:"storageClass,omitempty"`
+	Provisioner        string          `json:"provisioner,omitempty"`
+	VolumeType         string          `json:"volumeType,omitempty"`
+	IOPS               float64         `json:"iops,omitempty"`
+	StorageParameters  string          `json:"storageParameters,omitempty"`
+	StoragePrice       float64         `json:"storagePrice,omitempty"`
+	PriceSegments      []*PriceSegment `json:"priceSegments,omitempty"`
+
+	// parameters of the storage class, used for selecting storage prices of the provisioner
+	parameters map[string]string
 }
 
 func createPersistentVolumeObject(pv api_v1.PersistentVolume, client *kubernetes.Clientset) PersistentVolume {
@@ -59,6 +98,8 @@ func createPersistentVolumeObject(pv api_v1.PersistentVolume, client *kubernetes
 	newPv.StorageCapacity = utils.ConvertToFloat64GB(&capacity)
 	newPv.StorageType = utils.GetFinalStorageTypeOfPV(pv, client)
 	logrus.Debugf("PV: %s, storageType: %s", newPv.Name, newPv.StorageType)
+	setStorageDetails(&newPv, pv, getStorageClassParameters(&newPv, pv, client))
+	newPv.StoragePrice = getStoragePriceForPersistentVolume(newPv)
 
 	deletionTimestamp := pv.GetDeletionTimestamp()
 	if !deletionTimestamp.IsZero() {
@@ -78,13 +119,135 @@ func StorePersistentVolume(pv api_v1.PersistentVolume, client *kubernetes.Client
 	if uid != "" {
 		newPv.UID = uid
 	}
+	if newPv.EndTime != "" && uid != "" {
+		// price of the volume stays as it was during its lifetime
+		newPv.StoragePrice = 0
+		newPv.PriceSegments = getPriceSegmentsOnEnd(uid, newPv.EndTime)
+	} else {
+		newPv.PriceSegments = getPriceSegmentsOnPriceChange(uid, time.Now(), func(prices *ResourcePrices) {
+			prices.StoragePrice = newPv.StoragePrice
+		})
+	}
 	assigned, err := dgraph.MutateNode(newPv, dgraph.CREATE)
 	if err != nil {
 		return "", err
 	}
+	if uid != "" && newPv.EndTime == "" {
+		updateStoragePriceOfClaims(uid, newPv.StoragePrice)
+	}
 	return assigned.Uids["blank-0"], nil
 }
 
+// getStorageClassParameters sets storage class and provisioner of the volume, returns parameters of its storage class
+func getStorageClassParameters(newPv *PersistentVolume, pv api_v1.PersistentVolume, client *kubernetes.Clientset) map[string]string {
+	newPv.StorageClass = pv.Spec.StorageClassName
+	newPv.Provisioner = getInTreeProvisioner(pv)
+	if provisioner := pv.GetAnnotations()[ProvisionedByAnnotationKey]; provisioner != "" {
+		newPv.Provisioner = provisioner
+	}
+	if newPv.StorageClass == "" || client == nil {
+		return nil
+	}
+	storageClass, err := utils.RetrieveStorageClass(client, meta_v1.GetOptions{}, newPv.StorageClass)
+	if err != nil {
+		return nil
+	}
+	newPv.Provisioner = storageClass.Provisioner
+	return storageClass.Parameters
+}
+
+// setStorageDetails sets volume type, provisioned IOPS and parameters of the volume from storage class parameters
+func setStorageDetails(newPv *PersistentVolume, pv api_v1.PersistentVolume, parameters map[string]string) {
+	newPv.parameters = parameters
+	newPv.StorageParameters = labels.Set(parameters).String()
+
+	volumeType := ""
+	for _, key := range volumeTypeParameterKeys {
+		if value := parameters[key]; value != "" {
+			volumeType = value
+			break
+		}
+	}
+	if volumeType == "" {
+		volumeType = defaultVolumeTypes[newPv.Provisioner]
+	}
+	// azure sku names carry redundancy of the disk(ex: Premium_LRS) which isn't part of its price
+	newPv.VolumeType = strings.SplitN(volumeType, "_", 2)[0]
+
+	if iops, err := strconv.ParseFloat(parameters["iops"], 64); err == nil {
+		newPv.IOPS = iops
+	} else if iopsPerGB, err := strconv.ParseFloat(parameters["iopsPerGB"], 64); err == nil {
+		newPv.IOPS = iopsPerGB * newPv.StorageCapacity
+	}
+}
+
+// getInTreeProvisioner returns provisioner of in-tree cloud volumes which aren't provisioned by a storage class
+func getInTreeProvisioner(pv api_v1.PersistentVolume) string {
+	switch {
+	case pv.Spec.AWSElasticBlockStore != nil:
+		return AWSEBSProvisioner
+	case pv.Spec.GCEPersistentDisk != nil:
+		return GCEPDProvisioner
+	case pv.Spec.AzureDisk != nil:
+		return AzureDiskProvisioner
+	}
+	return ""
+}
+
+// updateStoragePriceOfClaims sets storage price of the live claims bound to a persistent volume, price segments are
+// recorded for claims whose price changed
+func updateStoragePriceOfClaims(pvUID string, storagePrice float64) {
+	query := `query {
+		pvcs(func: uid(` + pvUID + `)) {
+			~pv @filter(has(isPersistentVolumeClaim) AND NOT has(endTime)) {
+				uid
+			}
+		}
+	}`
+	type root struct {
+		Pvcs []struct {
+			Claims []PersistentVolumeClaim `json:"~pv"`
+		} `json:"pvcs"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil || len(newRoot.Pvcs) < 1 {
+		return
+	}
+	changeTime := time.Now()
+	var claims []PersistentVolumeClaim
+	for _, claim := range newRoot.Pvcs[0].Claims {
+		segments := getPriceSegmentsOnPriceChange(claim.UID, changeTime, func(prices *ResourcePrices) {
+			prices.StoragePrice = storagePrice
+		})
+		claims = append(claims, PersistentVolumeClaim{ID: dgraph.ID{UID: claim.UID}, StoragePrice: storagePrice, PriceSegments: segments})
+	}
+	if len(claims) > 0 {
+		_, err = dgraph.MutateNode(claims, dgraph.UPDATE)
+		if err != nil {
+			logrus.Errorf("unable to update storage price of claims of pv: %s, err: %v", pvUID, err)
+		}
+	}
+}
+
+// retrieveStoragePriceOfPersistentVolume returns storage price of a persistent volume, default price when it isn't priced
+func retrieveStoragePriceOfPersistentVolume(uid string) float64 {
+	query := `query {
+		pvs(func: uid(` + uid + `)) {
+			storagePrice
+		}
+	}`
+	type root struct {
+		Pvs []PersistentVolume `json:"pvs"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil || len(newRoot.Pvs) < 1 || newRoot.Pvs[0].StoragePrice <= 0 {
+		return DefaultStorageCostInFloat64
+	}
+	return newRoot.Pvs[0].StoragePrice
+}
+
 // CreateOrGetPersistentVolumeByID returns the uid of persistent volume if exists,
 // otherwise creates the persistent volume and returns uid.
 func CreateOrGetPersistentVolumeByID(xid string) string {
@@ -0,0 +1,56 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// TestSetStorageDetails ...
+func TestSetStorageDetails(t *testing.T) {
+	pv := PersistentVolume{Provisioner: AWSEBSProvisioner, StorageCapacity: 100}
+	setStorageDetails(&pv, api_v1.PersistentVolume{}, map[string]string{"type": "io1", "iopsPerGB": "10"})
+	assert.Equal(t, "io1", pv.VolumeType)
+	assert.Equal(t, 1000.0, pv.IOPS)
+	assert.Equal(t, "iopsPerGB=10,type=io1", pv.StorageParameters)
+
+	pv = PersistentVolume{Provisioner: AWSEBSCSIProvisioner}
+	setStorageDetails(&pv, api_v1.PersistentVolume{}, map[string]string{"iops": "4000"})
+	assert.Equal(t, "gp3", pv.VolumeType)
+	assert.Equal(t, 4000.0, pv.IOPS)
+
+	pv = PersistentVolume{Provisioner: AzureDiskCSIProvisioner}
+	setStorageDetails(&pv, api_v1.PersistentVolume{}, map[string]string{"skuName": "Premium_LRS"})
+	assert.Equal(t, "Premium", pv.VolumeType)
+
+	pv = PersistentVolume{Provisioner: "csi.vsphere.vmware.com"}
+	setStorageDetails(&pv, api_v1.PersistentVolume{}, nil)
+	assert.Equal(t, "", pv.VolumeType)
+	assert.Equal(t, 0.0, pv.IOPS)
+}
+
+// TestGetInTreeProvisioner ...
+func TestGetInTreeProvisioner(t *testing.T) {
+	pv := api_v1.PersistentVolume{}
+	assert.Equal(t, "", getInTreeProvisioner(pv))
+	pv.Spec.GCEPersistentDisk = &api_v1.GCEPersistentDiskVolumeSource{PDName: "disk-1"}
+	assert.Equal(t, GCEPDProvisioner, getInTreeProvisioner(pv))
+}
@@ -43,6 +43,8 @@ type PersistentVolumeClaim struct {
 	Type                    string            `json:"type,omitempty"`
 	StorageCapacity         float64           `json:"storageCapacity,omitempty"`
 	PersistentVolume        *PersistentVolume `json:"pv,omitempty"`
+	StoragePrice            float64           `json:"storagePrice,omitempty"`
+	PriceSegments           []*PriceSegment   `json:"priceSegments,omitempty"`
 }
 
 func createPvcObject(pvc api_v1.PersistentVolumeClaim) PersistentVolumeClaim {
@@ -58,8 +60,10 @@ func createPvcObject(pvc api_v1.PersistentVolumeClaim) PersistentVolumeClaim {
 
 	volume := pvc.Spec.VolumeName
 	pvUID := CreateOrGetPersistentVolumeByID(volume)
+	newPvc.StoragePrice = DefaultStorageCostInFloat64
 	if volume != "" {
 		newPvc.PersistentVolume = &PersistentVolume{ID: dgraph.ID{UID: pvUID}}
+		newPvc.StoragePrice = retrieveStoragePriceOfPersistentVolume(pvUID)
 	}
 
 	namespaceUID := CreateOrGetNamespaceByID(pvc.Namespace)
@@ -84,6 +88,15 @@ func StorePersistentVolumeClaim(pvc api_v1.PersistentVolumeClaim) (string, error
 	if uid != "" {
 		newPvc.UID = uid
 	}
+	if newPvc.EndTime != "" && uid != "" {
+		// price of the claim stays as it was during its lifetime
+		newPvc.StoragePrice = 0
+		newPvc.PriceSegments = getPriceSegmentsOnEnd(uid, newPvc.EndTime)
+	} else {
+		newPvc.PriceSegments = getPriceSegmentsOnPriceChange(uid, time.Now(), func(prices *ResourcePrices) {
+			prices.StoragePrice = newPvc.StoragePrice
+		})
+	}
 	assigned, err := dgraph.MutateNode(newPvc, dgraph.CREATE)
 	if err != nil {
 		return "", err
@@ -122,6 +135,7 @@ func getPVCFromUID(uid string) (PersistentVolumeClaim, error) {
 			name
 			type
 			storageCapacity
+			storagePrice
 		}
 	}`
 
@@ -0,0 +1,337 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+// Constants of anomaly detection. Cost of a namespace or group in an hour is anomalous when it is at least
+// anomalyScore standard deviations and minAnomalyIncrease(fraction) above its mean hourly cost over the
+// anomalyBaselineHours hours before it. Standard deviation of the baseline is at least minAnomalyCost.
+const (
+	anomalyBaselineHours = 7 * 24
+	minBaselineHours     = 24
+	anomalyScore         = 3
+	minAnomalyIncrease   = 0.25
+	minAnomalyCost       = 0.01
+	maxAnomalyWorkloads  = 5
+)
+
+// ErrInvalidAnomalyKind is returned for anomalies of resources other than namespaces and groups
+var ErrInvalidAnomalyKind = errors.New("kind of anomaly must be namespace or group")
+
+var anomalyWorkloadKinds = []string{DeploymentType, StatefulsetType, DaemonsetType, JobType}
+
+// WorkloadContribution is cost of a workload in the hour of an anomaly along with its mean hourly cost in the baseline
+type WorkloadContribution struct {
+	Kind         string  `json:"kind"`
+	Name         string  `json:"name"`
+	Cost         float64 `json:"cost"`
+	BaselineCost float64 `json:"baselineCost"`
+}
+
+// CostAnomaly is an hour in which cost of a namespace or group rose well above its baseline, baseline cost is its mean
+// hourly cost in the week before and score is the rise in standard deviations of the baseline. TopWorkloads are the
+// workloads whose cost rose the most.
+type CostAnomaly struct {
+	Kind           string                 `json:"kind"`
+	Name           string                 `json:"name"`
+	Start          string                 `json:"start"`
+	End            string                 `json:"end"`
+	Cost           float64                `json:"cost"`
+	BaselineCost   float64                `json:"baselineCost"`
+	BaselineStdDev float64                `json:"baselineStdDev"`
+	Score          float64                `json:"score"`
+	TopWorkloads   []WorkloadContribution `json:"topWorkloads"`
+}
+
+// costBaseline is cost of a resource in an hour along with sum and sum of squares of its cost in the hours before
+type costBaseline struct {
+	cost       float64
+	hasCost    bool
+	sum        float64
+	sumSquares float64
+	hours      int
+}
+
+type costAnomaliesRoot struct {
+	Anomalies []models.CostAnomaly `json:"anomalies"`
+}
+
+// DetectCostAnomalies compares cost of namespaces and groups in the hour starting at hourStart with their baseline.
+// groupWorkloads has workloads(kind/name) of pods of every group, top workloads of a group are chosen from them.
+func DetectCostAnomalies(hourStart time.Time, groupWorkloads map[string]map[string]bool) ([]CostAnomaly, error) {
+	window := Window{Start: hourStart.Add(-anomalyBaselineHours * time.Hour), End: hourStart.Add(time.Hour)}
+	snapshots := map[string][]models.CostSnapshot{}
+	for _, kind := range append([]string{NamespaceType, GroupType}, anomalyWorkloadKinds...) {
+		root := costSnapshotsRoot{}
+		err := executeQuery(getQueryForCostSnapshots(kind, All, window), &root)
+		if err != nil {
+			return nil, err
+		}
+		for i := range root.Snapshots {
+			root.Snapshots[i].SnapshotKind = kind
+		}
+		snapshots[kind] = root.Snapshots
+	}
+
+	var workloadSnapshots []models.CostSnapshot
+	for _, kind := range anomalyWorkloadKinds {
+		workloadSnapshots = append(workloadSnapshots, snapshots[kind]...)
+	}
+	workloads, workloadHours := getCostBaselines(workloadSnapshots, hourStart)
+
+	anomalies := detectCostAnomalies(NamespaceType, snapshots[NamespaceType], hourStart)
+	for i := range anomalies {
+		prefix := anomalies[i].Name + ":"
+		anomalies[i].TopWorkloads = getTopWorkloads(workloads, workloadHours, func(workload string) bool {
+			return strings.HasPrefix(workload[strings.Index(workload, "/")+1:], prefix)
+		})
+	}
+	groupAnomalies := detectCostAnomalies(GroupType, snapshots[GroupType], hourStart)
+	for i := range groupAnomalies {
+		groupWorkload := groupWorkloads[groupAnomalies[i].Name]
+		groupAnomalies[i].TopWorkloads = getTopWorkloads(workloads, workloadHours, func(workload string) bool {
+			return groupWorkload[workload]
+		})
+	}
+	return append(anomalies, groupAnomalies...), nil
+}
+
+// detectCostAnomalies returns anomalies of resources of the kind in the hour starting at hourStart, resources with less
+// than a day of baseline are skipped
+func detectCostAnomalies(kind string, snapshots []models.CostSnapshot, hourStart time.Time) []CostAnomaly {
+	baselines, _ := getCostBaselines(snapshots, hourStart)
+	anomalies := []CostAnomaly{}
+	for key, baseline := range baselines {
+		if !baseline.hasCost || baseline.hours < minBaselineHours {
+			continue
+		}
+		mean, stdDev := baseline.getMeanAndStdDev()
+		increase := baseline.cost - mean
+		score := increase / math.Max(stdDev, minAnomalyCost)
+		if increase < minAnomalyCost || baseline.cost < mean*(1+minAnomalyIncrease) || score < anomalyScore {
+			continue
+		}
+		anomalies = append(anomalies, CostAnomaly{
+			Kind:           kind,
+			Name:           key[strings.Index(key, "/")+1:],
+			Start:          utils.ConverTimeToRFC3339(hourStart),
+			End:            utils.ConverTimeToRFC3339(hourStart.Add(time.Hour)),
+			Cost:           baseline.cost,
+			BaselineCost:   mean,
+			BaselineStdDev: stdDev,
+			Score:          score,
+		})
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].Name < anomalies[j].Name
+	})
+	return anomalies
+}
+
+// getCostBaselines returns baseline of every resource(kind/name) of the snapshots along with the number of hours before
+// hourStart in which snapshots were taken
+func getCostBaselines(snapshots []models.CostSnapshot, hourStart time.Time) (map[string]*costBaseline, int) {
+	baselines := map[string]*costBaseline{}
+	hours := map[time.Time]bool{}
+	for _, snapshot := range snapshots {
+		snapshotTime, err := time.Parse(time.RFC3339, snapshot.SnapshotTime)
+		if err != nil {
+			logrus.Debugf("invalid time of cost snapshot: %s, err: %v", snapshot.SnapshotTime, err)
+			continue
+		}
+		key := snapshot.SnapshotKind + "/" + snapshot.SnapshotName
+		baseline, isPresent := baselines[key]
+		if !isPresent {
+			baseline = &costBaseline{}
+			baselines[key] = baseline
+		}
+		if snapshotTime.Equal(hourStart) {
+			baseline.cost += snapshot.Cost
+			baseline.hasCost = true
+		} else if snapshotTime.Before(hourStart) {
+			baseline.sum += snapshot.Cost
+			baseline.sumSquares += snapshot.Cost * snapshot.Cost
+			baseline.hours++
+			hours[snapshotTime] = true
+		}
+	}
+	return baselines, len(hours)
+}
+
+func (b costBaseline) getMeanAndStdDev() (float64, float64) {
+	if b.hours == 0 {
+		return 0, 0
+	}
+	mean := b.sum / float64(b.hours)
+	if b.hours == 1 {
+		return mean, 0
+	}
+	variance := (b.sumSquares - float64(b.hours)*mean*mean) / float64(b.hours-1)
+	return mean, math.Sqrt(math.Max(variance, 0))
+}
+
+// getTopWorkloads returns workloads(kind/name) accepted by the filter whose cost rose the most over their mean hourly
+// cost in the baseline, hours in which a workload wasn't present count as zero cost
+func getTopWorkloads(workloads map[string]*costBaseline, baselineHours int, accept func(string) bool) []WorkloadContribution {
+	contributions := []WorkloadContribution{}
+	for key, workload := range workloads {
+		if !accept(key) {
+			continue
+		}
+		baselineCost := 0.0
+		if baselineHours > 0 {
+			baselineCost = workload.sum / float64(baselineHours)
+		}
+		if workload.cost <= baselineCost {
+			continue
+		}
+		separator := strings.Index(key, "/")
+		contributions = append(contributions, WorkloadContribution{
+			Kind:         key[:separator],
+			Name:         key[separator+1:],
+			Cost:         workload.cost,
+			BaselineCost: baselineCost,
+		})
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		return contributions[i].Cost-contributions[i].BaselineCost > contributions[j].Cost-contributions[j].BaselineCost
+	})
+	if len(contributions) > maxAnomalyWorkloads {
+		contributions = contributions[:maxAnomalyWorkloads]
+	}
+	return contributions
+}
+
+// RetrievePodsWorkloads returns workloads(kind/name) owning the pods, deployments are found through replicasets
+func RetrievePodsWorkloads(podsUIDs string) (map[string]bool, error) {
+	workloads := map[string]bool{}
+	if podsUIDs == "" {
+		return workloads, nil
+	}
+	root := podRoot{}
+	err := executeQuery(getQueryForPodsWorkloads(podsUIDs), &root)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range root.Pods {
+		if pod.Replicaset != nil && pod.Replicaset.Deployment != nil {
+			workloads[DeploymentType+"/"+pod.Replicaset.Deployment.Xid] = true
+		}
+		if pod.Statefulset != nil {
+			workloads[StatefulsetType+"/"+pod.Statefulset.Xid] = true
+		}
+		if pod.Daemonset != nil {
+			workloads[DaemonsetType+"/"+pod.Daemonset.Xid] = true
+		}
+		if pod.Job != nil {
+			workloads[JobType+"/"+pod.Job.Xid] = true
+		}
+	}
+	return workloads, nil
+}
+
+// NewCostAnomalyModel returns dgraph model of the anomaly
+func NewCostAnomalyModel(anomaly CostAnomaly) models.CostAnomaly {
+	start, err := time.Parse(time.RFC3339, anomaly.Start)
+	if err != nil {
+		logrus.Debugf("invalid start of cost anomaly: %s, err: %v", anomaly.Start, err)
+	}
+	model := models.NewCostAnomaly(anomaly.Kind, anomaly.Name, start)
+	model.Cost = anomaly.Cost
+	model.BaselineCost = anomaly.BaselineCost
+	model.BaselineStdDev = anomaly.BaselineStdDev
+	model.Score = anomaly.Score
+	for _, workload := range anomaly.TopWorkloads {
+		model.Contributors = append(model.Contributors, &models.AnomalyContributor{
+			WorkloadKind: workload.Kind,
+			WorkloadName: workload.Name,
+			Cost:         workload.Cost,
+			BaselineCost: workload.BaselineCost,
+		})
+	}
+	return model
+}
+
+// RetrieveCostAnomalies returns anomalies of namespaces and groups(or only of the kind and name if given) detected in
+// hours starting in the window, latest first
+func RetrieveCostAnomalies(kind, name string, window Window) ([]CostAnomaly, error) {
+	switch kind {
+	case All, NamespaceType, GroupType:
+	default:
+		return nil, ErrInvalidAnomalyKind
+	}
+
+	root := costAnomaliesRoot{}
+	err := executeQuery(getQueryForCostAnomalies(kind, name, window), &root)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := []CostAnomaly{}
+	for _, model := range root.Anomalies {
+		anomalies = append(anomalies, newCostAnomaly(model))
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].Start != anomalies[j].Start {
+			return anomalies[i].Start > anomalies[j].Start
+		}
+		return anomalies[i].Score > anomalies[j].Score
+	})
+	return anomalies, nil
+}
+
+func newCostAnomaly(model models.CostAnomaly) CostAnomaly {
+	anomaly := CostAnomaly{
+		Kind:           model.AnomalyKind,
+		Name:           model.AnomalyName,
+		Start:          model.AnomalyTime,
+		Cost:           model.Cost,
+		BaselineCost:   model.BaselineCost,
+		BaselineStdDev: model.BaselineStdDev,
+		Score:          model.Score,
+		TopWorkloads:   []WorkloadContribution{},
+	}
+	if start, err := time.Parse(time.RFC3339, model.AnomalyTime); err == nil {
+		anomaly.Start = utils.ConverTimeToRFC3339(start)
+		anomaly.End = utils.ConverTimeToRFC3339(start.Add(time.Hour))
+	}
+	for _, contributor := range model.Contributors {
+		anomaly.TopWorkloads = append(anomaly.TopWorkloads, WorkloadContribution{
+			Kind:         contributor.WorkloadKind,
+			Name:         contributor.WorkloadName,
+			Cost:         contributor.Cost,
+			BaselineCost: contributor.BaselineCost,
+		})
+	}
+	sort.Slice(anomaly.TopWorkloads, func(i, j int) bool {
+		return anomaly.TopWorkloads[i].Cost-anomaly.TopWorkloads[i].BaselineCost >
+			anomaly.TopWorkloads[j].Cost-anomaly.TopWorkloads[j].BaselineCost
+	})
+	return anomaly
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+var anomalyHour = time.Date(2019, 6, 15, 12, 0, 0, 0, time.UTC)
+
+// getAnomalySnapshots returns snapshots of the 48 hours before anomalyHour alternating between the costs followed by
+// snapshot of anomalyHour with spikeCost
+func getAnomalySnapshots(kind, name string, costs [2]float64, spikeCost float64) []models.CostSnapshot {
+	var snapshots []models.CostSnapshot
+	for i := 48; i > 0; i-- {
+		snapshots = append(snapshots, models.CostSnapshot{
+			SnapshotKind: kind,
+			SnapshotName: name,
+			SnapshotTime: anomalyHour.Add(-time.Duration(i) * time.Hour).Format(time.RFC3339),
+			Cost:         costs[i%2],
+		})
+	}
+	return append(snapshots, models.CostSnapshot{
+		SnapshotKind: kind,
+		SnapshotName: name,
+		SnapshotTime: anomalyHour.Format(time.RFC3339),
+		Cost:         spikeCost,
+	})
+}
+
+func mockDgraphForCostAnomalyDetection() {
+	snapshots := map[string][]models.CostSnapshot{
+		NamespaceType: append(getAnomalySnapshots(NamespaceType, "default", [2]float64{1, 1.2}, 3),
+			getAnomalySnapshots(NamespaceType, "steady", [2]float64{1, 1.2}, 1.2)...),
+		GroupType: getAnomalySnapshots(GroupType, "team-a", [2]float64{2, 2.2}, 7),
+		DeploymentType: append(append(getAnomalySnapshots(DeploymentType, "default:web", [2]float64{0.5, 0.5}, 2),
+			getAnomalySnapshots(DeploymentType, "default:db", [2]float64{0.5, 0.5}, 0.5)...),
+			getAnomalySnapshots(DeploymentType, "other:api", [2]float64{1, 1}, 6)...),
+	}
+	executeQuery = func(query string, root interface{}) error {
+		dummyCostSnapshotsRoot, ok := root.(*costSnapshotsRoot)
+		if !ok {
+			return fmt.Errorf("wrong root received")
+		}
+		for kind, kindSnapshots := range snapshots {
+			if strings.Contains(query, `eq(snapshotKind, "`+kind+`")`) {
+				dummyCostSnapshotsRoot.Snapshots = kindSnapshots
+			}
+		}
+		return nil
+	}
+}
+
+// TestDetectCostAnomalies ...
+func TestDetectCostAnomalies(t *testing.T) {
+	mockDgraphForCostAnomalyDetection()
+	got, err := DetectCostAnomalies(anomalyHour, map[string]map[string]bool{
+		"team-a": {"deployment/other:api": true},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+
+	assert.Equal(t, NamespaceType, got[0].Kind)
+	assert.Equal(t, "default", got[0].Name)
+	assert.Equal(t, "2019-06-15T12:00:00Z", got[0].Start)
+	assert.Equal(t, "2019-06-15T13:00:00Z", got[0].End)
+	assert.InDelta(t, 3, got[0].Cost, 1e-9)
+	assert.InDelta(t, 1.1, got[0].BaselineCost, 1e-9)
+	assert.InDelta(t, 0.1010, got[0].BaselineStdDev, 1e-4)
+	assert.InDelta(t, 18.81, got[0].Score, 1e-2)
+	assert.Equal(t, []WorkloadContribution{{Kind: DeploymentType, Name: "default:web", Cost: 2, BaselineCost: 0.5}}, got[0].TopWorkloads)
+
+	assert.Equal(t, GroupType, got[1].Kind)
+	assert.Equal(t, "team-a", got[1].Name)
+	assert.Equal(t, []WorkloadContribution{{Kind: DeploymentType, Name: "other:api", Cost: 6, BaselineCost: 1}}, got[1].TopWorkloads)
+}
+
+// TestDetectCostAnomaliesWithShortBaseline ...
+func TestDetectCostAnomaliesWithShortBaseline(t *testing.T) {
+	snapshots := getAnomalySnapshots(NamespaceType, "default", [2]float64{1, 1.2}, 3)
+	assert.Len(t, detectCostAnomalies(NamespaceType, snapshots[30:], anomalyHour), 0)
+	assert.Len(t, detectCostAnomalies(NamespaceType, snapshots, anomalyHour), 1)
+}
+
+// TestGetTopWorkloads ...
+func TestGetTopWorkloads(t *testing.T) {
+	workloads := map[string]*costBaseline{}
+	for i := 1; i <= 7; i++ {
+		workloads[fmt.Sprintf("job/default:job-%d", i)] = &costBaseline{cost: float64(i), sum: 4, hours: 2}
+	}
+	workloads["job/default:job-new"] = &costBaseline{cost: 1.5, hasCost: true}
+
+	got := getTopWorkloads(workloads, 4, func(string) bool { return true })
+	assert.Len(t, got, maxAnomalyWorkloads)
+	assert.Equal(t, WorkloadContribution{Kind: JobType, Name: "default:job-7", Cost: 7, BaselineCost: 1}, got[0])
+	assert.Equal(t, "default:job-3", got[4].Name)
+
+	got = getTopWorkloads(workloads, 4, func(workload string) bool { return workload == "job/default:job-new" })
+	assert.Equal(t, []WorkloadContribution{{Kind: JobType, Name: "default:job-new", Cost: 1.5, BaselineCost: 0}}, got)
+}
+
+// TestRetrievePodsWorkloads ...
+func TestRetrievePodsWorkloads(t *testing.T) {
+	executeQuery = func(query string, root interface{}) error {
+		dummyPodRoot, ok := root.(*podRoot)
+		if !ok {
+			return fmt.Errorf("wrong root received")
+		}
+		dummyPodRoot.Pods = []models.Pod{
+			{Replicaset: &models.Replicaset{Deployment: &models.Deployment{ID: dgraph.ID{Xid: "default:web"}}}},
+			{Job: &models.Job{ID: dgraph.ID{Xid: "default:backup"}}},
+			{Name: "standalone"},
+		}
+		return nil
+	}
+	got, err := RetrievePodsWorkloads("0x1, 0x2, 0x3")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"deployment/default:web": true, "job/default:backup": true}, got)
+
+	got, err = RetrievePodsWorkloads("")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+// TestNewCostAnomaly ...
+func TestNewCostAnomaly(t *testing.T) {
+	anomaly := CostAnomaly{
+		Kind:           NamespaceType,
+		Name:           "default",
+		Start:          "2019-06-15T12:00:00Z",
+		End:            "2019-06-15T13:00:00Z",
+		Cost:           3,
+		BaselineCost:   1.1,
+		BaselineStdDev: 0.1,
+		Score:          19,
+		TopWorkloads: []WorkloadContribution{
+			{Kind: DeploymentType, Name: "default:db", Cost: 1, BaselineCost: 0.5},
+			{Kind: DeploymentType, Name: "default:web", Cost: 2, BaselineCost: 0.5},
+		},
+	}
+	model := NewCostAnomalyModel(anomaly)
+	assert.Equal(t, "anomaly-namespace-default-2019-06-15T12:00:00Z", model.Xid)
+	assert.Len(t, model.Contributors, 2)
+
+	got := newCostAnomaly(model)
+	assert.Equal(t, anomaly.Start, got.Start)
+	assert.Equal(t, anomaly.End, got.End)
+	assert.Equal(t, "default:web", got.TopWorkloads[0].Name)
+	assert.Equal(t, "default:db", got.TopWorkloads[1].Name)
+}
+
+// TestRetrieveCostAnomaliesWithInvalidKind ...
+func TestRetrieveCostAnomaliesWithInvalidKind(t *testing.T) {
+	_, err := RetrieveCostAnomalies(NodeType, All, Window{})
+	assert.Equal(t, ErrInvalidAnomalyKind, err)
+}
@@ -59,21 +59,22 @@ func RetrieveClusterHierarchy(view string) JSONDataWrapper {
 	return root
 }
 
-func getClusterMetricsQuery(view string) string {
+func getClusterMetricsQuery(view, costMode string, window Window) string {
 	switch view {
 	case Physical:
-		return getMetricsQueryForPhysicalResources()
+		return getMetricsQueryForPhysicalResources(window)
 	case Logical:
-		return getMetricsQueryForLogicalResources()
+		return getMetricsQueryForLogicalResources(costMode, window)
 	default:
 		return ""
 	}
 }
 
 // RetrieveClusterMetrics returns all namespaces with metrics if view is logical and
-// returns all nodes and disks with metrics if view is physical
-func RetrieveClusterMetrics(view string) JSONDataWrapper {
-	query := getClusterMetricsQuery(view)
+// returns all nodes and disks with metrics if view is physical. Pods in logical view are costed as per the cost mode.
+// Metrics are computed in the window, along with every step of the window if step is given.
+func RetrieveClusterMetrics(view, costMode string, window Window) JSONDataWrapper {
+	query := getClusterMetricsQuery(view, costMode, window)
 	parentRoot := ParentWrapper{}
 	err := executeQuery(query, &parentRoot)
 	calculateAggregateMetrics(&parentRoot)
@@ -92,8 +93,19 @@ func RetrieveClusterMetrics(view string) JSONDataWrapper {
 			CPUCost:     parentRoot.CPUCost,
 			MemoryCost:  parentRoot.MemoryCost,
 			StorageCost: parentRoot.StorageCost,
+			GPU:         parentRoot.GPU,
+			GPUCost:     parentRoot.GPUCost,
+
+			ExtendedResourceCost: parentRoot.ExtendedResourceCost,
+			ServiceCost:          parentRoot.ServiceCost,
 		},
 	}
+	if window.Step > 0 {
+		for _, step := range window.split() {
+			stepData := RetrieveClusterMetrics(view, costMode, step)
+			root.Series = append(root.Series, newSeriesPoint(step, stepData.Data))
+		}
+	}
 	logrus.Debugf("data: (%v)", root.Data)
 	return root
 }
@@ -106,6 +118,10 @@ func calculateAggregateMetrics(objRoot *ParentWrapper) {
 		objRoot.CPUCost += obj.CPUCost
 		objRoot.MemoryCost += obj.MemoryCost
 		objRoot.StorageCost += obj.StorageCost
+		objRoot.GPU += obj.GPU
+		objRoot.GPUCost += obj.GPUCost
+		objRoot.ExtendedResourceCost += obj.ExtendedResourceCost
+		objRoot.ServiceCost += obj.ServiceCost
 	}
 }
 
@@ -119,8 +135,8 @@ func PopulateClusterAllocationAndCapacity(jsonData *JSONDataWrapper) {
 
 // ComputeClusterAllocationAndCapacity returns allocated, capacity for cpu, memory and storage
 func ComputeClusterAllocationAndCapacity() {
-	allocation := RetrieveClusterMetrics(Logical)
-	capacity := RetrieveClusterMetrics(Physical)
+	allocation := RetrieveClusterMetrics(Logical, RequestCostMode, Window{})
+	capacity := RetrieveClusterMetrics(Physical, RequestCostMode, Window{})
 	allocatedAndCapacity = &ParentWrapper{
 		CPUAllocated:     allocation.Data.CPU,
 		MemoryAllocated:  allocation.Data.Memory,
@@ -131,9 +147,12 @@ func ComputeClusterAllocationAndCapacity() {
 	}
 }
 
-// PopulateNodeOrPVAllocationAndCapacity returns allocated, capacity for cpu, memory and storage
+// PopulateNodeOrPVAllocationAndCapacity returns allocated, capacity for cpu, memory and storage.
+// Allocation is always computed from requests irrespective of the cost mode.
 func (r *Resource) PopulateNodeOrPVAllocationAndCapacity(jsonData *JSONDataWrapper) {
-	q := r.getQueryForResourceMetrics()
+	allocation := *r
+	allocation.CostMode = RequestCostMode
+	q := allocation.getQueryForResourceMetrics()
 	resourceData := getJSONDataFromQuery(q)
 	populateCapacityData(resourceData.Data, jsonData)
 }
@@ -162,7 +162,7 @@ func TestRetrieveClusterHierarchyPhysicalView(t *testing.T) {
 // TestRetrieveClusterMetricsNoView ...
 func TestRetrieveClusterMetricsNoView(t *testing.T) {
 	mockDgraphForClusterQueries(testMetrics)
-	got := RetrieveClusterMetrics("")
+	got := RetrieveClusterMetrics("", RequestCostMode, Window{})
 	expected := JSONDataWrapper{}
 	assert.Equal(t, expected, got)
 }
@@ -170,7 +170,7 @@ func TestRetrieveClusterMetricsNoView(t *testing.T) {
 // TestRetrieveClusterMetricsLogicalView ...
 func TestRetrieveClusterMetricsLogicalView(t *testing.T) {
 	mockDgraphForClusterQueries(testMetrics)
-	got := RetrieveClusterMetrics(Logical)
+	got := RetrieveClusterMetrics(Logical, RequestCostMode, Window{})
 	firstNamespaceWithMetrics := Children{
 		Name:        "namespace-first",
 		Type:        NamespaceType,
@@ -211,7 +211,7 @@ func TestRetrieveClusterMetricsLogicalView(t *testing.T) {
 // TestRetrieveClusterMetricsPhysicalView ...
 func TestRetrieveClusterMetricsPhysicalView(t *testing.T) {
 	mockDgraphForClusterQueries(testMetrics)
-	got := RetrieveClusterMetrics(Physical)
+	got := RetrieveClusterMetrics(Physical, RequestCostMode, Window{})
 	firstNamespaceWithMetrics := Children{
 		Name:        "namespace-first",
 		Type:        NamespaceType,
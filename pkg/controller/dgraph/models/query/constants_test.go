@@ -31,12 +31,11 @@ const (
 	testRetrieveAllGroups    = "retrieveAllGroups"
 	testRetrieveGroupMetrics = "retrieveGroupMetrics"
 	testRetrieveSubscribers  = "retrieveSubscribers"
+	testRetrieveRateCard     = "retrieveRateCard"
+	testRateCardHistory      = "rateCardHistory"
 	testLabelFilterPods      = "labelFilterPods"
 	testAlivePods            = "alivePods"
 	testPodInteractions      = "podInteractions"
-	testPodPrices            = "podPrices"
 	testCapacity             = "capacityAllocation"
 	testWrongQuery           = "wrongQuery"
-	testCPUPrice             = 0.24
-	testMemoryPrice          = 0.1
 )
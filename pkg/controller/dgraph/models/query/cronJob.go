@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/robfig/cron"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+// maxRunsInMonth caps the number of runs counted while projecting cost of a cronjob(every minute schedule has 43200)
+const maxRunsInMonth = 50000
+
+// JobRunCost is cost of a finished run of a job
+type JobRunCost struct {
+	Job        string  `json:"job"`
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	Succeeded  bool    `json:"succeeded"`
+	Duration   float64 `json:"durationInHours"`
+	CPU        float64 `json:"cpu"`
+	Memory     float64 `json:"memory"`
+	GPU        float64 `json:"gpu,omitempty"`
+	NodePrice  float64 `json:"nodePrice"`
+	CPUCost    float64 `json:"cpuCost"`
+	MemoryCost float64 `json:"memoryCost"`
+	GPUCost    float64 `json:"gpuCost,omitempty"`
+	Cost       float64 `json:"cost"`
+}
+
+// CronJobCost is cost of runs of a cronjob which ended in a window, monthly cost is projected from the average cost
+// of its runs and the number of runs its schedule has in a month
+type CronJobCost struct {
+	Name                 string       `json:"name"`
+	Schedule             string       `json:"schedule"`
+	Suspend              bool         `json:"suspend"`
+	Start                string       `json:"start"`
+	End                  string       `json:"end"`
+	Runs                 []JobRunCost `json:"runs"`
+	RunsCount            int          `json:"runsCount"`
+	Cost                 float64      `json:"cost"`
+	AverageRunCost       float64      `json:"averageRunCost"`
+	ProjectedMonthlyCost float64      `json:"projectedMonthlyCost"`
+}
+
+type cronJobRuns struct {
+	Xid      string          `json:"xid"`
+	Schedule string          `json:"schedule"`
+	Suspend  bool            `json:"suspend"`
+	Runs     []models.JobRun `json:"runs"`
+}
+
+type cronJobsRoot struct {
+	CronJobs []cronJobRuns `json:"cronJobs"`
+}
+
+// RetrieveCronJobsCost returns cost of runs of cronjobs(or only the named one) alive in the window
+func RetrieveCronJobsCost(name string, window Window) ([]CronJobCost, error) {
+	root := cronJobsRoot{}
+	err := executeQuery(getQueryForCronJobRuns(name, window), &root)
+	if err != nil {
+		return nil, err
+	}
+
+	cronJobsCost := []CronJobCost{}
+	for _, cronJob := range root.CronJobs {
+		cronJobsCost = append(cronJobsCost, newCronJobCost(cronJob, window, time.Now()))
+	}
+	return cronJobsCost, nil
+}
+
+func newCronJobCost(cronJob cronJobRuns, window Window, now time.Time) CronJobCost {
+	cronJobCost := CronJobCost{
+		Name:     cronJob.Xid,
+		Schedule: cronJob.Schedule,
+		Suspend:  cronJob.Suspend,
+		Start:    utils.ConverTimeToRFC3339(window.getStart()),
+		End:      utils.ConverTimeToRFC3339(window.getEnd()),
+		Runs:     []JobRunCost{},
+	}
+	for _, run := range cronJob.Runs {
+		cronJobCost.Runs = append(cronJobCost.Runs, JobRunCost{
+			Job:        run.JobName,
+			Start:      run.RunStartTime,
+			End:        run.RunEndTime,
+			Succeeded:  run.Succeeded,
+			Duration:   run.RunHours,
+			CPU:        run.CPU,
+			Memory:     run.Memory,
+			GPU:        run.GPU,
+			NodePrice:  run.NodePrice,
+			CPUCost:    run.CPUCost,
+			MemoryCost: run.MemoryCost,
+			GPUCost:    run.GPUCost,
+			Cost:       run.Cost,
+		})
+		cronJobCost.Cost += run.Cost
+	}
+	cronJobCost.RunsCount = len(cronJobCost.Runs)
+	if cronJobCost.RunsCount > 0 {
+		cronJobCost.AverageRunCost = cronJobCost.Cost / float64(cronJobCost.RunsCount)
+	}
+	if !cronJob.Suspend {
+		cronJobCost.ProjectedMonthlyCost = cronJobCost.AverageRunCost * float64(getRunsInMonth(cronJob.Schedule, now))
+	}
+	return cronJobCost
+}
+
+// getRunsInMonth returns number of runs of the schedule in a month starting from now
+func getRunsInMonth(schedule string, now time.Time) int {
+	parsedSchedule, err := cron.ParseStandard(schedule)
+	if err != nil {
+		logrus.Debugf("unable to parse schedule: %s, err: %v", schedule, err)
+		return 0
+	}
+	monthEnd := now.Add(models.HoursInMonth * time.Hour)
+	runs := 0
+	for next := parsedSchedule.Next(now); !next.IsZero() && next.Before(monthEnd) && runs < maxRunsInMonth; next = parsedSchedule.Next(next) {
+		runs++
+	}
+	return runs
+}
+
+// getQueryForCronJobRuns returns query for runs of cronjobs which ended in the window, name of a cronjob is
+// namespace prefixed(ex: default:backup)
+func getQueryForCronJobRuns(name string, window Window) string {
+	root := `cronJobs(func: has(isCronJob)) @filter(` + window.getLiveFilter() + `)`
+	if name != All {
+		root = `cronJobs(func: eq(xid, "` + name + `")) @filter(has(isCronJob))`
+	}
+	return `query {
+		` + root + ` {
+			xid
+			schedule
+			suspend
+			runs: ~cronJob (orderasc: runStartTime) @filter(has(isJobRun) AND ge(runEndTime, "` + utils.ConverTimeToRFC3339(window.getStart()) +
+		`") AND lt(runEndTime, "` + utils.ConverTimeToRFC3339(window.getEnd()) + `")) {
+				jobName
+				runStartTime
+				runEndTime
+				succeeded
+				runHours
+				cpu
+				memory
+				gpu
+				nodePrice
+				cpuCost
+				memoryCost
+				gpuCost
+				cost
+			}
+		}
+	}`
+}
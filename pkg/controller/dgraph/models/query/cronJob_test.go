@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// TestGetRunsInMonth ...
+func TestGetRunsInMonth(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 30, 0, 0, time.UTC)
+	assert.Equal(t, 720, getRunsInMonth("0 * * * *", now))
+	assert.Equal(t, 30, getRunsInMonth("0 2 * * *", now))
+	assert.Equal(t, 30, getRunsInMonth("@daily", now))
+	assert.Equal(t, 0, getRunsInMonth("not a schedule", now))
+}
+
+// TestNewCronJobCost ...
+func TestNewCronJobCost(t *testing.T) {
+	now := time.Date(2019, 6, 1, 0, 30, 0, 0, time.UTC)
+	cronJob := cronJobRuns{
+		Xid:      "default:backup",
+		Schedule: "0 2 * * *",
+		Runs: []models.JobRun{
+			{JobName: "default:backup-1", RunHours: 1, Cost: 0.2, Succeeded: true},
+			{JobName: "default:backup-2", RunHours: 2, Cost: 0.4},
+		},
+	}
+	cronJobCost := newCronJobCost(cronJob, Window{}, now)
+
+	assert.Equal(t, "default:backup", cronJobCost.Name)
+	assert.Equal(t, 2, cronJobCost.RunsCount)
+	assert.Equal(t, "default:backup-1", cronJobCost.Runs[0].Job)
+	assert.True(t, cronJobCost.Runs[0].Succeeded)
+	assert.InDelta(t, 0.6, cronJobCost.Cost, 1e-12)
+	assert.InDelta(t, 0.3, cronJobCost.AverageRunCost, 1e-12)
+	assert.InDelta(t, 9.0, cronJobCost.ProjectedMonthlyCost, 1e-12)
+
+	// suspended cronjobs don't run
+	cronJob.Suspend = true
+	assert.Equal(t, 0.0, newCronJobCost(cronJob, Window{}, now).ProjectedMonthlyCost)
+}
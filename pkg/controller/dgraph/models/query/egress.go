@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"sort"
+
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+// TrafficCost is traffic of a class sent by a resource in a window along with its cost
+type TrafficCost struct {
+	TrafficClass string  `json:"trafficClass"`
+	Connections  int     `json:"connections"`
+	EgressGB     float64 `json:"egressGB"`
+	Cost         float64 `json:"cost"`
+}
+
+// EgressCost is egress of a pod, namespace or group in a window split by class of traffic
+type EgressCost struct {
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Start    string        `json:"start"`
+	End      string        `json:"end"`
+	Traffic  []TrafficCost `json:"traffic"`
+	EgressGB float64       `json:"egressGB"`
+	Cost     float64       `json:"cost"`
+}
+
+type egressSample struct {
+	TrafficClass string  `json:"trafficClass"`
+	Connections  int     `json:"connections"`
+	EgressGB     float64 `json:"egressGB"`
+	EgressCost   float64 `json:"egressCost"`
+}
+
+type podEgress struct {
+	Xid       string `json:"xid"`
+	Namespace struct {
+		Xid string `json:"xid"`
+	} `json:"namespace"`
+	EgressSamples []egressSample `json:"egressSamples"`
+}
+
+type podsEgressRoot struct {
+	Pods []podEgress `json:"pods"`
+}
+
+// RetrieveEgressCosts returns egress of pods or namespaces(or only the named one) in the window
+func RetrieveEgressCosts(kind, name string, window Window) ([]EgressCost, error) {
+	root := podsEgressRoot{}
+	err := executeQuery(getQueryForPodsEgress(kind, name, window), &root)
+	if err != nil {
+		return nil, err
+	}
+
+	egressCosts := map[string]*EgressCost{}
+	for _, pod := range root.Pods {
+		resourceName := pod.Xid
+		if kind == NamespaceType {
+			resourceName = pod.Namespace.Xid
+		}
+		if name != All && resourceName != name {
+			continue
+		}
+		if _, isPresent := egressCosts[resourceName]; !isPresent {
+			egressCosts[resourceName] = newEgressCost(kind, resourceName, window)
+		}
+		addSamplesToEgressCost(egressCosts[resourceName], pod.EgressSamples)
+	}
+
+	result := []EgressCost{}
+	for _, egressCost := range egressCosts {
+		result = append(result, *egressCost)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// RetrieveEgressCostFromPodUIDs returns egress of the group having given pods in the window
+func RetrieveEgressCostFromPodUIDs(name, podsUIDs string, window Window) (EgressCost, error) {
+	egressCost := newEgressCost(GroupType, name, window)
+	if podsUIDs == "" {
+		return *egressCost, nil
+	}
+
+	root := podsEgressRoot{}
+	err := executeQuery(getQueryForPodsEgressFromUIDs(podsUIDs, window), &root)
+	if err != nil {
+		return EgressCost{}, err
+	}
+	for _, pod := range root.Pods {
+		addSamplesToEgressCost(egressCost, pod.EgressSamples)
+	}
+	return *egressCost, nil
+}
+
+func newEgressCost(kind, name string, window Window) *EgressCost {
+	return &EgressCost{
+		Kind:    kind,
+		Name:    name,
+		Start:   utils.ConverTimeToRFC3339(window.getStart()),
+		End:     utils.ConverTimeToRFC3339(window.getEnd()),
+		Traffic: []TrafficCost{},
+	}
+}
+
+// addSamplesToEgressCost adds samples to the traffic of their class, traffic is kept sorted by class
+func addSamplesToEgressCost(egressCost *EgressCost, samples []egressSample) {
+	for _, sample := range samples {
+		traffic := getTrafficOfClass(egressCost, sample.TrafficClass)
+		traffic.Connections += sample.Connections
+		traffic.EgressGB += sample.EgressGB
+		traffic.Cost += sample.EgressCost
+		egressCost.EgressGB += sample.EgressGB
+		egressCost.Cost += sample.EgressCost
+	}
+	sort.Slice(egressCost.Traffic, func(i, j int) bool {
+		return egressCost.Traffic[i].TrafficClass < egressCost.Traffic[j].TrafficClass
+	})
+}
+
+func getTrafficOfClass(egressCost *EgressCost, trafficClass string) *TrafficCost {
+	for index := range egressCost.Traffic {
+		if egressCost.Traffic[index].TrafficClass == trafficClass {
+			return &egressCost.Traffic[index]
+		}
+	}
+	egressCost.Traffic = append(egressCost.Traffic, TrafficCost{TrafficClass: trafficClass})
+	return &egressCost.Traffic[len(egressCost.Traffic)-1]
+}
+
+// getQueryForPodsEgress returns query for egress samples of pods in the window, name of a pod is its xid
+func getQueryForPodsEgress(kind, name string, window Window) string {
+	root := `pods(func: has(isPod)) @filter(has(egressSamples))`
+	if kind == PodType && name != All {
+		root = `pods(func: eq(xid, "` + name + `")) @filter(has(isPod))`
+	}
+	return `query {
+		` + root + ` {
+			xid
+			namespace {
+				xid
+			}
+			` + getEgressSamplesInWindow(window) + `
+		}
+	}`
+}
+
+func getQueryForPodsEgressFromUIDs(podsUIDs string, window Window) string {
+	return `query {
+		pods(func: uid(` + podsUIDs + `)) {
+			xid
+			` + getEgressSamplesInWindow(window) + `
+		}
+	}`
+}
+
+func getEgressSamplesInWindow(window Window) string {
+	return `egressSamples @filter(ge(sampleTime, "` + utils.ConverTimeToRFC3339(window.getStart()) + `") AND lt(sampleTime, "` +
+		utils.ConverTimeToRFC3339(window.getEnd()) + `")) {
+				trafficClass
+				connections
+				egressGB
+				egressCost
+			}`
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAddSamplesToEgressCost ...
+func TestAddSamplesToEgressCost(t *testing.T) {
+	egressCost := newEgressCost(NamespaceType, "default", Window{})
+	addSamplesToEgressCost(egressCost, []egressSample{
+		{TrafficClass: "internet", Connections: 2, EgressGB: 1, EgressCost: 0.09},
+		{TrafficClass: "crossZone", Connections: 1, EgressGB: 2, EgressCost: 0.02},
+	})
+	addSamplesToEgressCost(egressCost, []egressSample{
+		{TrafficClass: "internet", Connections: 1, EgressGB: 1, EgressCost: 0.09},
+	})
+
+	assert.Equal(t, []TrafficCost{
+		{TrafficClass: "crossZone", Connections: 1, EgressGB: 2, Cost: 0.02},
+		{TrafficClass: "internet", Connections: 3, EgressGB: 2, Cost: 0.18},
+	}, egressCost.Traffic)
+	assert.Equal(t, 4.0, egressCost.EgressGB)
+	assert.InDelta(t, 0.2, egressCost.Cost, 1e-12)
+}
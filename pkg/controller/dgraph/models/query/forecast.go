@@ -0,0 +1,360 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Constants used in forecasts of cost, kind of a forecast is one of cluster, namespace or group. Forecasts are fitted
+// on daily cost of the resource over the last forecastHistoryDays days.
+const (
+	ClusterType = "cluster"
+
+	forecastHistoryDays    = 56
+	minSeasonalHistoryDays = 14
+	forecastDays           = 30
+	seasonalFitIterations  = 20
+	confidenceZScore       = 1.96
+)
+
+// ErrInvalidForecastKind is returned for forecasts of resources other than cluster, namespaces and groups
+var ErrInvalidForecastKind = errors.New("kind of forecast must be one of cluster, namespace or group")
+
+// CostProjection is projected cost of a resource over a period, lower and upper are the bounds of its 95% confidence
+// band
+type CostProjection struct {
+	Start string  `json:"start"`
+	End   string  `json:"end"`
+	Cost  float64 `json:"cost"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// CostForecast is projection of cost of a resource till the end of current month and over the next 30 days. End of
+// month projection includes cost of the month till now. DailyTrend is the change of daily cost per day and Seasonal is
+// true if weekly seasonality is part of the forecast.
+type CostForecast struct {
+	Kind            string         `json:"kind"`
+	Name            string         `json:"name"`
+	HistoryDays     int            `json:"historyDays"`
+	DailyTrend      float64        `json:"dailyTrend"`
+	Seasonal        bool           `json:"seasonal"`
+	MonthToDateCost float64        `json:"monthToDateCost"`
+	EndOfMonth      CostProjection `json:"endOfMonth"`
+	Next30Days      CostProjection `json:"next30Days"`
+}
+
+// costHistory is cost and hours covered of every day since start of the history, days are indexed from the start
+type costHistory struct {
+	dailyCost       map[int]float64
+	dailyHours      map[int]int
+	monthToDateCost float64
+}
+
+// forecastModel is linear trend of daily cost with mean deviation from the trend on every weekday
+type forecastModel struct {
+	start      time.Time
+	intercept  float64
+	slope      float64
+	seasonal   [7]float64
+	isSeasonal bool
+	stdDev     float64
+	days       int
+}
+
+// RetrieveCostForecasts returns forecasts of resources of the kind(or only the named one) from their cost snapshots.
+// Cost of the cluster is the sum of cost of its namespaces.
+func RetrieveCostForecasts(kind, name string) ([]CostForecast, error) {
+	snapshotKind := kind
+	switch kind {
+	case ClusterType:
+		snapshotKind, name = NamespaceType, All
+	case NamespaceType, GroupType:
+	default:
+		return nil, ErrInvalidForecastKind
+	}
+
+	now := time.Now()
+	window := Window{Start: getDayStart(now).AddDate(0, 0, -forecastHistoryDays), End: now}
+	root := costSnapshotsRoot{}
+	err := executeQuery(getQueryForCostSnapshots(snapshotKind, name, window), &root)
+	if err != nil {
+		return nil, err
+	}
+	return getCostForecasts(kind, root.Snapshots, now), nil
+}
+
+// ForecastGroupCost returns forecast of cost of a group of pods from its cost in every step of the series, steps must
+// be a day long and start at the start of a day
+func ForecastGroupCost(groupCost GroupCost, now time.Time) CostForecast {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	history := &costHistory{dailyCost: map[int]float64{}, dailyHours: map[int]int{}}
+	var historyStart time.Time
+	for _, step := range groupCost.Series {
+		stepStart, err := time.Parse(time.RFC3339, step.Start)
+		if err != nil {
+			logrus.Debugf("invalid start of group cost step: %s, err: %v", step.Start, err)
+			continue
+		}
+		stepEnd, err := time.Parse(time.RFC3339, step.End)
+		if err != nil {
+			logrus.Debugf("invalid end of group cost step: %s, err: %v", step.End, err)
+			continue
+		}
+		hours := int(math.Round(stepEnd.Sub(stepStart).Hours()))
+		if hours <= 0 {
+			continue
+		}
+		stepStart = stepStart.In(now.Location())
+		if historyStart.IsZero() {
+			historyStart = getDayStart(stepStart)
+		}
+
+		day := getDayIndex(historyStart, stepStart)
+		history.dailyCost[day] += step.Cost
+		history.dailyHours[day] += hours
+		if !stepStart.Before(monthStart) {
+			history.monthToDateCost += step.Cost
+		}
+	}
+	model := fitForecastModel(history, historyStart)
+	return model.newCostForecast(GroupType, groupCost.Name, history.monthToDateCost, now)
+}
+
+// getCostForecasts fits a model on history of every resource in the snapshots and projects its cost from now
+func getCostForecasts(kind string, snapshots []models.CostSnapshot, now time.Time) []CostForecast {
+	historyStart := getDayStart(now).AddDate(0, 0, -forecastHistoryDays)
+	histories := getCostHistories(kind, snapshots, historyStart, now)
+
+	forecasts := []CostForecast{}
+	for name, history := range histories {
+		model := fitForecastModel(history, historyStart)
+		forecasts = append(forecasts, model.newCostForecast(kind, name, history.monthToDateCost, now))
+	}
+	sort.Slice(forecasts, func(i, j int) bool {
+		return forecasts[i].Name < forecasts[j].Name
+	})
+	return forecasts
+}
+
+// getCostHistories adds snapshots to the day they were taken in, snapshots of the cluster are added up
+func getCostHistories(kind string, snapshots []models.CostSnapshot, historyStart, now time.Time) map[string]*costHistory {
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	histories := map[string]*costHistory{}
+	clusterHours := map[time.Time]bool{}
+	for _, snapshot := range snapshots {
+		snapshotTime, err := time.Parse(time.RFC3339, snapshot.SnapshotTime)
+		if err != nil {
+			logrus.Debugf("invalid time of cost snapshot: %s, err: %v", snapshot.SnapshotTime, err)
+			continue
+		}
+		snapshotTime = snapshotTime.In(now.Location())
+		if snapshotTime.Before(historyStart) || !snapshotTime.Before(now) {
+			continue
+		}
+
+		name := snapshot.SnapshotName
+		if kind == ClusterType {
+			name = ClusterType
+		}
+		history, isPresent := histories[name]
+		if !isPresent {
+			history = &costHistory{dailyCost: map[int]float64{}, dailyHours: map[int]int{}}
+			histories[name] = history
+		}
+
+		day := getDayIndex(historyStart, snapshotTime)
+		history.dailyCost[day] += snapshot.Cost
+		isNewHour := true
+		if kind == ClusterType {
+			isNewHour = !clusterHours[snapshotTime]
+			clusterHours[snapshotTime] = true
+		}
+		if isNewHour {
+			history.dailyHours[day]++
+		}
+		if !snapshotTime.Before(monthStart) {
+			history.monthToDateCost += snapshot.Cost
+		}
+	}
+	return histories
+}
+
+// fitForecastModel fits linear trend on daily cost of observed days by least squares, days with missing snapshots
+// are scaled to a full day. Once there are at least two weeks of history, trend and weekly seasonality are fitted
+// together by alternately fitting each on the cost left by the other.
+func fitForecastModel(history *costHistory, historyStart time.Time) forecastModel {
+	model := forecastModel{start: historyStart}
+	var days []int
+	for day := range history.dailyCost {
+		days = append(days, day)
+	}
+	sort.Ints(days)
+	model.days = len(days)
+	if model.days == 0 {
+		return model
+	}
+
+	costs := make([]float64, len(days))
+	for i, day := range days {
+		costs[i] = history.dailyCost[day] * 24 / float64(history.dailyHours[day])
+	}
+	model.fitTrend(days, costs)
+
+	parameters := 2
+	if days[len(days)-1]-days[0]+1 >= minSeasonalHistoryDays {
+		for i := 0; i < seasonalFitIterations; i++ {
+			model.fitSeasonality(days, costs)
+			model.fitTrend(days, costs)
+		}
+		model.isSeasonal = true
+		parameters += 6
+	}
+
+	squaredResiduals := 0.0
+	for i, day := range days {
+		residual := costs[i] - model.getFittedCost(day)
+		squaredResiduals += residual * residual
+	}
+	if degreesOfFreedom := len(days) - parameters; degreesOfFreedom > 0 {
+		model.stdDev = math.Sqrt(squaredResiduals / float64(degreesOfFreedom))
+	}
+	return model
+}
+
+// fitTrend fits intercept and slope on daily cost without its seasonal component
+func (m *forecastModel) fitTrend(days []int, costs []float64) {
+	meanDay, meanCost := 0.0, 0.0
+	for i, day := range days {
+		meanDay += float64(day) / float64(len(days))
+		meanCost += (costs[i] - m.seasonal[m.getWeekday(day)]) / float64(len(days))
+	}
+	covariance, variance := 0.0, 0.0
+	for i, day := range days {
+		deseasonalized := costs[i] - m.seasonal[m.getWeekday(day)]
+		covariance += (float64(day) - meanDay) * (deseasonalized - meanCost)
+		variance += (float64(day) - meanDay) * (float64(day) - meanDay)
+	}
+	m.slope = 0
+	if variance > 0 {
+		m.slope = covariance / variance
+	}
+	m.intercept = meanCost - m.slope*meanDay
+}
+
+// fitSeasonality sets seasonal component of every weekday to its mean residual from the linear trend, components are
+// centered so that they don't shift the trend
+func (m *forecastModel) fitSeasonality(days []int, costs []float64) {
+	var residuals [7]float64
+	var counts [7]int
+	for i, day := range days {
+		weekday := m.getWeekday(day)
+		residuals[weekday] += costs[i] - m.intercept - m.slope*float64(day)
+		counts[weekday]++
+	}
+
+	mean, weekdays := 0.0, 0
+	for weekday := range residuals {
+		if counts[weekday] > 0 {
+			m.seasonal[weekday] = residuals[weekday] / float64(counts[weekday])
+			mean += m.seasonal[weekday]
+			weekdays++
+		}
+	}
+	for weekday := range m.seasonal {
+		if counts[weekday] > 0 {
+			m.seasonal[weekday] -= mean / float64(weekdays)
+		}
+	}
+}
+
+// getFittedCost returns fitted cost of the day, it isn't clipped to zero
+func (m forecastModel) getFittedCost(day int) float64 {
+	return m.intercept + m.slope*float64(day) + m.seasonal[m.getWeekday(day)]
+}
+
+func (m forecastModel) getWeekday(day int) time.Weekday {
+	return m.start.AddDate(0, 0, day).Weekday()
+}
+
+// project returns forecast of cost from start to end with half width of its confidence band, errors of days are
+// taken as independent and partial days are weighted by their hours
+func (m forecastModel) project(start, end time.Time) (float64, float64) {
+	cost, variance := 0.0, 0.0
+	for dayStart := getDayStart(start); dayStart.Before(end); dayStart = dayStart.AddDate(0, 0, 1) {
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		overlapStart, overlapEnd := dayStart, dayEnd
+		if start.After(overlapStart) {
+			overlapStart = start
+		}
+		if end.Before(overlapEnd) {
+			overlapEnd = end
+		}
+		weight := overlapEnd.Sub(overlapStart).Hours() / 24
+		cost += weight * math.Max(m.getFittedCost(getDayIndex(m.start, dayStart)), 0)
+		variance += weight * weight * m.stdDev * m.stdDev
+	}
+	return cost, confidenceZScore * math.Sqrt(variance)
+}
+
+func (m forecastModel) newCostForecast(kind, name string, monthToDateCost float64, now time.Time) CostForecast {
+	hourStart := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0)
+	nextDaysEnd := hourStart.AddDate(0, 0, forecastDays)
+
+	forecast := CostForecast{
+		Kind:            kind,
+		Name:            name,
+		HistoryDays:     m.days,
+		DailyTrend:      m.slope,
+		Seasonal:        m.isSeasonal,
+		MonthToDateCost: monthToDateCost,
+	}
+	cost, margin := m.project(hourStart, monthEnd)
+	forecast.EndOfMonth = newCostProjection(monthStart, monthEnd, monthToDateCost, cost, margin)
+	cost, margin = m.project(hourStart, nextDaysEnd)
+	forecast.Next30Days = newCostProjection(hourStart, nextDaysEnd, 0, cost, margin)
+	return forecast
+}
+
+func newCostProjection(start, end time.Time, actualCost, projectedCost, margin float64) CostProjection {
+	return CostProjection{
+		Start: start.Format(time.RFC3339),
+		End:   end.Format(time.RFC3339),
+		Cost:  actualCost + projectedCost,
+		Lower: actualCost + math.Max(projectedCost-margin, 0),
+		Upper: actualCost + projectedCost + margin,
+	}
+}
+
+func getDayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// getDayIndex returns number of days from start to the day of t, days shortened or lengthened by DST are rounded
+func getDayIndex(start, t time.Time) int {
+	return int(math.Round(getDayStart(t).Sub(start).Hours() / 24))
+}
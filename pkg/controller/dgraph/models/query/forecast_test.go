@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+var forecastNow = time.Date(2019, 6, 15, 12, 30, 0, 0, time.UTC)
+
+// getHourlySnapshots returns snapshots of every hour from start till the hour before forecastNow
+func getHourlySnapshots(name string, start time.Time, hourlyCost func(time.Time) float64) []models.CostSnapshot {
+	var snapshots []models.CostSnapshot
+	for hour := start; hour.Add(time.Hour).Before(forecastNow); hour = hour.Add(time.Hour) {
+		snapshots = append(snapshots, models.CostSnapshot{
+			SnapshotName: name,
+			SnapshotTime: hour.Format(time.RFC3339),
+			Cost:         hourlyCost(hour),
+		})
+	}
+	return snapshots
+}
+
+// TestGetCostForecastsOfSteadyCost ...
+func TestGetCostForecastsOfSteadyCost(t *testing.T) {
+	start := time.Date(2019, 5, 18, 0, 0, 0, 0, time.UTC)
+	snapshots := getHourlySnapshots("default", start, func(time.Time) float64 { return 1 })
+
+	got := getCostForecasts(NamespaceType, snapshots, forecastNow)
+	assert.Len(t, got, 1)
+	assert.Equal(t, "default", got[0].Name)
+	assert.Equal(t, 29, got[0].HistoryDays)
+	assert.True(t, got[0].Seasonal)
+	assert.InDelta(t, 0, got[0].DailyTrend, 1e-9)
+	assert.InDelta(t, 348, got[0].MonthToDateCost, 1e-9)
+
+	assert.Equal(t, "2019-06-01T00:00:00Z", got[0].EndOfMonth.Start)
+	assert.Equal(t, "2019-07-01T00:00:00Z", got[0].EndOfMonth.End)
+	assert.InDelta(t, 720, got[0].EndOfMonth.Cost, 1e-6)
+	assert.InDelta(t, 720, got[0].EndOfMonth.Lower, 1e-6)
+	assert.InDelta(t, 720, got[0].EndOfMonth.Upper, 1e-6)
+
+	assert.Equal(t, "2019-06-15T12:00:00Z", got[0].Next30Days.Start)
+	assert.Equal(t, "2019-07-15T12:00:00Z", got[0].Next30Days.End)
+	assert.InDelta(t, 720, got[0].Next30Days.Cost, 1e-6)
+}
+
+// TestGetCostForecastsOfGrowingCost ...
+func TestGetCostForecastsOfGrowingCost(t *testing.T) {
+	start := time.Date(2019, 6, 8, 0, 0, 0, 0, time.UTC)
+	snapshots := getHourlySnapshots("team-a", start, func(hour time.Time) float64 {
+		return 1 + 0.1*float64(hour.Sub(start)/(24*time.Hour))
+	})
+
+	got := getCostForecasts(GroupType, snapshots, forecastNow)
+	assert.Len(t, got, 1)
+	assert.False(t, got[0].Seasonal)
+	assert.InDelta(t, 2.4, got[0].DailyTrend, 1e-9)
+
+	// 12 hours of 15th June at 1.7/hour and 15 days from 16th June with 2.4 added every day
+	expectedRest := 12*1.7 + 15*24*1.7 + 2.4*(15*16/2)
+	assert.InDelta(t, got[0].MonthToDateCost+expectedRest, got[0].EndOfMonth.Cost, 1e-6)
+	assert.True(t, got[0].Next30Days.Cost > got[0].EndOfMonth.Cost-got[0].MonthToDateCost)
+}
+
+// TestGetCostForecastsOfWeeklyCost ...
+func TestGetCostForecastsOfWeeklyCost(t *testing.T) {
+	start := time.Date(2019, 5, 4, 0, 0, 0, 0, time.UTC)
+	snapshots := getHourlySnapshots("batch", start, func(hour time.Time) float64 {
+		if hour.Weekday() == time.Saturday || hour.Weekday() == time.Sunday {
+			return 0.2
+		}
+		return 1 + 0.01*float64(hour.Day()%3)
+	})
+
+	historyStart := forecastNow.AddDate(0, 0, -forecastHistoryDays).Truncate(24 * time.Hour)
+	histories := getCostHistories(NamespaceType, snapshots, historyStart, forecastNow)
+	model := fitForecastModel(histories["batch"], historyStart)
+	assert.True(t, model.isSeasonal)
+	saturday := getDayIndex(historyStart, time.Date(2019, 6, 22, 0, 0, 0, 0, time.UTC))
+	monday := getDayIndex(historyStart, time.Date(2019, 6, 24, 0, 0, 0, 0, time.UTC))
+	assert.InDelta(t, 4.8, model.getFittedCost(saturday), 0.5)
+	assert.InDelta(t, 24.24, model.getFittedCost(monday), 0.5)
+
+	got := getCostForecasts(NamespaceType, snapshots, forecastNow)
+	assert.True(t, got[0].EndOfMonth.Lower < got[0].EndOfMonth.Cost)
+	assert.True(t, got[0].EndOfMonth.Upper > got[0].EndOfMonth.Cost)
+	assert.True(t, got[0].EndOfMonth.Lower >= got[0].MonthToDateCost)
+}
+
+// TestGetCostForecastsOfCluster ...
+func TestGetCostForecastsOfCluster(t *testing.T) {
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := getHourlySnapshots("default", start, func(time.Time) float64 { return 1 })
+	snapshots = append(snapshots, getHourlySnapshots("kube-system", start, func(time.Time) float64 { return 0.5 })...)
+
+	got := getCostForecasts(ClusterType, snapshots, forecastNow)
+	assert.Len(t, got, 1)
+	assert.Equal(t, ClusterType, got[0].Name)
+	assert.InDelta(t, 348*1.5, got[0].MonthToDateCost, 1e-9)
+	assert.InDelta(t, 720*1.5, got[0].EndOfMonth.Cost, 1e-6)
+}
+
+// TestRetrieveCostForecastsWithInvalidKind ...
+func TestRetrieveCostForecastsWithInvalidKind(t *testing.T) {
+	_, err := RetrieveCostForecasts(NodeType, All)
+	assert.Equal(t, ErrInvalidForecastKind, err)
+}
+
+// TestForecastGroupCost ...
+func TestForecastGroupCost(t *testing.T) {
+	groupCost := GroupCost{Name: "team-a"}
+	for day := time.Date(2019, 5, 18, 0, 0, 0, 0, time.UTC); day.Before(forecastNow); day = day.AddDate(0, 0, 1) {
+		end, cost := day.AddDate(0, 0, 1), 24.0
+		if end.After(forecastNow) {
+			end, cost = time.Date(2019, 6, 15, 12, 0, 0, 0, time.UTC), 12
+		}
+		groupCost.Series = append(groupCost.Series, GroupCost{
+			Start: day.Format(time.RFC3339),
+			End:   end.Format(time.RFC3339),
+			Cost:  cost,
+		})
+	}
+
+	got := ForecastGroupCost(groupCost, forecastNow)
+	assert.Equal(t, "team-a", got.Name)
+	assert.Equal(t, 29, got.HistoryDays)
+	assert.InDelta(t, 348, got.MonthToDateCost, 1e-9)
+	assert.InDelta(t, 720, got.EndOfMonth.Cost, 1e-6)
+}
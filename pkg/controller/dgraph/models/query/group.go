@@ -19,6 +19,7 @@ package query
 
 import (
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
 
 	"github.com/Sirupsen/logrus"
 )
@@ -38,6 +39,10 @@ type GroupMetrics struct {
 	CostCPU                  float64
 	CostMemory               float64
 	CostStorage              float64
+	MTDGPU                   float64
+	CostGPU                  float64
+	CostExtendedResource     float64
+	CostService              float64
 	CostCPUPerHour           float64
 	CostMemoryPerHour        float64
 	CostStoragePerHour       float64
@@ -50,6 +55,27 @@ type GroupMetrics struct {
 	PodsCount                int
 }
 
+// GroupCost is cpu, memory, storage and gpu hours along with cost of a group in a window, and for every step of the
+// window if step is given. Cost includes the group's share of load balancers of services backed by its pods.
+type GroupCost struct {
+	Name        string      `json:"name,omitempty"`
+	Start       string      `json:"start"`
+	End         string      `json:"end"`
+	CPU         float64     `json:"cpu"`
+	Memory      float64     `json:"memory"`
+	Storage     float64     `json:"storage"`
+	CPUCost     float64     `json:"cpuCost"`
+	MemoryCost  float64     `json:"memoryCost"`
+	StorageCost float64     `json:"storageCost"`
+	GPU         float64     `json:"gpu,omitempty"`
+	GPUCost     float64     `json:"gpuCost,omitempty"`
+	Cost        float64     `json:"cost"`
+	Series      []GroupCost `json:"series,omitempty"`
+
+	ExtendedResourceCost float64 `json:"extendedResourceCost,omitempty"`
+	ServiceCost          float64 `json:"serviceCost,omitempty"`
+}
+
 type groupsRoot struct {
 	Groups []models.Group `json:"groups,omitempty"`
 }
@@ -83,6 +109,55 @@ func RetrieveGroupMetricsFromPodUIDs(podsUIDs string) (GroupMetrics, error) {
 	return convertToGroupMetrics(newRoot.JSONMetrics), nil
 }
 
+// RetrieveGroupCostFromPodUIDs returns cost of the group having given pods in the window
+func RetrieveGroupCostFromPodUIDs(name, podsUIDs string, window Window) (GroupCost, error) {
+	groupCost, err := retrieveGroupCostInWindow(podsUIDs, window)
+	if err != nil {
+		return GroupCost{}, err
+	}
+	groupCost.Name = name
+	if window.Step > 0 {
+		for _, step := range window.split() {
+			stepCost, err := retrieveGroupCostInWindow(podsUIDs, step)
+			if err != nil {
+				return GroupCost{}, err
+			}
+			groupCost.Series = append(groupCost.Series, stepCost)
+		}
+	}
+	return groupCost, nil
+}
+
+func retrieveGroupCostInWindow(podsUIDs string, window Window) (GroupCost, error) {
+	groupCost := GroupCost{
+		Start: utils.ConverTimeToRFC3339(window.getStart()),
+		End:   utils.ConverTimeToRFC3339(window.getEnd()),
+	}
+	if podsUIDs == "" {
+		return groupCost, nil
+	}
+
+	newRoot := groupJSONMetrics{}
+	err := executeQuery(getQueryForGroupMetricsInWindow(podsUIDs, window), &newRoot)
+	if err != nil {
+		return GroupCost{}, err
+	}
+	groupMetrics := convertToGroupMetrics(newRoot.JSONMetrics)
+	groupCost.CPU = groupMetrics.MTDCpu
+	groupCost.Memory = groupMetrics.MTDMemory
+	groupCost.Storage = groupMetrics.MTDStorage
+	groupCost.CPUCost = groupMetrics.CostCPU
+	groupCost.MemoryCost = groupMetrics.CostMemory
+	groupCost.StorageCost = groupMetrics.CostStorage
+	groupCost.GPU = groupMetrics.MTDGPU
+	groupCost.GPUCost = groupMetrics.CostGPU
+	groupCost.ExtendedResourceCost = groupMetrics.CostExtendedResource
+	groupCost.ServiceCost = groupMetrics.CostService
+	groupCost.Cost = groupMetrics.CostCPU + groupMetrics.CostMemory + groupMetrics.CostStorage + groupMetrics.CostGPU +
+		groupMetrics.CostExtendedResource + groupMetrics.CostService
+	return groupCost, nil
+}
+
 func convertToGroupMetrics(jsonMetrics []map[string]float64) GroupMetrics {
 	var groupMetrics GroupMetrics
 	for _, data := range jsonMetrics {
@@ -125,6 +200,14 @@ func populateMetric(groupMetrics *GroupMetrics, key string, value float64) {
 		groupMetrics.CostMemory = value
 	case "storageCost":
 		groupMetrics.CostStorage = value
+	case "mtdGPU":
+		groupMetrics.MTDGPU = value
+	case "gpuCost":
+		groupMetrics.CostGPU = value
+	case "extendedResourceCost":
+		groupMetrics.CostExtendedResource = value
+	case "serviceCost":
+		groupMetrics.CostService = value
 	case "cpuCostPerHour":
 		groupMetrics.CostCPUPerHour = value
 	case "memoryCostPerHour":
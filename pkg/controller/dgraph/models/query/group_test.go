@@ -20,6 +20,7 @@ package query
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
 
@@ -144,3 +145,26 @@ func TestGroupMetricsFromPodUIDs(t *testing.T) {
 	assert.Equal(t, expected, got)
 	assert.NoError(t, err)
 }
+
+// TestRetrieveGroupCostFromPodUIDs ...
+func TestRetrieveGroupCostFromPodUIDs(t *testing.T) {
+	mockDgraphForGroupQueries(testRetrieveGroupMetrics)
+	start := time.Now().Add(-3 * time.Hour)
+	window := Window{Start: start, End: start.Add(2 * time.Hour), Step: time.Hour}
+	got, err := RetrieveGroupCostFromPodUIDs("group-purser", testPodUIDList, window)
+	assert.NoError(t, err)
+	assert.Equal(t, "group-purser", got.Name)
+	assert.Equal(t, 13.1, got.CPU)
+	assert.Equal(t, 24.2, got.Memory)
+	assert.Equal(t, 20.0, got.Storage)
+	assert.InDelta(t, 3.94, got.Cost, 0.0001)
+	assert.Len(t, got.Series, 2)
+}
+
+// TestRetrieveGroupCostFromPodUIDsWithoutPods ...
+func TestRetrieveGroupCostFromPodUIDsWithoutPods(t *testing.T) {
+	mockDgraphForGroupQueries(testWrongQuery)
+	got, err := RetrieveGroupCostFromPodUIDs("group-purser", "", Window{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, got.Cost)
+}
@@ -19,6 +19,7 @@ package query
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
 	"github.com/vmware/purser/pkg/controller/utils"
@@ -46,67 +47,298 @@ func getSecondsSinceForOtherMonths() map[string]string {
 	return secondsSince
 }
 
-func getQueryForMetricsComputationWithAliasAndVariables(suffix string) string {
+func getQueryForMetricsComputationWithAliasAndVariables(suffix, costMode string, window Window) string {
 	return `name
 			type
-			cpu: cpu` + suffix + ` as cpuRequest
-			memory: memory` + suffix + ` as memoryRequest
+			` + getQueryForResourceComputation(suffix, costMode, window, true) + `
 			storage: storage` + suffix + ` as storageRequest
-			` + getQueryForTimeComputation(suffix) + `
-			` + getQueryForCostWithPriceWithAliasAndVariables(suffix)
+			` + getQueryForTimeComputation(suffix, window) + `
+			` + getQueryForCostWithPriceWithAliasAndVariables(suffix, window, gpuPrice, extendedResourcePrice) + `
+			` + getQueryForExtendedResourceCostComputation(suffix, true, true)
 }
 
-func getQueryForMetricsComputationWithAlias(suffix string) string {
+func getQueryForMetricsComputationWithAlias(suffix, costMode string, window Window) string {
 	return `name
 			type
-			cpu: cpu` + suffix + ` as cpuRequest
-			memory: memory` + suffix + ` as memoryRequest
+			` + getQueryForResourceComputation(suffix, costMode, window, true) + `
 			storage: storage` + suffix + ` as storageRequest
-			` + getQueryForTimeComputation(suffix) + `
-			` + getQueryForCostWithPriceWithAlias(suffix)
+			` + getQueryForTimeComputation(suffix, window) + `
+			` + getQueryForCostWithPriceWithAlias(suffix, window, gpuPrice, extendedResourcePrice) + `
+			` + getQueryForExtendedResourceCostComputation(suffix, true, false)
 }
 
-func getQueryForMetricsComputation(suffix string) string {
-	return `cpu` + suffix + ` as cpuRequest
-			memory` + suffix + ` as memoryRequest
+func getQueryForMetricsComputation(suffix, costMode string, window Window) string {
+	return getQueryForResourceComputation(suffix, costMode, window, false) + `
 			storage` + suffix + ` as storageRequest
-			` + getQueryForTimeComputation(suffix) + `
-			` + getQueryForCostWithPrice(suffix)
+			` + getQueryForTimeComputation(suffix, window) + `
+			` + getQueryForCostWithPrice(suffix, window, gpuPrice, extendedResourcePrice) + `
+			` + getQueryForExtendedResourceCostComputation(suffix, false, true)
 }
 
-func getQueryForTimeComputation(suffix string) string {
-	secondsSinceMonthStart := secondsFromFirstOfCurrentMonth()
-	return `st` + suffix + ` as startTime
+// getQueryForResourceComputation defines cpu<suffix> and memory<suffix> variables of a pod as its requests, the usage
+// of its containers averaged over their samples in the window or the larger of both depending on the cost mode. Usage
+// without samples in the window is taken as zero in usage cost mode, in max cost mode requests are used for it.
+func getQueryForResourceComputation(suffix, costMode string, window Window, withAlias bool) string {
+	return getQueryForResourceComputationFrom(suffix, costMode, `containers {
+				`+getQueryForUsageComputation(suffix+"Container", window)+`
+			}
+			usedCPU`+suffix+` as sum(val(usedCPU`+suffix+`Container))
+			usedMemory`+suffix+` as sum(val(usedMemory`+suffix+`Container))
+			hasUsageSamples`+suffix+` as sum(val(hasUsageSamples`+suffix+`Container))`, withAlias)
+}
+
+// getQueryForContainerResourceComputation defines cpu<suffix> and memory<suffix> variables of a container like
+// getQueryForResourceComputation does for a pod
+func getQueryForContainerResourceComputation(suffix, costMode string, window Window, withAlias bool) string {
+	return getQueryForResourceComputationFrom(suffix, costMode, getQueryForUsageComputation(suffix, window), withAlias)
+}
+
+// getQueryForUsageComputation defines usedCPU<suffix> and usedMemory<suffix> variables of a container as the average
+// of its usage samples taken in the window, hasUsageSamples<suffix> is the number of those samples
+func getQueryForUsageComputation(suffix string, window Window) string {
+	filter := window.getSampleFilter()
+	return `usageSamples @filter(` + filter + `) {
+				sampledCPU` + suffix + ` as cpuUsage
+				sampledMemory` + suffix + ` as memoryUsage
+			}
+			hasUsageSamples` + suffix + ` as count(usageSamples @filter(` + filter + `))
+			sampledCPUSum` + suffix + ` as sum(val(sampledCPU` + suffix + `))
+			sampledMemorySum` + suffix + ` as sum(val(sampledMemory` + suffix + `))
+			usedCPU` + suffix + ` as math(cond(hasUsageSamples` + suffix + ` == 0, 0.0, sampledCPUSum` + suffix + ` / max(hasUsageSamples` + suffix + `, 1.0)))
+			usedMemory` + suffix + ` as math(cond(hasUsageSamples` + suffix + ` == 0, 0.0, sampledMemorySum` + suffix + ` / max(hasUsageSamples` + suffix + `, 1.0)))`
+}
+
+// getQueryForResourceComputationFrom defines cpu<suffix> and memory<suffix> variables from requests and the given
+// query of usedCPU<suffix>, usedMemory<suffix> and hasUsageSamples<suffix> variables
+func getQueryForResourceComputationFrom(suffix, costMode, usage string, withAlias bool) string {
+	cpuAlias, memoryAlias := "", ""
+	if withAlias {
+		cpuAlias, memoryAlias = "cpu: ", "memory: "
+	}
+
+	switch GetCostMode(costMode) {
+	case UsageCostMode:
+		return usage + `
+			` + cpuAlias + `cpu` + suffix + ` as math(cond(hasUsageSamples` + suffix + ` == 0, 0.0, usedCPU` + suffix + `))
+			` + memoryAlias + `memory` + suffix + ` as math(cond(hasUsageSamples` + suffix + ` == 0, 0.0, usedMemory` + suffix + `))`
+	case MaxCostMode:
+		return `requestedCPU` + suffix + ` as cpuRequest
+			requestedMemory` + suffix + ` as memoryRequest
+			hasRequestedCPU` + suffix + ` as count(cpuRequest)
+			hasRequestedMemory` + suffix + ` as count(memoryRequest)
+			` + usage + `
+			` + cpuAlias + `cpu` + suffix + ` as math(cond(hasUsageSamples` + suffix + ` == 0, requestedCPU` + suffix + `, cond(hasRequestedCPU` + suffix + ` == 0, usedCPU` + suffix + `, max(requestedCPU` + suffix + `, usedCPU` + suffix + `))))
+			` + memoryAlias + `memory` + suffix + ` as math(cond(hasUsageSamples` + suffix + ` == 0, requestedMemory` + suffix + `, cond(hasRequestedMemory` + suffix + ` == 0, usedMemory` + suffix + `, max(requestedMemory` + suffix + `, usedMemory` + suffix + `))))`
+	}
+	return cpuAlias + `cpu` + suffix + ` as cpuRequest
+			` + memoryAlias + `memory` + suffix + ` as memoryRequest`
+}
+
+// getQueryForExtendedResourceCostComputation defines gpu<suffix>, gpuCost<suffix> and extendedResourceCost<suffix> of
+// a pod. GPUs are priced by the gpu price of the pod's node and other extended resources by the hourly price of the
+// pod's requests, pods without gpus or priced extended resources cost nothing for them. Variables of costs aren't
+// defined when only aliases are asked for. gpuPriceHours<suffix> and extendedResourcePriceHours<suffix> of the pod
+// must be defined.
+func getQueryForExtendedResourceCostComputation(suffix string, withAlias, withVariables bool) string {
+	gpu := `gpu` + suffix + ` as `
+	gpuCost := `gpuCost` + suffix + ` as `
+	extendedResourceCost := `extendedResourceCost` + suffix + ` as `
+	if withAlias {
+		gpu = `gpu: ` + gpu
+		if withVariables {
+			gpuCost, extendedResourceCost = `gpuCost: `+gpuCost, `extendedResourceCost: `+extendedResourceCost
+		} else {
+			gpuCost, extendedResourceCost = `gpuCost: `, `extendedResourceCost: `
+		}
+	}
+	return `requestedGPU` + suffix + ` as gpuRequest
+			hasRequestedGPU` + suffix + ` as count(gpuRequest)
+			` + gpu + `math(cond(hasRequestedGPU` + suffix + ` == 0, 0.0, requestedGPU` + suffix + `))
+			` + gpuCost + `math(gpu` + suffix + ` * gpuPriceHours` + suffix + `)
+			` + extendedResourceCost + `math(extendedResourcePriceHours` + suffix + `)`
+}
+
+// getQueryForTimeComputation defines durationInHours<suffix> variable, hours of the resource in the window
+func getQueryForTimeComputation(suffix string, window Window) string {
+	return getQueryForTimeComputationFrom(suffix, "startTime", window)
+}
+
+// getQueryForTimeComputationFrom defines durationInHours<suffix> variable, hours of the resource in the window from the
+// time held by the start predicate(ex: provisionedTime of load balancers) to its end time
+func getQueryForTimeComputationFrom(suffix, startPredicate string, window Window) string {
+	w := window.toTimeWindow(suffix)
+	return `st` + suffix + ` as ` + startPredicate + `
 			stSeconds` + suffix + ` as math(since(st` + suffix + `))
-			secondsSinceStart` + suffix + ` as math(cond(stSeconds` + suffix + ` > ` + secondsSinceMonthStart + `, ` + secondsSinceMonthStart + `, stSeconds` + suffix + `))
+			secondsSinceStart` + suffix + ` as math(cond(stSeconds` + suffix + ` > ` + w.secondsSinceStart + `, ` + w.secondsSinceStart + `, stSeconds` + suffix + `))
 			et` + suffix + ` as endTime
 			isTerminated` + suffix + ` as count(endTime)
-			secondsSinceEnd` + suffix + ` as math(cond(isTerminated` + suffix + ` == 0, 0.0, since(et` + suffix + `)))
+			etSeconds` + suffix + ` as math(cond(isTerminated` + suffix + ` == 0, 0.0, since(et` + suffix + `)))
+			secondsSinceEnd` + suffix + ` as math(cond(etSeconds` + suffix + ` > ` + w.secondsSinceEnd + `, etSeconds` + suffix + `, ` + w.secondsSinceEnd + `))
 			durationInHours` + suffix + ` as math(cond(secondsSinceStart` + suffix + ` > secondsSinceEnd` + suffix + `, (secondsSinceStart` + suffix + ` - secondsSinceEnd` + suffix + `) / 3600, 0.0))`
 }
 
-func getQueryForCostWithPriceWithAliasAndVariables(suffix string) string {
-	return `pricePerCPU` + suffix + ` as cpuPrice
-			pricePerMemory` + suffix + ` as memoryPrice
-			cpuCost: cpuCost` + suffix + ` as math(cpu` + suffix + ` * durationInHours` + suffix + ` * pricePerCPU` + suffix + `)
-			memoryCost: memoryCost` + suffix + ` as math(memory` + suffix + ` * durationInHours` + suffix + ` * pricePerMemory` + suffix + `)
-			storageCost: storageCost` + suffix + ` as math(storage` + suffix + ` * durationInHours` + suffix + ` * ` + models.DefaultStorageCostPerGBPerHour + `)`
+// getQueryForServiceCostComputation defines serviceCost<suffix> variable of a service, cost of its load balancer and
+// public IPs in the window from the time the load balancer was provisioned. Load balancers are costed by their price
+// segments, services without a price cost nothing.
+func getQueryForServiceCostComputation(suffix string, window Window) string {
+	return getQueryForTimeComputationFrom(suffix, "provisionedTime", window) + `
+			` + getQueryForOwnPriceResolution(suffix, loadBalancerPrice) + `
+			` + getQueryForPriceHoursComputation(suffix, []segmentedPrice{loadBalancerPrice}, window.toTimeWindow(suffix)) + `
+			serviceCost` + suffix + ` as math(loadBalancerPriceHours` + suffix + `)`
+}
+
+// timeWindow is a window(seconds since its start and end) in which price hours of resources are computed,
+// durationInHours is the variable holding hours of a resource in the window
+type timeWindow struct {
+	suffix            string
+	secondsSinceStart string
+	secondsSinceEnd   string
+	durationInHours   string
+}
+
+// getQueryForPriceResolution defines pricePerCPU<suffix> and pricePerMemory<suffix> of a pod. Live pods are priced by
+// the current price of their node, which is resolved from node prices of the rate card when the node is stored. Ended
+// pods keep the price they had when they ended so that rate card updates don't reprice them, the node's price is used
+// only for ended pods stored without a price. Resources without a node(ex: nodes) use their own price and resources
+// without any price use the default price. Every cost query prices pods through this resolver so that costs of
+// containers, pods, workloads and namespaces reconcile. isTerminated<suffix> of the resource must be defined.
+func getQueryForPriceResolution(suffix string) string {
+	return `node {
+				nodeCPUPrice` + suffix + ` as cpuPrice
+				nodeMemoryPrice` + suffix + ` as memoryPrice
+			}
+			hasNode` + suffix + ` as count(node)
+			cpuPriceOfNode` + suffix + ` as sum(val(nodeCPUPrice` + suffix + `))
+			memoryPriceOfNode` + suffix + ` as sum(val(nodeMemoryPrice` + suffix + `))
+			ownCPUPrice` + suffix + ` as cpuPrice
+			ownMemoryPrice` + suffix + ` as memoryPrice
+			hasOwnPrice` + suffix + ` as count(cpuPrice)
+			pricePerCPU` + suffix + ` as math(cond(hasNode` + suffix + ` > 0, cond(isTerminated` + suffix + ` > 0, cond(hasOwnPrice` + suffix + ` > 0, ownCPUPrice` + suffix + `, cpuPriceOfNode` + suffix + `), cpuPriceOfNode` + suffix + `), cond(hasOwnPrice` + suffix + ` > 0, ownCPUPrice` + suffix + `, ` + models.DefaultCPUCostPerCPUPerHour + `)))
+			pricePerMemory` + suffix + ` as math(cond(hasNode` + suffix + ` > 0, cond(isTerminated` + suffix + ` > 0, cond(hasOwnPrice` + suffix + ` > 0, ownMemoryPrice` + suffix + `, memoryPriceOfNode` + suffix + `), memoryPriceOfNode` + suffix + `), cond(hasOwnPrice` + suffix + ` > 0, ownMemoryPrice` + suffix + `, ` + models.DefaultMemCostPerGBPerHour + `)))`
+}
+
+// getQueryForOwnPriceResolution defines the price variable(ex: pricePerGPU<suffix>) of each of the prices as the
+// resource's own price, zero when it has none
+func getQueryForOwnPriceResolution(suffix string, prices ...segmentedPrice) string {
+	var query []string
+	for _, price := range prices {
+		query = append(query, `own`+price.name+`Price`+suffix+` as `+price.predicate+`
+			has`+price.name+`Price`+suffix+` as count(`+price.predicate+`)
+			`+price.pricePer+suffix+` as math(cond(has`+price.name+`Price`+suffix+` == 0, 0.0, own`+price.name+`Price`+suffix+`))`)
+	}
+	return strings.Join(query, `
+			`)
+}
+
+// getQueryForPodPriceHoursComputation defines cpuPriceHours<suffix> and memoryPriceHours<suffix> of a container as
+// the price hours of its pod in the window, costs of containers then add up to the cost of their pod
+func getQueryForPodPriceHoursComputation(suffix string, window Window) string {
+	podSuffix := suffix + "OfPod"
+	return `pod {
+				` + getQueryForTimeComputation(podSuffix, window) + `
+				` + getQueryForPriceResolution(podSuffix) + `
+				` + getQueryForPriceHoursComputation(podSuffix, computePrices, window.toTimeWindow(podSuffix)) + `
+			}
+			cpuPriceHours` + suffix + ` as sum(val(cpuPriceHours` + podSuffix + `))
+			memoryPriceHours` + suffix + ` as sum(val(memoryPriceHours` + podSuffix + `))`
+}
+
+// segmentedPrice is an hourly price recorded in price segments of resources
+type segmentedPrice struct {
+	// name of the price in variables(ex: segmentCPUPrice<suffix>)
+	name string
+	// predicate of the price in resources and their price segments
+	predicate string
+	// variable holding the price of resources without price segments
+	pricePer string
+	// variable holding price hours of resources
+	priceHours string
+}
+
+var (
+	cpuPrice              = segmentedPrice{name: "CPU", predicate: "cpuPrice", pricePer: "pricePerCPU", priceHours: "cpuPriceHours"}
+	memoryPrice           = segmentedPrice{name: "Memory", predicate: "memoryPrice", pricePer: "pricePerMemory", priceHours: "memoryPriceHours"}
+	gpuPrice              = segmentedPrice{name: "GPU", predicate: "gpuPrice", pricePer: "pricePerGPU", priceHours: "gpuPriceHours"}
+	extendedResourcePrice = segmentedPrice{name: "ExtendedResource", predicate: "extendedResourcePrice", pricePer: "pricePerExtendedResources", priceHours: "extendedResourcePriceHours"}
+	storagePrice          = segmentedPrice{name: "Storage", predicate: "storagePrice", pricePer: "pricePerStorage", priceHours: "storagePriceHours"}
+	loadBalancerPrice     = segmentedPrice{name: "LoadBalancer", predicate: "price", pricePer: "servicePrice", priceHours: "loadBalancerPriceHours"}
+
+	// prices of cpu and memory, the ones every cost query computes
+	computePrices = []segmentedPrice{cpuPrice, memoryPrice}
+	// prices of nodes
+	nodePrices = []segmentedPrice{cpuPrice, memoryPrice, gpuPrice}
+	// prices of cpu, memory and storage of pods
+	podResourcePrices = []segmentedPrice{cpuPrice, memoryPrice, storagePrice}
+	// prices of persistent volumes and claims
+	storagePrices = []segmentedPrice{storagePrice}
+)
+
+// getQueryForPriceHoursComputation defines price hours variables(ex: cpuPriceHours<window>) of each of the prices for
+// each window, sum of price times hours of the resource's price segments in the window. Resources without price
+// segments weren't repriced, their own price is used for the whole window. Price variables(ex: pricePerCPU<suffix>)
+// of the resource must be defined.
+func getQueryForPriceHoursComputation(suffix string, prices []segmentedPrice, windows ...timeWindow) string {
+	segmentsQuery := `priceSegments {
+				segmentSt` + suffix + ` as startTime
+				segmentStSeconds` + suffix + ` as math(since(segmentSt` + suffix + `))
+				segmentEt` + suffix + ` as endTime
+				isSegmentEnded` + suffix + ` as count(endTime)
+				segmentEtSeconds` + suffix + ` as math(cond(isSegmentEnded` + suffix + ` == 0, 0.0, since(segmentEt` + suffix + `)))`
+	for _, price := range prices {
+		segmentsQuery += `
+				segment` + price.name + `Price` + suffix + ` as ` + price.predicate
+	}
+	resourceQuery := `segmentsCount` + suffix + ` as count(priceSegments)`
+	for _, window := range windows {
+		w := window.suffix
+		segmentsQuery += `
+				segmentSecondsSinceStart` + w + ` as math(cond(segmentStSeconds` + suffix + ` > ` + window.secondsSinceStart + `, ` + window.secondsSinceStart + `, segmentStSeconds` + suffix + `))
+				segmentSecondsSinceEnd` + w + ` as math(cond(segmentEtSeconds` + suffix + ` > ` + window.secondsSinceEnd + `, segmentEtSeconds` + suffix + `, ` + window.secondsSinceEnd + `))
+				segmentDurationInHours` + w + ` as math(cond(segmentSecondsSinceStart` + w + ` > segmentSecondsSinceEnd` + w + `, (segmentSecondsSinceStart` + w + ` - segmentSecondsSinceEnd` + w + `) / 3600, 0.0))`
+		for _, price := range prices {
+			segmentsQuery += `
+				segment` + price.name + `PriceHours` + w + ` as math(segment` + price.name + `Price` + suffix + ` * segmentDurationInHours` + w + `)`
+			resourceQuery += `
+			segments` + price.name + `PriceHours` + w + ` as sum(val(segment` + price.name + `PriceHours` + w + `))
+			` + price.priceHours + w + ` as math(cond(segmentsCount` + suffix + ` == 0, ` + price.pricePer + suffix + ` * ` + window.durationInHours + `, segments` + price.name + `PriceHours` + w + `))`
+		}
+	}
+	return segmentsQuery + `
+			}
+			` + resourceQuery
+}
+
+func getQueryForCostWithPriceWithAliasAndVariables(suffix string, window Window, otherPrices ...segmentedPrice) string {
+	return getQueryForPriceResolution(suffix) + `
+			` + getQueryForPricesComputation(suffix, window, otherPrices) + `
+			cpuCost: cpuCost` + suffix + ` as math(cpu` + suffix + ` * cpuPriceHours` + suffix + `)
+			memoryCost: memoryCost` + suffix + ` as math(memory` + suffix + ` * memoryPriceHours` + suffix + `)
+			storageCost: storageCost` + suffix + ` as math(storage` + suffix + ` * storagePriceHours` + suffix + `)`
+}
+
+func getQueryForCostWithPriceWithAlias(suffix string, window Window, otherPrices ...segmentedPrice) string {
+	return getQueryForPriceResolution(suffix) + `
+			` + getQueryForPricesComputation(suffix, window, otherPrices) + `
+			cpuCost: math(cpu` + suffix + ` * cpuPriceHours` + suffix + `)
+			memoryCost: math(memory` + suffix + ` * memoryPriceHours` + suffix + `)
+			storageCost: math(storage` + suffix + ` * storagePriceHours` + suffix + `)`
 }
 
-func getQueryForCostWithPriceWithAlias(suffix string) string {
-	return `pricePerCPU` + suffix + ` as cpuPrice
-			pricePerMemory` + suffix + ` as memoryPrice
-			cpuCost: math(cpu` + suffix + ` * durationInHours` + suffix + ` * pricePerCPU` + suffix + `)
-			memoryCost: math(memory` + suffix + ` * durationInHours` + suffix + ` * pricePerMemory` + suffix + `)
-			storageCost: math(storage` + suffix + ` * durationInHours` + suffix + ` * ` + models.DefaultStorageCostPerGBPerHour + `)`
+func getQueryForCostWithPrice(suffix string, window Window, otherPrices ...segmentedPrice) string {
+	return getQueryForPriceResolution(suffix) + `
+			` + getQueryForPricesComputation(suffix, window, otherPrices) + `
+			cpuCost` + suffix + ` as math(cpu` + suffix + ` * cpuPriceHours` + suffix + `)
+			memoryCost` + suffix + ` as math(memory` + suffix + ` * memoryPriceHours` + suffix + `)
+			storageCost` + suffix + ` as math(storage` + suffix + ` * storagePriceHours` + suffix + `)`
 }
 
-func getQueryForCostWithPrice(suffix string) string {
-	return `pricePerCPU` + suffix + ` as cpuPrice
-			pricePerMemory` + suffix + ` as memoryPrice
-			cpuCost` + suffix + ` as math(cpu` + suffix + ` * durationInHours` + suffix + ` * pricePerCPU` + suffix + `)
-			memoryCost` + suffix + ` as math(memory` + suffix + ` * durationInHours` + suffix + ` * pricePerMemory` + suffix + `)
-			storageCost` + suffix + ` as math(storage` + suffix + ` * durationInHours` + suffix + ` * ` + models.DefaultStorageCostPerGBPerHour + `)`
+// getQueryForPricesComputation defines price hours of cpu, memory, storage and the other prices of the resource in the
+// window, storage and the other prices are the resource's own prices
+func getQueryForPricesComputation(suffix string, window Window, otherPrices []segmentedPrice) string {
+	ownPrices := append(append([]segmentedPrice{}, storagePrices...), otherPrices...)
+	prices := append(append([]segmentedPrice{}, podResourcePrices...), otherPrices...)
+	return getQueryForOwnPriceResolution(suffix, ownPrices...) + `
+			` + getQueryForPriceHoursComputation(suffix, prices, window.toTimeWindow(suffix))
 }
 
 func getQueryForAggregatingChildMetricsWithAlias(childSuffix string) string {
@@ -117,7 +349,10 @@ func getQueryForAggregatingChildMetricsWithAlias(childSuffix string) string {
 			storage: sum(val(storage` + childSuffix + `))
 			cpuCost: sum(val(cpuCost` + childSuffix + `))
 			memoryCost: sum(val(memoryCost` + childSuffix + `))
-			storageCost: sum(val(storageCost` + childSuffix + `))`
+			storageCost: sum(val(storageCost` + childSuffix + `))
+			gpu: sum(val(gpu` + childSuffix + `))
+			gpuCost: sum(val(gpuCost` + childSuffix + `))
+			extendedResourceCost: sum(val(extendedResourceCost` + childSuffix + `))`
 }
 
 func getQueryForAggregatingChildMetrics(parentSuffix, childSuffix string) string {
@@ -126,7 +361,10 @@ func getQueryForAggregatingChildMetrics(parentSuffix, childSuffix string) string
 			storage` + parentSuffix + ` as sum(val(storage` + childSuffix + `))
 			cpuCost` + parentSuffix + ` as sum(val(cpuCost` + childSuffix + `))
 			memoryCost` + parentSuffix + ` as sum(val(memoryCost` + childSuffix + `))
-			storageCost` + parentSuffix + ` as sum(val(storageCost` + childSuffix + `))`
+			storageCost` + parentSuffix + ` as sum(val(storageCost` + childSuffix + `))
+			gpu` + parentSuffix + ` as sum(val(gpu` + childSuffix + `))
+			gpuCost` + parentSuffix + ` as sum(val(gpuCost` + childSuffix + `))
+			extendedResourceCost` + parentSuffix + ` as sum(val(extendedResourceCost` + childSuffix + `))`
 }
 
 func getQueryFromSubQueryWithAlias(suffix string) string {
@@ -137,14 +375,17 @@ func getQueryFromSubQueryWithAlias(suffix string) string {
 			storage: val(storage` + suffix + `)
 			cpuCost: val(cpuCost` + suffix + `)
 			memoryCost: val(memoryCost` + suffix + `)
-			storageCost: val(storageCost` + suffix + `)`
+			storageCost: val(storageCost` + suffix + `)
+			gpu: val(gpu` + suffix + `)
+			gpuCost: val(gpuCost` + suffix + `)
+			extendedResourceCost: val(extendedResourceCost` + suffix + `)`
 }
 
 func (r *Resource) getQueryForPodParentMetrics() string {
 	return `query {
 		parent(func: has(` + r.Check + `)) @filter(eq(name, "` + r.Name + `")) {
 			children: ~` + r.Type + ` @filter(has(isPod)) {
-				` + getQueryForMetricsComputationWithAliasAndVariables("Pod") + `
+				` + getQueryForMetricsComputationWithAliasAndVariables("Pod", r.CostMode, r.Window) + `
 			}
 			` + getQueryForAggregatingChildMetricsWithAlias("Pod") + `
 		}
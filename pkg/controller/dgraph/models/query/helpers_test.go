@@ -19,9 +19,12 @@ package query
 
 import (
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
 )
 
 // TestGetSecondsSinceMonthStart ...
@@ -33,3 +36,167 @@ func TestGetSecondsSinceMonthStart(t *testing.T) {
 	assert.False(t, gotFloat > maxSecondsInAMonth, "secondsSinceMonthStart can't be greater than 2678400")
 	assert.False(t, gotFloat < 0, "secondsSinceMonthStart can't be less than 0")
 }
+
+// TestGetQueryForPriceHoursComputation ...
+func TestGetQueryForPriceHoursComputation(t *testing.T) {
+	windows := []timeWindow{
+		{suffix: "CurrentMonth", secondsSinceStart: "100.0", secondsSinceEnd: "0.0", durationInHours: "currentMonthDuration"},
+		{suffix: "LastMonth", secondsSinceStart: "200.0", secondsSinceEnd: "100.0", durationInHours: "lastMonthDuration"},
+	}
+	got := getQueryForPriceHoursComputation("Pod", nodePrices, windows...)
+	assert.True(t, strings.HasPrefix(got, "priceSegments {"))
+	assert.Contains(t, got, "segmentsCountPod as count(priceSegments)")
+	assert.Contains(t, got, "segmentGPUPricePod as gpuPrice")
+	for _, window := range windows {
+		assert.Contains(t, got, "cpuPriceHours"+window.suffix+" as math(cond(segmentsCountPod == 0, pricePerCPUPod * "+window.durationInHours)
+		assert.Contains(t, got, "memoryPriceHours"+window.suffix+" as math(cond(segmentsCountPod == 0, pricePerMemoryPod * "+window.durationInHours)
+		assert.Contains(t, got, "gpuPriceHours"+window.suffix+" as math(cond(segmentsCountPod == 0, pricePerGPUPod * "+window.durationInHours)
+		assert.Contains(t, got, "segmentGPUPriceHours"+window.suffix+" as math(segmentGPUPricePod * segmentDurationInHours"+window.suffix+")")
+		assert.Contains(t, got, window.secondsSinceStart)
+	}
+	// variables in dgraph queries are defined once and only for the given prices
+	assert.Equal(t, 1, strings.Count(got, "segmentCPUPricePod as"))
+	assert.NotContains(t, got, "extendedResourcePrice")
+}
+
+// TestGetQueryForOwnPriceResolution ...
+func TestGetQueryForOwnPriceResolution(t *testing.T) {
+	got := getQueryForOwnPriceResolution("Pod", gpuPrice, extendedResourcePrice)
+	assert.Contains(t, got, "ownGPUPricePod as gpuPrice")
+	assert.Contains(t, got, "pricePerGPUPod as math(cond(hasGPUPricePod == 0, 0.0, ownGPUPricePod))")
+	assert.Contains(t, got, "pricePerExtendedResourcesPod as math(cond(hasExtendedResourcePricePod == 0, 0.0, ownExtendedResourcePricePod))")
+	assert.Equal(t, "", getQueryForOwnPriceResolution("Pod"))
+}
+
+// TestGetQueryForMetricsComputationOfGPUs checks that gpus and extended resources are costed by their price segments
+// like cpu and memory
+func TestGetQueryForMetricsComputationOfGPUs(t *testing.T) {
+	got := getQueryForMetricsComputation("Pod", RequestCostMode, Window{})
+	assert.Contains(t, got, "gpuPriceHoursPod as math(cond(segmentsCountPod == 0, pricePerGPUPod * durationInHoursPod, segmentsGPUPriceHoursPod))")
+	assert.Contains(t, got, "extendedResourcePriceHoursPod as math(cond(segmentsCountPod == 0, pricePerExtendedResourcesPod * durationInHoursPod, segmentsExtendedResourcePriceHoursPod))")
+	assert.True(t, strings.Index(got, "gpuPriceHoursPod as") < strings.Index(got, "gpuCostPod as"))
+	assert.Contains(t, getQueryForNodeGPUCostComputation(), "gpuCost: math(nodeGPU * gpuPriceHours)")
+}
+
+// TestGetQueryForResourceComputation ...
+func TestGetQueryForResourceComputation(t *testing.T) {
+	window := Window{
+		Start: time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2018, time.March, 2, 0, 0, 0, 0, time.UTC),
+	}
+	sampleFilter := `@filter(ge(sampleTime, "2018-03-01T00:00:00Z") AND lt(sampleTime, "2018-03-02T00:00:00Z"))`
+
+	got := getQueryForResourceComputation("Pod", RequestCostMode, window, true)
+	assert.Equal(t, "cpu: cpuPod as cpuRequest\n\t\t\tmemory: memoryPod as memoryRequest", got)
+
+	got = getQueryForResourceComputation("Pod", "", window, false)
+	assert.Equal(t, "cpuPod as cpuRequest\n\t\t\tmemoryPod as memoryRequest", got)
+
+	got = getQueryForResourceComputation("Pod", UsageCostMode, window, true)
+	assert.Contains(t, got, "usageSamples "+sampleFilter)
+	assert.Contains(t, got, "hasUsageSamplesPodContainer as count(usageSamples "+sampleFilter+")")
+	assert.Contains(t, got, "usedCPUPodContainer as math(cond(hasUsageSamplesPodContainer == 0, 0.0, sampledCPUSumPodContainer / max(hasUsageSamplesPodContainer, 1.0)))")
+	assert.Contains(t, got, "usedCPUPod as sum(val(usedCPUPodContainer))")
+	assert.Contains(t, got, "hasUsageSamplesPod as sum(val(hasUsageSamplesPodContainer))")
+	assert.Contains(t, got, "cpu: cpuPod as math(cond(hasUsageSamplesPod == 0, 0.0, usedCPUPod))")
+	assert.Contains(t, got, "memory: memoryPod as math(cond(hasUsageSamplesPod == 0, 0.0, usedMemoryPod))")
+	assert.NotContains(t, got, "cpuRequest")
+
+	got = getQueryForResourceComputation("Pod", MaxCostMode, window, false)
+	assert.Contains(t, got, "usageSamples "+sampleFilter)
+	assert.Contains(t, got, "cpuPod as math(cond(hasUsageSamplesPod == 0, requestedCPUPod, cond(hasRequestedCPUPod == 0, usedCPUPod, max(requestedCPUPod, usedCPUPod))))")
+	assert.Contains(t, got, "memoryPod as math(cond(hasUsageSamplesPod == 0, requestedMemoryPod, cond(hasRequestedMemoryPod == 0, usedMemoryPod, max(requestedMemoryPod, usedMemoryPod))))")
+	assert.NotContains(t, got, "cpu: ")
+
+	// running averages of usage aren't used, they don't belong to the window
+	got = getQueryForContainerResourceComputation("Container", UsageCostMode, window, true)
+	assert.NotContains(t, got, "containers {")
+	assert.Contains(t, got, "sampledCPUContainer as cpuUsage")
+	assert.Contains(t, got, "usedMemoryContainer as math(cond(hasUsageSamplesContainer == 0, 0.0, sampledMemorySumContainer / max(hasUsageSamplesContainer, 1.0)))")
+	assert.Contains(t, got, "cpu: cpuContainer as math(cond(hasUsageSamplesContainer == 0, 0.0, usedCPUContainer))")
+	assert.Equal(t, 1, strings.Count(got, " as cpuUsage"))
+}
+
+// TestGetCostMode ...
+func TestGetCostMode(t *testing.T) {
+	assert.Equal(t, UsageCostMode, GetCostMode(UsageCostMode))
+	assert.Equal(t, MaxCostMode, GetCostMode(MaxCostMode))
+	assert.Equal(t, RequestCostMode, GetCostMode(RequestCostMode))
+	assert.Equal(t, RequestCostMode, GetCostMode(""))
+	assert.Equal(t, RequestCostMode, GetCostMode("unknown"))
+}
+
+// TestGetQueryForPriceResolution ...
+func TestGetQueryForPriceResolution(t *testing.T) {
+	got := getQueryForPriceResolution("Pod")
+	assert.True(t, strings.HasPrefix(got, "node {"))
+	assert.Contains(t, got, "nodeCPUPricePod as cpuPrice")
+	assert.Contains(t, got, "pricePerCPUPod as math(cond(hasNodePod > 0, cond(isTerminatedPod > 0, cond(hasOwnPricePod > 0, ownCPUPricePod, cpuPriceOfNodePod), cpuPriceOfNodePod), cond(hasOwnPricePod > 0, ownCPUPricePod, "+models.DefaultCPUCostPerCPUPerHour+")))")
+	assert.Contains(t, got, "pricePerMemoryPod as math(cond(hasNodePod > 0, cond(isTerminatedPod > 0, cond(hasOwnPricePod > 0, ownMemoryPricePod, memoryPriceOfNodePod), memoryPriceOfNodePod), cond(hasOwnPricePod > 0, ownMemoryPricePod, "+models.DefaultMemCostPerGBPerHour+")))")
+}
+
+// TestGetQueryForPriceResolutionOfEndedPod checks that an ended pod is priced by its own price, which isn't updated by
+// rate card updates, so that its cost stays the same when the price of its node changes
+func TestGetQueryForPriceResolutionOfEndedPod(t *testing.T) {
+	query := getQueryForMetricsComputation("Pod", RequestCostMode, Window{})
+	assert.True(t, strings.Index(query, "isTerminatedPod as count(endTime)") < strings.Index(query, "pricePerCPUPod as math("))
+
+	got := getQueryForPriceResolution("Pod")
+	endedPodCPUPrice := "cond(isTerminatedPod > 0, cond(hasOwnPricePod > 0, ownCPUPricePod, cpuPriceOfNodePod), cpuPriceOfNodePod)"
+	endedPodMemoryPrice := "cond(isTerminatedPod > 0, cond(hasOwnPricePod > 0, ownMemoryPricePod, memoryPriceOfNodePod), memoryPriceOfNodePod)"
+	assert.Contains(t, got, "pricePerCPUPod as math(cond(hasNodePod > 0, "+endedPodCPUPrice)
+	assert.Contains(t, got, "pricePerMemoryPod as math(cond(hasNodePod > 0, "+endedPodMemoryPrice)
+}
+
+// TestGetQueryForPodPriceHoursComputation ...
+func TestGetQueryForPodPriceHoursComputation(t *testing.T) {
+	got := getQueryForPodPriceHoursComputation("Container", Window{})
+	assert.True(t, strings.HasPrefix(got, "pod {"))
+	assert.Contains(t, got, "pricePerCPUContainerOfPod as math(")
+	assert.Contains(t, got, "durationInHoursContainerOfPod as math(")
+	assert.Contains(t, got, "cpuPriceHoursContainer as sum(val(cpuPriceHoursContainerOfPod))")
+	assert.Contains(t, got, "memoryPriceHoursContainer as sum(val(memoryPriceHoursContainerOfPod))")
+}
+
+// TestGetQueryForExtendedResourceCostComputation ...
+func TestGetQueryForExtendedResourceCostComputation(t *testing.T) {
+	got := getQueryForExtendedResourceCostComputation("Pod", false, true)
+	assert.Contains(t, got, "gpuPod as math(cond(hasRequestedGPUPod == 0, 0.0, requestedGPUPod))")
+	assert.Contains(t, got, "gpuCostPod as math(gpuPod * gpuPriceHoursPod)")
+	assert.Contains(t, got, "extendedResourceCostPod as math(extendedResourcePriceHoursPod)")
+
+	got = getQueryForExtendedResourceCostComputation("Pod", true, true)
+	assert.Contains(t, got, "gpu: gpuPod as math(")
+	assert.Contains(t, got, "gpuCost: gpuCostPod as math(")
+	assert.Contains(t, got, "extendedResourceCost: extendedResourceCostPod as math(")
+
+	// gpu variable is used by gpu cost even when only aliases are asked for
+	got = getQueryForExtendedResourceCostComputation("Pod", true, false)
+	assert.Contains(t, got, "gpu: gpuPod as math(")
+	assert.Contains(t, got, "gpuCost: math(")
+	assert.Contains(t, got, "extendedResourceCost: math(")
+	assert.NotContains(t, got, "gpuCostPod as")
+}
+
+// TestGetQueryForServiceCostComputation ...
+func TestGetQueryForServiceCostComputation(t *testing.T) {
+	got := getQueryForServiceCostComputation("Service", Window{})
+	assert.Contains(t, got, "stService as provisionedTime")
+	assert.Contains(t, got, "ownLoadBalancerPriceService as price")
+	assert.Contains(t, got, "segmentLoadBalancerPriceService as price")
+	assert.Contains(t, got, "loadBalancerPriceHoursService as math(cond(segmentsCountService == 0, servicePriceService * durationInHoursService, segmentsLoadBalancerPriceHoursService))")
+	assert.Contains(t, got, "serviceCostService as math(loadBalancerPriceHoursService)")
+	assert.Contains(t, getQueryForTimeComputation("Pod", Window{}), "stPod as startTime")
+}
+
+// TestGetQueryForStorageCostComputation checks that storage of pods, volumes and claims is costed by price segments
+func TestGetQueryForStorageCostComputation(t *testing.T) {
+	got := getQueryForMetricsComputation("Pod", RequestCostMode, Window{})
+	assert.Contains(t, got, "storagePriceHoursPod as math(cond(segmentsCountPod == 0, pricePerStoragePod * durationInHoursPod, segmentsStoragePriceHoursPod))")
+	assert.Contains(t, got, "storageCostPod as math(storagePod * storagePriceHoursPod)")
+
+	got = getQueryForPVMetrics("pv-a", Window{})
+	assert.Contains(t, got, "storageCost: math(pvcStorage * storagePriceHoursPVC)")
+	assert.Contains(t, got, "storageCost: math(storage * storagePriceHours)")
+	assert.NotContains(t, got, "cpuPrice")
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Options of idle query parameter, idle cost is either reported as a separate bucket or distributed
+// proportionally to the cost of each child
+const (
+	Idle           = "idle"
+	IdleBucket     = "bucket"
+	IdleDistribute = "distribute"
+	IdleType       = "idle"
+)
+
+// NodeCost is month to date cost of a node's capacity along with cost allocated to pods running on it
+type NodeCost struct {
+	Name                string  `json:"name,omitempty"`
+	CPUCost             float64 `json:"cpuCost,omitempty"`
+	MemoryCost          float64 `json:"memoryCost,omitempty"`
+	CPUAllocatedCost    float64 `json:"cpuAllocatedCost,omitempty"`
+	MemoryAllocatedCost float64 `json:"memoryAllocatedCost,omitempty"`
+}
+
+// IdleCost is month to date cost of node capacity which isn't allocated to any pod, along with cost allocated to pods
+type IdleCost struct {
+	CPUCost             float64
+	MemoryCost          float64
+	CPUAllocatedCost    float64
+	MemoryAllocatedCost float64
+}
+
+type nodesCostRoot struct {
+	Nodes []NodeCost `json:"nodes"`
+}
+
+// RetrieveIdleCost returns idle cost of the cluster in the window, per node it is capacity cost minus sum of
+// allocations of its pods
+func RetrieveIdleCost(window Window) (IdleCost, error) {
+	newRoot := nodesCostRoot{}
+	err := executeQuery(getQueryForNodesCost(window), &newRoot)
+	if err != nil {
+		return IdleCost{}, err
+	}
+	return computeIdleCost(newRoot.Nodes), nil
+}
+
+func computeIdleCost(nodes []NodeCost) IdleCost {
+	idleCost := IdleCost{}
+	for _, node := range nodes {
+		// a node can be over allocated when pods are costed at a different price than it
+		if node.CPUCost > node.CPUAllocatedCost {
+			idleCost.CPUCost += node.CPUCost - node.CPUAllocatedCost
+		}
+		if node.MemoryCost > node.MemoryAllocatedCost {
+			idleCost.MemoryCost += node.MemoryCost - node.MemoryAllocatedCost
+		}
+		idleCost.CPUAllocatedCost += node.CPUAllocatedCost
+		idleCost.MemoryAllocatedCost += node.MemoryAllocatedCost
+	}
+	return idleCost
+}
+
+// GetShare returns share of idle cpu and memory cost for the given cost allocated to pods
+func (idleCost IdleCost) GetShare(cpuCost, memoryCost float64) (float64, float64) {
+	return getShare(idleCost.CPUCost, cpuCost, idleCost.CPUAllocatedCost),
+		getShare(idleCost.MemoryCost, memoryCost, idleCost.MemoryAllocatedCost)
+}
+
+func getShare(total, part, whole float64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return total * part / whole
+}
+
+// PopulateIdleCost adds idle cost in the window to cluster metrics of logical view(and every step of the window),
+// either as a separate child of type idle or distributed to children proportionally to their cost
+func PopulateIdleCost(jsonData *JSONDataWrapper, idle string, window Window) {
+	idleCost, err := RetrieveIdleCost(window)
+	if err != nil {
+		logrus.Errorf("Unable to retrieve idle cost: (%v)", err)
+		return
+	}
+	addIdleCost(&jsonData.Data, idleCost, idle)
+
+	if window.Step > 0 {
+		for i, step := range window.split() {
+			if i >= len(jsonData.Series) {
+				break
+			}
+			idleCost, err = RetrieveIdleCost(step)
+			if err != nil {
+				logrus.Errorf("Unable to retrieve idle cost: (%v)", err)
+				return
+			}
+			addIdleCost(&jsonData.Series[i].Data, idleCost, idle)
+		}
+	}
+}
+
+func addIdleCost(data *ParentWrapper, idleCost IdleCost, idle string) {
+	data.IdleCPUCost = idleCost.CPUCost
+	data.IdleMemoryCost = idleCost.MemoryCost
+
+	switch idle {
+	case IdleDistribute:
+		// children are costed as per the cost mode, so shares are taken over their total cost
+		var cpuCost, memoryCost float64
+		for _, child := range data.Children {
+			cpuCost += child.CPUCost
+			memoryCost += child.MemoryCost
+		}
+		for i, child := range data.Children {
+			data.Children[i].CPUCost += getShare(idleCost.CPUCost, child.CPUCost, cpuCost)
+			data.Children[i].MemoryCost += getShare(idleCost.MemoryCost, child.MemoryCost, memoryCost)
+		}
+		// idle cost is reported as idle when no child has cost to distribute it by
+		undistributed := IdleCost{}
+		if cpuCost <= 0 {
+			undistributed.CPUCost = idleCost.CPUCost
+		}
+		if memoryCost <= 0 {
+			undistributed.MemoryCost = idleCost.MemoryCost
+		}
+		if undistributed.CPUCost > 0 || undistributed.MemoryCost > 0 {
+			data.Children = append(data.Children, newIdleChild(undistributed))
+		}
+	case IdleBucket:
+		data.Children = append(data.Children, newIdleChild(idleCost))
+	default:
+		return
+	}
+	data.CPUCost += idleCost.CPUCost
+	data.MemoryCost += idleCost.MemoryCost
+}
+
+func newIdleChild(idleCost IdleCost) Children {
+	return Children{
+		Name:       IdleType,
+		Type:       IdleType,
+		CPUCost:    idleCost.CPUCost,
+		MemoryCost: idleCost.MemoryCost,
+	}
+}
+
+// DistributeIdleCostToGroups adds share of idle cost of groups to their month to date costs
+func DistributeIdleCostToGroups(groups []models.Group) {
+	for i := range groups {
+		groups[i].MtdCPUCost += groups[i].MtdIdleCPUCost
+		groups[i].MtdMemoryCost += groups[i].MtdIdleMemoryCost
+		groups[i].MtdCost += groups[i].MtdIdleCost
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+func mockDgraphForIdleCostQueries() {
+	executeQuery = func(query string, root interface{}) error {
+		dummyNodesCostRoot, ok := root.(*nodesCostRoot)
+		if !ok {
+			return fmt.Errorf("wrong root received")
+		}
+		dummyNodesCostRoot.Nodes = []NodeCost{
+			{Name: "node-1", CPUCost: 10, MemoryCost: 4, CPUAllocatedCost: 6, MemoryAllocatedCost: 1},
+			{Name: "node-2", CPUCost: 10, MemoryCost: 4, CPUAllocatedCost: 12, MemoryAllocatedCost: 3},
+		}
+		return nil
+	}
+}
+
+// TestRetrieveIdleCost ...
+func TestRetrieveIdleCost(t *testing.T) {
+	mockDgraphForIdleCostQueries()
+	got, err := RetrieveIdleCost(Window{})
+	assert.NoError(t, err)
+	// over allocated node-2 has no idle cpu
+	expected := IdleCost{CPUCost: 4, MemoryCost: 4, CPUAllocatedCost: 18, MemoryAllocatedCost: 4}
+	assert.Equal(t, expected, got)
+}
+
+// TestIdleCostGetShare ...
+func TestIdleCostGetShare(t *testing.T) {
+	idleCost := IdleCost{CPUCost: 4, MemoryCost: 2, CPUAllocatedCost: 8, MemoryAllocatedCost: 0}
+	cpuShare, memoryShare := idleCost.GetShare(2, 1)
+	assert.Equal(t, 1.0, cpuShare)
+	assert.Equal(t, 0.0, memoryShare)
+}
+
+func getTestClusterMetrics() ParentWrapper {
+	return ParentWrapper{
+		Name:       "cluster",
+		Type:       "cluster",
+		CPUCost:    4,
+		MemoryCost: 2,
+		Children: []Children{
+			{Name: "namespace-default", Type: NamespaceType, CPUCost: 3, MemoryCost: 2},
+			{Name: "namespace-kube-system", Type: NamespaceType, CPUCost: 1},
+		},
+	}
+}
+
+// TestAddIdleCost ...
+func TestAddIdleCost(t *testing.T) {
+	idleCost := IdleCost{CPUCost: 2, MemoryCost: 1}
+
+	data := getTestClusterMetrics()
+	addIdleCost(&data, idleCost, All)
+	assert.Len(t, data.Children, 2)
+	assert.Equal(t, 4.0, data.CPUCost)
+	assert.Equal(t, 2.0, data.IdleCPUCost)
+	assert.Equal(t, 1.0, data.IdleMemoryCost)
+
+	data = getTestClusterMetrics()
+	addIdleCost(&data, idleCost, IdleBucket)
+	assert.Len(t, data.Children, 3)
+	assert.Equal(t, Children{Name: IdleType, Type: IdleType, CPUCost: 2, MemoryCost: 1}, data.Children[2])
+	assert.Equal(t, 6.0, data.CPUCost)
+	assert.Equal(t, 3.0, data.MemoryCost)
+
+	data = getTestClusterMetrics()
+	addIdleCost(&data, idleCost, IdleDistribute)
+	assert.Len(t, data.Children, 2)
+	assert.Equal(t, 4.5, data.Children[0].CPUCost)
+	assert.Equal(t, 3.0, data.Children[0].MemoryCost)
+	assert.Equal(t, 1.5, data.Children[1].CPUCost)
+	assert.Equal(t, 0.0, data.Children[1].MemoryCost)
+	assert.Equal(t, 6.0, data.CPUCost)
+	assert.Equal(t, 3.0, data.MemoryCost)
+}
+
+// TestAddIdleCostWithoutChildrenCost ...
+func TestAddIdleCostWithoutChildrenCost(t *testing.T) {
+	data := ParentWrapper{Name: "cluster", Type: "cluster"}
+	addIdleCost(&data, IdleCost{CPUCost: 2, MemoryCost: 1}, IdleDistribute)
+	assert.Equal(t, []Children{{Name: IdleType, Type: IdleType, CPUCost: 2, MemoryCost: 1}}, data.Children)
+	assert.Equal(t, 2.0, data.CPUCost)
+}
+
+// TestDistributeIdleCostToGroups ...
+func TestDistributeIdleCostToGroups(t *testing.T) {
+	groups := []models.Group{{Name: "group-1", MtdCPUCost: 1, MtdMemoryCost: 1, MtdCost: 2, MtdIdleCPUCost: 0.5, MtdIdleMemoryCost: 0.25, MtdIdleCost: 0.75}}
+	DistributeIdleCostToGroups(groups)
+	assert.Equal(t, 1.5, groups[0].MtdCPUCost)
+	assert.Equal(t, 1.25, groups[0].MtdMemoryCost)
+	assert.Equal(t, 2.75, groups[0].MtdCost)
+}
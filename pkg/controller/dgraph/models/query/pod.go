@@ -90,23 +90,6 @@ func RetrievePodsInteractions(name string, isOrphan bool) []byte {
 	return result
 }
 
-func getPricePerResourceForPod(name string) (float64, float64) {
-	query := `query {
-		pods(func: has(isPod)) @filter(eq(name, "` + name + `")) {
-			cpuPrice
-			memoryPrice
-		}
-	}`
-	newRoot := podRoot{}
-	err := executeQuery(query, &newRoot)
-	if err != nil || len(newRoot.Pods) < 1 {
-		logrus.Errorf("err: %v", err)
-		return models.DefaultCPUCostInFloat64, models.DefaultMemCostInFloat64
-	}
-	pod := newRoot.Pods[0]
-	return pod.CPUPrice, pod.MemoryPrice
-}
-
 // RetrievePodsInteractionsForAllLivePodsWithCount returns all pods in the dgraph
 func RetrievePodsInteractionsForAllLivePodsWithCount() ([]models.Pod, error) {
 	q := `query {
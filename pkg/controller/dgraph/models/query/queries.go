@@ -19,15 +19,16 @@ package query
 
 import (
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
 )
 
 // DeploymentMetrics query
-func getQueryForDeploymentMetrics(name string) string {
+func getQueryForDeploymentMetrics(name, costMode string, window Window) string {
 	return `query {
 		dep as var(func: has(isDeployment)) @filter(eq(name, "` + name + `")) {
 			~deployment @filter(has(isReplicaset)) {
 				~replicaset @filter(has(isPod)) {
-					` + getQueryForMetricsComputation("ReplicasetPod") + `
+					` + getQueryForMetricsComputation("ReplicasetPod", costMode, window) + `
 				}
 				` + getQueryForAggregatingChildMetrics("DeploymentReplicaset", "ReplicasetPod") + `
 			}
@@ -44,97 +45,148 @@ func getQueryForDeploymentMetrics(name string) string {
 }
 
 // PodMetrics query
-func getQueryForPodMetrics(name, cpuPrice, memoryPrice string) string {
+func getQueryForPodMetrics(name, costMode string, window Window) string {
 	return `query {
 		parent(func: has(isPod)) @filter(eq(name, "` + name + `")) {
 			children: ~pod @filter(has(isContainer)) {
 				name
 				type
-				` + getQueryForTimeComputation("Container") + `
-				cpu: cpu as cpuRequest
-				memory: memory as memoryRequest
-				cpuCost: math(cpu * durationInHoursContainer * ` + cpuPrice + `)
-				memoryCost: math(memory * durationInHoursContainer * ` + memoryPrice + `)
+				` + getQueryForContainerResourceComputation("Container", costMode, window, true) + `
+				` + getQueryForPodPriceHoursComputation("Container", window) + `
+				cpuCost: math(cpuContainer * cpuPriceHoursContainer)
+				memoryCost: math(memoryContainer * memoryPriceHoursContainer)
+				gpu: gpuRequest
 			}
-			` + getQueryForMetricsComputationWithAlias("Pod") + `
+			` + getQueryForMetricsComputationWithAlias("Pod", costMode, window) + `
 		}
 	}`
 }
 
 // ContainerMetrics query
-func getQueryForContainerMetrics(name string) string {
+func getQueryForContainerMetrics(name, costMode string, window Window) string {
 	return `query {
 		parent(func: has(isContainer)) @filter(eq(name, "` + name + `")) {
 			name
 			type
-			cpu: cpu as cpuRequest
-			memory: memory as memoryRequest
-			` + getQueryForTimeComputation("") + `
-			cpuCost: math(cpu * durationInHours * ` + models.DefaultCPUCostPerCPUPerHour + `)
-			memoryCost: math(memory * durationInHours * ` + models.DefaultMemCostPerGBPerHour + `)
+			` + getQueryForContainerResourceComputation("", costMode, window, true) + `
+			` + getQueryForPodPriceHoursComputation("", window) + `
+			cpuCost: math(cpu * cpuPriceHours)
+			memoryCost: math(memory * memoryPriceHours)
+			gpu: gpuRequest
 		}
 	}`
 }
 
 // PVMetrics query
-func getQueryForPVMetrics(name string) string {
+func getQueryForPVMetrics(name string, window Window) string {
 	return `query {
 		parent(func: has(isPersistentVolume)) @filter(eq(name, "` + name + `")) {
 			children: ~pv @filter(has(isPersistentVolumeClaim)) {
 				name
 				type
 				storage: pvcStorage as storageCapacity
-				` + getQueryForTimeComputation("PVC") + `
-				storageCost: math(pvcStorage * durationInHoursPVC * ` + models.DefaultStorageCostPerGBPerHour + `)
+				` + getQueryForTimeComputation("PVC", window) + `
+				` + getQueryForOwnPriceResolution("PVC", storagePrices...) + `
+				` + getQueryForPriceHoursComputation("PVC", storagePrices, window.toTimeWindow("PVC")) + `
+				storageCost: math(pvcStorage * storagePriceHoursPVC)
 			}
 			name
 			type
 			storage: storage as storageCapacity
 			storageCapacity
-			` + getQueryForTimeComputation("") + `
-			storageCost: math(storage * durationInHours * ` + models.DefaultStorageCostPerGBPerHour + `)
+			` + getQueryForTimeComputation("", window) + `
+			` + getQueryForOwnPriceResolution("", storagePrices...) + `
+			` + getQueryForPriceHoursComputation("", storagePrices, window.toTimeWindow("")) + `
+			storageCost: math(storage * storagePriceHours)
 			storageAllocated: sum(val(pvcStorage))
         }
     }`
 }
 
 // PVCMetrics query
-func getQueryForPVCMetrics(name string) string {
+func getQueryForPVCMetrics(name string, window Window) string {
 	return `query {
 		parent(func: has(isPersistentVolumeClaim)) @filter(eq(name, "` + name + `")) {
 			name
 			type
 			storage: storage as storageCapacity
-			` + getQueryForTimeComputation("") + `
-			storageCost: math(storage * durationInHours * ` + models.DefaultStorageCostPerGBPerHour + `)
+			` + getQueryForTimeComputation("", window) + `
+			` + getQueryForOwnPriceResolution("", storagePrices...) + `
+			` + getQueryForPriceHoursComputation("", storagePrices, window.toTimeWindow("")) + `
+			storageCost: math(storage * storagePriceHours)
         }
     }`
 }
 
 // NodeMetrics query
-func getQueryForNodeMetrics(name string) string {
+func getQueryForNodeMetrics(name, costMode string, window Window) string {
 	return `query {
 		parent(func: has(isNode)) @filter(eq(name, "` + name + `")) {
 			children: ~node @filter(has(isPod)) {
-				` + getQueryForMetricsComputationWithAlias("Pod") + `
+				` + getQueryForMetricsComputationWithAlias("Pod", costMode, window) + `
+				podStorageCost as math(storagePod * storagePriceHoursPod)
 			}
 			name
 			type
 			cpu: cpu as cpuCapacity
 			memory: memory as memoryCapacity
-			storage: storage as sum(val(storagePod))
+			storage: sum(val(storagePod))
 			cpuAllocated: sum(val(cpuPod))
 			memoryAllocated: sum(val(memoryPod))
+			gpuAllocated: sum(val(gpuPod))
 			cpuCapacity
 			memoryCapacity
-			` + getQueryForTimeComputation("") + `
-			` + getQueryForCostWithPriceWithAlias("") + `
+			gpuCapacity
+			pricingTerm
+			` + getQueryForTimeComputation("", window) + `
+			pricePerCPU as cpuPrice
+			pricePerMemory as memoryPrice
+			` + getQueryForOwnPriceResolution("", gpuPrice) + `
+			` + getQueryForPriceHoursComputation("", nodePrices, window.toTimeWindow("")) + `
+			cpuCost: math(cpu * cpuPriceHours)
+			memoryCost: math(memory * memoryPriceHours)
+			storageCost: sum(val(podStorageCost))
+			` + getQueryForNodeGPUCostComputation() + `
+		}
+	}`
+}
+
+// getQueryForNodeGPUCostComputation returns gpu capacity and its cost for nodes with gpus, gpuPriceHours of the node
+// must be defined
+func getQueryForNodeGPUCostComputation() string {
+	return `gpu: nodeGPU as gpuCapacity
+			gpuCost: math(nodeGPU * gpuPriceHours)`
+}
+
+// NodesCost query, month to date cost of capacity of nodes along with cost allocated to their pods by requests
+func getQueryForNodesCost(window Window) string {
+	return `query {
+		nodes(func: has(isNode)) {
+			~node @filter(has(isPod)) {
+				` + getQueryForResourceComputation("Pod", RequestCostMode, window, false) + `
+				` + getQueryForTimeComputation("Pod", window) + `
+				` + getQueryForPriceResolution("Pod") + `
+				` + getQueryForPriceHoursComputation("Pod", computePrices, window.toTimeWindow("Pod")) + `
+				cpuCostPod as math(cpuPod * cpuPriceHoursPod)
+				memoryCostPod as math(memoryPod * memoryPriceHoursPod)
+			}
+			name
+			cpu as cpuCapacity
+			memory as memoryCapacity
+			` + getQueryForTimeComputation("", window) + `
+			pricePerCPU as cpuPrice
+			pricePerMemory as memoryPrice
+			` + getQueryForPriceHoursComputation("", computePrices, window.toTimeWindow("")) + `
+			cpuCost: math(cpu * cpuPriceHours)
+			memoryCost: math(memory * memoryPriceHours)
+			cpuAllocatedCost: sum(val(cpuCostPod))
+			memoryAllocatedCost: sum(val(memoryCostPod))
 		}
 	}`
 }
 
 // NamespaceMetrics query
-func getQueryForNamespaceMetrics(name string) string {
+func getQueryForNamespaceMetrics(name, costMode string, window Window) string {
 	return `query {
 		ns as var(func: has(isNamespace)) @filter(eq(name, "` + name + `")) {
 			childs as ~namespace @filter(has(isDeployment) OR has(isStatefulset) OR has(isJob) OR has(isDaemonset) OR (has(isReplicaset) AND (NOT has(deployment)))) {
@@ -144,21 +196,21 @@ func getQueryForNamespaceMetrics(name string) string {
 					name
 					type
 					~replicaset @filter(has(isPod)) {
-						` + getQueryForMetricsComputation("ReplicasetPod") + `
+						` + getQueryForMetricsComputation("ReplicasetPod", costMode, window) + `
 			        }
 					` + getQueryForAggregatingChildMetrics("DeploymentReplicaset", "ReplicasetPod") + `
                 }
 				~statefulset @filter(has(isPod)) {
-					` + getQueryForMetricsComputation("StatefulsetPod") + `
+					` + getQueryForMetricsComputation("StatefulsetPod", costMode, window) + `
                 }
 				~job @filter(has(isPod)) {
-					` + getQueryForMetricsComputation("JobPod") + `
+					` + getQueryForMetricsComputation("JobPod", costMode, window) + `
                 }
 				~daemonset @filter(has(isPod)) {
-					` + getQueryForMetricsComputation("DaemonsetPod") + `
+					` + getQueryForMetricsComputation("DaemonsetPod", costMode, window) + `
                 }
 				~replicaset @filter(has(isPod)) {
-					` + getQueryForMetricsComputation("ReplicasetSimplePod") + `
+					` + getQueryForMetricsComputation("ReplicasetSimplePod", costMode, window) + `
                 }
 				` + getQueryForAggregatingChildMetrics("SumReplicasetSimplePod", "ReplicasetSimplePod") + `
 				` + getQueryForAggregatingChildMetrics("SumDaemonsetPod", "DaemonsetPod") + `
@@ -171,8 +223,12 @@ func getQueryForNamespaceMetrics(name string) string {
 				cpuCostNamespaceChild as math(cpuCost` + "SumReplicasetSimplePod" + ` + cpuCost` + "SumDaemonsetPod" + ` + cpuCost` + "SumJobPod" + ` + cpuCost` + "SumStatefulsetPod" + ` + cpuCost` + "SumDeploymentReplicaset" + `)
 				memoryCostNamespaceChild as math(memoryCost` + "SumReplicasetSimplePod" + ` + memoryCost` + "SumDaemonsetPod" + ` + memoryCost` + "SumJobPod" + ` + memoryCost` + "SumStatefulsetPod" + ` + memoryCost` + "SumDeploymentReplicaset" + `)
 				storageCostNamespaceChild as math(storageCost` + "SumReplicasetSimplePod" + ` + storageCost` + "SumDaemonsetPod" + ` + storageCost` + "SumJobPod" + ` + storageCost` + "SumStatefulsetPod" + ` + storageCost` + "SumDeploymentReplicaset" + `)
+				gpuNamespaceChild as math(gpu` + "SumReplicasetSimplePod" + ` + gpu` + "SumDaemonsetPod" + ` + gpu` + "SumJobPod" + ` + gpu` + "SumStatefulsetPod" + ` + gpu` + "SumDeploymentReplicaset" + `)
+				gpuCostNamespaceChild as math(gpuCost` + "SumReplicasetSimplePod" + ` + gpuCost` + "SumDaemonsetPod" + ` + gpuCost` + "SumJobPod" + ` + gpuCost` + "SumStatefulsetPod" + ` + gpuCost` + "SumDeploymentReplicaset" + `)
+				extendedResourceCostNamespaceChild as math(extendedResourceCost` + "SumReplicasetSimplePod" + ` + extendedResourceCost` + "SumDaemonsetPod" + ` + extendedResourceCost` + "SumJobPod" + ` + extendedResourceCost` + "SumStatefulsetPod" + ` + extendedResourceCost` + "SumDeploymentReplicaset" + `)
 			}
 			` + getQueryForAggregatingChildMetrics("Namespace", "NamespaceChild") + `
+			` + getQueryForNamespaceServicesCost(window) + `
 		}
 
 		parent(func: uid(ns)) {
@@ -180,37 +236,50 @@ func getQueryForNamespaceMetrics(name string) string {
 				` + getQueryFromSubQueryWithAlias("NamespaceChild") + `
 			}
 			` + getQueryFromSubQueryWithAlias("Namespace") + `
+			serviceCost: val(serviceCostNamespace)
         }
     }`
 }
 
 // LogicalResourcesMetrics query
-func getMetricsQueryForLogicalResources() string {
+func getMetricsQueryForLogicalResources(costMode string, window Window) string {
 	return `query {
 			ns as var(func: has(isNamespace)) {
-				~namespace @filter(has(isPod) AND (NOT has(endTime))) {
-					` + getQueryForMetricsComputation("NamespacePod") + `
+				~namespace @filter(has(isPod) AND ` + window.getLiveFilter() + `) {
+					` + getQueryForMetricsComputation("NamespacePod", costMode, window) + `
 				}
 				` + getQueryForAggregatingChildMetrics("Namespace", "NamespacePod") + `
+				` + getQueryForNamespaceServicesCost(window) + `
 			}
 	
 			children(func: uid(ns)) {
 				` + getQueryFromSubQueryWithAlias("Namespace") + `
+				serviceCost: val(serviceCostNamespace)
 			}
 		}`
 }
 
+// getQueryForNamespaceServicesCost defines serviceCostNamespace variable of a namespace, cost of load balancers of its
+// services in the window
+func getQueryForNamespaceServicesCost(window Window) string {
+	return `services: ~namespace @filter(has(isService) AND has(price)) {
+				` + getQueryForServiceCostComputation("NamespaceService", window) + `
+			}
+			serviceCostNamespace as sum(val(serviceCostNamespaceService))`
+}
+
 // PhysicalResourcesMetrics query
-func getMetricsQueryForPhysicalResources() string {
+func getMetricsQueryForPhysicalResources(window Window) string {
 	return `query {
-			children(func: has(name)) @filter((has(isNode) OR has(isPersistentVolume)) AND (NOT has(endTime))) {
+			children(func: has(name)) @filter((has(isNode) OR has(isPersistentVolume)) AND ` + window.getLiveFilter() + `) {
 				name
 			type
 			cpu: cpu as cpuCapacity
 			memory: memory as memoryCapacity
 			storage: storage as storageCapacity
-			` + getQueryForTimeComputation("") + `
-			` + getQueryForCostWithPriceWithAlias("") + `
+			` + getQueryForTimeComputation("", window) + `
+			` + getQueryForCostWithPriceWithAlias("", window, gpuPrice) + `
+			` + getQueryForNodeGPUCostComputation() + `
 			}
 		}`
 }
@@ -254,6 +323,9 @@ func getQueryForAllGroupsData() string {
 			mtdMemoryCost
 			mtdStorageCost
 			mtdCost
+			mtdIdleCPUCost
+			mtdIdleMemoryCost
+			mtdIdleCost
 			projectedCPUCost
 			projectedMemoryCost
 			projectedStorageCost
@@ -270,6 +342,30 @@ func getQueryForAllGroupsData() string {
 	}`
 }
 
+// getGroupMetricsWindows returns current, last and last to last month windows of group metrics
+func getGroupMetricsWindows(secondsSince map[string]string) []timeWindow {
+	return []timeWindow{
+		{
+			suffix:            "CurrentMonth",
+			secondsSinceStart: secondsSince["currentMonthStart"],
+			secondsSinceEnd:   "0.0",
+			durationInHours:   "currentMonthTrueDurationInHours",
+		},
+		{
+			suffix:            "LastMonth",
+			secondsSinceStart: secondsSince["lastMonthStart"],
+			secondsSinceEnd:   secondsSince["lastMonthEnd"],
+			durationInHours:   "lastMonthTrueDurationInHours",
+		},
+		{
+			suffix:            "LastLastMonth",
+			secondsSinceStart: secondsSince["lastLastMonthStart"],
+			secondsSinceEnd:   secondsSince["lastLastMonthEnd"],
+			durationInHours:   "lastLastMonthTrueDurationInHours",
+		},
+	}
+}
+
 func getQueryForGroupMetrics(podsUIDs string) string {
 	secondsSince := getSecondsSinceForOtherMonths()
 	return `query {
@@ -308,23 +404,21 @@ func getQueryForGroupMetrics(podsUIDs string) string {
 			mtdPvcStorage as math(pvcStorage * currentMonthTrueDurationInHours)
 			mtdPodCPULimit as math(podCpuLimit * currentMonthTrueDurationInHours)
 			mtdPodMemoryLimit as math(podMemoryLimit * currentMonthTrueDurationInHours)
-			pricePerCPU as cpuPrice
-			pricePerMemory as memoryPrice
-			podCpuCost as math(mtdPodCPU * pricePerCPU)
-			podMemoryCost as math(mtdPodMemory * pricePerMemory)
-			podStorageCost as math(mtdPvcStorage * ` + models.DefaultStorageCostPerGBPerHour + `)
+			` + getQueryForPriceResolution("") + `
+			` + getQueryForOwnPriceResolution("", storagePrices...) + `
+			` + getQueryForPriceHoursComputation("", podResourcePrices, getGroupMetricsWindows(secondsSince)...) + `
+			podCpuCost as math(podCpu * cpuPriceHoursCurrentMonth)
+			podMemoryCost as math(podMemory * memoryPriceHoursCurrentMonth)
+			podStorageCost as math(pvcStorage * storagePriceHoursCurrentMonth)
 			podLiveCPUCostPerHour as math(pitPodCPU * pricePerCPU)
 			podLiveMemoryCostPerHour as math(pitPodMemory * pricePerMemory)
-			podLiveStorageCostPerHour as math(pitPvcStorage * ` + models.DefaultStorageCostPerGBPerHour + `)
-			podCPUCostPerHour as math(podCpu * pricePerCPU)
-			podMemoryCostPerHour as math(podMemory * pricePerMemory)
-			podStorageCostPerHour as math(pvcStorage * ` + models.DefaultStorageCostPerGBPerHour + `)
-			podCPUCostLastMonth as math(podCPUCostPerHour * lastMonthTrueDurationInHours)
-			podMemoryCostLastMonth as math(podMemoryCostPerHour * lastMonthTrueDurationInHours)
-			podStorageCostLastMonth as math(podStorageCostPerHour * lastMonthTrueDurationInHours)
-			podCPUCostLastLastMonth as math(podCPUCostPerHour * lastLastMonthTrueDurationInHours)
-			podMemoryCostLastLastMonth as math(podMemoryCostPerHour * lastLastMonthTrueDurationInHours)
-			podStorageCostLastLastMonth as math(podStorageCostPerHour * lastLastMonthTrueDurationInHours)
+			podLiveStorageCostPerHour as math(pitPvcStorage * pricePerStorage)
+			podCPUCostLastMonth as math(podCpu * cpuPriceHoursLastMonth)
+			podMemoryCostLastMonth as math(podMemory * memoryPriceHoursLastMonth)
+			podStorageCostLastMonth as math(pvcStorage * storagePriceHoursLastMonth)
+			podCPUCostLastLastMonth as math(podCpu * cpuPriceHoursLastLastMonth)
+			podMemoryCostLastLastMonth as math(podMemory * memoryPriceHoursLastLastMonth)
+			podStorageCostLastLastMonth as math(pvcStorage * storagePriceHoursLastLastMonth)
 		}
 		
 		group() {
@@ -390,3 +484,256 @@ func getQueryForPodsWithLabelFilter(labelFilter string) string {
 		}
 	}`
 }
+
+func getQueryForRateCard() string {
+	return `query {
+		rateCards(func: has(isRateCard)) {
+			cloudProvider
+			region
+			zone
+			locationSource
+			version
+			effectiveFrom
+		}
+	}`
+}
+
+func getQueryForRateCardHistory() string {
+	return `query {
+		rateCards(func: has(isRateCardHistory)) {
+			cloudProvider
+			region
+			zone
+			locationSource
+			version
+			effectiveFrom
+			pricesDigest
+		}
+	}`
+}
+
+// RateCardVersion query, version of the rate card effective from the given time along with its prices
+func getQueryForRateCardVersion(effectiveFrom string) string {
+	return `query {
+		rateCards(func: eq(xid, "` + models.RateCardXID + "-" + effectiveFrom + `")) @filter(has(isRateCardHistory)) {
+			cloudProvider
+			region
+			zone
+			locationSource
+			version
+			effectiveFrom
+			pricesDigest
+			prices
+		}
+	}`
+}
+
+func getQueryForInstanceTypePrices() string {
+	return `query {
+		nodePrices(func: has(isNodePrice)) @filter(has(instanceType) AND NOT has(labelSelector)) {
+			instanceType
+			instanceFamily
+			operatingSystem
+			price
+			reservedPrice
+			spotPrice
+			gpuCount
+			cpuCapacity
+			memoryCapacity
+		}
+	}`
+}
+
+func getQueryForLiveNodesPrice() string {
+	return `query {
+		nodes(func: has(isNode)) @filter(NOT has(endTime)) {
+			xid
+			instanceType
+			os
+			pricingTerm
+			cpuCapacity
+			memoryCapacity
+			gpuCapacity
+			cpuPrice
+			memoryPrice
+			gpuPrice
+		}
+	}`
+}
+
+// getQueryForGroupPodsCost returns query for namespace, cpu request(if alive) and month to date cost of given pods
+func getQueryForGroupPodsCost(podsUIDs string) string {
+	return `query {
+		pods(func: uid(` + podsUIDs + `)) {
+			uid
+			namespace {
+				name
+			}
+			` + getQueryForMetricsComputation("Pod", RequestCostMode, Window{}) + `
+			cpu: math(cond(isTerminatedPod == 0, cpuPod, 0.0))
+			cost: math(cpuCostPod + memoryCostPod + storageCostPod)
+		}
+	}`
+}
+
+// getQueryForGroupMetricsInWindow returns query for cpu, memory and storage hours along with their cost of given pods
+// in the window. Cost of a service is shared by the groups whose pods back it, in proportion to their backing pods.
+func getQueryForGroupMetricsInWindow(podsUIDs string, window Window) string {
+	return `query {
+		groupPods as var(func: uid(` + podsUIDs + `)) {
+			` + getQueryForMetricsComputation("Pod", RequestCostMode, window) + `
+			cpuHoursPod as math(cpuPod * durationInHoursPod)
+			memoryHoursPod as math(memoryPod * durationInHoursPod)
+			storageHoursPod as math(storagePod * durationInHoursPod)
+			gpuHoursPod as math(gpuPod * durationInHoursPod)
+			groupServices as ~pod @filter(has(isService) AND has(price)) {
+				name
+			}
+		}
+
+		var(func: uid(groupServices)) {
+			backingPods as count(pod)
+			groupBackingPods as count(pod @filter(uid(groupPods)))
+			` + getQueryForServiceCostComputation("Service", window) + `
+			groupServiceCost as math(serviceCostService * groupBackingPods / backingPods)
+		}
+
+		group() {
+			mtdCPU: sum(val(cpuHoursPod))
+			mtdMemory: sum(val(memoryHoursPod))
+			mtdStorage: sum(val(storageHoursPod))
+			cpuCost: sum(val(cpuCostPod))
+			memoryCost: sum(val(memoryCostPod))
+			storageCost: sum(val(storageCostPod))
+			mtdGPU: sum(val(gpuHoursPod))
+			gpuCost: sum(val(gpuCostPod))
+			extendedResourceCost: sum(val(extendedResourceCostPod))
+			serviceCost: sum(val(groupServiceCost))
+		}
+	}`
+}
+
+// WorkloadsMetrics query, metrics of all deployments, statefulsets, daemonsets and jobs alive in the window
+func getQueryForWorkloadsMetrics(window Window) string {
+	live := window.getLiveFilter()
+	return `query {
+		deploymentOwners as var(func: has(isDeployment)) @filter(` + live + `) {
+			~deployment @filter(has(isReplicaset)) {
+				~replicaset @filter(has(isPod) AND ` + live + `) {
+					` + getQueryForMetricsComputation("ReplicasetPod", RequestCostMode, window) + `
+				}
+				` + getQueryForAggregatingChildMetrics("DeploymentReplicaset", "ReplicasetPod") + `
+			}
+			` + getQueryForAggregatingChildMetrics("Deployment", "DeploymentReplicaset") + `
+		}
+		` + getQueryForPodOwnersMetrics("statefulset", "isStatefulset", "Statefulset", window) + `
+		` + getQueryForPodOwnersMetrics("daemonset", "isDaemonset", "Daemonset", window) + `
+		` + getQueryForPodOwnersMetrics("job", "isJob", "Job", window) + `
+
+		deployments(func: uid(deploymentOwners)) {
+			xid
+			` + getQueryFromSubQueryWithAlias("Deployment") + `
+		}
+		statefulsets(func: uid(statefulsetOwners)) {
+			xid
+			` + getQueryFromSubQueryWithAlias("Statefulset") + `
+		}
+		daemonsets(func: uid(daemonsetOwners)) {
+			xid
+			` + getQueryFromSubQueryWithAlias("Daemonset") + `
+		}
+		jobs(func: uid(jobOwners)) {
+			xid
+			` + getQueryFromSubQueryWithAlias("Job") + `
+		}
+	}`
+}
+
+// getQueryForPodOwnersMetrics defines <ownerType>Owners variable holding owners(ex: statefulsets) of pods, alive in the
+// window, along with metrics of their pods
+func getQueryForPodOwnersMetrics(ownerType, ownerCheck, suffix string, window Window) string {
+	live := window.getLiveFilter()
+	return ownerType + `Owners as var(func: has(` + ownerCheck + `)) @filter(` + live + `) {
+			~` + ownerType + ` @filter(has(isPod) AND ` + live + `) {
+				` + getQueryForMetricsComputation(suffix+"Pod", RequestCostMode, window) + `
+			}
+			` + getQueryForAggregatingChildMetrics(suffix, suffix+"Pod") + `
+		}`
+}
+
+// CostSnapshots query, snapshots of the kind(and name if given) taken in the window
+func getQueryForCostSnapshots(kind, name string, window Window) string {
+	nameFilter := ""
+	if name != All {
+		nameFilter = ` AND eq(snapshotName, "` + name + `")`
+	}
+	return `query {
+		snapshots(func: ge(snapshotTime, "` + utils.ConverTimeToRFC3339(window.getStart()) + `")) @filter(has(isCostSnapshot) AND lt(snapshotTime, "` +
+		utils.ConverTimeToRFC3339(window.getEnd()) + `") AND eq(snapshotKind, "` + kind + `")` + nameFilter + `) {
+			snapshotName
+			snapshotTime
+			cpu
+			memory
+			storage
+			cpuCost
+			memoryCost
+			storageCost
+			gpu
+			gpuCost
+			extendedResourceCost
+			serviceCost
+			cost
+		}
+	}`
+}
+
+// PodsWorkloads query, deployments(through replicasets), statefulsets, daemonsets and jobs owning the pods
+func getQueryForPodsWorkloads(podsUIDs string) string {
+	return `query {
+		pods(func: uid(` + podsUIDs + `)) {
+			replicaset {
+				deployment {
+					xid
+				}
+			}
+			statefulset {
+				xid
+			}
+			daemonset {
+				xid
+			}
+			job {
+				xid
+			}
+		}
+	}`
+}
+
+// CostAnomalies query, anomalies of the kind and name(if given) detected in hours starting in the window
+func getQueryForCostAnomalies(kind, name string, window Window) string {
+	filter := ""
+	if kind != All {
+		filter += ` AND eq(anomalyKind, "` + kind + `")`
+	}
+	if name != All {
+		filter += ` AND eq(anomalyName, "` + name + `")`
+	}
+	return `query {
+		anomalies(func: ge(anomalyTime, "` + utils.ConverTimeToRFC3339(window.getStart()) + `")) @filter(has(isCostAnomaly) AND lt(anomalyTime, "` +
+		utils.ConverTimeToRFC3339(window.getEnd()) + `")` + filter + `) {
+			anomalyKind
+			anomalyName
+			anomalyTime
+			cost
+			baselineCost
+			baselineStdDev
+			score
+			contributors {
+				workloadKind
+				workloadName
+				cost
+				baselineCost
+			}
+		}
+	}`
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// EffectiveFrom is query parameter of the time(RFC3339) from which a version of the rate card is effective
+const EffectiveFrom = "effectiveFrom"
+
+type rateCardRoot struct {
+	RateCards []models.RateCard `json:"rateCards"`
+}
+
+// RetrieveRateCard returns the cloud provider, region and zone of the rate card stored in dgraph
+func RetrieveRateCard() (*models.RateCard, error) {
+	q := getQueryForRateCard()
+	newRoot := rateCardRoot{}
+	err := executeQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	} else if len(newRoot.RateCards) < 1 {
+		return nil, fmt.Errorf("rate card is not yet populated")
+	}
+	return &newRoot.RateCards[0], nil
+}
+
+// RetrieveRateCardHistory returns all the rate cards used for pricing, latest first
+func RetrieveRateCardHistory() ([]models.RateCard, error) {
+	q := getQueryForRateCardHistory()
+	newRoot := rateCardRoot{}
+	err := executeQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	// effectiveFrom is RFC3339, so lexical order is time order
+	sort.SliceStable(newRoot.RateCards, func(i, j int) bool {
+		return newRoot.RateCards[i].EffectiveFrom > newRoot.RateCards[j].EffectiveFrom
+	})
+	return newRoot.RateCards, nil
+}
+
+// RetrieveRateCardVersion returns version of the rate card effective from the given time along with the node, storage,
+// extended resource and network prices it had
+func RetrieveRateCardVersion(effectiveFrom string) (*models.RateCard, error) {
+	newRoot := rateCardRoot{}
+	err := executeQuery(getQueryForRateCardVersion(effectiveFrom), &newRoot)
+	if err != nil {
+		return nil, err
+	} else if len(newRoot.RateCards) < 1 {
+		return nil, fmt.Errorf("no version of rate card is effective from: %s", effectiveFrom)
+	}
+
+	rateCard := newRoot.RateCards[0]
+	if rateCard.Prices != "" {
+		err = json.Unmarshal([]byte(rateCard.Prices), &rateCard)
+		if err != nil {
+			return nil, fmt.Errorf("unable to unmarshal prices of rate card effective from: %s, err: %v", effectiveFrom, err)
+		}
+		rateCard.Prices = ""
+	}
+	return &rateCard, nil
+}
+
+type nodePricesRoot struct {
+	NodePrices []models.NodePrice `json:"nodePrices"`
+	Nodes      []models.Node      `json:"nodes"`
+}
+
+// RetrieveInstanceTypePrices returns prices of instance types of the rate card, node prices selected by node labels
+// and the default node price aren't instance types
+func RetrieveInstanceTypePrices() ([]models.NodePrice, error) {
+	newRoot := nodePricesRoot{}
+	err := executeQuery(getQueryForInstanceTypePrices(), &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	nodePrices := []models.NodePrice{}
+	for _, nodePrice := range newRoot.NodePrices {
+		if nodePrice.InstanceType != models.DefaultNodeInstance {
+			nodePrices = append(nodePrices, nodePrice)
+		}
+	}
+	return nodePrices, nil
+}
+
+// RetrieveLiveNodesPrice returns live nodes along with their per unit resource prices
+func RetrieveLiveNodesPrice() ([]models.Node, error) {
+	newRoot := nodePricesRoot{}
+	err := executeQuery(getQueryForLiveNodesPrice(), &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Nodes, nil
+}
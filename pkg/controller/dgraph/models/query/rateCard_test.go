@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+func mockDgraphForRateCardQueries(queryType string) {
+	executeQuery = func(query string, root interface{}) error {
+		dummyRateCardRoot, ok := root.(*rateCardRoot)
+		if !ok {
+			return fmt.Errorf("wrong root received")
+		}
+
+		if queryType == testRetrieveRateCard {
+			dummyRateCardRoot.RateCards = []models.RateCard{{
+				CloudProvider:  models.AWS,
+				Region:         "us-west-2",
+				Zone:           "us-west-2a",
+				LocationSource: "nodes",
+			}}
+		} else if queryType == testRateCardHistory {
+			dummyRateCardRoot.RateCards = []models.RateCard{
+				{CloudProvider: models.AWS, Version: "1", EffectiveFrom: "2019-05-01T00:00:00Z"},
+				{CloudProvider: models.AWS, Version: "3", EffectiveFrom: "2019-07-01T00:00:00Z"},
+				{CloudProvider: models.AWS, Version: "2", EffectiveFrom: "2019-06-01T00:00:00Z"},
+			}
+		}
+		return nil
+	}
+}
+
+// TestRetrieveRateCard ...
+func TestRetrieveRateCard(t *testing.T) {
+	mockDgraphForRateCardQueries(testRetrieveRateCard)
+	got, err := RetrieveRateCard()
+	expected := &models.RateCard{
+		CloudProvider:  models.AWS,
+		Region:         "us-west-2",
+		Zone:           "us-west-2a",
+		LocationSource: "nodes",
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
+
+// TestRetrieveRateCardWhenNotPopulated ...
+func TestRetrieveRateCardWhenNotPopulated(t *testing.T) {
+	mockDgraphForRateCardQueries(testWrongQuery)
+	_, err := RetrieveRateCard()
+	assert.Error(t, err)
+}
+
+// TestRetrieveRateCardHistory ...
+func TestRetrieveRateCardHistory(t *testing.T) {
+	mockDgraphForRateCardQueries(testRateCardHistory)
+	got, err := RetrieveRateCardHistory()
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+	for i, version := range []string{"3", "2", "1"} {
+		assert.Equal(t, version, got[i].Version)
+	}
+}
+
+// TestRetrieveRateCardVersion ...
+func TestRetrieveRateCardVersion(t *testing.T) {
+	executeQuery = func(query string, root interface{}) error {
+		dummyRateCardRoot, ok := root.(*rateCardRoot)
+		if !ok {
+			return fmt.Errorf("wrong root received")
+		}
+		if strings.Contains(query, `"purser-rateCard-2019-06-01T00:00:00Z"`) {
+			dummyRateCardRoot.RateCards = []models.RateCard{{
+				CloudProvider: models.AWS,
+				Version:       "2",
+				EffectiveFrom: "2019-06-01T00:00:00Z",
+				Prices:        `{"nodePrices":[{"xid":"m5.large-linux","instanceType":"m5.large","price":0.096}],"storagePrices":[{"xid":"gp2-EBS:VolumeUsage.gp2","price":0.1}]}`,
+			}}
+		}
+		return nil
+	}
+
+	got, err := RetrieveRateCardVersion("2019-06-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", got.Version)
+	assert.Equal(t, "", got.Prices)
+	assert.Len(t, got.NodePrices, 1)
+	assert.Equal(t, "m5.large", got.NodePrices[0].InstanceType)
+	assert.Equal(t, 0.096, got.NodePrices[0].Price)
+	assert.Len(t, got.StoragePrices, 1)
+	assert.Equal(t, 0.1, got.StoragePrices[0].Price)
+
+	_, err = RetrieveRateCardVersion("2019-05-01T00:00:00Z")
+	assert.Error(t, err)
+}
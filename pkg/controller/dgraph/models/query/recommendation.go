@@ -0,0 +1,333 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+// Query parameter of recommendations, safety margin is the fraction added to observed usage(ex: 0.2 for 20%)
+const (
+	Margin        = "margin"
+	DefaultMargin = 0.2
+
+	// recommendations never go below 10 millicores of cpu and 10MB of memory
+	minCPURecommendation    = 0.01
+	minMemoryRecommendation = 0.01
+
+	// recommendations of containers with fewer samples(p95 of fewer samples is their max) or whose samples span less
+	// than a day(a daily cycle of load) are low confidence
+	minRecommendationSamples = 20
+	minRecommendationSpan    = 24 * time.Hour
+)
+
+// UsagePercentiles is p50, p95 and max of usage samples of a container
+type UsagePercentiles struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	Max float64 `json:"max"`
+}
+
+// ContainerRecommendation is observed usage of a container of a workload in a window along with its current and
+// recommended requests and limits. Requests are recommended at p95 usage and limits at max usage plus the margin.
+// Savings of low confidence recommendations aren't projected.
+type ContainerRecommendation struct {
+	Name                     string           `json:"name"`
+	Samples                  int              `json:"samples"`
+	SampledHours             float64          `json:"sampledHours"`
+	LowConfidence            bool             `json:"lowConfidence"`
+	CPUUsage                 UsagePercentiles `json:"cpuUsage"`
+	MemoryUsage              UsagePercentiles `json:"memoryUsage"`
+	CPURequest               float64          `json:"cpuRequest"`
+	CPULimit                 float64          `json:"cpuLimit"`
+	MemoryRequest            float64          `json:"memoryRequest"`
+	MemoryLimit              float64          `json:"memoryLimit"`
+	RecommendedCPURequest    float64          `json:"recommendedCpuRequest"`
+	RecommendedCPULimit      float64          `json:"recommendedCpuLimit"`
+	RecommendedMemoryRequest float64          `json:"recommendedMemoryRequest"`
+	RecommendedMemoryLimit   float64          `json:"recommendedMemoryLimit"`
+	MonthlySavings           float64          `json:"monthlySavings"`
+}
+
+// WorkloadRecommendation is rightsizing recommendation of containers of a deployment, statefulset or daemonset.
+// Savings are monthly cost of the change in requests of its live replicas at the price of their nodes, they are
+// negative when the workload is under provisioned.
+type WorkloadRecommendation struct {
+	Kind           string                    `json:"kind"`
+	Name           string                    `json:"name"`
+	Replicas       int                       `json:"replicas"`
+	CPUPrice       float64                   `json:"cpuPrice"`
+	MemoryPrice    float64                   `json:"memoryPrice"`
+	Containers     []ContainerRecommendation `json:"containers"`
+	MonthlySavings float64                   `json:"monthlySavings"`
+}
+
+type containerUsage struct {
+	Name          string               `json:"name"`
+	CPURequest    float64              `json:"cpuRequest"`
+	CPULimit      float64              `json:"cpuLimit"`
+	MemoryRequest float64              `json:"memoryRequest"`
+	MemoryLimit   float64              `json:"memoryLimit"`
+	UsageSamples  []models.UsageSample `json:"usageSamples"`
+}
+
+type nodeUsagePrice struct {
+	CPUPrice    float64 `json:"cpuPrice"`
+	MemoryPrice float64 `json:"memoryPrice"`
+}
+
+type podUsage struct {
+	EndTime     string           `json:"endTime"`
+	CPUPrice    float64          `json:"cpuPrice"`
+	MemoryPrice float64          `json:"memoryPrice"`
+	Node        *nodeUsagePrice  `json:"node"`
+	Containers  []containerUsage `json:"containers"`
+}
+
+type workloadUsage struct {
+	Xid         string `json:"xid"`
+	Replicasets []struct {
+		Pods []podUsage `json:"pods"`
+	} `json:"replicasets"`
+	Pods []podUsage `json:"pods"`
+}
+
+type workloadsUsageRoot struct {
+	Deployments  []workloadUsage `json:"deployments"`
+	Statefulsets []workloadUsage `json:"statefulsets"`
+	Daemonsets   []workloadUsage `json:"daemonsets"`
+}
+
+// RetrieveRecommendations returns rightsizing recommendations of live deployments, statefulsets and daemonsets(or
+// only those of the kind or only the named one) from usage samples of their containers in the window
+func RetrieveRecommendations(kind, name string, margin float64, window Window) ([]WorkloadRecommendation, error) {
+	root := workloadsUsageRoot{}
+	err := executeQuery(getQueryForWorkloadsUsage(kind, name, window), &root)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations := []WorkloadRecommendation{}
+	for _, workload := range root.Deployments {
+		pods := []podUsage{}
+		for _, replicaset := range workload.Replicasets {
+			pods = append(pods, replicaset.Pods...)
+		}
+		recommendations = appendRecommendation(recommendations, DeploymentType, workload.Xid, pods, margin)
+	}
+	for _, workload := range root.Statefulsets {
+		recommendations = appendRecommendation(recommendations, StatefulsetType, workload.Xid, workload.Pods, margin)
+	}
+	for _, workload := range root.Daemonsets {
+		recommendations = appendRecommendation(recommendations, DaemonsetType, workload.Xid, workload.Pods, margin)
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].MonthlySavings > recommendations[j].MonthlySavings
+	})
+	return recommendations, nil
+}
+
+// appendRecommendation appends recommendation of the workload unless none of its containers has usage samples
+func appendRecommendation(recommendations []WorkloadRecommendation, kind, name string, pods []podUsage, margin float64) []WorkloadRecommendation {
+	recommendation := newWorkloadRecommendation(kind, name, pods, margin)
+	if len(recommendation.Containers) == 0 {
+		return recommendations
+	}
+	return append(recommendations, recommendation)
+}
+
+// newWorkloadRecommendation computes recommendation of every container(by name) of the workload from samples of all
+// its pods, current requests, limits and prices are taken from live pods
+func newWorkloadRecommendation(kind, name string, pods []podUsage, margin float64) WorkloadRecommendation {
+	recommendation := WorkloadRecommendation{Kind: kind, Name: name, Containers: []ContainerRecommendation{}}
+	cpuSamples, memorySamples := map[string][]float64{}, map[string][]float64{}
+	firstSampleTimes, lastSampleTimes := map[string]time.Time{}, map[string]time.Time{}
+	current := map[string]containerUsage{}
+	containerNames := []string{}
+	for _, pod := range pods {
+		isLive := pod.EndTime == ""
+		if isLive {
+			recommendation.Replicas++
+			cpuPrice, memoryPrice := getPodUsagePrice(pod)
+			recommendation.CPUPrice += cpuPrice
+			recommendation.MemoryPrice += memoryPrice
+		}
+		for _, container := range pod.Containers {
+			if _, isSeen := cpuSamples[container.Name]; !isSeen {
+				containerNames = append(containerNames, container.Name)
+				cpuSamples[container.Name], memorySamples[container.Name] = []float64{}, []float64{}
+			}
+			for _, sample := range container.UsageSamples {
+				cpuSamples[container.Name] = append(cpuSamples[container.Name], sample.CPUUsage)
+				memorySamples[container.Name] = append(memorySamples[container.Name], sample.MemoryUsage)
+				sampleTime, err := time.Parse(time.RFC3339, sample.SampleTime)
+				if err != nil {
+					continue
+				}
+				if first, isSeen := firstSampleTimes[container.Name]; !isSeen || sampleTime.Before(first) {
+					firstSampleTimes[container.Name] = sampleTime
+				}
+				if last, isSeen := lastSampleTimes[container.Name]; !isSeen || sampleTime.After(last) {
+					lastSampleTimes[container.Name] = sampleTime
+				}
+			}
+			if isLive {
+				current[container.Name] = container
+			}
+		}
+	}
+	if recommendation.Replicas > 0 {
+		recommendation.CPUPrice /= float64(recommendation.Replicas)
+		recommendation.MemoryPrice /= float64(recommendation.Replicas)
+	}
+
+	sort.Strings(containerNames)
+	for _, containerName := range containerNames {
+		if len(cpuSamples[containerName]) == 0 {
+			continue
+		}
+		container := newContainerRecommendation(current[containerName], cpuSamples[containerName], memorySamples[containerName], margin)
+		container.Name = containerName
+		container.SampledHours = lastSampleTimes[containerName].Sub(firstSampleTimes[containerName]).Hours()
+		container.LowConfidence = container.Samples < minRecommendationSamples ||
+			container.SampledHours < minRecommendationSpan.Hours()
+		if !container.LowConfidence {
+			container.MonthlySavings = ((container.CPURequest-container.RecommendedCPURequest)*recommendation.CPUPrice +
+				(container.MemoryRequest-container.RecommendedMemoryRequest)*recommendation.MemoryPrice) *
+				float64(recommendation.Replicas) * models.HoursInMonth
+		}
+		recommendation.Containers = append(recommendation.Containers, container)
+		recommendation.MonthlySavings += container.MonthlySavings
+	}
+	return recommendation
+}
+
+func newContainerRecommendation(current containerUsage, cpuSamples, memorySamples []float64, margin float64) ContainerRecommendation {
+	recommendation := ContainerRecommendation{
+		Samples:       len(cpuSamples),
+		CPUUsage:      getUsagePercentiles(cpuSamples),
+		MemoryUsage:   getUsagePercentiles(memorySamples),
+		CPURequest:    current.CPURequest,
+		CPULimit:      current.CPULimit,
+		MemoryRequest: current.MemoryRequest,
+		MemoryLimit:   current.MemoryLimit,
+	}
+	recommendation.RecommendedCPURequest = math.Max(recommendation.CPUUsage.P95*(1+margin), minCPURecommendation)
+	recommendation.RecommendedCPULimit = math.Max(recommendation.CPUUsage.Max*(1+margin), recommendation.RecommendedCPURequest)
+	recommendation.RecommendedMemoryRequest = math.Max(recommendation.MemoryUsage.P95*(1+margin), minMemoryRecommendation)
+	recommendation.RecommendedMemoryLimit = math.Max(recommendation.MemoryUsage.Max*(1+margin), recommendation.RecommendedMemoryRequest)
+	return recommendation
+}
+
+// getUsagePercentiles returns p50, p95 and max of the samples by nearest rank
+func getUsagePercentiles(samples []float64) UsagePercentiles {
+	if len(samples) == 0 {
+		return UsagePercentiles{}
+	}
+	sorted := append([]float64{}, samples...)
+	sort.Float64s(sorted)
+	return UsagePercentiles{
+		P50: getPercentile(sorted, 50),
+		P95: getPercentile(sorted, 95),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+func getPercentile(sorted []float64, percentile float64) float64 {
+	rank := int(math.Ceil(percentile / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// getPodUsagePrice returns price of the pod's node, its own price when it has no node or defaults otherwise
+func getPodUsagePrice(pod podUsage) (float64, float64) {
+	if pod.Node != nil && (pod.Node.CPUPrice > 0 || pod.Node.MemoryPrice > 0) {
+		return pod.Node.CPUPrice, pod.Node.MemoryPrice
+	}
+	if pod.CPUPrice > 0 || pod.MemoryPrice > 0 {
+		return pod.CPUPrice, pod.MemoryPrice
+	}
+	return models.DefaultCPUCostInFloat64, models.DefaultMemCostInFloat64
+}
+
+// getQueryForWorkloadsUsage returns query for usage samples in the window of containers of live workloads, name of a
+// workload is namespace prefixed(ex: default:nginx)
+func getQueryForWorkloadsUsage(kind, name string, window Window) string {
+	pods := `@filter(has(isPod) AND ` + window.getLiveFilter() + `) {
+				endTime
+				cpuPrice
+				memoryPrice
+				node {
+					cpuPrice
+					memoryPrice
+				}
+				containers: ~pod @filter(has(isContainer)) {
+					name
+					cpuRequest
+					cpuLimit
+					memoryRequest
+					memoryLimit
+					usageSamples @filter(ge(sampleTime, "` + utils.ConverTimeToRFC3339(window.getStart()) + `") AND lt(sampleTime, "` +
+		utils.ConverTimeToRFC3339(window.getEnd()) + `")) {
+						sampleTime
+						cpuUsage
+						memoryUsage
+					}
+				}
+			}`
+
+	blocks := []string{}
+	if kind == All || kind == DeploymentType {
+		blocks = append(blocks, getWorkloadsRoot("deployments", "isDeployment", name)+` {
+			xid
+			replicasets: ~deployment @filter(has(isReplicaset)) {
+				pods: ~replicaset `+pods+`
+			}
+		}`)
+	}
+	if kind == All || kind == StatefulsetType {
+		blocks = append(blocks, getWorkloadsRoot("statefulsets", "isStatefulset", name)+` {
+			xid
+			pods: ~statefulset `+pods+`
+		}`)
+	}
+	if kind == All || kind == DaemonsetType {
+		blocks = append(blocks, getWorkloadsRoot("daemonsets", "isDaemonset", name)+` {
+			xid
+			pods: ~daemonset `+pods+`
+		}`)
+	}
+	return `query {
+		` + strings.Join(blocks, "\n\t\t") + `
+	}`
+}
+
+func getWorkloadsRoot(block, isType, name string) string {
+	if name != All {
+		return block + `(func: eq(xid, "` + name + `")) @filter(has(` + isType + `) AND NOT has(endTime))`
+	}
+	return block + `(func: has(` + isType + `)) @filter(NOT has(endTime))`
+}
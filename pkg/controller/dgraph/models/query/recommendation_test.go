@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// TestGetUsagePercentiles ...
+func TestGetUsagePercentiles(t *testing.T) {
+	samples := []float64{}
+	for i := 20; i >= 1; i-- {
+		samples = append(samples, float64(i))
+	}
+	percentiles := getUsagePercentiles(samples)
+	assert.Equal(t, 10.0, percentiles.P50)
+	assert.Equal(t, 19.0, percentiles.P95)
+	assert.Equal(t, 20.0, percentiles.Max)
+	assert.Equal(t, 20.0, samples[0], "samples must not be reordered")
+
+	assert.Equal(t, UsagePercentiles{}, getUsagePercentiles(nil))
+	assert.Equal(t, UsagePercentiles{P50: 2, P95: 2, Max: 2}, getUsagePercentiles([]float64{2}))
+}
+
+// TestNewWorkloadRecommendation ...
+func TestNewWorkloadRecommendation(t *testing.T) {
+	samples := []models.UsageSample{}
+	firstSampleTime := time.Date(2019, time.May, 30, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, models.UsageSample{
+			SampleTime:  firstSampleTime.Add(time.Duration(i-1) * 2 * time.Hour).Format(time.RFC3339),
+			CPUUsage:    0.01 * float64(i),
+			MemoryUsage: 0.05 * float64(i),
+		})
+	}
+	livePod := podUsage{
+		Node: &nodeUsagePrice{CPUPrice: 0.03, MemoryPrice: 0.005},
+		Containers: []containerUsage{
+			{Name: "container-nginx", CPURequest: 1, CPULimit: 2, MemoryRequest: 2, MemoryLimit: 4, UsageSamples: samples[:10]},
+			{Name: "container-sidecar", CPURequest: 0.1, MemoryRequest: 0.1},
+		},
+	}
+	endedPod := podUsage{
+		EndTime:    "2019-06-01T00:00:00Z",
+		Containers: []containerUsage{{Name: "container-nginx", CPURequest: 4, MemoryRequest: 8, UsageSamples: samples[10:]}},
+	}
+
+	recommendation := newWorkloadRecommendation(DeploymentType, "default:nginx", []podUsage{livePod, livePod, endedPod}, 0.2)
+
+	assert.Equal(t, DeploymentType, recommendation.Kind)
+	assert.Equal(t, 2, recommendation.Replicas)
+	assert.InDelta(t, 0.03, recommendation.CPUPrice, 1e-12)
+	assert.InDelta(t, 0.005, recommendation.MemoryPrice, 1e-12)
+	// sidecar has no usage samples
+	assert.Equal(t, 1, len(recommendation.Containers))
+
+	container := recommendation.Containers[0]
+	assert.Equal(t, "container-nginx", container.Name)
+	assert.Equal(t, 30, container.Samples)
+	assert.Equal(t, 38.0, container.SampledHours)
+	assert.False(t, container.LowConfidence)
+	assert.Equal(t, 1.0, container.CPURequest, "requests are taken from live pods")
+	assert.InDelta(t, 0.19, container.CPUUsage.P95, 1e-12)
+	assert.InDelta(t, 0.2, container.CPUUsage.Max, 1e-12)
+	assert.InDelta(t, 0.228, container.RecommendedCPURequest, 1e-12)
+	assert.InDelta(t, 0.24, container.RecommendedCPULimit, 1e-12)
+	assert.InDelta(t, 1.14, container.RecommendedMemoryRequest, 1e-12)
+	assert.InDelta(t, 1.2, container.RecommendedMemoryLimit, 1e-12)
+
+	expectedSavings := ((1-0.228)*0.03 + (2-1.14)*0.005) * 2 * models.HoursInMonth
+	assert.InDelta(t, expectedSavings, container.MonthlySavings, 1e-9)
+	assert.InDelta(t, expectedSavings, recommendation.MonthlySavings, 1e-9)
+}
+
+// TestNewWorkloadRecommendationWithLowConfidence ...
+func TestNewWorkloadRecommendationWithLowConfidence(t *testing.T) {
+	samples := []models.UsageSample{}
+	firstSampleTime := time.Date(2019, time.May, 30, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		samples = append(samples, models.UsageSample{
+			SampleTime: firstSampleTime.Add(time.Duration(i) * 5 * time.Minute).Format(time.RFC3339),
+			CPUUsage:   0.1,
+		})
+	}
+	pod := podUsage{
+		Containers: []containerUsage{
+			{Name: "container-hour", CPURequest: 1, UsageSamples: samples},
+			{Name: "container-few", CPURequest: 1, UsageSamples: []models.UsageSample{samples[0], samples[len(samples)-1]}},
+		},
+	}
+	recommendation := newWorkloadRecommendation(DeploymentType, "default:nginx", []podUsage{pod}, 0.2)
+
+	// an hour of samples doesn't cover a day, two samples are too few
+	assert.Equal(t, 2, len(recommendation.Containers))
+	for _, container := range recommendation.Containers {
+		assert.True(t, container.LowConfidence, container.Name)
+		assert.Equal(t, 0.0, container.MonthlySavings, container.Name)
+		assert.InDelta(t, 0.12, container.RecommendedCPURequest, 1e-12, container.Name)
+	}
+	assert.InDelta(t, 59.0/12, recommendation.Containers[1].SampledHours, 1e-12)
+	assert.Equal(t, 0.0, recommendation.MonthlySavings)
+}
+
+// TestNewContainerRecommendationFloor ...
+func TestNewContainerRecommendationFloor(t *testing.T) {
+	recommendation := newContainerRecommendation(containerUsage{}, []float64{0, 0}, []float64{0, 0}, DefaultMargin)
+	assert.Equal(t, minCPURecommendation, recommendation.RecommendedCPURequest)
+	assert.Equal(t, minCPURecommendation, recommendation.RecommendedCPULimit)
+	assert.Equal(t, minMemoryRecommendation, recommendation.RecommendedMemoryRequest)
+	assert.Equal(t, minMemoryRecommendation, recommendation.RecommendedMemoryLimit)
+}
+
+// TestGetPodUsagePrice ...
+func TestGetPodUsagePrice(t *testing.T) {
+	cpuPrice, memoryPrice := getPodUsagePrice(podUsage{CPUPrice: 0.05, MemoryPrice: 0.02})
+	assert.Equal(t, 0.05, cpuPrice)
+	assert.Equal(t, 0.02, memoryPrice)
+
+	cpuPrice, memoryPrice = getPodUsagePrice(podUsage{})
+	assert.Equal(t, models.DefaultCPUCostInFloat64, cpuPrice)
+	assert.Equal(t, models.DefaultMemCostInFloat64, memoryPrice)
+}
@@ -18,8 +18,6 @@
 package query
 
 import (
-	"strconv"
-
 	"github.com/Sirupsen/logrus"
 )
 
@@ -71,6 +69,8 @@ type Resource struct {
 	Type        string
 	Name        string
 	ChildFilter string
+	CostMode    string
+	Window      Window
 }
 
 // RetrieveResourceHierarchy returns hierarchy for a given resource
@@ -83,35 +83,42 @@ func (r *Resource) RetrieveResourceHierarchy() JSONDataWrapper {
 	return getJSONDataFromQuery(query)
 }
 
-// RetrieveResourceMetrics returns metrics for a given resource
+// RetrieveResourceMetrics returns metrics for a given resource in its window, along with metrics for every step
+// of the window if step is given
 func (r *Resource) RetrieveResourceMetrics() JSONDataWrapper {
 	if r.Name == All {
 		logrus.Errorf("wrong type of query, empty name is given")
 		return JSONDataWrapper{}
 	}
 	query := r.getQueryForResourceMetrics()
-	return getJSONDataFromQuery(query)
+	jsonData := getJSONDataFromQuery(query)
+	if r.Window.Step > 0 {
+		for _, step := range r.Window.split() {
+			resourceInStep := *r
+			resourceInStep.Window = step
+			stepData := getJSONDataFromQuery(resourceInStep.getQueryForResourceMetrics())
+			jsonData.Series = append(jsonData.Series, newSeriesPoint(step, stepData.Data))
+		}
+	}
+	return jsonData
 }
 
 func (r *Resource) getQueryForResourceMetrics() string {
 	switch r.Type {
 	case DeploymentType:
-		return getQueryForDeploymentMetrics(r.Name)
+		return getQueryForDeploymentMetrics(r.Name, r.CostMode, r.Window)
 	case NamespaceType:
-		return getQueryForNamespaceMetrics(r.Name)
+		return getQueryForNamespaceMetrics(r.Name, r.CostMode, r.Window)
 	case NodeType:
-		return getQueryForNodeMetrics(r.Name)
+		return getQueryForNodeMetrics(r.Name, r.CostMode, r.Window)
 	case PVType:
-		return getQueryForPVMetrics(r.Name)
+		return getQueryForPVMetrics(r.Name, r.Window)
 	case PVCType:
-		return getQueryForPVCMetrics(r.Name)
+		return getQueryForPVCMetrics(r.Name, r.Window)
 	case ContainerType:
-		return getQueryForContainerMetrics(r.Name)
+		return getQueryForContainerMetrics(r.Name, r.CostMode, r.Window)
 	case PodType:
-		cpuPriceInFloat64, memoryPriceInFloat64 := getPricePerResourceForPod(r.Name)
-		cpuPrice := strconv.FormatFloat(cpuPriceInFloat64, 'f', 11, 64)
-		memoryPrice := strconv.FormatFloat(memoryPriceInFloat64, 'f', 11, 64)
-		return getQueryForPodMetrics(r.Name, cpuPrice, memoryPrice)
+		return getQueryForPodMetrics(r.Name, r.CostMode, r.Window)
 	}
 	return r.getQueryForPodParentMetrics()
 }
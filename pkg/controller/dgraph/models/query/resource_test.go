@@ -21,26 +21,11 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/vmware/purser/pkg/controller/dgraph/models"
-
 	"github.com/stretchr/testify/assert"
 )
 
 func mockDgraphForResourceQueries(queryType, resourceName, resourceType string) {
 	executeQuery = func(query string, root interface{}) error {
-		if queryType == testPodPrices {
-			newRoot, ok := root.(*podRoot)
-			if !ok {
-				return fmt.Errorf("wrong pod root received")
-			}
-			pod := models.Pod{
-				CPUPrice:    testCPUPrice,
-				MemoryPrice: testMemoryPrice,
-			}
-			newRoot.Pods = []models.Pod{pod}
-			return nil
-		}
-
 		dummyParentWrapper, ok := root.(*ParentWrapper)
 		if !ok {
 			return fmt.Errorf("wrong root received")
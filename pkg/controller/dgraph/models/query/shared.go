@@ -0,0 +1,314 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	sharedcostpolicy_v1 "github.com/vmware/purser/pkg/apis/sharedcostpolicy/v1"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+const namespacePrefix = "namespace-"
+
+// tenant is a namespace or group sharing cost of shared namespaces and groups
+type tenant struct {
+	cpuRequest float64
+	cost       float64
+}
+
+// PopulateSharedCost splits cost of shared namespaces between the other(tenant) namespaces as per the policies.
+// Namespaces of logical cluster metrics or the given namespace(and every step of the window) report their direct
+// cost along with shared cost allocated to them, shared namespaces are marked as shared.
+func PopulateSharedCost(jsonData *JSONDataWrapper, policies []sharedcostpolicy_v1.SharedCostPolicy, costMode string, window Window) {
+	if len(policies) == 0 {
+		return
+	}
+
+	cluster := jsonData
+	if jsonData.Data.Type == NamespaceType {
+		clusterMetrics := RetrieveClusterMetrics(Logical, costMode, window)
+		cluster = &clusterMetrics
+	} else if jsonData.Data.Type != "cluster" {
+		return
+	}
+
+	cpuRequests := getNamespaceCPURequests(cluster.Data.Children, policies, costMode, window)
+	allocateSharedCostToNamespaces(cluster.Data.Children, cpuRequests, policies)
+	for i := range cluster.Series {
+		allocateSharedCostToNamespaces(cluster.Series[i].Data.Children, cpuRequests, policies)
+	}
+
+	if jsonData.Data.Type == NamespaceType {
+		populateNamespaceSharedCost(&jsonData.Data, cluster.Data.Children)
+		for i := range jsonData.Series {
+			if i < len(cluster.Series) {
+				populateNamespaceSharedCost(&jsonData.Series[i].Data, cluster.Series[i].Data.Children)
+			}
+		}
+	}
+}
+
+func populateNamespaceSharedCost(data *ParentWrapper, namespaces []Children) {
+	for _, namespace := range namespaces {
+		if namespace.Name == data.Name {
+			data.DirectCost = namespace.DirectCost
+			data.SharedCost = namespace.SharedCost
+			data.Shared = namespace.Shared
+		}
+	}
+}
+
+// getNamespaceCPURequests returns cpu requested by each namespace, children report requests in request cost mode
+// otherwise they are retrieved if any policy splits cost by cpu request
+func getNamespaceCPURequests(namespaces []Children, policies []sharedcostpolicy_v1.SharedCostPolicy, costMode string, window Window) map[string]float64 {
+	cpuRequests := map[string]float64{}
+	if GetCostMode(costMode) != RequestCostMode {
+		for _, policy := range policies {
+			if policy.Spec.Strategy == sharedcostpolicy_v1.CPURequestSplit {
+				namespaces = RetrieveClusterMetrics(Logical, RequestCostMode, Window{Start: window.Start, End: window.End}).Data.Children
+				break
+			}
+		}
+	}
+	for _, namespace := range namespaces {
+		cpuRequests[namespace.Name] = namespace.CPU
+	}
+	return cpuRequests
+}
+
+func allocateSharedCostToNamespaces(namespaces []Children, cpuRequests map[string]float64, policies []sharedcostpolicy_v1.SharedCostPolicy) {
+	policies = assignSharedTenants(policies)
+	shared := map[string]bool{}
+	for _, policy := range policies {
+		for _, namespace := range policy.Spec.Namespaces {
+			shared[namespacePrefix+namespace] = true
+		}
+	}
+
+	var tenantIndices []int
+	var tenants []tenant
+	for i, namespace := range namespaces {
+		namespaces[i].DirectCost = getTotalCost(namespace)
+		namespaces[i].Shared = shared[namespace.Name]
+		if !shared[namespace.Name] && namespace.Type != IdleType {
+			tenantIndices = append(tenantIndices, i)
+			tenants = append(tenants, tenant{cpuRequest: cpuRequests[namespace.Name], cost: namespaces[i].DirectCost})
+		}
+	}
+
+	for _, policy := range policies {
+		var sharedCost float64
+		for _, namespace := range namespaces {
+			if contains(policy.Spec.Namespaces, strings.TrimPrefix(namespace.Name, namespacePrefix)) {
+				sharedCost += namespace.DirectCost
+			}
+		}
+		for i, share := range splitSharedCost(sharedCost, tenants, policy.Spec.Strategy) {
+			namespaces[tenantIndices[i]].SharedCost += share
+		}
+	}
+}
+
+// groupPod is a pod of groups along with its namespace, cpu request if it is alive and month to date cost
+type groupPod struct {
+	ID        string `json:"uid"`
+	Namespace struct {
+		Name string `json:"name"`
+	} `json:"namespace"`
+	CPU  float64 `json:"cpu"`
+	Cost float64 `json:"cost"`
+}
+
+// AllocateSharedCostToGroups splits cost of shared groups and shared namespaces between the other(tenant) groups
+// as per the policies, month to date cost of groups stays their direct cost. groupsPodsUIDs are uids of pods of each
+// group. Cost of pods already in tenant groups isn't split again and doesn't weigh their share of it, pods in
+// several shared groups or in shared namespaces too are counted once.
+func AllocateSharedCostToGroups(groups []models.Group, groupsPodsUIDs map[string][]string, namespaces []Children, policies []sharedcostpolicy_v1.SharedCostPolicy) error {
+	pods, err := retrieveGroupPods(groupsPodsUIDs)
+	if err != nil {
+		return err
+	}
+	allocateSharedCostToGroups(groups, groupsPodsUIDs, pods, namespaces, policies)
+	return nil
+}
+
+func retrieveGroupPods(groupsPodsUIDs map[string][]string) (map[string]groupPod, error) {
+	pods := map[string]groupPod{}
+	var podsUIDs []string
+	for _, uids := range groupsPodsUIDs {
+		for _, uid := range uids {
+			if _, isPresent := pods[uid]; !isPresent {
+				pods[uid] = groupPod{ID: uid}
+				podsUIDs = append(podsUIDs, uid)
+			}
+		}
+	}
+	if len(podsUIDs) == 0 {
+		return pods, nil
+	}
+
+	type root struct {
+		Pods []groupPod `json:"pods"`
+	}
+	newRoot := root{}
+	err := executeQuery(getQueryForGroupPodsCost(strings.Join(podsUIDs, ", ")), &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range newRoot.Pods {
+		pods[pod.ID] = pod
+	}
+	return pods, nil
+}
+
+func allocateSharedCostToGroups(groups []models.Group, groupsPodsUIDs map[string][]string, pods map[string]groupPod, namespaces []Children, policies []sharedcostpolicy_v1.SharedCostPolicy) {
+	policies = assignSharedTenants(policies)
+	shared := map[string]bool{}
+	sharedNamespaces := map[string]bool{}
+	for _, policy := range policies {
+		for _, group := range policy.Spec.Groups {
+			shared[group] = true
+		}
+		for _, namespace := range policy.Spec.Namespaces {
+			sharedNamespaces[namespacePrefix+namespace] = true
+		}
+	}
+	sharedPods := map[string]bool{}
+	for _, group := range groups {
+		if shared[group.Name] {
+			for _, uid := range groupsPodsUIDs[group.Name] {
+				sharedPods[uid] = true
+			}
+		}
+	}
+
+	var tenantIndices []int
+	var tenants []tenant
+	tenantPods := map[string]bool{}
+	for i, group := range groups {
+		if shared[group.Name] {
+			continue
+		}
+		t := tenant{cpuRequest: group.CPU, cost: group.MtdCost}
+		for _, uid := range groupsPodsUIDs[group.Name] {
+			tenantPods[uid] = true
+			if pod := pods[uid]; sharedPods[uid] || sharedNamespaces[pod.Namespace.Name] {
+				t.cpuRequest -= pod.CPU
+				t.cost -= pod.Cost
+			}
+		}
+		tenantIndices = append(tenantIndices, i)
+		tenants = append(tenants, tenant{cpuRequest: math.Max(t.cpuRequest, 0), cost: math.Max(t.cost, 0)})
+	}
+
+	for _, policy := range policies {
+		var sharedCost float64
+		for _, namespace := range namespaces {
+			if contains(policy.Spec.Namespaces, strings.TrimPrefix(namespace.Name, namespacePrefix)) {
+				sharedCost += getTotalCost(namespace)
+			}
+		}
+		policyPods := map[string]bool{}
+		for _, group := range policy.Spec.Groups {
+			for _, uid := range groupsPodsUIDs[group] {
+				policyPods[uid] = true
+			}
+		}
+		for uid, pod := range pods {
+			inSharedNamespace := contains(policy.Spec.Namespaces, strings.TrimPrefix(pod.Namespace.Name, namespacePrefix))
+			if tenantPods[uid] && inSharedNamespace {
+				sharedCost -= pod.Cost
+			} else if policyPods[uid] && !tenantPods[uid] && !inSharedNamespace {
+				sharedCost += pod.Cost
+			}
+		}
+		for i, share := range splitSharedCost(math.Max(sharedCost, 0), tenants, policy.Spec.Strategy) {
+			groups[tenantIndices[i]].MtdSharedCost += share
+		}
+	}
+}
+
+// assignSharedTenants returns policies(oldest first) in which every shared namespace and group is listed by the oldest
+// policy listing it only, so that its cost isn't split more than once
+func assignSharedTenants(policies []sharedcostpolicy_v1.SharedCostPolicy) []sharedcostpolicy_v1.SharedCostPolicy {
+	sorted := append([]sharedcostpolicy_v1.SharedCostPolicy{}, policies...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	assignedNamespaces := map[string]bool{}
+	assignedGroups := map[string]bool{}
+	for i := range sorted {
+		sorted[i].Spec.Namespaces = getUnassigned(sorted[i].Spec.Namespaces, assignedNamespaces)
+		sorted[i].Spec.Groups = getUnassigned(sorted[i].Spec.Groups, assignedGroups)
+	}
+	return sorted
+}
+
+// getUnassigned returns values which aren't assigned yet and marks them as assigned
+func getUnassigned(values []string, assigned map[string]bool) []string {
+	var unassigned []string
+	for _, value := range values {
+		if !assigned[value] {
+			assigned[value] = true
+			unassigned = append(unassigned, value)
+		}
+	}
+	return unassigned
+}
+
+// splitSharedCost returns share of each tenant in shared cost as per the strategy, cost is split evenly when
+// the strategy is unknown or no tenant has cpu request or cost to split it by
+func splitSharedCost(sharedCost float64, tenants []tenant, strategy string) []float64 {
+	weights := make([]float64, len(tenants))
+	var totalWeight float64
+	for i, t := range tenants {
+		switch strategy {
+		case sharedcostpolicy_v1.CPURequestSplit:
+			weights[i] = t.cpuRequest
+		case sharedcostpolicy_v1.CostSplit:
+			weights[i] = t.cost
+		}
+		totalWeight += weights[i]
+	}
+
+	shares := make([]float64, len(tenants))
+	for i := range tenants {
+		if totalWeight > 0 {
+			shares[i] = sharedCost * weights[i] / totalWeight
+		} else {
+			shares[i] = sharedCost / float64(len(tenants))
+		}
+	}
+	return shares
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
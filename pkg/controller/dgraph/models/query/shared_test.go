@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	sharedcostpolicy_v1 "github.com/vmware/purser/pkg/apis/sharedcostpolicy/v1"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestSharedCostPolicies(strategy string) []sharedcostpolicy_v1.SharedCostPolicy {
+	return []sharedcostpolicy_v1.SharedCostPolicy{{
+		Spec: sharedcostpolicy_v1.SharedCostPolicySpec{
+			Namespaces: []string{"kube-system"},
+			Groups:     []string{"platform"},
+			Strategy:   strategy,
+		},
+	}}
+}
+
+func getTestNamespaces() []Children {
+	return []Children{
+		{Name: "namespace-kube-system", Type: NamespaceType, CPU: 1, CPUCost: 4, MemoryCost: 2},
+		{Name: "namespace-team-a", Type: NamespaceType, CPU: 3, CPUCost: 1},
+		{Name: "namespace-team-b", Type: NamespaceType, CPU: 1, CPUCost: 2, StorageCost: 1},
+		{Name: IdleType, Type: IdleType, CPUCost: 5},
+	}
+}
+
+// TestSplitSharedCost ...
+func TestSplitSharedCost(t *testing.T) {
+	tenants := []tenant{{cpuRequest: 3, cost: 1}, {cpuRequest: 1, cost: 3}}
+	assert.Equal(t, []float64{3, 3}, splitSharedCost(6, tenants, sharedcostpolicy_v1.EvenSplit))
+	assert.Equal(t, []float64{4.5, 1.5}, splitSharedCost(6, tenants, sharedcostpolicy_v1.CPURequestSplit))
+	assert.Equal(t, []float64{1.5, 4.5}, splitSharedCost(6, tenants, sharedcostpolicy_v1.CostSplit))
+	assert.Equal(t, []float64{3, 3}, splitSharedCost(6, tenants, ""))
+	// no tenant has cost, so it is split evenly
+	assert.Equal(t, []float64{3, 3}, splitSharedCost(6, []tenant{{}, {}}, sharedcostpolicy_v1.CostSplit))
+}
+
+// TestAllocateSharedCostToNamespaces ...
+func TestAllocateSharedCostToNamespaces(t *testing.T) {
+	namespaces := getTestNamespaces()
+	cpuRequests := map[string]float64{"namespace-kube-system": 1, "namespace-team-a": 3, "namespace-team-b": 1}
+	allocateSharedCostToNamespaces(namespaces, cpuRequests, getTestSharedCostPolicies(sharedcostpolicy_v1.CPURequestSplit))
+
+	assert.True(t, namespaces[0].Shared)
+	assert.Equal(t, 6.0, namespaces[0].DirectCost)
+	assert.Equal(t, 0.0, namespaces[0].SharedCost)
+	assert.Equal(t, 1.0, namespaces[1].DirectCost)
+	assert.Equal(t, 4.5, namespaces[1].SharedCost)
+	assert.Equal(t, 3.0, namespaces[2].DirectCost)
+	assert.Equal(t, 1.5, namespaces[2].SharedCost)
+	// idle cost isn't a tenant
+	assert.Equal(t, 0.0, namespaces[3].SharedCost)
+}
+
+// TestAllocateSharedCostOfNamespaceInSeveralPolicies ...
+func TestAllocateSharedCostOfNamespaceInSeveralPolicies(t *testing.T) {
+	created := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	newer := sharedcostpolicy_v1.SharedCostPolicy{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "by-cpu", CreationTimestamp: meta_v1.NewTime(created.Add(time.Hour))},
+		Spec: sharedcostpolicy_v1.SharedCostPolicySpec{
+			Namespaces: []string{"kube-system", "team-b"},
+			Groups:     []string{"platform"},
+			Strategy:   sharedcostpolicy_v1.CPURequestSplit,
+		},
+	}
+	older := sharedcostpolicy_v1.SharedCostPolicy{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "even", CreationTimestamp: meta_v1.NewTime(created)},
+		Spec: sharedcostpolicy_v1.SharedCostPolicySpec{
+			Namespaces: []string{"kube-system"},
+			Groups:     []string{"platform"},
+			Strategy:   sharedcostpolicy_v1.EvenSplit,
+		},
+	}
+	policies := []sharedcostpolicy_v1.SharedCostPolicy{newer, older}
+
+	// kube-system and platform group are shared by the older policy only
+	assigned := assignSharedTenants(policies)
+	assert.Equal(t, "even", assigned[0].Name)
+	assert.Equal(t, []string{"kube-system"}, assigned[0].Spec.Namespaces)
+	assert.Equal(t, []string{"platform"}, assigned[0].Spec.Groups)
+	assert.Equal(t, []string{"team-b"}, assigned[1].Spec.Namespaces)
+	assert.Empty(t, assigned[1].Spec.Groups)
+	// policies themselves aren't changed
+	assert.Equal(t, []string{"kube-system", "team-b"}, policies[0].Spec.Namespaces)
+
+	namespaces := getTestNamespaces()
+	cpuRequests := map[string]float64{"namespace-kube-system": 1, "namespace-team-a": 3, "namespace-team-b": 1}
+	allocateSharedCostToNamespaces(namespaces, cpuRequests, policies)
+
+	// cost of kube-system(6) is split evenly once, team-a is the only tenant so it gets cost of team-b(3) too
+	assert.True(t, namespaces[2].Shared)
+	assert.Equal(t, 6.0+3.0, namespaces[1].SharedCost)
+	assert.Equal(t, 0.0, namespaces[2].SharedCost)
+}
+
+func newTestGroupPod(uid, namespace string, cpu, cost float64) groupPod {
+	pod := groupPod{ID: uid, CPU: cpu, Cost: cost}
+	pod.Namespace.Name = namespacePrefix + namespace
+	return pod
+}
+
+// TestAllocateSharedCostToGroups ...
+func TestAllocateSharedCostToGroups(t *testing.T) {
+	groups := []models.Group{
+		{Name: "platform", CPU: 1, MtdCost: 2},
+		{Name: "team-a", CPU: 1, MtdCost: 1},
+		{Name: "team-b", CPU: 3, MtdCost: 3},
+	}
+	groupsPodsUIDs := map[string][]string{"platform": {"0x1"}, "team-a": {"0x2"}, "team-b": {"0x3"}}
+	pods := map[string]groupPod{
+		"0x1": newTestGroupPod("0x1", "monitoring", 1, 2),
+		"0x2": newTestGroupPod("0x2", "team-a", 1, 1),
+		"0x3": newTestGroupPod("0x3", "team-b", 3, 3),
+	}
+	allocateSharedCostToGroups(groups, groupsPodsUIDs, pods, getTestNamespaces(), getTestSharedCostPolicies(sharedcostpolicy_v1.CostSplit))
+
+	// cost of platform group and kube-system namespace is split by cost of the other groups
+	assert.Equal(t, 0.0, groups[0].MtdSharedCost)
+	assert.Equal(t, 2.0, groups[1].MtdSharedCost)
+	assert.Equal(t, 6.0, groups[2].MtdSharedCost)
+	assert.Equal(t, 1.0, groups[1].MtdCost)
+}
+
+// TestAllocateSharedCostToOverlappingGroups ...
+func TestAllocateSharedCostToOverlappingGroups(t *testing.T) {
+	groups := []models.Group{
+		{Name: "platform", CPU: 2, MtdCost: 6},
+		{Name: "team-a", CPU: 2, MtdCost: 2},
+		{Name: "team-b", CPU: 5, MtdCost: 6},
+	}
+	// 0x4 in kube-system is in both tenant groups, 0x2 of platform group is in team-b too
+	groupsPodsUIDs := map[string][]string{
+		"platform": {"0x1", "0x2"},
+		"team-a":   {"0x3", "0x4"},
+		"team-b":   {"0x2", "0x4", "0x5"},
+	}
+	pods := map[string]groupPod{
+		"0x1": newTestGroupPod("0x1", "kube-system", 1, 4),
+		"0x2": newTestGroupPod("0x2", "monitoring", 1, 2),
+		"0x3": newTestGroupPod("0x3", "team-a", 1, 1),
+		"0x4": newTestGroupPod("0x4", "kube-system", 1, 1),
+		"0x5": newTestGroupPod("0x5", "team-b", 2, 3),
+	}
+	allocateSharedCostToGroups(groups, groupsPodsUIDs, pods, getTestNamespaces(), getTestSharedCostPolicies(sharedcostpolicy_v1.CostSplit))
+
+	// kube-system costs 6, 0x1 of platform group is in it and 0x4 is already paid by tenant groups, so 5 is split
+	// by cost of tenant groups without their pods in kube-system or platform group(1 and 3)
+	assert.Equal(t, 0.0, groups[0].MtdSharedCost)
+	assert.Equal(t, 1.25, groups[1].MtdSharedCost)
+	assert.Equal(t, 3.75, groups[2].MtdSharedCost)
+	assert.Equal(t, 6.0, groups[2].MtdCost)
+
+	groups[1].MtdSharedCost, groups[2].MtdSharedCost = 0, 0
+	allocateSharedCostToGroups(groups, groupsPodsUIDs, pods, getTestNamespaces(), getTestSharedCostPolicies(sharedcostpolicy_v1.CPURequestSplit))
+	assert.Equal(t, 1.25, groups[1].MtdSharedCost)
+	assert.Equal(t, 3.75, groups[2].MtdSharedCost)
+}
+
+// TestRetrieveGroupPods ...
+func TestRetrieveGroupPods(t *testing.T) {
+	var gotQuery string
+	executeQuery = func(query string, root interface{}) error {
+		gotQuery = query
+		return json.Unmarshal([]byte(`{"pods": [{"uid": "0x1", "namespace": {"name": "namespace-kube-system"}, "cpu": 0.5, "cost": 2}]}`), root)
+	}
+	defer func() { executeQuery = dgraph.ExecuteQuery }()
+
+	pods, err := retrieveGroupPods(map[string][]string{"platform": {"0x1"}, "team-a": {"0x1", "0x2"}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(gotQuery, "0x1"))
+	assert.Contains(t, gotQuery, "0x2")
+	assert.Equal(t, newTestGroupPod("0x1", "kube-system", 0.5, 2), pods["0x1"])
+	assert.Equal(t, "0x2", pods["0x2"].ID)
+
+	executeQuery = func(query string, root interface{}) error {
+		return fmt.Errorf("dgraph is down")
+	}
+	_, err = retrieveGroupPods(map[string][]string{"platform": {"0x1"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+// Constants used in query parameters of cost trends, kind is one of namespace, deployment, statefulset, daemonset,
+// job, node or group
+const (
+	Kind      = "kind"
+	GroupType = "group"
+)
+
+// ErrTrendWindowTooLong is returned for trends over a window longer than the retention of cost snapshots
+var ErrTrendWindowTooLong = errors.New("window of trends can't be longer than a year")
+
+// TrendPoint is allocation and cost of a resource in a step of the window. Allocation is the average of hourly
+// snapshots in the step, cost is their sum.
+type TrendPoint struct {
+	Start       string  `json:"start"`
+	End         string  `json:"end"`
+	CPU         float64 `json:"cpu"`
+	Memory      float64 `json:"memory"`
+	Storage     float64 `json:"storage"`
+	CPUCost     float64 `json:"cpuCost"`
+	MemoryCost  float64 `json:"memoryCost"`
+	StorageCost float64 `json:"storageCost"`
+	GPU         float64 `json:"gpu,omitempty"`
+	GPUCost     float64 `json:"gpuCost,omitempty"`
+	Cost        float64 `json:"cost"`
+	snapshots   int
+
+	ExtendedResourceCost float64 `json:"extendedResourceCost,omitempty"`
+	ServiceCost          float64 `json:"serviceCost,omitempty"`
+}
+
+// CostTrend is cost of a resource over time computed from its hourly snapshots
+type CostTrend struct {
+	Kind   string       `json:"kind"`
+	Name   string       `json:"name"`
+	Points []TrendPoint `json:"points"`
+}
+
+type snapshotResource struct {
+	Xid string `json:"xid"`
+	Children
+}
+
+type workloadsRoot struct {
+	Deployments  []snapshotResource `json:"deployments"`
+	Statefulsets []snapshotResource `json:"statefulsets"`
+	Daemonsets   []snapshotResource `json:"daemonsets"`
+	Jobs         []snapshotResource `json:"jobs"`
+}
+
+type costSnapshotsRoot struct {
+	Snapshots []models.CostSnapshot `json:"snapshots"`
+}
+
+// RetrieveCostSnapshots returns snapshots of namespaces, workloads and nodes for the hour starting at hourStart
+func RetrieveCostSnapshots(hourStart time.Time) []models.CostSnapshot {
+	window := Window{Start: hourStart, End: hourStart.Add(time.Hour)}
+	var snapshots []models.CostSnapshot
+	for _, namespace := range RetrieveClusterMetrics(Logical, RequestCostMode, window).Data.Children {
+		snapshots = append(snapshots, newCostSnapshot(NamespaceType, namespace.Name, hourStart, namespace))
+	}
+	for _, node := range RetrieveClusterMetrics(Physical, RequestCostMode, window).Data.Children {
+		if node.Type == NodeType {
+			snapshots = append(snapshots, newCostSnapshot(NodeType, node.Name, hourStart, node))
+		}
+	}
+
+	root := workloadsRoot{}
+	err := executeQuery(getQueryForWorkloadsMetrics(window), &root)
+	if err != nil {
+		logrus.Errorf("unable to retrieve metrics of workloads for cost snapshots, err: %v", err)
+		return snapshots
+	}
+	for _, workloads := range [][]snapshotResource{root.Deployments, root.Statefulsets, root.Daemonsets, root.Jobs} {
+		for _, workload := range workloads {
+			snapshots = append(snapshots, newCostSnapshot(workload.Type, workload.Xid, hourStart, workload.Children))
+		}
+	}
+	return snapshots
+}
+
+// NewGroupCostSnapshot returns snapshot of the group from its cost in the hour starting at hourStart
+func NewGroupCostSnapshot(groupCost GroupCost, hourStart time.Time) models.CostSnapshot {
+	return newCostSnapshot(GroupType, groupCost.Name, hourStart, Children{
+		CPU:         groupCost.CPU,
+		Memory:      groupCost.Memory,
+		Storage:     groupCost.Storage,
+		CPUCost:     groupCost.CPUCost,
+		MemoryCost:  groupCost.MemoryCost,
+		StorageCost: groupCost.StorageCost,
+		GPU:         groupCost.GPU,
+		GPUCost:     groupCost.GPUCost,
+
+		ExtendedResourceCost: groupCost.ExtendedResourceCost,
+		ServiceCost:          groupCost.ServiceCost,
+	})
+}
+
+func newCostSnapshot(kind, name string, hourStart time.Time, metrics Children) models.CostSnapshot {
+	snapshot := models.NewCostSnapshot(kind, name, hourStart)
+	snapshot.CPU = metrics.CPU
+	snapshot.Memory = metrics.Memory
+	snapshot.Storage = metrics.Storage
+	snapshot.CPUCost = metrics.CPUCost
+	snapshot.MemoryCost = metrics.MemoryCost
+	snapshot.StorageCost = metrics.StorageCost
+	snapshot.GPU = metrics.GPU
+	snapshot.GPUCost = metrics.GPUCost
+	snapshot.ExtendedResourceCost = metrics.ExtendedResourceCost
+	snapshot.ServiceCost = metrics.ServiceCost
+	return snapshot
+}
+
+// RetrieveCostTrends returns trends of resources of the kind(or only the named one) in the window. Trends have a
+// point for every step of the window, or for every hour if step isn't given.
+func RetrieveCostTrends(kind, name string, window Window) ([]CostTrend, error) {
+	if window.getEnd().Sub(window.getStart()) > utils.CostSnapshotRetention {
+		return nil, ErrTrendWindowTooLong
+	}
+
+	root := costSnapshotsRoot{}
+	err := executeQuery(getQueryForCostSnapshots(kind, name, window), &root)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateCostSnapshots(kind, root.Snapshots, window), nil
+}
+
+// aggregateCostSnapshots groups snapshots by resource and adds them to the point of the step they were taken in
+func aggregateCostSnapshots(kind string, snapshots []models.CostSnapshot, window Window) []CostTrend {
+	points := map[string]map[time.Time]*TrendPoint{}
+	for _, snapshot := range snapshots {
+		snapshotTime, err := time.Parse(time.RFC3339, snapshot.SnapshotTime)
+		if err != nil {
+			logrus.Debugf("invalid time of cost snapshot: %s, err: %v", snapshot.SnapshotTime, err)
+			continue
+		}
+		pointStart, pointEnd := window.getStepOf(snapshotTime)
+		if _, isPresent := points[snapshot.SnapshotName]; !isPresent {
+			points[snapshot.SnapshotName] = map[time.Time]*TrendPoint{}
+		}
+		point, isPresent := points[snapshot.SnapshotName][pointStart]
+		if !isPresent {
+			point = &TrendPoint{
+				Start: utils.ConverTimeToRFC3339(pointStart),
+				End:   utils.ConverTimeToRFC3339(pointEnd),
+			}
+			points[snapshot.SnapshotName][pointStart] = point
+		}
+		addSnapshotToPoint(point, snapshot)
+	}
+
+	trends := []CostTrend{}
+	for name, namePoints := range points {
+		trend := CostTrend{Kind: kind, Name: name}
+		for _, point := range namePoints {
+			trend.Points = append(trend.Points, *point)
+		}
+		sort.Slice(trend.Points, func(i, j int) bool {
+			return trend.Points[i].Start < trend.Points[j].Start
+		})
+		trends = append(trends, trend)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		return trends[i].Name < trends[j].Name
+	})
+	return trends
+}
+
+// addSnapshotToPoint keeps allocation of the point as the average of its snapshots and cost as their sum
+func addSnapshotToPoint(point *TrendPoint, snapshot models.CostSnapshot) {
+	count := point.snapshots
+	point.CPU = getRunningAverage(point.CPU, snapshot.CPU, count)
+	point.Memory = getRunningAverage(point.Memory, snapshot.Memory, count)
+	point.Storage = getRunningAverage(point.Storage, snapshot.Storage, count)
+	point.GPU = getRunningAverage(point.GPU, snapshot.GPU, count)
+	point.CPUCost += snapshot.CPUCost
+	point.MemoryCost += snapshot.MemoryCost
+	point.StorageCost += snapshot.StorageCost
+	point.GPUCost += snapshot.GPUCost
+	point.ExtendedResourceCost += snapshot.ExtendedResourceCost
+	point.ServiceCost += snapshot.ServiceCost
+	point.Cost += snapshot.Cost
+	point.snapshots++
+}
+
+func getRunningAverage(average, value float64, count int) float64 {
+	return (average*float64(count) + value) / float64(count+1)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+func mockDgraphForSnapshotQueries() {
+	executeQuery = func(query string, root interface{}) error {
+		switch dummyRoot := root.(type) {
+		case *ParentWrapper:
+			dummyRoot.Children = []Children{
+				{Name: "default", Type: NamespaceType, CPU: 1, CPUCost: 0.5},
+				{Name: "node-1", Type: NodeType, CPU: 4, CPUCost: 2},
+				{Name: "pv-1", Type: PVType, Storage: 10, StorageCost: 0.1},
+			}
+			return nil
+		case *workloadsRoot:
+			dummyRoot.Deployments = []snapshotResource{{Xid: "default:web", Children: Children{Name: "web", Type: DeploymentType, CPU: 0.5, CPUCost: 0.25}}}
+			dummyRoot.Jobs = []snapshotResource{{Xid: "default:backup", Children: Children{Name: "backup", Type: JobType, Memory: 2, MemoryCost: 0.1}}}
+			return nil
+		case *costSnapshotsRoot:
+			dummyRoot.Snapshots = []models.CostSnapshot{
+				{SnapshotName: "default", SnapshotTime: "2019-06-04T10:00:00Z", CPU: 1, CPUCost: 0.5, Cost: 0.5},
+				{SnapshotName: "default", SnapshotTime: "2019-06-04T11:00:00Z", CPU: 3, CPUCost: 1.5, Cost: 1.5},
+			}
+			return nil
+		}
+		return fmt.Errorf("wrong root received")
+	}
+}
+
+// TestRetrieveCostSnapshots ...
+func TestRetrieveCostSnapshots(t *testing.T) {
+	mockDgraphForSnapshotQueries()
+	hourStart := time.Date(2019, 6, 4, 10, 0, 0, 0, time.UTC)
+	got := RetrieveCostSnapshots(hourStart)
+
+	var names []string
+	for _, snapshot := range got {
+		assert.Equal(t, "2019-06-04T10:00:00Z", snapshot.SnapshotTime)
+		names = append(names, snapshot.SnapshotKind+"/"+snapshot.SnapshotName)
+	}
+	assert.Contains(t, names, "namespace/default")
+	assert.Contains(t, names, "node/node-1")
+	assert.Contains(t, names, "deployment/default:web")
+	assert.Contains(t, names, "job/default:backup")
+	assert.NotContains(t, names, "pv/pv-1")
+}
+
+// TestNewGroupCostSnapshot ...
+func TestNewGroupCostSnapshot(t *testing.T) {
+	hourStart := time.Date(2019, 6, 4, 10, 0, 0, 0, time.UTC)
+	got := NewGroupCostSnapshot(GroupCost{Name: "team-a", CPU: 2, CPUCost: 1, MemoryCost: 0.5}, hourStart)
+	assert.Equal(t, "snapshot-group-team-a-2019-06-04T10:00:00Z", got.Xid)
+	assert.Equal(t, 2.0, got.CPU)
+	assert.Equal(t, 1.0, got.CPUCost)
+	assert.Equal(t, 0.5, got.MemoryCost)
+}
+
+// TestRetrieveCostTrends ...
+func TestRetrieveCostTrends(t *testing.T) {
+	mockDgraphForSnapshotQueries()
+	start := time.Date(2019, 6, 4, 0, 0, 0, 0, time.UTC)
+	window := Window{Start: start, End: start.Add(24 * time.Hour), Step: 24 * time.Hour}
+	got, err := RetrieveCostTrends(NamespaceType, "default", window)
+	assert.NoError(t, err)
+	expected := []CostTrend{{
+		Kind: NamespaceType,
+		Name: "default",
+		Points: []TrendPoint{{
+			Start:     "2019-06-04T00:00:00Z",
+			End:       "2019-06-05T00:00:00Z",
+			CPU:       2,
+			CPUCost:   2,
+			Cost:      2,
+			snapshots: 2,
+		}},
+	}}
+	assert.Equal(t, expected, got)
+}
+
+// TestRetrieveCostTrendsHourly ...
+func TestRetrieveCostTrendsHourly(t *testing.T) {
+	mockDgraphForSnapshotQueries()
+	start := time.Date(2019, 6, 4, 0, 0, 0, 0, time.UTC)
+	got, err := RetrieveCostTrends(NamespaceType, "default", Window{Start: start, End: start.Add(24 * time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+	assert.Len(t, got[0].Points, 2)
+	assert.Equal(t, "2019-06-04T10:00:00Z", got[0].Points[0].Start)
+	assert.Equal(t, "2019-06-04T11:00:00Z", got[0].Points[0].End)
+	assert.Equal(t, 3.0, got[0].Points[1].CPU)
+}
+
+// TestRetrieveCostTrendsWindowTooLong ...
+func TestRetrieveCostTrendsWindowTooLong(t *testing.T) {
+	mockDgraphForSnapshotQueries()
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := RetrieveCostTrends(NamespaceType, "", Window{Start: start, End: start.Add(400 * 24 * time.Hour)})
+	assert.Equal(t, ErrTrendWindowTooLong, err)
+}
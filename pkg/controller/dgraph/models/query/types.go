@@ -26,8 +26,25 @@ const (
 	Physical = "physical"
 	Logical  = "logical"
 	False    = "false"
+	CostMode = "costMode"
 )
 
+// Cost modes, resources of pods are costed by their requests, their average usage or the larger of both
+const (
+	RequestCostMode = "request"
+	UsageCostMode   = "usage"
+	MaxCostMode     = "max"
+)
+
+// GetCostMode returns the cost mode if it is known otherwise request cost mode
+func GetCostMode(costMode string) string {
+	switch costMode {
+	case UsageCostMode, MaxCostMode:
+		return costMode
+	}
+	return RequestCostMode
+}
+
 // Children structure
 type Children struct {
 	Name        string  `json:"name,omitempty"`
@@ -38,6 +55,20 @@ type Children struct {
 	CPUCost     float64 `json:"cpuCost,omitempty"`
 	MemoryCost  float64 `json:"memoryCost,omitempty"`
 	StorageCost float64 `json:"storageCost,omitempty"`
+	GPU         float64 `json:"gpu,omitempty"`
+	GPUCost     float64 `json:"gpuCost,omitempty"`
+	DirectCost  float64 `json:"directCost,omitempty"`
+	SharedCost  float64 `json:"sharedCost,omitempty"`
+	Shared      bool    `json:"shared,omitempty"`
+
+	ExtendedResourceCost float64 `json:"extendedResourceCost,omitempty"`
+	ServiceCost          float64 `json:"serviceCost,omitempty"`
+}
+
+// getTotalCost returns cost of the child over all of its resources
+func getTotalCost(child Children) float64 {
+	return child.CPUCost + child.MemoryCost + child.StorageCost + child.GPUCost + child.ExtendedResourceCost +
+		child.ServiceCost
 }
 
 // ParentWrapper structure
@@ -52,15 +83,29 @@ type ParentWrapper struct {
 	CPUCost          float64         `json:"cpuCost,omitempty"`
 	MemoryCost       float64         `json:"memoryCost,omitempty"`
 	StorageCost      float64         `json:"storageCost,omitempty"`
+	GPU              float64         `json:"gpu,omitempty"`
+	GPUCost          float64         `json:"gpuCost,omitempty"`
 	CPUAllocated     float64         `json:"cpuAllocated,omitempty"`
 	MemoryAllocated  float64         `json:"memoryAllocated,omitempty"`
 	StorageAllocated float64         `json:"storageAllocated,omitempty"`
+	GPUAllocated     float64         `json:"gpuAllocated,omitempty"`
 	CPUCapacity      float64         `json:"cpuCapacity,omitempty"`
 	MemoryCapacity   float64         `json:"memoryCapacity,omitempty"`
 	StorageCapacity  float64         `json:"storageCapacity,omitempty"`
+	GPUCapacity      float64         `json:"gpuCapacity,omitempty"`
+	PricingTerm      string          `json:"pricingTerm,omitempty"`
+	IdleCPUCost      float64         `json:"idleCPUCost,omitempty"`
+	IdleMemoryCost   float64         `json:"idleMemoryCost,omitempty"`
+	DirectCost       float64         `json:"directCost,omitempty"`
+	SharedCost       float64         `json:"sharedCost,omitempty"`
+	Shared           bool            `json:"shared,omitempty"`
+
+	ExtendedResourceCost float64 `json:"extendedResourceCost,omitempty"`
+	ServiceCost          float64 `json:"serviceCost,omitempty"`
 }
 
 // JSONDataWrapper structure
 type JSONDataWrapper struct {
-	Data ParentWrapper `json:"data,omitempty"`
+	Data   ParentWrapper `json:"data,omitempty"`
+	Series []SeriesPoint `json:"series,omitempty"`
 }
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+// Query parameters of time windows, start and end are in RFC3339 format and step is a duration(ex: 6h, 1d)
+const (
+	Start = "start"
+	End   = "end"
+	Step  = "step"
+
+	maxWindowSteps = 1000
+)
+
+// Window is the time range in which metrics and costs are computed, start and end time of every resource are
+// clipped to the window. Zero Start is the start of current month and zero End is now. Metrics are also computed
+// for every step of the window when Step is given.
+type Window struct {
+	Start time.Time
+	End   time.Time
+	Step  time.Duration
+}
+
+// SeriesPoint is metrics computed for a step of a window
+type SeriesPoint struct {
+	Start string        `json:"start"`
+	End   string        `json:"end"`
+	Data  ParentWrapper `json:"data"`
+}
+
+// ParseWindow returns window from start, end and step query parameters, empty parameters keep the defaults
+func ParseWindow(start, end, step string) (Window, error) {
+	window := Window{}
+	var err error
+	if start != "" {
+		window.Start, err = time.Parse(time.RFC3339, start)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid start: %v", err)
+		}
+	}
+	if end != "" {
+		window.End, err = time.Parse(time.RFC3339, end)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid end: %v", err)
+		}
+	}
+	if !window.getEnd().After(window.getStart()) {
+		return Window{}, fmt.Errorf("end of window must be after its start")
+	}
+	if step != "" {
+		window.Step, err = parseStep(step)
+		if err != nil {
+			return Window{}, fmt.Errorf("invalid step: %v", err)
+		}
+		if window.Step <= 0 {
+			return Window{}, fmt.Errorf("step must be positive")
+		}
+		if window.getEnd().Sub(window.getStart())/window.Step > maxWindowSteps {
+			return Window{}, fmt.Errorf("window can't have more than %d steps", maxWindowSteps)
+		}
+	}
+	return window, nil
+}
+
+// parseStep parses duration, days(ex: 1d) are supported along with units of time.ParseDuration
+func parseStep(step string) (time.Duration, error) {
+	if strings.HasSuffix(step, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(step, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(step)
+}
+
+func (w Window) getStart() time.Time {
+	if w.Start.IsZero() {
+		return utils.GetCurrentMonthStartTime()
+	}
+	return w.Start
+}
+
+func (w Window) getEnd() time.Time {
+	if w.End.IsZero() {
+		return time.Now()
+	}
+	return w.End
+}
+
+// isMonthToDate returns true if start and end of the window aren't set
+func (w Window) isMonthToDate() bool {
+	return w.Start.IsZero() && w.End.IsZero()
+}
+
+// split returns steps of the window, the last step ends with the window
+func (w Window) split() []Window {
+	var steps []Window
+	end := w.getEnd()
+	for start := w.getStart(); start.Before(end); start = start.Add(w.Step) {
+		stepEnd := start.Add(w.Step)
+		if stepEnd.After(end) {
+			stepEnd = end
+		}
+		steps = append(steps, Window{Start: start, End: stepEnd})
+	}
+	return steps
+}
+
+// getStepOf returns start and end of the step of the window containing t, steps are an hour long if step isn't given
+func (w Window) getStepOf(t time.Time) (time.Time, time.Time) {
+	step := w.Step
+	if step <= 0 {
+		step = time.Hour
+	}
+	start := w.getStart()
+	stepStart := start.Add(t.Sub(start) / step * step)
+	stepEnd := stepStart.Add(step)
+	if end := w.getEnd(); stepEnd.After(end) {
+		stepEnd = end
+	}
+	return stepStart, stepEnd
+}
+
+// toTimeWindow returns seconds since start and end of the window, resource's hours in the window are held by
+// durationInHours<suffix> variable
+func (w Window) toTimeWindow(suffix string) timeWindow {
+	secondsSinceStart := secondsFromFirstOfCurrentMonth()
+	if !w.Start.IsZero() {
+		secondsSinceStart = fmt.Sprintf("%f", utils.GetSecondsSince(w.Start))
+	}
+	secondsSinceEnd := "0.0"
+	if !w.End.IsZero() && w.End.Before(time.Now()) {
+		secondsSinceEnd = fmt.Sprintf("%f", utils.GetSecondsSince(w.End))
+	}
+	return timeWindow{
+		suffix:            suffix,
+		secondsSinceStart: secondsSinceStart,
+		secondsSinceEnd:   secondsSinceEnd,
+		durationInHours:   "durationInHours" + suffix,
+	}
+}
+
+// getSampleFilter returns filter for usage samples taken during the window
+func (w Window) getSampleFilter() string {
+	return `ge(sampleTime, "` + utils.ConverTimeToRFC3339(w.getStart()) + `") AND lt(sampleTime, "` +
+		utils.ConverTimeToRFC3339(w.getEnd()) + `")`
+}
+
+// getLiveFilter returns filter for resources alive during the window, live resources if the window is month to date
+func (w Window) getLiveFilter() string {
+	if w.isMonthToDate() {
+		return `(NOT has(endTime))`
+	}
+	return `(NOT has(endTime) OR ge(endTime, "` + utils.ConverTimeToRFC3339(w.getStart()) + `")) AND le(startTime, "` +
+		utils.ConverTimeToRFC3339(w.getEnd()) + `")`
+}
+
+func newSeriesPoint(step Window, data ParentWrapper) SeriesPoint {
+	return SeriesPoint{
+		Start: utils.ConverTimeToRFC3339(step.Start),
+		End:   utils.ConverTimeToRFC3339(step.End),
+		Data:  data,
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseWindow ...
+func TestParseWindow(t *testing.T) {
+	got, err := ParseWindow("", "", "")
+	assert.NoError(t, err)
+	assert.True(t, got.isMonthToDate())
+
+	got, err = ParseWindow("2018-10-01T00:00:00Z", "2018-10-08T00:00:00Z", "1d")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC), got.Start)
+	assert.Equal(t, time.Date(2018, 10, 8, 0, 0, 0, 0, time.UTC), got.End)
+	assert.Equal(t, 24*time.Hour, got.Step)
+
+	got, err = ParseWindow("2018-10-01T00:00:00Z", "2018-10-02T00:00:00Z", "6h")
+	assert.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, got.Step)
+}
+
+// TestParseWindowInvalid ...
+func TestParseWindowInvalid(t *testing.T) {
+	testCases := []struct {
+		start, end, step string
+	}{
+		{start: "yesterday"},
+		{start: "2018-10-01T00:00:00Z", end: "today"},
+		{start: "2018-10-02T00:00:00Z", end: "2018-10-01T00:00:00Z"},
+		{start: "2018-10-01T00:00:00Z", end: "2018-10-02T00:00:00Z", step: "xd"},
+		{start: "2018-10-01T00:00:00Z", end: "2018-10-02T00:00:00Z", step: "-1h"},
+		{start: "2018-10-01T00:00:00Z", end: "2018-10-02T00:00:00Z", step: "1s"},
+	}
+	for _, testCase := range testCases {
+		_, err := ParseWindow(testCase.start, testCase.end, testCase.step)
+		assert.Error(t, err, "start: %s, end: %s, step: %s", testCase.start, testCase.end, testCase.step)
+	}
+}
+
+// TestWindowSplit ...
+func TestWindowSplit(t *testing.T) {
+	start := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+	window := Window{Start: start, End: start.Add(10 * time.Hour), Step: 4 * time.Hour}
+	got := window.split()
+	expected := []Window{
+		{Start: start, End: start.Add(4 * time.Hour)},
+		{Start: start.Add(4 * time.Hour), End: start.Add(8 * time.Hour)},
+		{Start: start.Add(8 * time.Hour), End: start.Add(10 * time.Hour)},
+	}
+	assert.Equal(t, expected, got)
+}
+
+// TestWindowToTimeWindow ...
+func TestWindowToTimeWindow(t *testing.T) {
+	got := Window{}.toTimeWindow("Pod")
+	assert.Equal(t, testSecondsSinceMonthStart, got.secondsSinceStart)
+	assert.Equal(t, "0.0", got.secondsSinceEnd)
+	assert.Equal(t, "durationInHoursPod", got.durationInHours)
+
+	now := time.Now()
+	got = Window{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}.toTimeWindow("Pod")
+	assert.NotEqual(t, testSecondsSinceMonthStart, got.secondsSinceStart)
+	assert.NotEqual(t, "0.0", got.secondsSinceEnd)
+
+	// end of window in future is clipped to now
+	got = Window{Start: now.Add(-2 * time.Hour), End: now.Add(time.Hour)}.toTimeWindow("Pod")
+	assert.Equal(t, "0.0", got.secondsSinceEnd)
+}
+
+// TestWindowGetLiveFilter ...
+func TestWindowGetLiveFilter(t *testing.T) {
+	assert.Equal(t, "(NOT has(endTime))", Window{}.getLiveFilter())
+
+	start := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+	got := Window{Start: start, End: start.Add(24 * time.Hour)}.getLiveFilter()
+	expected := `(NOT has(endTime) OR ge(endTime, "2018-10-01T00:00:00Z")) AND le(startTime, "2018-10-02T00:00:00Z")`
+	assert.Equal(t, expected, got)
+}
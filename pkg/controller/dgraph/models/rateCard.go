@@ -18,32 +18,73 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/vmware/purser/pkg/controller/dgraph"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // RateCard constants
 const (
-	IsRateCard     = "isRateCard"
-	IsNodePrice    = "isNodePrice"
-	IsStoragePrice = "isStoragePrice"
-	RateCardXID    = "purser-rateCard"
+	IsRateCard        = "isRateCard"
+	IsRateCardHistory = "isRateCardHistory"
+	IsNodePrice       = "isNodePrice"
+	IsStoragePrice    = "isStoragePrice"
+	RateCardXID       = "purser-rateCard"
+
+	// DefaultNodePriceXID is XID of the price used for nodes matching no other price(ex: on-prem rate card)
+	DefaultNodePriceXID = "purser-default-nodePrice"
+
+	// Usage types of storage prices which are looked up by storage class name or provisioner instead of volume type
+	StorageClassUsageType = "storageClass"
+	ProvisionerUsageType  = "provisioner"
+
+	customMachine               = "custom"
+	defaultCustomMachineFamily  = "n1"
+	nodeCapacityPriceSplitRatio = 0.5
 )
 
 // RateCard structure
 type RateCard struct {
 	dgraph.ID
-	IsRateCard    bool            `json:"isRateCard,omitempty"`
-	CloudProvider string          `json:"cloudProvider,omitempty"`
-	Region        string          `json:"region,omitempty"`
-	NodePrices    []*NodePrice    `json:"nodePrices,omitempty"`
-	StoragePrices []*StoragePrice `json:"storagePrices,omitempty"`
+	IsRateCard        bool            `json:"isRateCard,omitempty"`
+	IsRateCardHistory bool            `json:"isRateCardHistory,omitempty"`
+	CloudProvider     string          `json:"cloudProvider,omitempty"`
+	Region            string          `json:"region,omitempty"`
+	Zone              string          `json:"zone,omitempty"`
+	LocationSource    string          `json:"locationSource,omitempty"`
+	Version           string          `json:"version,omitempty"`
+	EffectiveFrom     string          `json:"effectiveFrom,omitempty"`
+	NodePrices        []*NodePrice    `json:"nodePrices,omitempty"`
+	StoragePrices     []*StoragePrice `json:"storagePrices,omitempty"`
+
+	ExtendedResourcePrices []*ExtendedResourcePrice `json:"extendedResourcePrices,omitempty"`
+	NetworkPrices          []*NetworkPrice          `json:"networkPrices,omitempty"`
+
+	// Prices of a version in the history of the rate card(json of its node, storage, extended resource and network
+	// prices), PricesDigest tells whether prices of a later rate card changed
+	Prices       string `json:"prices,omitempty"`
+	PricesDigest string `json:"pricesDigest,omitempty"`
+}
+
+// rateCardPrices are prices of a version of the rate card, kept in its history as they are overwritten in place
+type rateCardPrices struct {
+	NodePrices             []NodePrice             `json:"nodePrices,omitempty"`
+	StoragePrices          []StoragePrice          `json:"storagePrices,omitempty"`
+	ExtendedResourcePrices []ExtendedResourcePrice `json:"extendedResourcePrices,omitempty"`
+	NetworkPrices          []NetworkPrice          `json:"networkPrices,omitempty"`
 }
 
 // NodePrice structure
-// Unit of Node Price should be USD($)-(per Hour)
+// Unit of Node Price should be USD($)-(per Hour), PriceSplit tells how price is split to PricePerCPU, PricePerMemory
+// and PricePerGPU. CPUCapacity(vCPUs) and MemoryCapacity(GB) are set when the price list tells the shape of the
+// instance type.
 type NodePrice struct {
 	dgraph.ID
 	IsNodePrice     bool    `json:"isNodePrice,omitempty"`
@@ -53,36 +94,179 @@ type NodePrice struct {
 	Price           float64 `json:"price,omitempty"`
 	PricePerCPU     float64 `json:"cpuPrice,omitempty"`
 	PricePerMemory  float64 `json:"memoryPrice,omitempty"`
+	PricePerGPU     float64 `json:"gpuPrice,omitempty"`
+	GPUCount        float64 `json:"gpuCount,omitempty"`
+	LabelSelector   string  `json:"labelSelector,omitempty"`
+	Priority        int     `json:"priority,omitempty"`
+	ReservedPrice   float64 `json:"reservedPrice,omitempty"`
+	SpotPrice       float64 `json:"spotPrice,omitempty"`
+	PriceSplit      string  `json:"priceSplit,omitempty"`
+	CPUCapacity     float64 `json:"cpuCapacity,omitempty"`
+	MemoryCapacity  float64 `json:"memoryCapacity,omitempty"`
 }
 
 // StoragePrice structure
-// Unit of Storage Price should be USD($)-(per GB)-(per Hour)
+// Unit of Storage Price should be USD($)-(per GB)-(per Hour), unit of IOPSPrice is USD($)-(per IOPS)-(per Hour).
+// VolumeType is the volume type of storage class parameters(ex: gp2, pd-ssd), storage class name or provisioner
+// depending on UsageType. ParameterSelector selects storage classes of a provisioner by their parameters.
 type StoragePrice struct {
 	dgraph.ID
-	IsStoragePrice bool    `json:"isStoragePrice,omitempty"`
-	VolumeType     string  `json:"volumeType,omitempty"`
-	UsageType      string  `json:"usageType,omitempty"`
-	Price          float64 `json:"price,omitempty"`
+	IsStoragePrice    bool    `json:"isStoragePrice,omitempty"`
+	VolumeType        string  `json:"volumeType,omitempty"`
+	UsageType         string  `json:"usageType,omitempty"`
+	Price             float64 `json:"price,omitempty"`
+	IOPSPrice         float64 `json:"iopsPrice,omitempty"`
+	ParameterSelector string  `json:"parameterSelector,omitempty"`
+	Priority          int     `json:"priority,omitempty"`
 }
 
-// StoreRateCard given a cloudProvider and region it gets rate card and stores(create/update) in dgraph
+// StoreRateCard stores(create/update) rate card along with its node, storage and network prices in dgraph,
+// a version of the rate card effective from now is kept in its history when its location or prices changed
 func StoreRateCard(rateCard *RateCard) {
 	logrus.Debugf("IsRateCardNil: %v", rateCard == nil)
 	if rateCard != nil {
+		rateCard.Prices, rateCard.PricesDigest = getRateCardPrices(rateCard)
+		latest, err := retrieveLatestRateCardHistory()
+		if err != nil {
+			logrus.Errorf("unable to retrieve latest version of rate card history: %v", err)
+		}
+		isChanged := latest == nil || !isSameRateCardVersion(*latest, *rateCard)
+		if rateCard.EffectiveFrom == "" {
+			rateCard.EffectiveFrom = time.Now().UTC().Format(time.RFC3339)
+			if !isChanged {
+				rateCard.EffectiveFrom = latest.EffectiveFrom
+			}
+		}
+		prices := rateCard.Prices
+		// prices are only kept in the history
+		rateCard.Prices = ""
+
+		rateCard.NodePrices = StoreNodePrices(rateCard.NodePrices)
+		rateCard.StoragePrices = StoreStoragePrices(rateCard.StoragePrices)
+		rateCard.ExtendedResourcePrices = StoreExtendedResourcePrices(rateCard.ExtendedResourcePrices)
+		rateCard.NetworkPrices = StoreNetworkPrices(rateCard.NetworkPrices)
 		uid := dgraph.GetUID(RateCardXID, IsRateCard)
 		if uid != "" {
 			rateCard.ID = dgraph.ID{UID: uid, Xid: RateCardXID}
 		}
 		logrus.Debugf("RateCard: (%v)", rateCard)
-		_, err := dgraph.MutateNode(rateCard, dgraph.CREATE)
+		_, err = dgraph.MutateNode(rateCard, dgraph.CREATE)
 		if err != nil {
 			logrus.Errorf("Unable to store rateCard reason: %v", err)
 			return
 		}
 		logrus.Infof("Successfully stored/updated rateCard")
+		if !isChanged {
+			logrus.Infof("Prices of rateCard are unchanged since: %s", rateCard.EffectiveFrom)
+			return
+		}
+		storeRateCardHistory(rateCard, prices)
 	}
 }
 
+// storeRateCardHistory stores version of the rate card along with its prices which aren't replaced by later rate cards.
+// Prices applied to pods and nodes while the version was in effect are kept in their price segments.
+func storeRateCardHistory(rateCard *RateCard, prices string) {
+	history := RateCard{
+		ID:                dgraph.ID{Xid: RateCardXID + "-" + rateCard.EffectiveFrom},
+		IsRateCardHistory: true,
+		CloudProvider:     rateCard.CloudProvider,
+		Region:            rateCard.Region,
+		Zone:              rateCard.Zone,
+		LocationSource:    rateCard.LocationSource,
+		Version:           rateCard.Version,
+		EffectiveFrom:     rateCard.EffectiveFrom,
+		Prices:            prices,
+		PricesDigest:      rateCard.PricesDigest,
+	}
+	uid := dgraph.GetUID(history.Xid, IsRateCardHistory)
+	if uid != "" {
+		history.UID = uid
+	}
+	_, err := dgraph.MutateNode(history, dgraph.CREATE)
+	if err != nil {
+		logrus.Errorf("Unable to store rateCard history reason: %v", err)
+	}
+}
+
+// getRateCardPrices returns json of prices of the rate card ordered by their XID along with its digest, uids of the
+// prices are left out so that the same prices always have the same digest
+func getRateCardPrices(rateCard *RateCard) (string, string) {
+	prices := rateCardPrices{}
+	for _, nodePrice := range rateCard.NodePrices {
+		price := *nodePrice
+		price.UID = ""
+		prices.NodePrices = append(prices.NodePrices, price)
+	}
+	for _, storagePrice := range rateCard.StoragePrices {
+		price := *storagePrice
+		price.UID = ""
+		prices.StoragePrices = append(prices.StoragePrices, price)
+	}
+	for _, extendedResourcePrice := range rateCard.ExtendedResourcePrices {
+		price := *extendedResourcePrice
+		price.UID = ""
+		prices.ExtendedResourcePrices = append(prices.ExtendedResourcePrices, price)
+	}
+	for _, networkPrice := range rateCard.NetworkPrices {
+		price := *networkPrice
+		price.UID = ""
+		prices.NetworkPrices = append(prices.NetworkPrices, price)
+	}
+	sort.Slice(prices.NodePrices, func(i, j int) bool { return prices.NodePrices[i].Xid < prices.NodePrices[j].Xid })
+	sort.Slice(prices.StoragePrices, func(i, j int) bool { return prices.StoragePrices[i].Xid < prices.StoragePrices[j].Xid })
+	sort.Slice(prices.ExtendedResourcePrices, func(i, j int) bool {
+		return prices.ExtendedResourcePrices[i].Xid < prices.ExtendedResourcePrices[j].Xid
+	})
+	sort.Slice(prices.NetworkPrices, func(i, j int) bool { return prices.NetworkPrices[i].Xid < prices.NetworkPrices[j].Xid })
+
+	data, err := json.Marshal(prices)
+	if err != nil {
+		logrus.Errorf("unable to marshal prices of rate card: %v", err)
+		return "", ""
+	}
+	return string(data), fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// isSameRateCardVersion tells whether the rate card has the same location, version and prices as the version in history
+func isSameRateCardVersion(history, rateCard RateCard) bool {
+	return history.PricesDigest != "" && history.PricesDigest == rateCard.PricesDigest &&
+		history.CloudProvider == rateCard.CloudProvider && history.Region == rateCard.Region &&
+		history.Zone == rateCard.Zone && history.LocationSource == rateCard.LocationSource &&
+		history.Version == rateCard.Version
+}
+
+// retrieveLatestRateCardHistory returns the latest version in the history of the rate card, nil if there is none
+func retrieveLatestRateCardHistory() (*RateCard, error) {
+	query := `query {
+		rateCards(func: has(isRateCardHistory)) {
+			cloudProvider
+			region
+			zone
+			locationSource
+			version
+			effectiveFrom
+			pricesDigest
+		}
+	}`
+	type root struct {
+		RateCards []RateCard `json:"rateCards"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	var latest *RateCard
+	for i, rateCard := range newRoot.RateCards {
+		// effectiveFrom is RFC3339, so lexical order is time order
+		if latest == nil || rateCard.EffectiveFrom > latest.EffectiveFrom {
+			latest = &newRoot.RateCards[i]
+		}
+	}
+	return latest, nil
+}
+
 // StoreNodePrice given nodePrice and its XID it stores(create/update) in dgraph
 func StoreNodePrice(nodePrice *NodePrice, productXID string) string {
 	uid := dgraph.GetUID(productXID, IsNodePrice)
@@ -123,6 +307,113 @@ func StoreStoragePrice(storagePrice *StoragePrice, productXID string) string {
 	return assigned.Uids["blank-0"]
 }
 
+// StoreNodePrices stores(create/update) every nodePrice in dgraph using its XID, returns the stored ones
+func StoreNodePrices(nodePrices []*NodePrice) []*NodePrice {
+	var storedNodePrices []*NodePrice
+	for _, nodePrice := range nodePrices {
+		productXID := nodePrice.Xid
+		uid := StoreNodePrice(nodePrice, productXID)
+		if uid != "" {
+			nodePrice.ID = dgraph.ID{UID: uid, Xid: productXID}
+			storedNodePrices = append(storedNodePrices, nodePrice)
+		}
+	}
+	return storedNodePrices
+}
+
+// StoreStoragePrices stores(create/update) every storagePrice in dgraph using its XID, returns the stored ones
+func StoreStoragePrices(storagePrices []*StoragePrice) []*StoragePrice {
+	var storedStoragePrices []*StoragePrice
+	for _, storagePrice := range storagePrices {
+		productXID := storagePrice.Xid
+		uid := StoreStoragePrice(storagePrice, productXID)
+		if uid != "" {
+			storagePrice.ID = dgraph.ID{UID: uid, Xid: productXID}
+			storedStoragePrices = append(storedStoragePrices, storagePrice)
+		}
+	}
+	return storedStoragePrices
+}
+
+// DeleteNodePriceOverrides deletes node prices selected by node labels from dgraph
+func DeleteNodePriceOverrides() {
+	existingOverrides, err := retrieveNodePriceOverrides()
+	if err != nil {
+		logrus.Errorf("unable to retrieve node price overrides: %v", err)
+	} else if len(existingOverrides) > 0 {
+		var staleOverrides []NodePrice
+		for _, override := range existingOverrides {
+			staleOverrides = append(staleOverrides, NodePrice{ID: dgraph.ID{UID: override.UID}})
+		}
+		_, err = dgraph.MutateNode(staleOverrides, dgraph.DELETE)
+		if err != nil {
+			logrus.Errorf("unable to delete node price overrides: %v", err)
+		}
+	}
+}
+
+// DeleteDefaultNodePrice deletes the price used for nodes matching no other price from dgraph
+func DeleteDefaultNodePrice() {
+	uid := dgraph.GetUID(DefaultNodePriceXID, IsNodePrice)
+	if uid == "" {
+		return
+	}
+	_, err := dgraph.MutateNode(NodePrice{ID: dgraph.ID{UID: uid}}, dgraph.DELETE)
+	if err != nil {
+		logrus.Errorf("unable to delete default node price: %v", err)
+	}
+}
+
+// DeleteInstanceNodePrices deletes prices of cloud instance types from dgraph so that they aren't selected before the
+// default node price once prices are defined by a RateCard custom resource
+func DeleteInstanceNodePrices() {
+	instancePrices, err := retrieveInstanceNodePrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve instance node prices: %v", err)
+		return
+	}
+	var stalePrices []NodePrice
+	for _, instancePrice := range instancePrices {
+		stalePrices = append(stalePrices, NodePrice{ID: dgraph.ID{UID: instancePrice.UID}})
+	}
+	if len(stalePrices) > 0 {
+		_, err = dgraph.MutateNode(stalePrices, dgraph.DELETE)
+		if err != nil {
+			logrus.Errorf("unable to delete instance node prices: %v", err)
+		}
+	}
+}
+
+// DeleteProvisionerStoragePrices deletes storage prices selected by provisioner from dgraph
+func DeleteProvisionerStoragePrices() {
+	deleteStoragePrices(ProvisionerUsageType)
+}
+
+// DeleteStorageClassStoragePrices deletes storage prices selected by storage class name from dgraph
+func DeleteStorageClassStoragePrices() {
+	deleteStoragePrices(StorageClassUsageType)
+}
+
+func deleteStoragePrices(usageType string) {
+	storagePrices, err := retrieveStoragePrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve storage prices: %v", err)
+		return
+	}
+	var stalePrices []StoragePrice
+	for _, storagePrice := range storagePrices {
+		if storagePrice.UsageType == usageType {
+			stalePrices = append(stalePrices, StoragePrice{ID: dgraph.ID{UID: storagePrice.UID}})
+		}
+	}
+	if len(stalePrices) > 0 {
+		_, err = dgraph.MutateNode(stalePrices, dgraph.DELETE)
+		if err != nil {
+			logrus.Errorf("unable to delete %s storage prices: %v", usageType, err)
+		}
+	}
+}
+
 // retrieveNode given a node name it returns pointer to models.Node - nil in case of error
 func retrieveNode(name string) (*Node, error) {
 	query := `query {
@@ -133,8 +424,13 @@ func retrieveNode(name string) (*Node, error) {
 			endTime
 			cpuCapacity
 			memoryCapacity
+			gpuCapacity
 			instanceType
 			os
+			cpuPrice
+			memoryPrice
+			gpuPrice
+			pricingTerm
         }
     }`
 	type root struct {
@@ -161,6 +457,9 @@ func retrieveNodePrice(xid string) (*NodePrice, error) {
 			price
 			cpuPrice
 			memoryPrice
+			gpuPrice
+			reservedPrice
+			spotPrice
         }
     }`
 	type root struct {
@@ -177,20 +476,277 @@ func retrieveNodePrice(xid string) (*NodePrice, error) {
 	return &newRoot.NodePrices[0], nil
 }
 
-// getPerUnitResourcePriceForNode returns price per cpu and price per memory
-func getPerUnitResourcePriceForNode(nodeName string) (float64, float64) {
+// retrieveNodePriceOverrides returns node prices which are selected by node labels
+func retrieveNodePriceOverrides() ([]NodePrice, error) {
+	query := `query {
+		nodePrices(func: has(isNodePrice)) @filter(has(labelSelector)) {
+			uid
+			instanceType
+			cpuPrice
+			memoryPrice
+			gpuPrice
+			labelSelector
+			priority
+        }
+    }`
+	type root struct {
+		NodePrices []NodePrice `json:"nodePrices"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.NodePrices, nil
+}
+
+// retrieveInstanceNodePrices returns node prices of instance types(ex: m5.large-linux), that is all node prices
+// other than the default node price and node price overrides
+func retrieveInstanceNodePrices() ([]NodePrice, error) {
+	query := `query {
+		nodePrices(func: has(isNodePrice)) @filter(has(instanceType)) {
+			uid
+			instanceType
+        }
+    }`
+	type root struct {
+		NodePrices []NodePrice `json:"nodePrices"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	var instancePrices []NodePrice
+	for _, nodePrice := range newRoot.NodePrices {
+		if nodePrice.InstanceType != DefaultNodeInstance {
+			instancePrices = append(instancePrices, nodePrice)
+		}
+	}
+	return instancePrices, nil
+}
+
+// retrieveStoragePrices returns storage prices of the rate card
+func retrieveStoragePrices() ([]StoragePrice, error) {
+	query := `query {
+		storagePrices(func: has(isStoragePrice)) {
+			uid
+			volumeType
+			usageType
+			price
+			iopsPrice
+			parameterSelector
+			priority
+        }
+    }`
+	type root struct {
+		StoragePrices []StoragePrice `json:"storagePrices"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.StoragePrices, nil
+}
+
+// getStoragePriceForPersistentVolume returns price per GB per hour of a persistent volume, price of its provisioned
+// IOPS is added per GB of the volume
+func getStoragePriceForPersistentVolume(pv PersistentVolume) float64 {
+	storagePrices, err := retrieveStoragePrices()
+	if err != nil {
+		logrus.Errorf("unable to retrieve storage prices: %v", err)
+		return DefaultStorageCostInFloat64
+	}
+	storagePrice := selectStoragePrice(storagePrices, pv)
+	if storagePrice == nil || storagePrice.Price <= 0 {
+		return DefaultStorageCostInFloat64
+	}
+	price := storagePrice.Price
+	if pv.IOPS > 0 && pv.StorageCapacity > 0 {
+		price += pv.IOPS * storagePrice.IOPSPrice / pv.StorageCapacity
+	}
+	return price
+}
+
+// selectStoragePrice returns the storage price of a persistent volume. Lookup order is price of its storage class,
+// price of its provisioner(ex: CSI driver) selected by storage class parameters and then price of its volume type,
+// cheapest one is used when the volume type has several prices(ex: zonal and regional disks, disk tiers).
+func selectStoragePrice(storagePrices []StoragePrice, pv PersistentVolume) *StoragePrice {
+	var provisionerPrices []StoragePrice
+	var volumeTypePrice *StoragePrice
+	for i, storagePrice := range storagePrices {
+		switch storagePrice.UsageType {
+		case StorageClassUsageType:
+			if pv.StorageClass != "" && storagePrice.VolumeType == pv.StorageClass {
+				return &storagePrices[i]
+			}
+		case ProvisionerUsageType:
+			if pv.Provisioner != "" && storagePrice.VolumeType == pv.Provisioner {
+				provisionerPrices = append(provisionerPrices, storagePrice)
+			}
+		default:
+			if pv.VolumeType != "" && storagePrice.VolumeType == pv.VolumeType &&
+				(volumeTypePrice == nil || storagePrice.Price < volumeTypePrice.Price) {
+				volumeTypePrice = &storagePrices[i]
+			}
+		}
+	}
+
+	sort.SliceStable(provisionerPrices, func(i, j int) bool {
+		return provisionerPrices[i].Priority < provisionerPrices[j].Priority
+	})
+	for i := range provisionerPrices {
+		selector, err := labels.Parse(provisionerPrices[i].ParameterSelector)
+		if err != nil {
+			logrus.Errorf("invalid parameter selector: %s of storage price, err: %v", provisionerPrices[i].ParameterSelector, err)
+			continue
+		}
+		if selector.Matches(labels.Set(pv.parameters)) {
+			return &provisionerPrices[i]
+		}
+	}
+	return volumeTypePrice
+}
+
+// getPerUnitResourcePriceForNode returns price per cpu, price per memory and price per gpu
+func getPerUnitResourcePriceForNode(nodeName string) (float64, float64, float64) {
 	node, err := retrieveNode(nodeName)
 	if err == nil {
-		return getPricePerUnitResourceFromNodePrice(*node)
+		if node.CPUPrice > 0 && node.MemoryPrice > 0 {
+			return node.CPUPrice, node.MemoryPrice, node.GPUPrice
+		}
+		pricePerCPU, pricePerMemory, pricePerGPU, _ := getPricePerUnitResourceFromNodePrice(*node, nil)
+		if node.GPUCapacity <= 0 {
+			pricePerGPU = 0
+		}
+		return pricePerCPU, pricePerMemory, pricePerGPU
 	}
-	return DefaultCPUCostInFloat64, DefaultMemCostInFloat64
+	return DefaultCPUCostInFloat64, DefaultMemCostInFloat64, 0
 }
 
-func getPricePerUnitResourceFromNodePrice(node Node) (float64, float64) {
-	nodePriceXID := node.InstanceType + "-" + node.OS
-	nodePrice, err := retrieveNodePrice(nodePriceXID)
-	if err == nil {
+// getPricePerUnitResourceFromNodePrice returns price per cpu, price per memory, price per gpu and the pricing term
+// applied to a node. Lookup order is node price selected by node labels, node price of instance type and os, default
+// node price and then constants. Price of the node's pricing term is used when the node price has one, on-demand price
+// otherwise.
+func getPricePerUnitResourceFromNodePrice(node Node, nodeLabels map[string]string) (float64, float64, float64, string) {
+	if nodePrice := getNodePriceOverride(nodeLabels); nodePrice != nil {
+		return nodePrice.PricePerCPU, nodePrice.PricePerMemory, getPricePerGPU(nodePrice), OnDemandPricingTerm
+	}
+
+	nodePrice, err := retrieveNodePrice(node.InstanceType + "-" + node.OS)
+	if err != nil {
+		if family := getCustomMachineFamily(node.InstanceType); family != "" {
+			nodePrice, err = retrieveNodePrice(family + "-" + node.OS)
+		}
+	}
+	if err != nil {
+		nodePrice, err = retrieveNodePrice(DefaultNodePriceXID)
+	}
+	if err != nil {
+		return DefaultCPUCostInFloat64, DefaultMemCostInFloat64, DefaultGPUCostInFloat64, OnDemandPricingTerm
+	}
+
+	pricePerCPU, pricePerMemory, term := getPricePerUnitResourceForTerm(node, nodePrice)
+	return pricePerCPU, pricePerMemory, getPricePerGPU(nodePrice) * getPricingTermRatio(term, nodePrice), term
+}
+
+// getPricePerUnitResourceForTerm scales per unit resource prices of nodePrice by the price of node's pricing term
+func getPricePerUnitResourceForTerm(node Node, nodePrice *NodePrice) (float64, float64, string) {
+	pricePerCPU, pricePerMemory := getPricePerUnitResource(node, nodePrice)
+
+	term := node.PricingTerm
+	if getTermPrice(term, nodePrice) <= 0 || nodePrice.Price <= 0 {
+		return pricePerCPU, pricePerMemory, OnDemandPricingTerm
+	}
+	ratio := getPricingTermRatio(term, nodePrice)
+	return pricePerCPU * ratio, pricePerMemory * ratio, term
+}
+
+// getPricingTermRatio returns ratio of the price of pricing term to on-demand price of nodePrice, 1 when the node price
+// has no price for the term
+func getPricingTermRatio(term string, nodePrice *NodePrice) float64 {
+	termPrice := getTermPrice(term, nodePrice)
+	if termPrice <= 0 || nodePrice.Price <= 0 {
+		return 1
+	}
+	return termPrice / nodePrice.Price
+}
+
+func getTermPrice(term string, nodePrice *NodePrice) float64 {
+	switch term {
+	case ReservedPricingTerm:
+		return nodePrice.ReservedPrice
+	case SpotPricingTerm:
+		return nodePrice.SpotPrice
+	}
+	return 0
+}
+
+// getPricePerGPU returns price per gpu of nodePrice. Instance prices without a gpu price include gpus of the instance
+// in their per cpu and per memory prices, other node prices(ex: on-prem) without one use the default gpu price.
+func getPricePerGPU(nodePrice *NodePrice) float64 {
+	if nodePrice.PricePerGPU > 0 {
+		return nodePrice.PricePerGPU
+	}
+	if nodePrice.InstanceType == DefaultNodeInstance {
+		return DefaultGPUCostInFloat64
+	}
+	return 0
+}
+
+// getNodePriceOverride returns the first node price(by priority) whose label selector matches node labels
+func getNodePriceOverride(nodeLabels map[string]string) *NodePrice {
+	if len(nodeLabels) == 0 {
+		return nil
+	}
+	overrides, err := retrieveNodePriceOverrides()
+	if err != nil {
+		logrus.Errorf("unable to retrieve node price overrides: %v", err)
+		return nil
+	}
+	return selectNodePriceOverride(overrides, nodeLabels)
+}
+
+func selectNodePriceOverride(overrides []NodePrice, nodeLabels map[string]string) *NodePrice {
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return overrides[i].Priority < overrides[j].Priority
+	})
+	for i := range overrides {
+		selector, err := labels.Parse(overrides[i].LabelSelector)
+		if err != nil {
+			logrus.Errorf("invalid label selector: %s of node price, err: %v", overrides[i].LabelSelector, err)
+			continue
+		}
+		if selector.Matches(labels.Set(nodeLabels)) {
+			return &overrides[i]
+		}
+	}
+	return nil
+}
+
+// getPricePerUnitResource returns per unit resource prices of nodePrice, when the rate card only has
+// the price of whole node(ex: azure) it is split between cpu and memory capacity of the node
+func getPricePerUnitResource(node Node, nodePrice *NodePrice) (float64, float64) {
+	if nodePrice.PricePerCPU != 0 && nodePrice.PricePerMemory != 0 {
 		return nodePrice.PricePerCPU, nodePrice.PricePerMemory
 	}
-	return DefaultCPUCostInFloat64, DefaultMemCostInFloat64
+	if nodePrice.Price <= 0 || node.CPUCapacity <= 0 || node.MemoryCapacity <= 0 {
+		return DefaultCPUCostInFloat64, DefaultMemCostInFloat64
+	}
+	pricePerCPU := nodeCapacityPriceSplitRatio * nodePrice.Price / node.CPUCapacity
+	pricePerMemory := (1 - nodeCapacityPriceSplitRatio) * nodePrice.Price / node.MemoryCapacity
+	return pricePerCPU, pricePerMemory
+}
+
+// getCustomMachineFamily returns the price key of custom machine types which are priced per vCPU and per GB
+// ex: n2-custom-4-8192 -> n2-custom, custom-4-5120 -> n1-custom (gcp)
+func getCustomMachineFamily(instanceType string) string {
+	if strings.HasPrefix(instanceType, customMachine+"-") {
+		return defaultCustomMachineFamily + "-" + customMachine
+	}
+	if index := strings.Index(instanceType, "-"+customMachine+"-"); index > 0 {
+		return instanceType[:index] + "-" + customMachine
+	}
+	return ""
 }
@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// TestGetCustomMachineFamily ...
+func TestGetCustomMachineFamily(t *testing.T) {
+	assert.Equal(t, "n2-custom", getCustomMachineFamily("n2-custom-4-8192"))
+	assert.Equal(t, "n1-custom", getCustomMachineFamily("custom-4-5120"))
+	assert.Equal(t, "", getCustomMachineFamily("n1-standard-4"))
+	assert.Equal(t, "", getCustomMachineFamily("m5.large"))
+}
+
+// TestGetPricePerUnitResource ...
+func TestGetPricePerUnitResource(t *testing.T) {
+	node := Node{CPUCapacity: 2, MemoryCapacity: 8}
+
+	cpuPrice, memoryPrice := getPricePerUnitResource(node, &NodePrice{PricePerCPU: 0.03, PricePerMemory: 0.004})
+	assert.Equal(t, 0.03, cpuPrice)
+	assert.Equal(t, 0.004, memoryPrice)
+
+	cpuPrice, memoryPrice = getPricePerUnitResource(node, &NodePrice{Price: 0.096})
+	assert.InDelta(t, 0.024, cpuPrice, 1e-9)
+	assert.InDelta(t, 0.006, memoryPrice, 1e-9)
+
+	cpuPrice, memoryPrice = getPricePerUnitResource(Node{}, &NodePrice{Price: 0.096})
+	assert.Equal(t, DefaultCPUCostInFloat64, cpuPrice)
+	assert.Equal(t, DefaultMemCostInFloat64, memoryPrice)
+}
+
+// TestSelectNodePriceOverride ...
+func TestSelectNodePriceOverride(t *testing.T) {
+	overrides := []NodePrice{
+		{LabelSelector: "hardware-generation=gen9", PricePerCPU: 0.02, Priority: 1},
+		{LabelSelector: "hardware-generation=gen9,rack=r1", PricePerCPU: 0.01, Priority: 0},
+	}
+
+	got := selectNodePriceOverride(overrides, map[string]string{"hardware-generation": "gen9", "rack": "r1"})
+	assert.Equal(t, 0.01, got.PricePerCPU)
+
+	got = selectNodePriceOverride(overrides, map[string]string{"hardware-generation": "gen9", "rack": "r2"})
+	assert.Equal(t, 0.02, got.PricePerCPU)
+
+	got = selectNodePriceOverride(overrides, map[string]string{"hardware-generation": "gen10"})
+	assert.Nil(t, got)
+}
+
+// TestGetPricePerUnitResourceForTerm ...
+func TestGetPricePerUnitResourceForTerm(t *testing.T) {
+	nodePrice := &NodePrice{Price: 0.096, PricePerCPU: 0.024, PricePerMemory: 0.006, ReservedPrice: 0.06, SpotPrice: 0.0288}
+
+	cpuPrice, memoryPrice, term := getPricePerUnitResourceForTerm(Node{PricingTerm: SpotPricingTerm}, nodePrice)
+	assert.Equal(t, SpotPricingTerm, term)
+	assert.InDelta(t, 0.0072, cpuPrice, 1e-9)
+	assert.InDelta(t, 0.0018, memoryPrice, 1e-9)
+
+	cpuPrice, memoryPrice, term = getPricePerUnitResourceForTerm(Node{PricingTerm: ReservedPricingTerm}, nodePrice)
+	assert.Equal(t, ReservedPricingTerm, term)
+	assert.InDelta(t, 0.015, cpuPrice, 1e-9)
+	assert.InDelta(t, 0.00375, memoryPrice, 1e-9)
+
+	cpuPrice, memoryPrice, term = getPricePerUnitResourceForTerm(Node{PricingTerm: OnDemandPricingTerm}, nodePrice)
+	assert.Equal(t, OnDemandPricingTerm, term)
+	assert.Equal(t, 0.024, cpuPrice)
+	assert.Equal(t, 0.006, memoryPrice)
+
+	// on-demand price is applied when the node price has no price for the term
+	nodePrice.SpotPrice = 0
+	cpuPrice, _, term = getPricePerUnitResourceForTerm(Node{PricingTerm: SpotPricingTerm}, nodePrice)
+	assert.Equal(t, OnDemandPricingTerm, term)
+	assert.Equal(t, 0.024, cpuPrice)
+}
+
+// TestGetPricePerGPU ...
+func TestGetPricePerGPU(t *testing.T) {
+	assert.Equal(t, 2.4, getPricePerGPU(&NodePrice{InstanceType: "p3.2xlarge", PricePerGPU: 2.4}))
+	// gpus of instance prices without gpu price are part of cpu and memory prices
+	assert.Equal(t, 0.0, getPricePerGPU(&NodePrice{InstanceType: "Standard_NC6"}))
+	assert.Equal(t, DefaultGPUCostInFloat64, getPricePerGPU(&NodePrice{InstanceType: DefaultNodeInstance}))
+
+	nodePrice := &NodePrice{Price: 3.06, SpotPrice: 0.918, PricePerGPU: 2.4}
+	assert.InDelta(t, 0.3, getPricingTermRatio(SpotPricingTerm, nodePrice), 1e-9)
+	assert.Equal(t, 1.0, getPricingTermRatio(ReservedPricingTerm, nodePrice))
+}
+
+// TestSelectStoragePrice ...
+func TestSelectStoragePrice(t *testing.T) {
+	storagePrices := []StoragePrice{
+		{VolumeType: "gp2", UsageType: "EBS:VolumeUsage.gp2", Price: 0.1},
+		{VolumeType: "pd-ssd", UsageType: "regional", Price: 0.34},
+		{VolumeType: "pd-ssd", UsageType: "zonal", Price: 0.17},
+		{VolumeType: "fast", UsageType: StorageClassUsageType, Price: 0.3},
+		{VolumeType: "csi.vsphere.vmware.com", UsageType: ProvisionerUsageType, Price: 0.01, Priority: 1},
+		{VolumeType: "csi.vsphere.vmware.com", UsageType: ProvisionerUsageType, Price: 0.02, ParameterSelector: "storagepolicyname=gold"},
+	}
+
+	assert.Equal(t, 0.1, selectStoragePrice(storagePrices, PersistentVolume{VolumeType: "gp2"}).Price)
+	assert.Equal(t, 0.17, selectStoragePrice(storagePrices, PersistentVolume{VolumeType: "pd-ssd"}).Price)
+	assert.Nil(t, selectStoragePrice(storagePrices, PersistentVolume{VolumeType: "gp3"}))
+
+	// price of storage class wins over price of volume type
+	assert.Equal(t, 0.3, selectStoragePrice(storagePrices, PersistentVolume{StorageClass: "fast", VolumeType: "gp2"}).Price)
+
+	vsphereVolume := PersistentVolume{StorageClass: "gold", Provisioner: "csi.vsphere.vmware.com"}
+	assert.Equal(t, 0.01, selectStoragePrice(storagePrices, vsphereVolume).Price)
+	vsphereVolume.parameters = map[string]string{"storagepolicyname": "gold"}
+	assert.Equal(t, 0.02, selectStoragePrice(storagePrices, vsphereVolume).Price)
+}
+
+// TestGetRateCardPrices ...
+func TestGetRateCardPrices(t *testing.T) {
+	rateCard := &RateCard{
+		NodePrices: []*NodePrice{
+			{ID: dgraph.ID{Xid: "m5.large-linux", UID: "0x1"}, InstanceType: "m5.large", Price: 0.096},
+			{ID: dgraph.ID{Xid: "c5.large-linux"}, InstanceType: "c5.large", Price: 0.085},
+		},
+		StoragePrices: []*StoragePrice{{ID: dgraph.ID{Xid: "gp2-EBS:VolumeUsage.gp2"}, VolumeType: "gp2", Price: 0.1}},
+	}
+	prices, digest := getRateCardPrices(rateCard)
+	assert.Contains(t, prices, `"nodePrices":[{"xid":"c5.large-linux"`)
+	assert.NotContains(t, prices, "0x1")
+
+	// order and uids of prices don't change the digest
+	reordered := &RateCard{
+		NodePrices:    []*NodePrice{rateCard.NodePrices[1], {ID: dgraph.ID{Xid: "m5.large-linux"}, InstanceType: "m5.large", Price: 0.096}},
+		StoragePrices: rateCard.StoragePrices,
+	}
+	_, reorderedDigest := getRateCardPrices(reordered)
+	assert.Equal(t, digest, reorderedDigest)
+
+	rateCard.NodePrices[0].Price = 0.1
+	_, changedDigest := getRateCardPrices(rateCard)
+	assert.NotEqual(t, digest, changedDigest)
+}
+
+// TestIsSameRateCardVersion ...
+func TestIsSameRateCardVersion(t *testing.T) {
+	history := RateCard{CloudProvider: AWS, Region: "us-west-2", Version: "1", PricesDigest: "abc"}
+	assert.True(t, isSameRateCardVersion(history, RateCard{CloudProvider: AWS, Region: "us-west-2", Version: "1", PricesDigest: "abc"}))
+	assert.False(t, isSameRateCardVersion(history, RateCard{CloudProvider: AWS, Region: "us-west-2", Version: "1", PricesDigest: "def"}))
+	assert.False(t, isSameRateCardVersion(history, RateCard{CloudProvider: AWS, Region: "us-east-1", Version: "1", PricesDigest: "abc"}))
+	// versions stored without a digest are always replaced
+	history.PricesDigest = ""
+	assert.False(t, isSameRateCardVersion(history, RateCard{CloudProvider: AWS, Region: "us-west-2", Version: "1"}))
+}
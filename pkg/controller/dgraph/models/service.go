@@ -30,19 +30,31 @@ import (
 // Dgraph Model Constants
 const (
 	IsService = "isService"
+
+	// annotation selecting the kind of AWS load balancer of a LoadBalancer service, nlb and external(AWS load
+	// balancer controller) services get a network load balancer
+	awsLoadBalancerTypeAnnotation = "service.beta.kubernetes.io/aws-load-balancer-type"
 )
 
 // Service model structure in Dgraph
+// ServiceType is the type of the kubernetes service(ex: LoadBalancer), ProvisionedTime is the time from which its load
+// balancer is charged and Price is the price per hour of the load balancer along with its public IPs. Price segments
+// are recorded when the load balancer is repriced or the service stops being a LoadBalancer.
 type Service struct {
 	dgraph.ID
-	IsService bool       `json:"isService,omitempty"`
-	Name      string     `json:"name,omitempty"`
-	StartTime string     `json:"startTime,omitempty"`
-	EndTime   string     `json:"endTime,omitempty"`
-	Pod       []*Pod     `json:"pod,omitempty"`
-	Interacts []*Service `json:"interacts,omitempty"`
-	Namespace *Namespace `json:"namespace,omitempty"`
-	Type      string     `json:"type,omitempty"`
+	IsService       bool            `json:"isService,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	StartTime       string          `json:"startTime,omitempty"`
+	EndTime         string          `json:"endTime,omitempty"`
+	Pod             []*Pod          `json:"pod,omitempty"`
+	Interacts       []*Service      `json:"interacts,omitempty"`
+	Namespace       *Namespace      `json:"namespace,omitempty"`
+	Type            string          `json:"type,omitempty"`
+	ServiceType     string          `json:"serviceType,omitempty"`
+	ProvisionedTime string          `json:"provisionedTime,omitempty"`
+	PublicIPs       int             `json:"publicIPs,omitempty"`
+	Price           float64         `json:"price,omitempty"`
+	PriceSegments   []*PriceSegment `json:"priceSegments,omitempty"`
 }
 
 func newService(svc api_v1.Service) (*api.Assigned, error) {
@@ -65,7 +77,8 @@ func StoreService(service api_v1.Service) error {
 	xid := service.Namespace + ":" + service.Name
 	uid := dgraph.GetUID(xid, IsService)
 
-	if uid == "" {
+	isNew := uid == ""
+	if isNew {
 		assigned, err := newService(service)
 		if err != nil {
 			return err
@@ -78,14 +91,73 @@ func StoreService(service api_v1.Service) error {
 	if !svcDeletionTimestamp.IsZero() {
 		et := svcDeletionTimestamp.Time.Format(time.RFC3339)
 		updatedService := Service{
-			ID:      dgraph.ID{Xid: xid + et, UID: uid},
-			EndTime: et,
-			Name:    "service-" + service.Name + "*" + et,
+			ID:            dgraph.ID{Xid: xid + et, UID: uid},
+			EndTime:       et,
+			Name:          "service-" + service.Name + "*" + et,
+			PriceSegments: getPriceSegmentsOnEnd(uid, et),
 		}
 		_, err := dgraph.MutateNode(updatedService, dgraph.UPDATE)
 		return err
 	}
-	return nil
+	return storeServiceType(service, xid, uid, isNew)
+}
+
+// storeServiceType stores type of the service, LoadBalancer services are priced once their load balancer is
+// provisioned(i.e, it has an ingress) and repriced on every update. Load balancers already provisioned when the
+// service is first seen are taken as provisioned at its creation. Cost of the load balancer ends when the service
+// stops being a LoadBalancer, a price segment without price is recorded from then on.
+func storeServiceType(service api_v1.Service, xid, uid string, isNew bool) error {
+	updatedService := Service{
+		ID:          dgraph.ID{Xid: xid, UID: uid},
+		ServiceType: string(service.Spec.Type),
+	}
+	if service.Spec.Type == api_v1.ServiceTypeLoadBalancer && len(service.Status.LoadBalancer.Ingress) > 0 {
+		updatedService.ProvisionedTime = getProvisionedTime(service, xid, isNew)
+		updatedService.PublicIPs = getPublicIPsCount(service)
+		updatedService.Price = getLoadBalancerPrice(getLoadBalancerResource(service), updatedService.PublicIPs)
+	}
+	if !isNew {
+		updatedService.PriceSegments = getPriceSegmentsOnPriceChange(uid, time.Now(), func(prices *ResourcePrices) {
+			prices.Price = updatedService.Price
+		})
+	}
+	_, err := dgraph.MutateNode(updatedService, dgraph.UPDATE)
+	return err
+}
+
+// getProvisionedTime returns the time at which load balancer of the service was provisioned
+func getProvisionedTime(service api_v1.Service, xid string, isNew bool) string {
+	if isNew {
+		return service.GetCreationTimestamp().Time.Format(time.RFC3339)
+	}
+	storedService, err := retrieveService(xid)
+	if err != nil {
+		log.Errorf("unable to retrieve service: %s, err: %v", xid, err)
+	} else if storedService.ProvisionedTime != "" {
+		return storedService.ProvisionedTime
+	}
+	return time.Now().Format(time.RFC3339)
+}
+
+// getLoadBalancerResource returns the network resource by which load balancer of the service is priced
+func getLoadBalancerResource(service api_v1.Service) string {
+	switch service.Annotations[awsLoadBalancerTypeAnnotation] {
+	case "nlb", "external":
+		return NetworkLoadBalancerNetworkResource
+	}
+	return LoadBalancerNetworkResource
+}
+
+// getPublicIPsCount returns number of IPs of the load balancer of the service, load balancers reachable only through
+// a hostname(ex: AWS ELB) carry no public IP of their own
+func getPublicIPsCount(service api_v1.Service) int {
+	publicIPs := 0
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			publicIPs++
+		}
+	}
+	return publicIPs
 }
 
 // StoreServicesInteraction stores the service interaction data in the Dgraph
@@ -193,6 +265,31 @@ func RetrieveServiceList() ([]Service, error) {
 	return newRoot.ServiceList, nil
 }
 
+// retrieveService given xid of a service it returns pointer to models.Service - nil in case of error
+func retrieveService(xid string) (*Service, error) {
+	query := `query {
+		services(func: has(isService)) @filter(eq(xid, "` + xid + `")) {
+			name
+			serviceType
+			provisionedTime
+			publicIPs
+			price
+        }
+    }`
+	type root struct {
+		Services []Service `json:"services"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	} else if len(newRoot.Services) < 1 {
+		return nil, fmt.Errorf("no service with xid: %v", xid)
+	}
+
+	return &newRoot.Services[0], nil
+}
+
 func retrieveServicesFromServicesXIDs(svcsXIDs []string) []*Service {
 	services := []*Service{}
 	for _, svcXID := range svcsXIDs {
@@ -0,0 +1,48 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// TestGetPublicIPsCount ...
+func TestGetPublicIPsCount(t *testing.T) {
+	service := api_v1.Service{}
+	service.Status.LoadBalancer.Ingress = []api_v1.LoadBalancerIngress{
+		{IP: "203.0.113.10"},
+		{Hostname: "a1b2c3.elb.amazonaws.com"},
+		{IP: "203.0.113.11"},
+	}
+	assert.Equal(t, 2, getPublicIPsCount(service))
+}
+
+// TestGetLoadBalancerResource ...
+func TestGetLoadBalancerResource(t *testing.T) {
+	service := api_v1.Service{}
+	assert.Equal(t, LoadBalancerNetworkResource, getLoadBalancerResource(service))
+
+	service.Annotations = map[string]string{awsLoadBalancerTypeAnnotation: "nlb"}
+	assert.Equal(t, NetworkLoadBalancerNetworkResource, getLoadBalancerResource(service))
+
+	service.Annotations[awsLoadBalancerTypeAnnotation] = "external"
+	assert.Equal(t, NetworkLoadBalancerNetworkResource, getLoadBalancerResource(service))
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// Dgraph Model Constants
+const (
+	IsUsageSample = "isUsageSample"
+)
+
+// UsageSample is cpu(cores) and memory(GB) used by a container at the time of sampling
+type UsageSample struct {
+	dgraph.ID
+	IsUsageSample bool    `json:"isUsageSample,omitempty"`
+	SampleTime    string  `json:"sampleTime,omitempty"`
+	CPUUsage      float64 `json:"cpuUsage,omitempty"`
+	MemoryUsage   float64 `json:"memoryUsage,omitempty"`
+}
+
+// ContainerUsage is cpu(cores) and memory(GB) used by a container as reported by a metrics source
+type ContainerUsage struct {
+	Namespace   string
+	Pod         string
+	Container   string
+	CPUUsage    float64
+	MemoryUsage float64
+}
+
+// StoreContainerUsages stores usage samples of containers taken at sampleTime. CPUUsage and MemoryUsage of
+// containers are the average of their samples, usage of a pod is the sum of its containers' usage.
+func StoreContainerUsages(usages []ContainerUsage, sampleTime time.Time) {
+	podUsages := map[string]*Pod{}
+	for _, usage := range usages {
+		podXID := usage.Namespace + ":" + usage.Pod
+		container, err := storeContainerUsage(podXID+":"+usage.Container, usage, sampleTime)
+		if err != nil {
+			log.Debugf("unable to store usage of container: %s:%s, err: %v", podXID, usage.Container, err)
+			continue
+		}
+		if _, isPresent := podUsages[podXID]; !isPresent {
+			podUsages[podXID] = &Pod{ID: dgraph.ID{Xid: podXID}}
+		}
+		podUsages[podXID].CPUUsage += container.CPUUsage
+		podUsages[podXID].MemoryUsage += container.MemoryUsage
+	}
+
+	for podXID, pod := range podUsages {
+		pod.UID = dgraph.GetUID(podXID, IsPod)
+		if pod.UID == "" {
+			continue
+		}
+		_, err := dgraph.MutateNode(pod, dgraph.UPDATE)
+		if err != nil {
+			log.Errorf("unable to store usage of pod: %s, err: %v", podXID, err)
+		}
+	}
+}
+
+// storeContainerUsage adds a usage sample to the container and updates its average usage
+func storeContainerUsage(containerXID string, usage ContainerUsage, sampleTime time.Time) (*Container, error) {
+	uid := dgraph.GetUID(containerXID, IsContainer)
+	if uid == "" {
+		return nil, errContainerNotPersisted
+	}
+	container, err := retrieveContainerUsage(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := newUsageSample(containerXID, usage, sampleTime)
+	updatedContainer := &Container{
+		ID:               dgraph.ID{UID: uid, Xid: containerXID},
+		CPUUsage:         getRunningAverage(container.CPUUsage, usage.CPUUsage, container.UsageSampleCount),
+		MemoryUsage:      getRunningAverage(container.MemoryUsage, usage.MemoryUsage, container.UsageSampleCount),
+		UsageSampleCount: container.UsageSampleCount + 1,
+		UsageSamples:     []*UsageSample{sample},
+	}
+	_, err = dgraph.MutateNode(updatedContainer, dgraph.UPDATE)
+	if err != nil {
+		return nil, err
+	}
+	return updatedContainer, nil
+}
+
+func newUsageSample(containerXID string, usage ContainerUsage, sampleTime time.Time) *UsageSample {
+	timestamp := sampleTime.Format(time.RFC3339)
+	return &UsageSample{
+		ID:            dgraph.ID{Xid: containerXID + "-usage-" + timestamp},
+		IsUsageSample: true,
+		SampleTime:    timestamp,
+		CPUUsage:      usage.CPUUsage,
+		MemoryUsage:   usage.MemoryUsage,
+	}
+}
+
+// getRunningAverage returns average of count values(whose average is average) and value
+func getRunningAverage(average, value float64, count int) float64 {
+	return (average*float64(count) + value) / float64(count+1)
+}
+
+func retrieveContainerUsage(uid string) (*Container, error) {
+	query := `query {
+		containers(func: uid(` + uid + `)) {
+			cpuUsage
+			memoryUsage
+			usageSampleCount
+		}
+	}`
+	type root struct {
+		Containers []Container `json:"containers"`
+	}
+	newRoot := root{}
+	err := dgraph.ExecuteQuery(query, &newRoot)
+	if err != nil {
+		return nil, err
+	} else if len(newRoot.Containers) < 1 {
+		return &Container{}, nil
+	}
+	return &newRoot.Containers[0], nil
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+)
+
+// TestNewUsageSample ...
+func TestNewUsageSample(t *testing.T) {
+	sampleTime := time.Date(2019, 6, 4, 10, 15, 0, 0, time.UTC)
+	usage := ContainerUsage{Namespace: "default", Pod: "pod-a", Container: "app", CPUUsage: 0.25, MemoryUsage: 0.5}
+	got := newUsageSample("default:pod-a:app", usage, sampleTime)
+	expected := &UsageSample{
+		ID:            dgraph.ID{Xid: "default:pod-a:app-usage-2019-06-04T10:15:00Z"},
+		IsUsageSample: true,
+		SampleTime:    "2019-06-04T10:15:00Z",
+		CPUUsage:      0.25,
+		MemoryUsage:   0.5,
+	}
+	assert.Equal(t, expected, got)
+}
+
+// TestGetRunningAverage ...
+func TestGetRunningAverage(t *testing.T) {
+	assert.Equal(t, 0.4, getRunningAverage(0, 0.4, 0))
+	assert.Equal(t, 0.3, getRunningAverage(0.2, 0.5, 2))
+}
@@ -40,6 +40,46 @@ func RemoveResourcesInactive() {
 	if err != nil {
 		log.Error(err)
 	}
+
+	err = removeOldUsageSamples()
+	if err != nil {
+		log.Error(err)
+	}
+
+	err = removeOldEgressSamples()
+	if err != nil {
+		log.Error(err)
+	}
+
+	err = removeOldCostSnapshots()
+	if err != nil {
+		log.Error(err)
+	}
+
+	err = removeOldJobRuns()
+	if err != nil {
+		log.Error(err)
+	}
+
+	err = removeOldCostAnomalies()
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+// removeOldCostAnomalies deletes cost anomalies along with their contributors, they are kept as long as cost snapshots
+func removeOldCostAnomalies() error {
+	uids, err := retrieveCostAnomaliesBeforeAYear()
+	if err != nil {
+		return err
+	}
+	if len(uids) == 0 {
+		log.Println("No old cost anomalies are present in dgraph")
+		return nil
+	}
+
+	_, err = MutateNode(uids, DELETE)
+	return err
 }
 
 func removeOldDeletedResources() error {
@@ -56,6 +96,7 @@ func removeOldDeletedResources() error {
 	return err
 }
 
+// removeOldDeletedPods deletes pods along with price segments(of pods and nodes) which are needed for last months' costs
 func removeOldDeletedPods() error {
 	uids, err := retrievePodsWithEndTimeBeforeThreeMonths()
 	if err != nil {
@@ -70,9 +111,70 @@ func removeOldDeletedPods() error {
 	return err
 }
 
+// removeOldUsageSamples deletes usage samples of containers which are older than the retention of deleted pods,
+// usage costs of windows within that retention are computed from the samples
+func removeOldUsageSamples() error {
+	uids, err := retrieveUsageSamplesBeforeThreeMonths()
+	if err != nil {
+		return err
+	}
+	if len(uids) == 0 {
+		log.Println("No old usage samples are present in dgraph")
+		return nil
+	}
+
+	_, err = MutateNode(uids, DELETE)
+	return err
+}
+
+// removeOldEgressSamples deletes egress samples of pods which are older than the retention of deleted pods
+func removeOldEgressSamples() error {
+	uids, err := retrieveEgressSamplesBeforeThreeMonths()
+	if err != nil {
+		return err
+	}
+	if len(uids) == 0 {
+		log.Println("No old egress samples are present in dgraph")
+		return nil
+	}
+
+	_, err = MutateNode(uids, DELETE)
+	return err
+}
+
+// removeOldCostSnapshots deletes cost snapshots older than a year
+func removeOldCostSnapshots() error {
+	uids, err := retrieveCostSnapshotsBeforeAYear()
+	if err != nil {
+		return err
+	}
+	if len(uids) == 0 {
+		log.Println("No old cost snapshots are present in dgraph")
+		return nil
+	}
+
+	_, err = MutateNode(uids, DELETE)
+	return err
+}
+
+// removeOldJobRuns deletes runs of jobs which ended before a year, they are kept as long as cost snapshots
+func removeOldJobRuns() error {
+	uids, err := retrieveJobRunsBeforeAYear()
+	if err != nil {
+		return err
+	}
+	if len(uids) == 0 {
+		log.Println("No old job runs are present in dgraph")
+		return nil
+	}
+
+	_, err = MutateNode(uids, DELETE)
+	return err
+}
+
 func retrieveResourcesWithEndTimeBeforeCurrentMonthStart() ([]resource, error) {
 	q := `query {
-		resources(func: le(endTime, "` + utils.ConverTimeToRFC3339(utils.GetCurrentMonthStartTime()) + `")) @filter(NOT(has(isPod))) {
+		resources(func: le(endTime, "` + utils.ConverTimeToRFC3339(utils.GetCurrentMonthStartTime()) + `")) @filter(NOT(has(isPod) OR has(isPriceSegment))) {
 			uid
 		}
 	}`
@@ -90,7 +192,7 @@ func retrieveResourcesWithEndTimeBeforeCurrentMonthStart() ([]resource, error) {
 
 func retrievePodsWithEndTimeBeforeThreeMonths() ([]resource, error) {
 	q := `query {
-		resources(func: le(endTime, "` + utils.ConverTimeToRFC3339(utils.GetCurrentMonthStartTime().Add(-time.Hour*24*30*2)) + `")) @filter(has(isPod)) {
+		resources(func: le(endTime, "` + utils.ConverTimeToRFC3339(utils.GetCurrentMonthStartTime().Add(-time.Hour*24*30*2)) + `")) @filter(has(isPod) OR has(isPriceSegment)) {
 			uid
 		}
 	}`
@@ -105,3 +207,105 @@ func retrievePodsWithEndTimeBeforeThreeMonths() ([]resource, error) {
 	}
 	return newRoot.Resources, nil
 }
+
+func retrieveUsageSamplesBeforeThreeMonths() ([]resource, error) {
+	q := `query {
+		resources(func: le(sampleTime, "` + utils.ConverTimeToRFC3339(utils.GetCurrentMonthStartTime().Add(-time.Hour*24*30*2)) + `")) @filter(has(isUsageSample)) {
+			uid
+		}
+	}`
+
+	type root struct {
+		Resources []resource `json:"resources"`
+	}
+	newRoot := root{}
+	err := ExecuteQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Resources, nil
+}
+
+func retrieveEgressSamplesBeforeThreeMonths() ([]resource, error) {
+	q := `query {
+		resources(func: le(sampleTime, "` + utils.ConverTimeToRFC3339(utils.GetCurrentMonthStartTime().Add(-time.Hour*24*30*2)) + `")) @filter(has(isEgressSample)) {
+			uid
+		}
+	}`
+
+	type root struct {
+		Resources []resource `json:"resources"`
+	}
+	newRoot := root{}
+	err := ExecuteQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Resources, nil
+}
+
+func retrieveCostSnapshotsBeforeAYear() ([]resource, error) {
+	q := `query {
+		resources(func: le(snapshotTime, "` + utils.ConverTimeToRFC3339(time.Now().Add(-utils.CostSnapshotRetention)) + `")) @filter(has(isCostSnapshot)) {
+			uid
+		}
+	}`
+
+	type root struct {
+		Resources []resource `json:"resources"`
+	}
+	newRoot := root{}
+	err := ExecuteQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Resources, nil
+}
+
+func retrieveJobRunsBeforeAYear() ([]resource, error) {
+	q := `query {
+		resources(func: le(runEndTime, "` + utils.ConverTimeToRFC3339(time.Now().Add(-utils.CostSnapshotRetention)) + `")) @filter(has(isJobRun)) {
+			uid
+		}
+	}`
+
+	type root struct {
+		Resources []resource `json:"resources"`
+	}
+	newRoot := root{}
+	err := ExecuteQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	return newRoot.Resources, nil
+}
+
+func retrieveCostAnomaliesBeforeAYear() ([]resource, error) {
+	q := `query {
+		resources(func: le(anomalyTime, "` + utils.ConverTimeToRFC3339(time.Now().Add(-utils.CostSnapshotRetention)) + `")) @filter(has(isCostAnomaly)) {
+			uid
+			contributors {
+				uid
+			}
+		}
+	}`
+
+	type anomalyResource struct {
+		ID
+		Contributors []resource `json:"contributors"`
+	}
+	type root struct {
+		Resources []anomalyResource `json:"resources"`
+	}
+	newRoot := root{}
+	err := ExecuteQuery(q, &newRoot)
+	if err != nil {
+		return nil, err
+	}
+	var resources []resource
+	for _, anomaly := range newRoot.Resources {
+		resources = append(resources, resource{ID: anomaly.ID})
+		resources = append(resources, anomaly.Contributors...)
+	}
+	return resources, nil
+}
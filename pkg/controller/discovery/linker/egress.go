@@ -0,0 +1,317 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package linker
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/pricing"
+)
+
+// ipRange is a configured range of addresses along with the class of traffic sent to them
+type ipRange struct {
+	network      *net.IPNet
+	trafficClass string
+}
+
+// podNodeTable: maps pod IP address with the name of its node
+// nodeIPTable: maps node IP addresses with node name
+// nodeLocationTable: maps node name with its region and zone
+// podsTraffic: maps pod xid with the traffic(of each class) sent by it in the current discovery
+// connectionBytes: maps pod xid and connection with bytes acknowledged on it till the previous discovery
+var (
+	podNodeTable      = make(map[string]string)
+	nodeIPTable       = make(map[string]string)
+	nodeLocationTable = make(map[string]pricing.Location)
+	ipRanges          []ipRange
+	podsTraffic       = make(map[string](map[string]*models.PodTraffic))
+	connectionBytes   = make(map[string]float64)
+	seenConnections   = make(map[string]bool)
+)
+
+var (
+	egressMu sync.Mutex
+)
+
+// privateNetworks are address ranges which aren't routed over internet, traffic to them is treated as intraZone
+// unless they belong to a node, a pod or a configured range
+var privateNetworks = parseNetworks("10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "169.254.0.0/16", "fc00::/7", "fe80::/10")
+
+// PopulateTopologyTables updates the pod, node and location tables used to classify traffic sent by pods
+// and resets the traffic of previous discovery. rawIPRanges is a comma separated list of cidr=trafficClass.
+func PopulateTopologyTables(pods *corev1.PodList, nodes *corev1.NodeList, rawIPRanges string) {
+	egressMu.Lock()
+	defer egressMu.Unlock()
+
+	podNodeTable = make(map[string]string)
+	nodeIPTable = make(map[string]string)
+	nodeLocationTable = make(map[string]pricing.Location)
+	podsTraffic = make(map[string](map[string]*models.PodTraffic))
+	seenConnections = make(map[string]bool)
+
+	if nodes != nil {
+		for _, node := range nodes.Items {
+			nodeLocationTable[node.Name] = pricing.GetNodeLocation(node)
+			for _, address := range node.Status.Addresses {
+				nodeIPTable[address.Address] = node.Name
+			}
+		}
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP != "" && pod.Spec.NodeName != "" {
+			podNodeTable[pod.Status.PodIP] = pod.Spec.NodeName
+		}
+	}
+
+	var err error
+	ipRanges, err = parseIPRanges(rawIPRanges)
+	if err != nil {
+		log.Errorf("ignoring configured ip ranges: %v", err)
+	}
+}
+
+// UpdatePodTraffic classifies connections of the pod and adds them to its traffic. bytesAcked maps connections with
+// bytes sent over them since they were opened, traffic of connections without bytes is counted but not sized.
+func UpdatePodTraffic(pod corev1.Pod, connections map[string]bool, bytesAcked map[string]float64) {
+	egressMu.Lock()
+	defer egressMu.Unlock()
+
+	podXID := pod.Namespace + KeySpliter + pod.Name
+	for connection := range connections {
+		address := strings.Split(connection, KeySpliter)
+		if len(address) != 4 {
+			continue
+		}
+		trafficClass := classifyTraffic(pod.Spec.NodeName, address[2])
+		if _, ok := podsTraffic[podXID]; !ok {
+			podsTraffic[podXID] = make(map[string]*models.PodTraffic)
+		}
+		if _, ok := podsTraffic[podXID][trafficClass]; !ok {
+			podsTraffic[podXID][trafficClass] = &models.PodTraffic{TrafficClass: trafficClass}
+		}
+		traffic := podsTraffic[podXID][trafficClass]
+		traffic.Connections++
+
+		connectionXID := podXID + KeySpliter + connection
+		seenConnections[connectionXID] = true
+		if bytes, isPresent := bytesAcked[connection]; isPresent {
+			traffic.Bytes += getBytesSinceLastDiscovery(connectionXID, bytes)
+		}
+	}
+}
+
+// StorePodsEgress stores traffic of pods collected in the current discovery and forgets closed connections
+func StorePodsEgress() {
+	egressMu.Lock()
+	defer egressMu.Unlock()
+
+	log.Info("Storing egress of pods ....")
+	traffic := make(map[string][]models.PodTraffic)
+	for podXID, classTraffic := range podsTraffic {
+		for _, podTraffic := range classTraffic {
+			traffic[podXID] = append(traffic[podXID], *podTraffic)
+		}
+		sort.Slice(traffic[podXID], func(i, j int) bool {
+			return traffic[podXID][i].TrafficClass < traffic[podXID][j].TrafficClass
+		})
+	}
+	models.StorePodsEgress(traffic, time.Now())
+
+	for connectionXID := range connectionBytes {
+		if !seenConnections[connectionXID] {
+			delete(connectionBytes, connectionXID)
+		}
+	}
+	log.Info("Finished storing egress of pods.")
+}
+
+// getBytesSinceLastDiscovery returns bytes sent over the connection after the previous discovery, the whole
+// count is used if the connection wasn't seen before or its counter went back(reused address and port)
+func getBytesSinceLastDiscovery(connectionXID string, bytes float64) float64 {
+	previous, isPresent := connectionBytes[connectionXID]
+	connectionBytes[connectionXID] = bytes
+	if !isPresent || bytes < previous {
+		return bytes
+	}
+	return bytes - previous
+}
+
+// classifyTraffic returns the class of traffic sent from a pod on srcNode to the remote IP address
+func classifyTraffic(srcNode, remoteIP string) string {
+	ip := net.ParseIP(remoteIP)
+	for _, configured := range ipRanges {
+		if ip != nil && configured.network.Contains(ip) {
+			return configured.trafficClass
+		}
+	}
+
+	dstNode, isPresent := podNodeTable[remoteIP]
+	if !isPresent {
+		dstNode, isPresent = nodeIPTable[remoteIP]
+	}
+	if isPresent {
+		return classifyTrafficBetweenNodes(srcNode, dstNode)
+	}
+
+	if ip != nil && containsIP(privateNetworks, ip) {
+		return models.IntraZoneTraffic
+	}
+	return models.InternetTraffic
+}
+
+// classifyTrafficBetweenNodes compares regions and zones of nodes, traffic is intraZone when they are unknown
+func classifyTrafficBetweenNodes(srcNode, dstNode string) string {
+	if srcNode == dstNode {
+		return models.IntraNodeTraffic
+	}
+	srcLocation, dstLocation := nodeLocationTable[srcNode], nodeLocationTable[dstNode]
+	if srcLocation.Region != "" && dstLocation.Region != "" && srcLocation.Region != dstLocation.Region {
+		return models.CrossRegionTraffic
+	}
+	if srcLocation.Zone != "" && dstLocation.Zone != "" && srcLocation.Zone != dstLocation.Zone {
+		return models.CrossZoneTraffic
+	}
+	return models.IntraZoneTraffic
+}
+
+func parseIPRanges(rawIPRanges string) ([]ipRange, error) {
+	var ranges []ipRange
+	for _, rawIPRange := range strings.Split(rawIPRanges, ",") {
+		rawIPRange = strings.TrimSpace(rawIPRange)
+		if rawIPRange == "" {
+			continue
+		}
+		parts := strings.SplitN(rawIPRange, "=", 2)
+		if len(parts) != 2 || !isTrafficClass(parts[1]) {
+			return nil, fmt.Errorf("invalid ip range: %s, expected cidr=trafficClass", rawIPRange)
+		}
+		_, network, err := net.ParseCIDR(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, ipRange{network: network, trafficClass: parts[1]})
+	}
+	return ranges, nil
+}
+
+func isTrafficClass(trafficClass string) bool {
+	switch trafficClass {
+	case models.IntraNodeTraffic, models.IntraZoneTraffic, models.CrossZoneTraffic, models.CrossRegionTraffic, models.InternetTraffic:
+		return true
+	}
+	return false
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSocketStatistics returns bytes acknowledged on each connection(localIP:localPort:remoteIP:remotePort)
+// from the output of `ss -tin`, where details of a connection are on the line following its addresses
+func ParseSocketStatistics(output string) map[string]float64 {
+	bytesAcked := make(map[string]float64)
+	connection := ""
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			connection = ""
+			if len(fields) >= 5 {
+				connection = getConnectionFromAddresses(fields[3], fields[4])
+			}
+			continue
+		}
+		if connection == "" {
+			continue
+		}
+		for _, field := range fields {
+			if strings.HasPrefix(field, "bytes_acked:") {
+				bytes, err := strconv.ParseFloat(strings.TrimPrefix(field, "bytes_acked:"), 64)
+				if err == nil {
+					bytesAcked[connection] = bytes
+				}
+			}
+		}
+	}
+	return bytesAcked
+}
+
+// getConnectionFromAddresses converts local and peer addresses of ss(ex: [::ffff:10.0.0.1]:8080) to a connection
+func getConnectionFromAddresses(local, peer string) string {
+	localIP, localPort := splitAddress(local)
+	remoteIP, remotePort := splitAddress(peer)
+	if localIP == "" || remoteIP == "" {
+		return ""
+	}
+	return localIP + KeySpliter + localPort + KeySpliter + remoteIP + KeySpliter + remotePort
+}
+
+func splitAddress(address string) (string, string) {
+	index := strings.LastIndex(address, ":")
+	if index < 0 {
+		return "", ""
+	}
+	ip := strings.Trim(address[:index], "[]")
+	ip = strings.TrimPrefix(ip, "::ffff:")
+	if zoneIndex := strings.Index(ip, "%"); zoneIndex >= 0 {
+		ip = ip[:zoneIndex]
+	}
+	return ip, address[index+1:]
+}
+
+// getConnection converts an address mapping of /proc/net/tcp(localIP:hexPort:remoteIP:hexPort) to a connection
+// with decimal ports as printed by ss
+func getConnection(address []string) string {
+	localPort, err := strconv.ParseInt(address[1], 16, 32)
+	if err != nil {
+		return ""
+	}
+	remotePort, err := strconv.ParseInt(address[3], 16, 32)
+	if err != nil {
+		return ""
+	}
+	return address[0] + KeySpliter + strconv.FormatInt(localPort, 10) + KeySpliter + address[2] + KeySpliter + strconv.FormatInt(remotePort, 10)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package linker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/pricing"
+)
+
+func setupTopologyTables(t *testing.T) {
+	podNodeTable = map[string]string{"10.1.0.5": "node-a", "10.1.1.5": "node-b", "10.1.2.5": "node-c", "10.1.3.5": "node-d"}
+	nodeIPTable = map[string]string{"192.168.0.10": "node-b"}
+	nodeLocationTable = map[string]pricing.Location{
+		"node-a": {Region: "us-east-1", Zone: "us-east-1a"},
+		"node-b": {Region: "us-east-1", Zone: "us-east-1b"},
+		"node-c": {Region: "us-west-2", Zone: "us-west-2a"},
+		"node-d": {},
+	}
+	var err error
+	ipRanges, err = parseIPRanges("10.20.0.0/16=crossRegion, 52.0.0.0/8=intraZone")
+	assert.NoError(t, err)
+}
+
+// TestClassifyTraffic ...
+func TestClassifyTraffic(t *testing.T) {
+	setupTopologyTables(t)
+
+	assert.Equal(t, models.IntraNodeTraffic, classifyTraffic("node-a", "10.1.0.5"))
+	assert.Equal(t, models.CrossZoneTraffic, classifyTraffic("node-a", "10.1.1.5"))
+	assert.Equal(t, models.CrossZoneTraffic, classifyTraffic("node-a", "192.168.0.10"))
+	assert.Equal(t, models.CrossRegionTraffic, classifyTraffic("node-a", "10.1.2.5"))
+	// zone of a node isn't known
+	assert.Equal(t, models.IntraZoneTraffic, classifyTraffic("node-a", "10.1.3.5"))
+	// configured ranges take precedence over private and public addresses
+	assert.Equal(t, models.CrossRegionTraffic, classifyTraffic("node-a", "10.20.3.4"))
+	assert.Equal(t, models.IntraZoneTraffic, classifyTraffic("node-a", "52.1.2.3"))
+	assert.Equal(t, models.IntraZoneTraffic, classifyTraffic("node-a", "10.96.0.1"))
+	assert.Equal(t, models.InternetTraffic, classifyTraffic("node-a", "8.8.8.8"))
+}
+
+// TestParseIPRangesWithInvalidRanges ...
+func TestParseIPRangesWithInvalidRanges(t *testing.T) {
+	_, err := parseIPRanges("10.20.0.0/16")
+	assert.Error(t, err)
+	_, err = parseIPRanges("10.20.0.0/16=nearby")
+	assert.Error(t, err)
+	_, err = parseIPRanges("10.20.0.0=internet")
+	assert.Error(t, err)
+
+	ranges, err := parseIPRanges("")
+	assert.NoError(t, err)
+	assert.Empty(t, ranges)
+}
+
+// TestParseSocketStatistics ...
+func TestParseSocketStatistics(t *testing.T) {
+	output := "State  Recv-Q Send-Q   Local Address:Port     Peer Address:Port\n" +
+		"ESTAB  0      0           10.1.0.5:43210        10.1.1.5:8080\n" +
+		"\t cubic wscale:7,7 rto:204 rtt:0.5/0.25 bytes_acked:2048 bytes_received:512 segs_out:10\n" +
+		"ESTAB  0      0    [::ffff:10.1.0.5]:80    [::ffff:8.8.8.8]:51234\n" +
+		"\t cubic wscale:7,7 rto:204 rtt:0.5/0.25 bytes_acked:1024 segs_out:4\n" +
+		"ESTAB  0      0           10.1.0.5:43212        10.1.1.5:8080\n"
+
+	bytesAcked := ParseSocketStatistics(output)
+	assert.Equal(t, map[string]float64{
+		"10.1.0.5:43210:10.1.1.5:8080": 2048,
+		"10.1.0.5:80:8.8.8.8:51234":    1024,
+	}, bytesAcked)
+}
+
+// TestGetConnection ...
+func TestGetConnection(t *testing.T) {
+	assert.Equal(t, "10.1.0.5:43210:10.1.1.5:8080", getConnection([]string{"10.1.0.5", "A8CA", "10.1.1.5", "1F90"}))
+	assert.Equal(t, "", getConnection([]string{"10.1.0.5", "XYZ", "10.1.1.5", "1F90"}))
+}
+
+// TestGetBytesSinceLastDiscovery ...
+func TestGetBytesSinceLastDiscovery(t *testing.T) {
+	connectionBytes = map[string]float64{}
+
+	assert.Equal(t, 100.0, getBytesSinceLastDiscovery("default:web:conn", 100))
+	assert.Equal(t, 50.0, getBytesSinceLastDiscovery("default:web:conn", 150))
+	// counter went back as the address and port were reused by a new connection
+	assert.Equal(t, 20.0, getBytesSinceLastDiscovery("default:web:conn", 20))
+}
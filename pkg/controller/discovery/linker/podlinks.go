@@ -32,6 +32,7 @@ type InteractionsWrapper struct {
 	PodInteractions             map[string](map[string]float64)
 	ProcessToPodInteraction     map[string](map[string]bool)
 	ContainerProcessInteraction map[string][]string
+	PodConnections              map[string]bool
 }
 
 // podIPTable: maps pod name with pod IP address
@@ -88,6 +89,7 @@ func PopulateMappingTables(tcpDump []string, pod corev1.Pod, process Process, co
 		srcName, dstName := podIPTable[srcIP], podIPTable[dstIP]
 		updatePodInteractions(srcName, dstName, interactions)
 		updatePodProcessInteractions(procXID, dstName, interactions)
+		updatePodConnections(address, interactions)
 	}
 }
 
@@ -106,6 +108,16 @@ func updatePodInteractions(srcName, dstName string, interactions *InteractionsWr
 	}
 }
 
+// updatePodConnections records the connection once as processes of a pod share its network namespace
+func updatePodConnections(address []string, interactions *InteractionsWrapper) {
+	if interactions.PodConnections == nil {
+		return
+	}
+	if connection := getConnection(address); connection != "" {
+		interactions.PodConnections[connection] = true
+	}
+}
+
 // UpdatePodToPodTable ...
 func UpdatePodToPodTable(podInteractions map[string](map[string]float64)) {
 	mu.Lock()
@@ -36,6 +36,7 @@ func processContainerDetails(conf controller.Config, pod corev1.Pod, containers
 		PodInteractions:             make(map[string](map[string]float64)),
 		ProcessToPodInteraction:     make(map[string](map[string]bool)),
 		ContainerProcessInteraction: make(map[string][]string),
+		PodConnections:              make(map[string]bool),
 	}
 	for _, container := range containers {
 		pidList, cmdList := getPIDList(conf, pod, container.Name)
@@ -47,6 +48,18 @@ func processContainerDetails(conf controller.Config, pod corev1.Pod, containers
 	return interactions
 }
 
+// getBytesAcked returns bytes sent on each connection of the pod using ss from the first container that has it,
+// containers share the network namespace of the pod so one of them is enough
+func getBytesAcked(conf controller.Config, pod corev1.Pod, containers []corev1.Container) map[string]float64 {
+	for _, container := range containers {
+		output, err := executeCommandInPod(conf, pod, "ss -tin", container.Name)
+		if err == nil {
+			return linker.ParseSocketStatistics(output)
+		}
+	}
+	return nil
+}
+
 func getPIDList(conf controller.Config, pod corev1.Pod, containerName string) ([]string, []string) {
 	command := "ps -A -o pid,cmd"
 	output, err := executeCommandInPod(conf, pod, command, containerName)
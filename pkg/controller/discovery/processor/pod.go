@@ -44,10 +44,13 @@ func ProcessPodInteractions(conf controller.Config) {
 	}
 
 	linker.PopulatePodIPTable(k8sPods)
+	k8sNodes := utils.RetrieveNodeList(conf.Kubeclient, metav1.ListOptions{})
+	linker.PopulateTopologyTables(k8sPods, k8sNodes, conf.Discovery.IPRanges)
 	processPodDetails(conf, k8sPods)
 
 	linker.GenerateAndStorePodInteractions()
 	log.Infof("Successfully generated Pod To Pod mapping.")
+	linker.StorePodsEgress()
 }
 
 func processPodDetails(conf controller.Config, pods *corev1.PodList) {
@@ -78,6 +81,9 @@ func processPodDetails(conf controller.Config, pods *corev1.PodList) {
 				containers := pod.Spec.Containers
 				interactions := processContainerDetails(conf, pod, containers)
 				linker.UpdatePodToPodTable(interactions.PodInteractions)
+				if len(interactions.PodConnections) > 0 {
+					linker.UpdatePodTraffic(pod, interactions.PodConnections, getBytesAcked(conf, pod, containers))
+				}
 				linker.StoreProcessInteractions(interactions.ContainerProcessInteraction, interactions.ProcessToPodInteraction,
 					pod.GetCreationTimestamp().Time)
 				log.Debugf("Finished processing Pod: (%s), (%d/%d)", pod.Name, index+1, podsCount)
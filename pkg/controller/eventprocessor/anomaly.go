@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eventprocessor
+
+import (
+	"encoding/json"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+	"github.com/vmware/purser/pkg/controller/utils"
+
+	groupsClient_v1 "github.com/vmware/purser/pkg/client/clientset/typed/groups/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Constants of cost anomaly notifications
+const (
+	CostAnomalyResourceType = "CostAnomaly"
+	AnomalyDetectedEvent    = "anomalyDetected"
+)
+
+// DetectCostAnomalies compares cost of namespaces and groups over the last hour with their baseline, stores anomalies
+// in dgraph and notifies subscribers of them
+func DetectCostAnomalies(groupCRDClient *groupsClient_v1.GroupClient) {
+	hourStart := utils.GetCurrentHourStartTime().Add(-time.Hour)
+	log.Infof("Started detecting cost anomalies of hour: %v", hourStart)
+
+	groupWorkloads := map[string]map[string]bool{}
+	groups := utils.RetrieveGroupList(groupCRDClient, meta_v1.ListOptions{})
+	if groups != nil {
+		for _, group := range groups.Items {
+			workloads, err := query.RetrievePodsWorkloads(getUIDQueryForGroupPods(group))
+			if err != nil {
+				log.Errorf("unable to retrieve workloads of group: %s, err: %v", group.Name, err)
+				continue
+			}
+			groupWorkloads[group.Name] = workloads
+		}
+	}
+
+	anomalies, err := query.DetectCostAnomalies(hourStart, groupWorkloads)
+	if err != nil {
+		log.Errorf("unable to detect cost anomalies, err: %v", err)
+		return
+	}
+
+	var payloads []*interface{}
+	for _, anomaly := range anomalies {
+		isStored, err := models.StoreCostAnomaly(query.NewCostAnomalyModel(anomaly))
+		if err != nil {
+			log.Errorf("unable to store cost anomaly of %s: %s, err: %v", anomaly.Kind, anomaly.Name, err)
+			continue
+		}
+		if isStored {
+			payloads = append(payloads, newAnomalyPayload(anomaly))
+		}
+	}
+
+	if len(payloads) > 0 {
+		log.Infof("Notifying subscribers of %d cost anomalies", len(payloads))
+		NotifySubscribers(payloads)
+	}
+}
+
+func newAnomalyPayload(anomaly query.CostAnomaly) *interface{} {
+	data, err := json.Marshal(anomaly)
+	if err != nil {
+		log.Errorf("unable to marshal cost anomaly of %s: %s, err: %v", anomaly.Kind, anomaly.Name, err)
+	}
+	var payload interface{} = &controller.Payload{
+		Key:          anomaly.Kind + "/" + anomaly.Name,
+		EventType:    AnomalyDetectedEvent,
+		ResourceType: CostAnomalyResourceType,
+		Data:         string(data),
+		CaptureTime:  meta_v1.NewTime(time.Now()),
+	}
+	return &payload
+}
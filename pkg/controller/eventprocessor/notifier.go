@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
 	"net/http"
 	"time"
 
@@ -37,6 +38,16 @@ type notifier struct {
 	headers map[string]string
 }
 
+// NotifySubscribers sends payloads to all live subscribers
+func NotifySubscribers(payload []*interface{}) {
+	subscribers, err := query.RetrieveSubscribers()
+	if err != nil {
+		log.Errorf("unable to retrieve subscribers from dgraph: %v", err)
+		return
+	}
+	notifySubscribers(payload, subscribers)
+}
+
 func notifySubscribers(payload []*interface{}, subscribers []models.SubscriberCRD) {
 	notifiers := getNotifiers(subscribers)
 
@@ -21,19 +21,22 @@ import (
 	"encoding/json"
 	"time"
 
-	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
-
 	log "github.com/Sirupsen/logrus"
 
 	groups_v1 "github.com/vmware/purser/pkg/apis/groups/v1"
+	ratecard_v1 "github.com/vmware/purser/pkg/apis/ratecard/v1"
 	subcriber_v1 "github.com/vmware/purser/pkg/apis/subscriber/v1"
 	"github.com/vmware/purser/pkg/controller"
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/pricing"
+	"github.com/vmware/purser/pkg/pricing/onprem"
 
 	apps_v1beta1 "k8s.io/api/apps/v1beta1"
 	batch_v1 "k8s.io/api/batch/v1"
+	batch_v1beta1 "k8s.io/api/batch/v1beta1"
 	api_v1 "k8s.io/api/core/v1"
 	ext_v1beta1 "k8s.io/api/extensions/v1beta1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ProcessEvents processes the event and notifies the subscribers.
@@ -52,12 +55,7 @@ func ProcessEvents(conf *controller.Config) {
 
 			ProcessPayloads(data, conf)
 
-			subscribers, err := query.RetrieveSubscribers()
-			if err == nil {
-				notifySubscribers(data, subscribers)
-			} else {
-				log.Errorf("unable to retrieve subscribers from dgraph: %v", err)
-			}
+			NotifySubscribers(data)
 
 			conf.RingBuffer.RemoveN(size)
 			conf.RingBuffer.PrintDetails()
@@ -122,6 +120,10 @@ func handlePayloadBasedOnResource(payload *controller.Payload, conf *controller.
 		job := batch_v1.Job{}
 		unmarshalPayload(payload, &job)
 		_, err = models.StoreJob(job)
+	case "CronJob":
+		cronJob := batch_v1beta1.CronJob{}
+		unmarshalPayload(payload, &cronJob)
+		_, err = models.StoreCronJob(cronJob)
 	case "Group":
 		groupCRD := &groups_v1.Group{}
 		unmarshalPayload(payload, &groupCRD)
@@ -130,6 +132,10 @@ func handlePayloadBasedOnResource(payload *controller.Payload, conf *controller.
 		subscriberCRD := subcriber_v1.Subscriber{}
 		unmarshalPayload(payload, &subscriberCRD)
 		_, err = models.StoreSubscriberCRD(subscriberCRD)
+	case "RateCard":
+		rateCardCRD := &ratecard_v1.RateCard{}
+		unmarshalPayload(payload, rateCardCRD)
+		handlePayloadForRateCard(payload, conf, rateCardCRD)
 	}
 	checkDgraphError(payload.ResourceType, err)
 }
@@ -159,3 +165,48 @@ func handlePayloadForGroup(payload *controller.Payload, conf *controller.Config,
 		}
 	}
 }
+
+// handlePayloadForRateCard applies prices of the oldest RateCard custom resource, rate cards created after it are
+// rejected. Prices of the cloud provider are restored once every RateCard is deleted.
+func handlePayloadForRateCard(payload *controller.Payload, conf *controller.Config, rateCardCRD *ratecard_v1.RateCard) {
+	rateCards, err := conf.RateCardclient.List(meta_v1.ListOptions{})
+	if err != nil {
+		log.Errorf("Unable to list RateCard custom resources: (%v)", err)
+		return
+	}
+	activeRateCard := onprem.SelectRateCard(rateCards.Items)
+	if activeRateCard == nil {
+		log.Infof("RateCard: %s deleted, restoring prices of the cloud provider", rateCardCRD.Name)
+		onprem.DeleteRateCard()
+		pricing.NewCloud(conf).RestoreRateCard()
+		return
+	}
+
+	if activeRateCard.Name != rateCardCRD.Name {
+		if payload.EventType == controller.Delete {
+			log.Infof("RateCard: %s deleted, prices of RateCard: %s are applied", rateCardCRD.Name, activeRateCard.Name)
+		} else {
+			log.Warnf("RateCard: %s rejected, prices of RateCard: %s are already applied", rateCardCRD.Name, activeRateCard.Name)
+			message := "prices of RateCard: " + activeRateCard.Name + " are already applied, only one RateCard is applied at a time"
+			updateRateCardStatus(conf, rateCardCRD, ratecard_v1.RejectedState, message)
+			return
+		}
+	}
+	updateRateCardStatus(conf, activeRateCard, ratecard_v1.ActiveState, "")
+	onprem.StoreRateCard(activeRateCard)
+	pricing.UpdateNodePrices(conf.Kubeclient)
+	pricing.UpdateStoragePrices(conf.Kubeclient)
+	pricing.UpdateServicePrices(conf.Kubeclient)
+}
+
+// updateRateCardStatus updates status of the rate card only when it changes so that the update isn't processed again
+func updateRateCardStatus(conf *controller.Config, rateCardCRD *ratecard_v1.RateCard, state, message string) {
+	if rateCardCRD.Status.State == state && rateCardCRD.Status.Message == message {
+		return
+	}
+	rateCardCRD.Status = ratecard_v1.RateCardStatus{State: state, Message: message}
+	_, err := conf.RateCardclient.Update(rateCardCRD)
+	if err != nil {
+		log.Errorf("Unable to update status of RateCard: %s, (%v)", rateCardCRD.Name, err)
+	}
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package eventprocessor
+
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/dgraph/models/query"
+	"github.com/vmware/purser/pkg/controller/utils"
+
+	groupsClient_v1 "github.com/vmware/purser/pkg/client/clientset/typed/groups/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TakeCostSnapshots stores allocation and cost of namespaces, workloads, nodes and groups over the last hour
+func TakeCostSnapshots(groupCRDClient *groupsClient_v1.GroupClient) {
+	hourStart := utils.GetCurrentHourStartTime().Add(-time.Hour)
+	log.Infof("Started taking cost snapshots of hour: %v", hourStart)
+	snapshots := query.RetrieveCostSnapshots(hourStart)
+
+	groups := utils.RetrieveGroupList(groupCRDClient, meta_v1.ListOptions{})
+	if groups != nil {
+		window := query.Window{Start: hourStart, End: hourStart.Add(time.Hour)}
+		for _, group := range groups.Items {
+			groupCost, err := RetrieveGroupCost(group, window)
+			if err != nil {
+				log.Errorf("unable to retrieve cost of group: %s for snapshot, err: %v", group.Name, err)
+				continue
+			}
+			snapshots = append(snapshots, query.NewGroupCostSnapshot(groupCost, hourStart))
+		}
+	}
+
+	models.StoreCostSnapshots(snapshots)
+	log.Infof("Stored %d cost snapshots", len(snapshots))
+}
@@ -18,6 +18,7 @@
 package eventprocessor
 
 import (
+	"strings"
 	"time"
 
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
@@ -41,13 +42,27 @@ func UpdateGroups(groupCRDClient *groupsClient_v1.GroupClient) {
 		return
 	}
 	log.Debugf("Retrieved groups of length: %d", len(groups.Items))
+	idleCost := retrieveIdleCost()
 	for _, group := range groups.Items {
-		UpdateGroup(group, groupCRDClient)
+		updateGroup(group, groupCRDClient, idleCost)
 	}
 }
 
 // UpdateGroup given a group it updates its spec with metrics
 func UpdateGroup(group *groups_v1.Group, groupCRDClient *groupsClient_v1.GroupClient) {
+	updateGroup(group, groupCRDClient, retrieveIdleCost())
+}
+
+func retrieveIdleCost() query.IdleCost {
+	idleCost, err := query.RetrieveIdleCost(query.Window{})
+	if err != nil {
+		log.Errorf("unable to retrieve idle cost, err: %v", err)
+	}
+	return idleCost
+}
+
+// updateGroup updates spec of the group with metrics and its share of idle cost
+func updateGroup(group *groups_v1.Group, groupCRDClient *groupsClient_v1.GroupClient, idleCost query.IdleCost) {
 	if group == nil {
 		log.Warn("Received empty group to update")
 		return
@@ -71,6 +86,12 @@ func UpdateGroup(group *groups_v1.Group, groupCRDClient *groupsClient_v1.GroupCl
 		StorageCost: groupMetrics.CostStorage,
 		TotalCost:   groupMetrics.CostCPU + groupMetrics.CostMemory + groupMetrics.CostStorage,
 	}
+	idleCPUCost, idleMemoryCost := idleCost.GetShare(groupMetrics.CostCPU, groupMetrics.CostMemory)
+	group.Spec.MTDIdleCost = &groups_v1.Cost{
+		CPUCost:    idleCPUCost,
+		MemoryCost: idleMemoryCost,
+		TotalCost:  idleCPUCost + idleMemoryCost,
+	}
 	group.Spec.PerHourCost = &groups_v1.Cost{
 		CPUCost:     groupMetrics.CostCPUPerHour,
 		MemoryCost:  groupMetrics.CostMemoryPerHour,
@@ -89,6 +110,7 @@ func UpdateGroup(group *groups_v1.Group, groupCRDClient *groupsClient_v1.GroupCl
 		StorageCost: groupMetrics.LastLastMonthStorageCost,
 		TotalCost:   groupMetrics.LastLastMonthCPUCost + groupMetrics.LastLastMonthMemoryCost + groupMetrics.LastLastMonthStorageCost,
 	}
+	group.Spec.ProjectedCost = getGroupProjectedCost(group.Name)
 	group.Spec.LastUpdated = time.Now()
 
 	_, err := groupCRDClient.Update(group)
@@ -106,6 +128,59 @@ func UpdateGroup(group *groups_v1.Group, groupCRDClient *groupsClient_v1.GroupCl
 }
 
 func getGroupMetrics(group *groups_v1.Group) query.GroupMetrics {
+	uidQueryForPods := getUIDQueryForGroupPods(group)
+
+	// get group metrics
+	groupMetrics, err := query.RetrieveGroupMetricsFromPodUIDs(uidQueryForPods)
+	if err != nil {
+		log.Errorf("Unable to retrieve group metrics, group: %v, UIDs: (%v)", group.Name, uidQueryForPods)
+		return query.GroupMetrics{}
+	}
+	return groupMetrics
+}
+
+// getGroupProjectedCost returns forecast of cost of the group from its cost snapshots, nil if it has no snapshots yet
+func getGroupProjectedCost(groupName string) *groups_v1.ProjectedCost {
+	forecasts, err := query.RetrieveCostForecasts(query.GroupType, groupName)
+	if err != nil {
+		log.Errorf("unable to retrieve cost forecast of group: (%s), error: (%v)", groupName, err)
+		return nil
+	}
+	if len(forecasts) == 0 {
+		return nil
+	}
+	forecast := forecasts[0]
+	return &groups_v1.ProjectedCost{
+		EndOfMonth:      forecast.EndOfMonth.Cost,
+		EndOfMonthLower: forecast.EndOfMonth.Lower,
+		EndOfMonthUpper: forecast.EndOfMonth.Upper,
+		Next30Days:      forecast.Next30Days.Cost,
+		Next30DaysLower: forecast.Next30Days.Lower,
+		Next30DaysUpper: forecast.Next30Days.Upper,
+	}
+}
+
+// RetrieveGroupCost returns cost of the group in the window
+func RetrieveGroupCost(group *groups_v1.Group, window query.Window) (query.GroupCost, error) {
+	return query.RetrieveGroupCostFromPodUIDs(group.Name, getUIDQueryForGroupPods(group), window)
+}
+
+// RetrieveGroupEgress returns egress of the group in the window
+func RetrieveGroupEgress(group *groups_v1.Group, window query.Window) (query.EgressCost, error) {
+	return query.RetrieveEgressCostFromPodUIDs(group.Name, getUIDQueryForGroupPods(group), window)
+}
+
+// RetrieveGroupPodsUIDs returns uids of pods satisfying all the expressions of the group
+func RetrieveGroupPodsUIDs(group *groups_v1.Group) []string {
+	uidQueryForPods := getUIDQueryForGroupPods(group)
+	if uidQueryForPods == "" {
+		return nil
+	}
+	return strings.Split(uidQueryForPods, ", ")
+}
+
+// getUIDQueryForGroupPods returns uid-query(i.e, "uid1, uid2, uid2...") of pods satisfying all the expressions of the group
+func getUIDQueryForGroupPods(group *groups_v1.Group) string {
 	log.Debugf("Group: (%v), expressions: (%v)", group.Name, group.Spec.Expressions)
 
 	// for each label-expression retrieve UIDs of pods that satisfy the label-expression
@@ -120,14 +195,7 @@ func getGroupMetrics(group *groups_v1.Group) query.GroupMetrics {
 	// get uid-query to retrieve such pods i.e, "uid1, uid2, uid2..."
 	uidQueryForPods := getUIDQueryForPods(podUIDsCounter, len(group.Spec.Expressions))
 	log.Debugf("Group: (%v), uidQuery: (%v)", group.Name, uidQueryForPods)
-
-	// get group metrics
-	groupMetrics, err := query.RetrieveGroupMetricsFromPodUIDs(uidQueryForPods)
-	if err != nil {
-		log.Errorf("Unable to retrieve group metrics, group: %v, UIDs: (%v)", group.Name, uidQueryForPods)
-		return query.GroupMetrics{}
-	}
-	return groupMetrics
+	return uidQueryForPods
 }
 
 // for each label-expression retrieve UIDs of pods that satisfy the label-expression
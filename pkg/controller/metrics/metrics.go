@@ -18,17 +18,26 @@
 package metrics
 
 import (
+	"strings"
+
 	log "github.com/Sirupsen/logrus"
 	api_v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
-// Metrics types
+// gpuResourceSuffix suffix of extended resources advertised by gpu device plugins(ex: nvidia.com/gpu, amd.com/gpu)
+const gpuResourceSuffix = "/gpu"
+
+// Metrics types, GPU limit and request count gpus of every vendor which are also part of extended resources
 type Metrics struct {
-	CPULimit      *resource.Quantity
-	MemoryLimit   *resource.Quantity
-	CPURequest    *resource.Quantity
-	MemoryRequest *resource.Quantity
+	CPULimit                 *resource.Quantity
+	MemoryLimit              *resource.Quantity
+	CPURequest               *resource.Quantity
+	MemoryRequest            *resource.Quantity
+	GPULimit                 *resource.Quantity
+	GPURequest               *resource.Quantity
+	ExtendedResourceLimits   api_v1.ResourceList
+	ExtendedResourceRequests api_v1.ResourceList
 }
 
 // CalculatePodStatsFromContainers returns the cumulative metrics from the containers.
@@ -37,27 +46,65 @@ func CalculatePodStatsFromContainers(pod *api_v1.Pod) *Metrics {
 	memoryLimit := &resource.Quantity{}
 	cpuRequest := &resource.Quantity{}
 	memoryRequest := &resource.Quantity{}
+	gpuLimit := &resource.Quantity{}
+	gpuRequest := &resource.Quantity{}
+	extendedResourceLimits := api_v1.ResourceList{}
+	extendedResourceRequests := api_v1.ResourceList{}
 	for _, c := range pod.Spec.Containers {
 		limits := c.Resources.Limits
 		if limits != nil {
 			cpuLimit.Add(*limits.Cpu())
 			memoryLimit.Add(*limits.Memory())
+			addExtendedResources(limits, extendedResourceLimits, gpuLimit)
 		}
 
 		requests := c.Resources.Requests
 		if requests != nil {
 			cpuRequest.Add(*requests.Cpu())
 			memoryRequest.Add(*requests.Memory())
+			addExtendedResources(requests, extendedResourceRequests, gpuRequest)
 		}
 	}
 	return &Metrics{
-		CPULimit:      cpuLimit,
-		MemoryLimit:   memoryLimit,
-		CPURequest:    cpuRequest,
-		MemoryRequest: memoryRequest,
+		CPULimit:                 cpuLimit,
+		MemoryLimit:              memoryLimit,
+		CPURequest:               cpuRequest,
+		MemoryRequest:            memoryRequest,
+		GPULimit:                 gpuLimit,
+		GPURequest:               gpuRequest,
+		ExtendedResourceLimits:   extendedResourceLimits,
+		ExtendedResourceRequests: extendedResourceRequests,
 	}
 }
 
+// addExtendedResources adds extended resources of a container to extendedResources and its gpus to gpus
+func addExtendedResources(resources, extendedResources api_v1.ResourceList, gpus *resource.Quantity) {
+	for name, quantity := range resources {
+		if !IsExtendedResource(name) {
+			continue
+		}
+		total := extendedResources[name]
+		total.Add(quantity)
+		extendedResources[name] = total
+		if IsGPUResource(name) {
+			gpus.Add(quantity)
+		}
+	}
+}
+
+// IsExtendedResource tells whether the resource is an extended resource(ex: advertised by a device plugin), resources
+// in kubernetes.io domain(ex: cpu, memory, hugepages) and resource quota names aren't extended resources
+func IsExtendedResource(name api_v1.ResourceName) bool {
+	resourceName := string(name)
+	return strings.Contains(resourceName, "/") && !strings.Contains(resourceName, "kubernetes.io/") &&
+		!strings.HasPrefix(resourceName, "requests.")
+}
+
+// IsGPUResource tells whether the resource is a gpu advertised by a device plugin
+func IsGPUResource(name api_v1.ResourceName) bool {
+	return IsExtendedResource(name) && strings.HasSuffix(string(name), gpuResourceSuffix)
+}
+
 // PrintPodStats displays the pod stats.
 func PrintPodStats(pod *api_v1.Pod, metrics *Metrics) {
 	log.Printf("Pod:\t%s\n", pod.Name)
@@ -65,4 +112,6 @@ func PrintPodStats(pod *api_v1.Pod, metrics *Metrics) {
 	log.Printf("\tMemory Limit = %s\n", metrics.MemoryLimit.String())
 	log.Printf("\tCPU Request = %s\n", metrics.CPURequest.String())
 	log.Printf("\tMemory Request = %s\n", metrics.MemoryRequest.String())
+	log.Printf("\tGPU Limit = %s\n", metrics.GPULimit.String())
+	log.Printf("\tGPU Request = %s\n", metrics.GPURequest.String())
 }
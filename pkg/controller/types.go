@@ -18,7 +18,10 @@
 package controller
 
 import (
+	budget_v1 "github.com/vmware/purser/pkg/client/clientset/typed/budget/v1"
 	groups_v1 "github.com/vmware/purser/pkg/client/clientset/typed/groups/v1"
+	ratecard_v1 "github.com/vmware/purser/pkg/client/clientset/typed/ratecard/v1"
+	sharedcostpolicy_v1 "github.com/vmware/purser/pkg/client/clientset/typed/sharedcostpolicy/v1"
 	subscriber_v1 "github.com/vmware/purser/pkg/client/clientset/typed/subscriber/v1"
 	"github.com/vmware/purser/pkg/controller/buffering"
 	"k8s.io/client-go/kubernetes"
@@ -43,10 +46,12 @@ type Resource struct {
 	StatefulSet           bool `json:"statefulset"`
 	Deployment            bool `json:"deployment"`
 	Job                   bool `json:"job"`
+	CronJob               bool `json:"cronjob"`
 	DaemonSet             bool `json:"daemonset"`
 	Namespace             bool `json:"namespace"`
 	Group                 bool `json:"groups.vmware.purser.com"`
 	Subscriber            bool `json:"subscribers.vmware.purser.com"`
+	RateCard              bool `json:"ratecards.vmware.purser.com"`
 }
 
 // Config contains config objects
@@ -56,5 +61,35 @@ type Config struct {
 	RingBuffer       *buffering.RingBuffer
 	Groupcrdclient   *groups_v1.GroupClient
 	Subscriberclient *subscriber_v1.SubscriberClient
+	RateCardclient   *ratecard_v1.RateCardClient
 	Kubeclient       *kubernetes.Clientset
+	Cloud            CloudConfig
+	Usage            UsageConfig
+	Discovery        DiscoveryConfig
+
+	SharedCostPolicyclient *sharedcostpolicy_v1.SharedCostPolicyClient
+	Budgetclient           *budget_v1.BudgetClient
+}
+
+// CloudConfig contains the cloud provider, region and zone to use when they can't be detected from nodes
+// along with the price list to use for providers whose prices aren't fetched over network and spot discounts
+// (instanceFamily=discount, comma separated) to use for price lists without spot prices
+type CloudConfig struct {
+	CloudProvider string
+	Region        string
+	Zone          string
+	PriceFile     string
+	SpotDiscounts string
+}
+
+// UsageConfig contains the source(metrics-server, prometheus) of cpu and memory usage of containers
+type UsageConfig struct {
+	Source        string
+	PrometheusURL string
+}
+
+// DiscoveryConfig contains IP ranges(cidr=trafficClass, comma separated) whose traffic class can't be derived
+// from nodes, ex: 10.20.0.0/16=crossRegion for a peered network in another region
+type DiscoveryConfig struct {
+	IPRanges string
 }
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage
+
+import (
+	"encoding/json"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/controller/utils"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+const podMetricsPath = "/apis/metrics.k8s.io/v1beta1/pods"
+
+// podMetricsList is the list of pod metrics served by metrics.k8s.io
+type podMetricsList struct {
+	Items []podMetrics `json:"items"`
+}
+
+type podMetrics struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Containers []struct {
+		Name  string            `json:"name"`
+		Usage map[string]string `json:"usage"`
+	} `json:"containers"`
+}
+
+// MetricsServer reads usage of containers from metrics.k8s.io api(ex: metrics-server)
+type MetricsServer struct {
+	fetch func() ([]byte, error)
+}
+
+// NewMetricsServer returns usage source reading pod metrics through the api server
+func NewMetricsServer(kubeclient *kubernetes.Clientset) *MetricsServer {
+	return &MetricsServer{
+		fetch: func() ([]byte, error) {
+			return kubeclient.CoreV1().RESTClient().Get().AbsPath(podMetricsPath).DoRaw()
+		},
+	}
+}
+
+// ContainerUsages returns cpu and memory used by every container of the cluster
+func (m *MetricsServer) ContainerUsages() ([]models.ContainerUsage, error) {
+	body, err := m.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return decodePodMetrics(body)
+}
+
+func decodePodMetrics(body []byte) ([]models.ContainerUsage, error) {
+	metricsList := podMetricsList{}
+	err := json.Unmarshal(body, &metricsList)
+	if err != nil {
+		return nil, err
+	}
+	var usages []models.ContainerUsage
+	for _, pod := range metricsList.Items {
+		for _, container := range pod.Containers {
+			usages = append(usages, models.ContainerUsage{
+				Namespace:   pod.Metadata.Namespace,
+				Pod:         pod.Metadata.Name,
+				Container:   container.Name,
+				CPUUsage:    parseQuantity(container.Usage["cpu"], utils.ConvertToFloat64CPU),
+				MemoryUsage: parseQuantity(container.Usage["memory"], utils.ConvertToFloat64GB),
+			})
+		}
+	}
+	return usages, nil
+}
+
+func parseQuantity(value string, convert func(*resource.Quantity) float64) float64 {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0
+	}
+	return convert(&quantity)
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+const testPodMetrics = `{"kind":"PodMetricsList","apiVersion":"metrics.k8s.io/v1beta1","items":[
+	{"metadata":{"name":"pod-1","namespace":"default"},"containers":[
+		{"name":"app","usage":{"cpu":"250m","memory":"512Mi"}},
+		{"name":"sidecar","usage":{"cpu":"50m","memory":"1Gi"}}]}]}`
+
+func TestMetricsServerContainerUsages(t *testing.T) {
+	source := &MetricsServer{fetch: func() ([]byte, error) {
+		return []byte(testPodMetrics), nil
+	}}
+
+	usages, err := source.ContainerUsages()
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ContainerUsage{
+		{Namespace: "default", Pod: "pod-1", Container: "app", CPUUsage: 0.25, MemoryUsage: 0.5},
+		{Namespace: "default", Pod: "pod-1", Container: "sidecar", CPUUsage: 0.05, MemoryUsage: 1},
+	}, usages)
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Queries of cadvisor metrics scraped by prometheus, usage of pause containers is excluded
+const (
+	cpuUsageQuery    = `sum(rate(container_cpu_usage_seconds_total{container!="",container!="POD"}[5m])) by (namespace,pod,container)`
+	memoryUsageQuery = `sum(container_memory_working_set_bytes{container!="",container!="POD"}) by (namespace,pod,container)`
+
+	prometheusQueryPath = "/api/v1/query"
+	prometheusTimeout   = 30 * time.Second
+	bytesInGB           = 1024.0 * 1024.0 * 1024.0
+)
+
+// prometheusResponse is the response of an instant query
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// Prometheus reads usage of containers from cadvisor metrics of a prometheus server
+type Prometheus struct {
+	url    string
+	client *http.Client
+}
+
+// NewPrometheus returns usage source querying the prometheus server at url
+func NewPrometheus(url string) *Prometheus {
+	return &Prometheus{
+		url:    strings.TrimRight(url, "/"),
+		client: &http.Client{Timeout: prometheusTimeout},
+	}
+}
+
+// ContainerUsages returns cpu and memory used by every container of the cluster
+func (p *Prometheus) ContainerUsages() ([]models.ContainerUsage, error) {
+	cpuUsages, err := p.query(cpuUsageQuery)
+	if err != nil {
+		return nil, err
+	}
+	memoryUsages, err := p.query(memoryUsageQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	var usages []models.ContainerUsage
+	for key, cpu := range cpuUsages {
+		usages = append(usages, models.ContainerUsage{
+			Namespace:   key.namespace,
+			Pod:         key.pod,
+			Container:   key.container,
+			CPUUsage:    cpu,
+			MemoryUsage: memoryUsages[key] / bytesInGB,
+		})
+	}
+	for key, memory := range memoryUsages {
+		if _, isPresent := cpuUsages[key]; !isPresent {
+			usages = append(usages, models.ContainerUsage{
+				Namespace:   key.namespace,
+				Pod:         key.pod,
+				Container:   key.container,
+				MemoryUsage: memory / bytesInGB,
+			})
+		}
+	}
+	return usages, nil
+}
+
+type containerKey struct {
+	namespace string
+	pod       string
+	container string
+}
+
+// query runs an instant query and returns value of each container
+func (p *Prometheus) query(query string) (map[containerKey]float64, error) {
+	resp, err := p.client.Get(p.url + prometheusQueryPath + "?query=" + url.QueryEscape(query))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	promResponse := prometheusResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&promResponse)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode prometheus response, status: %s, err: %v", resp.Status, err)
+	}
+	if promResponse.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", promResponse.Error)
+	}
+
+	values := map[containerKey]float64{}
+	for _, result := range promResponse.Data.Result {
+		if len(result.Value) != 2 {
+			continue
+		}
+		valueString, isString := result.Value[1].(string)
+		if !isString {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueString, 64)
+		if err != nil {
+			continue
+		}
+		key := containerKey{
+			namespace: result.Metric["namespace"],
+			pod:       result.Metric["pod"],
+			container: result.Metric["container"],
+		}
+		values[key] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+const (
+	testCPUResponse = `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"namespace":"default","pod":"pod-1","container":"app"},"value":[1546300800,"0.25"]},
+		{"metric":{"namespace":"default","pod":"pod-1","container":"sidecar"},"value":[1546300800,"0.05"]}]}}`
+	testMemoryResponse = `{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"namespace":"default","pod":"pod-1","container":"app"},"value":[1546300800,"536870912"]},
+		{"metric":{"namespace":"default","pod":"pod-2","container":"app"},"value":[1546300800,"1073741824"]}]}}`
+)
+
+func newFakePrometheus(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, prometheusQueryPath, r.URL.Path)
+		switch r.URL.Query().Get("query") {
+		case cpuUsageQuery:
+			fmt.Fprint(w, testCPUResponse)
+		case memoryUsageQuery:
+			fmt.Fprint(w, testMemoryResponse)
+		default:
+			fmt.Fprint(w, `{"status":"error","error":"unknown query"}`)
+		}
+	}))
+}
+
+func TestPrometheusContainerUsages(t *testing.T) {
+	server := newFakePrometheus(t)
+	defer server.Close()
+
+	usages, err := NewPrometheus(server.URL + "/").ContainerUsages()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.ContainerUsage{
+		{Namespace: "default", Pod: "pod-1", Container: "app", CPUUsage: 0.25, MemoryUsage: 0.5},
+		{Namespace: "default", Pod: "pod-1", Container: "sidecar", CPUUsage: 0.05},
+		{Namespace: "default", Pod: "pod-2", Container: "app", MemoryUsage: 1},
+	}, usages)
+}
+
+func TestPrometheusQueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"error","error":"bad query"}`)
+	}))
+	defer server.Close()
+
+	_, err := NewPrometheus(server.URL).ContainerUsages()
+	assert.EqualError(t, err, "prometheus query failed: bad query")
+}
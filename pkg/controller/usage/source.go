@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package usage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Kinds of usage sources
+const (
+	MetricsServerSource = "metrics-server"
+	PrometheusSource    = "prometheus"
+	DisabledSource      = "disable"
+)
+
+// Source samples cpu and memory used by containers of the cluster
+type Source interface {
+	ContainerUsages() ([]models.ContainerUsage, error)
+}
+
+// NewSource returns the usage source of given kind, prometheusURL is required for prometheus source
+func NewSource(kind string, kubeclient *kubernetes.Clientset, prometheusURL string) (Source, error) {
+	switch kind {
+	case MetricsServerSource:
+		return NewMetricsServer(kubeclient), nil
+	case PrometheusSource:
+		if prometheusURL == "" {
+			return nil, fmt.Errorf("prometheus url is required for usage source: %s", kind)
+		}
+		return NewPrometheus(prometheusURL), nil
+	}
+	return nil, fmt.Errorf("unknown usage source: %s", kind)
+}
+
+// Collect samples usage of containers from the source and stores it in dgraph
+func Collect(source Source) {
+	sampleTime := time.Now()
+	usages, err := source.ContainerUsages()
+	if err != nil {
+		logrus.Errorf("unable to collect usage of containers: %v", err)
+		return
+	}
+	logrus.Debugf("collected usage of %d containers", len(usages))
+	models.StoreContainerUsages(usages, sampleTime)
+}
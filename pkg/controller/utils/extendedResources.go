@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"github.com/vmware/purser/pkg/controller/metrics"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// GetExtendedResources returns quantities of extended resources in the resource list by resource name
+func GetExtendedResources(resources api_v1.ResourceList) map[string]float64 {
+	extendedResources := make(map[string]float64)
+	for name, quantity := range resources {
+		if metrics.IsExtendedResource(name) {
+			extendedResources[string(name)] = resourceToFloat64(&quantity)
+		}
+	}
+	return extendedResources
+}
+
+// GetGPUCount returns number of gpus(of every vendor) in the resource list
+func GetGPUCount(resources api_v1.ResourceList) float64 {
+	count := 0.0
+	for name, quantity := range resources {
+		if metrics.IsGPUResource(name) {
+			count += resourceToFloat64(&quantity)
+		}
+	}
+	return count
+}
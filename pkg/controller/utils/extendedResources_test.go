@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/vmware/purser/test/utils"
+	api_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func getTestResourceList() api_v1.ResourceList {
+	return api_v1.ResourceList{
+		api_v1.ResourceCPU:              resource.MustParse("4"),
+		api_v1.ResourceMemory:           resource.MustParse("8Gi"),
+		"hugepages-2Mi":                 resource.MustParse("1Gi"),
+		"nvidia.com/gpu":                resource.MustParse("2"),
+		"amd.com/gpu":                   resource.MustParse("1"),
+		"xilinx.com/fpga-xilinx_u200":   resource.MustParse("1"),
+		"example.kubernetes.io/special": resource.MustParse("3"),
+		"requests.nvidia.com/gpu":       resource.MustParse("4"),
+	}
+}
+
+func TestGetExtendedResources(t *testing.T) {
+	extendedResources := GetExtendedResources(getTestResourceList())
+	utils.Equals(t, map[string]float64{
+		"nvidia.com/gpu":              2,
+		"amd.com/gpu":                 1,
+		"xilinx.com/fpga-xilinx_u200": 1,
+	}, extendedResources)
+}
+
+func TestGetGPUCount(t *testing.T) {
+	utils.Equals(t, 3.0, GetGPUCount(getTestResourceList()))
+	utils.Equals(t, 0.0, GetGPUCount(nil))
+}
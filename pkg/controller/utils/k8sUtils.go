@@ -55,6 +55,16 @@ func RetrieveNodeList(client *kubernetes.Clientset, options metav1.ListOptions)
 	return nodes
 }
 
+// RetrievePersistentVolumeList returns list of persistent volumes
+func RetrievePersistentVolumeList(client *kubernetes.Clientset, options metav1.ListOptions) *corev1.PersistentVolumeList {
+	pvs, err := client.CoreV1().PersistentVolumes().List(options)
+	if err != nil {
+		log.Errorf("failed to retrieve persistent volumes: %v", err)
+		return nil
+	}
+	return pvs
+}
+
 // RetrieveServiceList returns list of services in the given namespace.
 func RetrieveServiceList(client *kubernetes.Clientset, options metav1.ListOptions) *corev1.ServiceList {
 	services, err := client.CoreV1().Services(metav1.NamespaceAll).List(options)
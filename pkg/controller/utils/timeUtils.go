@@ -42,3 +42,11 @@ func GetHoursRemainingInCurrentMonth() float64 {
 	monthEnd := time.Date(now.Year(), now.Month(), 30, 23, 59, 0, 0, time.Local)
 	return -time.Since(monthEnd).Hours()
 }
+
+// CostSnapshotRetention is the duration for which hourly cost snapshots are kept
+const CostSnapshotRetention = 366 * 24 * time.Hour
+
+// GetCurrentHourStartTime returns start time of the current hour
+func GetCurrentHourStartTime() time.Time {
+	return time.Now().Truncate(time.Hour)
+}
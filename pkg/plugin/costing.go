@@ -100,6 +100,16 @@ func GetClusterSummary() {
 	fmt.Printf("\t\t%s\t\t%.2f\n", "Compute cost($):", computeCost)
 	fmt.Printf("\t\t%s\t\t%.2f\n", "Storage cost($):", storageCost)
 	fmt.Printf("\t\t%s\t\t\t%.2f\n", "Total cost($):", computeCost+storageCost)
+
+	fmt.Println()
+	fmt.Printf("\tProjected Cost:\n")
+	forecast, err := getClusterForecast()
+	if err != nil {
+		fmt.Printf("\t\tUnable to get forecast from purser controller: %v\n", err)
+		return
+	}
+	printProjection("End of month($):", forecast.EndOfMonth)
+	printProjection("Next 30 days($):", forecast.Next30Days)
 }
 
 // GetSavings returns the savings summary.
@@ -119,6 +129,14 @@ func GetSavings() {
 	fmt.Printf("   %-30s   %.2f\n", "Unused Capacity(GB):", bytesToGB(storageCapacity-pvcCapacity))
 	fmt.Printf("   %-30s   %.2f\n", "Month To Date Savings($):", mtdSaving)
 	fmt.Printf("   %-30s   %.2f\n", "Projected Monthly Savings($):", projectedSaving)
+
+	fmt.Printf("Rightsizing:\n")
+	recommendations, err := getRecommendations()
+	if err != nil {
+		fmt.Printf("   Unable to get recommendations from purser controller: %v\n", err)
+		return
+	}
+	printRecommendations(recommendations)
 }
 
 // GetPodCost returns the cumulative cost for the pods.
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const forecastPath = "/api/forecast"
+
+type costProjection struct {
+	Cost  float64 `json:"cost"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+type costForecast struct {
+	Name       string         `json:"name"`
+	EndOfMonth costProjection `json:"endOfMonth"`
+	Next30Days costProjection `json:"next30Days"`
+}
+
+// getClusterForecast fetches forecast of cost of the cluster from the purser controller.
+func getClusterForecast() (*costForecast, error) {
+	response, err := ClientSetInstance.CoreV1().Services(controllerNamespace).
+		ProxyGet("http", controllerService, controllerPort, forecastPath, map[string]string{"kind": "cluster"}).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	forecasts := []costForecast{}
+	err = json.Unmarshal(response, &forecasts)
+	if err != nil {
+		return nil, err
+	}
+	if len(forecasts) == 0 {
+		return nil, fmt.Errorf("no cost history of the cluster yet")
+	}
+	return &forecasts[0], nil
+}
+
+func printProjection(name string, projection costProjection) {
+	fmt.Printf("\t\t%-24s%.2f (%.2f - %.2f)\n", name, projection.Cost, projection.Lower, projection.Upper)
+}
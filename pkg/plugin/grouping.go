@@ -72,6 +72,15 @@ func PrintGroup(group *groups_v1.Group) {
 		fmt.Printf("             %-30s%.2f\n", "Total Cost($):", cost.TotalCost)
 	}
 
+	if projectedCost := group.Spec.ProjectedCost; projectedCost != nil {
+		fmt.Println()
+		fmt.Printf("%-30s\n", "Projected Cost Stats:")
+		fmt.Printf("             %-30s%.2f (%.2f - %.2f)\n", "End of Month Cost($):", projectedCost.EndOfMonth,
+			projectedCost.EndOfMonthLower, projectedCost.EndOfMonthUpper)
+		fmt.Printf("             %-30s%.2f (%.2f - %.2f)\n", "Next 30 Days Cost($):", projectedCost.Next30Days,
+			projectedCost.Next30DaysLower, projectedCost.Next30DaysUpper)
+	}
+
 	fmt.Println()
 	fmt.Printf("Last updated %f minutes ago", time.Since(group.Spec.LastUpdated).Minutes())
 	fmt.Println()
@@ -0,0 +1,80 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// purser controller serves recommendations through its service
+const (
+	controllerNamespace = "purser"
+	controllerService   = "purser"
+	controllerPort      = "3030"
+	recommendationsPath = "/api/recommendations"
+)
+
+type containerRecommendation struct {
+	Name                     string  `json:"name"`
+	CPURequest               float64 `json:"cpuRequest"`
+	MemoryRequest            float64 `json:"memoryRequest"`
+	RecommendedCPURequest    float64 `json:"recommendedCpuRequest"`
+	RecommendedMemoryRequest float64 `json:"recommendedMemoryRequest"`
+	MonthlySavings           float64 `json:"monthlySavings"`
+	LowConfidence            bool    `json:"lowConfidence"`
+}
+
+type workloadRecommendation struct {
+	Kind           string                    `json:"kind"`
+	Name           string                    `json:"name"`
+	Replicas       int                       `json:"replicas"`
+	Containers     []containerRecommendation `json:"containers"`
+	MonthlySavings float64                   `json:"monthlySavings"`
+}
+
+// getRecommendations fetches rightsizing recommendations of workloads from the purser controller.
+func getRecommendations() ([]workloadRecommendation, error) {
+	response, err := ClientSetInstance.CoreV1().Services(controllerNamespace).
+		ProxyGet("http", controllerService, controllerPort, recommendationsPath, nil).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	recommendations := []workloadRecommendation{}
+	err = json.Unmarshal(response, &recommendations)
+	return recommendations, err
+}
+
+func printRecommendations(recommendations []workloadRecommendation) {
+	totalSavings := 0.0
+	for _, workload := range recommendations {
+		fmt.Printf("   %s %s (replicas: %d)\n", workload.Kind, workload.Name, workload.Replicas)
+		for _, container := range workload.Containers {
+			confidence := ""
+			if container.LowConfidence {
+				confidence = "   (low confidence, too few samples)"
+			}
+			fmt.Printf("      %-27s   cpu: %.2f -> %.2f   memory(GB): %.2f -> %.2f   savings($): %.2f%s\n", container.Name,
+				container.CPURequest, container.RecommendedCPURequest,
+				container.MemoryRequest, container.RecommendedMemoryRequest, container.MonthlySavings, confidence)
+		}
+		totalSavings += workload.MonthlySavings
+	}
+	fmt.Printf("   %-30s   %d\n", "Workloads:", len(recommendations))
+	fmt.Printf("   %-30s   %.2f\n", "Projected Monthly Savings($):", totalSavings)
+}
@@ -73,9 +73,12 @@ func totalHoursTillNow() float64 {
 	return currentMonthActiveTimeInHours(monthStart, currentTime)
 }
 
+// projectToMonth scales cost till now to the whole current month, it suits costs accruing at a steady rate(ex: unused
+// volumes). Forecasts of cost from its history are served by the purser controller.
 func projectToMonth(val float64) float64 {
-	// TODO: enhance this.
-	return (val * 31 * 24) / totalHoursTillNow()
+	monthStart := getCurrentMonthStartTime()
+	hoursInMonth := monthStart.AddDate(0, 1, 0).Sub(monthStart.Time).Hours()
+	return (val * hoursInMonth) / totalHoursTillNow()
 }
 
 func bytesToGB(val int64) float64 {
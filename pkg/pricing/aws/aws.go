@@ -18,11 +18,13 @@
 package aws
 
 import (
+	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/Sirupsen/logrus"
-	"github.com/vmware/purser/pkg/controller/utils"
 )
 
 const (
@@ -31,6 +33,7 @@ const (
 
 // Pricing structure
 type Pricing struct {
+	Version  string
 	Products map[string]Product
 	Terms    PlanList
 }
@@ -38,16 +41,20 @@ type Pricing struct {
 // PlanList structure
 type PlanList struct {
 	OnDemand map[string]map[string]TermAttributes
+	Reserved map[string]map[string]TermAttributes
 }
 
 // TermAttributes structure
 type TermAttributes struct {
 	PriceDimensions map[string]PricingData
+	// TermAttributes of a reservation(ex: LeaseContractLength: 1yr, OfferingClass: standard, PurchaseOption: No Upfront)
+	TermAttributes map[string]string
 }
 
 // PricingData structure
 type PricingData struct {
 	Unit         string
+	BeginRange   string
 	PricePerUnit map[string]string
 }
 
@@ -65,23 +72,66 @@ type ProductAttributes struct {
 	OperatingSystem string
 	PreInstalledSW  string
 	VolumeType      string
+	VolumeAPIName   string
 	UsageType       string
+	Group           string
 	Vcpu            string
 	Memory          string
+	GPU             string
+	RegionCode      string
+	Tenancy         string
+	CapacityStatus  string
+	FromRegionCode  string
+	TransferType    string
 }
 
 // GetAWSPricing function details
 // input: region
-// retrieves data from http get to the corresponding url for that region
+// streams the offer file of the region from http get to the corresponding url keeping only the products priced by purser
 func GetAWSPricing(region string) (*Pricing, error) {
 	var myClient = &http.Client{Timeout: httpTimeout}
-	rateCard := Pricing{}
-	err := utils.GetJSONResponse(myClient, getURLForRegion(region), &rateCard)
+	resp, err := myClient.Get(getURLForRegion(region))
 	if err != nil {
 		logrus.Errorf("Unable to get aws pricing. Reason: %v", err)
 		return nil, err
 	}
-	return &rateCard, nil
+	defer closeReader(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected status: %s", resp.Status)
+		logrus.Errorf("Unable to get aws pricing. Reason: %v", err)
+		return nil, err
+	}
+
+	rateCard, err := decodeAWSPricing(resp.Body, region)
+	if err != nil {
+		logrus.Errorf("Unable to parse aws pricing. Reason: %v", err)
+		return nil, err
+	}
+	return rateCard, nil
+}
+
+// GetAWSPricingFromFile streams a previously downloaded offer file keeping only the products of the region priced by purser
+func GetAWSPricingFromFile(path, region string) (*Pricing, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		logrus.Errorf("Unable to read aws pricing file: %s. Reason: %v", path, err)
+		return nil, err
+	}
+	defer closeReader(file)
+
+	rateCard, err := decodeAWSPricing(file, region)
+	if err != nil {
+		logrus.Errorf("Unable to parse aws pricing file: %s. Reason: %v", path, err)
+		return nil, err
+	}
+	return rateCard, nil
+}
+
+func closeReader(reader io.Closer) {
+	err := reader.Close()
+	if err != nil {
+		logrus.Errorf("unable to close aws pricing. Reason: %v", err)
+	}
 }
 
 func getURLForRegion(region string) string {
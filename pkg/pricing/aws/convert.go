@@ -18,6 +18,8 @@
 package aws
 
 import (
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -33,22 +35,65 @@ const (
 	deliminator     = "-"
 	storageInstance = "Storage"
 	computeInstance = "Compute Instance"
+	systemOperation = "System Operation"
+	ebsIOPSGroup    = "EBS IOPS"
+	iopsMonth       = "IOPS-Mo"
+	loadBalancer    = "Load Balancer"
+	networkLB       = "Load Balancer-Network"
+	natGateway      = "NAT Gateway"
+	ipAddress       = "IP Address"
+	dataTransfer    = "Data Transfer"
+	gb              = "GB"
 
-	// TODO: Determine priceSplitRatio according to instance type i.e, compute optimized or memory optimized etc
-	priceSplitRatio = 0.5
+	// usage type of public IPv4 addresses attached to a resource(ex: PublicIPv4:InUseAddress)
+	inUseAddressUsage = "InUseAddress"
+
+	hours               = "Hrs"
+	quantity            = "Quantity"
+	leaseContractLength = "LeaseContractLength"
+	offeringClass       = "OfferingClass"
+	purchaseOption      = "PurchaseOption"
+	oneYear             = "1yr"
+	threeYears          = "3yr"
+	standardOffering    = "standard"
+	noUpfront           = "No Upfront"
 )
 
-// GetRateCardForAWS takes region as input and returns RateCard and error if any
+// provisionedIOPSVolumeTypes volume types whose every provisioned IOPS is charged(gp3 IOPS above baseline are not priced)
+var provisionedIOPSVolumeTypes = map[string]bool{
+	"io1": true,
+	"io2": true,
+}
+
+// transferTrafficClasses maps transfer types of data transfer products to classes of traffic sent by pods
+var transferTrafficClasses = map[string]string{
+	"IntraRegion":          models.CrossZoneTraffic,
+	"InterRegion Outbound": models.CrossRegionTraffic,
+	"AWS Outbound":         models.InternetTraffic,
+}
+
+// leaseHours number of hours a reservation is paid for
+var leaseHours = map[string]float64{
+	oneYear:    12 * models.HoursInMonth,
+	threeYears: 36 * models.HoursInMonth,
+}
+
+// GetRateCardForAWS takes region as input and returns RateCard, nil in case of error
 func GetRateCardForAWS(region string) *models.RateCard {
-	awsPricing, err := GetAWSPricing(region)
-	if err == nil {
-		return convertAWSPricingToPurserRateCard(region, awsPricing)
+	provider := NewProvider("")
+	if err := provider.Fetch(region); err != nil {
+		return nil
+	}
+	rateCard, err := provider.RateCard(region)
+	if err != nil {
+		return nil
 	}
-	return nil
+	rateCard.Version = provider.Version()
+	return rateCard
 }
 
 func convertAWSPricingToPurserRateCard(region string, awsPricing *Pricing) *models.RateCard {
-	nodePrices, storagePrices := getResourcePricesFromAWSPricing(awsPricing)
+	nodePrices, storagePrices, networkPrices := getResourcePricesFromAWSPricing(awsPricing)
 	return &models.RateCard{
 		ID:            dgraph.ID{Xid: models.RateCardXID},
 		IsRateCard:    true,
@@ -56,26 +101,117 @@ func convertAWSPricingToPurserRateCard(region string, awsPricing *Pricing) *mode
 		Region:        region,
 		NodePrices:    nodePrices,
 		StoragePrices: storagePrices,
+		NetworkPrices: networkPrices,
 	}
 }
 
-func getResourcePricesFromAWSPricing(awsPricing *Pricing) ([]*models.NodePrice, []*models.StoragePrice) {
+// nolint: gocyclo
+func getResourcePricesFromAWSPricing(awsPricing *Pricing) ([]*models.NodePrice, []*models.StoragePrice, []*models.NetworkPrice) {
 	var nodePrices []*models.NodePrice
 	var storagePrices []*models.StoragePrice
+	networkPrices := make(map[string]*models.NetworkPrice)
 	products := awsPricing.Products
 	planList := awsPricing.Terms
 
+	split := getPriceSplit(products, planList)
 	duplicateComputeInstanceChecker := make(map[string]bool)
+	iopsPrices := make(map[string]float64)
 	for _, product := range products {
 		priceInFloat64, unit := getResourcePrice(product, planList)
 		switch product.ProductFamily {
 		case computeInstance:
-			nodePrices = updateComputeInstancePrices(product, priceInFloat64, duplicateComputeInstanceChecker, nodePrices)
+			reservedPrice := getReservedPrice(planList.Reserved[product.Sku])
+			nodePrices = updateComputeInstancePrices(product, priceInFloat64, reservedPrice, split, duplicateComputeInstanceChecker, nodePrices)
 		case storageInstance:
 			storagePrices = updateStorageInstancePrices(product, priceInFloat64, unit, storagePrices)
+		case systemOperation:
+			volumeType := getVolumeType(product)
+			if provisionedIOPSVolumeTypes[volumeType] {
+				iopsPrices[volumeType] = getIOPSPrice(planList.OnDemand[product.Sku])
+			}
+		case loadBalancer:
+			updateNetworkPrice(networkPrices, models.LoadBalancerNetworkResource, priceInFloat64, unit)
+		case networkLB:
+			updateNetworkPrice(networkPrices, models.NetworkLoadBalancerNetworkResource, priceInFloat64, unit)
+		case natGateway:
+			updateNetworkPrice(networkPrices, models.NATGatewayNetworkResource, priceInFloat64, unit)
+		case ipAddress:
+			updateNetworkPrice(networkPrices, models.PublicIPNetworkResource, priceInFloat64, unit)
+		case dataTransfer:
+			updateTransferPrice(networkPrices, transferTrafficClasses[product.Attributes.TransferType], planList.OnDemand[product.Sku])
+		}
+	}
+	for _, storagePrice := range storagePrices {
+		if iopsPrice, isPresent := iopsPrices[storagePrice.VolumeType]; isPresent && iopsPrice != models.PriceError {
+			storagePrice.IOPSPrice = iopsPrice
+		}
+	}
+	return nodePrices, storagePrices, getNetworkPrices(networkPrices)
+}
+
+// updateNetworkPrice sets hourly or per GB processed price of a network resource, a resource's hourly and data
+// processing charges are separate products of the offer file. The highest price is kept when several products of
+// the resource have the same unit so that the price doesn't depend on the order of products. Capacity units(ex: LCU
+// of application and network load balancers) aren't priced.
+func updateNetworkPrice(networkPrices map[string]*models.NetworkPrice, resource string, priceInFloat64 float64, unit string) {
+	if priceInFloat64 == models.PriceError {
+		return
+	}
+	networkPrice, isPresent := networkPrices[resource]
+	if !isPresent {
+		networkPrice = &models.NetworkPrice{
+			ID:              dgraph.ID{Xid: models.NetworkPriceXIDPrefix + resource},
+			IsNetworkPrice:  true,
+			NetworkResource: resource,
 		}
+		networkPrices[resource] = networkPrice
+	}
+	switch unit {
+	case hours:
+		networkPrice.Price = math.Max(networkPrice.Price, priceInFloat64)
+	case gb:
+		networkPrice.PricePerGB = math.Max(networkPrice.PricePerGB, priceInFloat64)
 	}
-	return nodePrices, storagePrices
+}
+
+// updateTransferPrice sets price per GB of the traffic class to the highest one among its tiers and destinations,
+// free tiers and volume discounts aren't applied to traffic of individual pods
+func updateTransferPrice(networkPrices map[string]*models.NetworkPrice, trafficClass string, terms map[string]TermAttributes) {
+	price := models.PriceError
+	for _, term := range terms {
+		for _, pricingData := range term.PriceDimensions {
+			if pricingData.Unit != gb {
+				continue
+			}
+			priceInFloat64, err := strconv.ParseFloat(pricingData.PricePerUnit["USD"], 64)
+			if err != nil {
+				logrus.Errorf("unable to parse data transfer price: %v, err: %v", pricingData.PricePerUnit, err)
+				continue
+			}
+			if priceInFloat64 > price {
+				price = priceInFloat64
+			}
+		}
+	}
+	if current, isPresent := networkPrices[trafficClass]; isPresent && current.PricePerGB > price {
+		return
+	}
+	updateNetworkPrice(networkPrices, trafficClass, price, gb)
+}
+
+// getNetworkPrices returns network prices ordered by their resource
+func getNetworkPrices(networkPrices map[string]*models.NetworkPrice) []*models.NetworkPrice {
+	var resources []string
+	for resource := range networkPrices {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	var prices []*models.NetworkPrice
+	for _, resource := range resources {
+		prices = append(prices, networkPrices[resource])
+	}
+	return prices
 }
 
 func getResourcePrice(product Product, planList PlanList) (float64, string) {
@@ -94,28 +230,83 @@ func getResourcePrice(product Product, planList PlanList) (float64, string) {
 	return models.PriceError, ""
 }
 
-func updateComputeInstancePrices(product Product, priceInFloat64 float64, duplicateComputeInstanceChecker map[string]bool, nodePrices []*models.NodePrice) []*models.NodePrice {
+// getReservedPrice returns effective hourly price of 1 year standard reservation without upfront payment, when there
+// is no such reservation the cheapest effective hourly price among other reservations is returned
+func getReservedPrice(reservations map[string]TermAttributes) float64 {
+	reservedPrice := models.PriceError
+	for _, reservation := range reservations {
+		price := getEffectiveHourlyPrice(reservation)
+		if price == models.PriceError {
+			continue
+		}
+		attributes := reservation.TermAttributes
+		if attributes[leaseContractLength] == oneYear && attributes[offeringClass] == standardOffering &&
+			attributes[purchaseOption] == noUpfront {
+			return price
+		}
+		if reservedPrice == models.PriceError || price < reservedPrice {
+			reservedPrice = price
+		}
+	}
+	return reservedPrice
+}
+
+// getEffectiveHourlyPrice returns hourly price of a reservation including its upfront payment spread over the lease
+func getEffectiveHourlyPrice(reservation TermAttributes) float64 {
+	lease, isPresent := leaseHours[reservation.TermAttributes[leaseContractLength]]
+	if !isPresent {
+		return models.PriceError
+	}
+	price := 0.0
+	for _, pricingData := range reservation.PriceDimensions {
+		for _, pricePerUnit := range pricingData.PricePerUnit {
+			priceInFloat64, err := strconv.ParseFloat(pricePerUnit, 64)
+			if err != nil {
+				logrus.Errorf("unable to parse string: %s to float. err: %v", pricePerUnit, err)
+				return models.PriceError
+			}
+			switch pricingData.Unit {
+			case hours:
+				price += priceInFloat64
+			case quantity:
+				price += priceInFloat64 / lease
+			}
+		}
+	}
+	return price
+}
+
+func updateComputeInstancePrices(product Product, priceInFloat64, reservedPrice float64, split priceSplit, duplicateComputeInstanceChecker map[string]bool, nodePrices []*models.NodePrice) []*models.NodePrice {
 	key := product.Sku + product.Attributes.InstanceType + product.Attributes.OperatingSystem
 	if _, isPresent := duplicateComputeInstanceChecker[key]; !isPresent && product.Attributes.PreInstalledSW == na {
 		// Unit of Compute price USD-perHour
-		productXID := product.Attributes.InstanceType + deliminator + product.Attributes.OperatingSystem
-		pricePerCPU, pricePerGB := getPriceForUnitResource(product, priceInFloat64)
+		// node os label is lower case(ex: linux)
+		operatingSystem := strings.ToLower(product.Attributes.OperatingSystem)
+		productXID := product.Attributes.InstanceType + deliminator + operatingSystem
+		pricePerCPU, pricePerGB, pricePerGPU, gpuCount := getPriceForUnitResource(product, priceInFloat64, split)
 		nodePrice := &models.NodePrice{
 			ID:              dgraph.ID{Xid: productXID},
 			IsNodePrice:     true,
 			InstanceType:    product.Attributes.InstanceType,
 			InstanceFamily:  product.Attributes.InstanceFamily,
-			OperatingSystem: product.Attributes.OperatingSystem,
+			OperatingSystem: operatingSystem,
 			Price:           priceInFloat64,
 			PricePerCPU:     pricePerCPU,
 			PricePerMemory:  pricePerGB,
+			PricePerGPU:     pricePerGPU,
+			GPUCount:        gpuCount,
+			PriceSplit:      split.note,
 		}
-		duplicateComputeInstanceChecker[key] = true
-		uid := models.StoreNodePrice(nodePrice, productXID)
-		if uid != "" {
-			nodePrice.ID = dgraph.ID{UID: uid, Xid: productXID}
-			nodePrices = append(nodePrices, nodePrice)
+		if capacity, err := getInstanceCapacity(product); err == nil {
+			nodePrice.CPUCapacity = capacity.cpu
+			nodePrice.MemoryCapacity = capacity.memory
 		}
+		// spot prices aren't part of the offer file, they are set from spot discounts of the controller
+		if priceInFloat64 > 0 && reservedPrice > 0 {
+			nodePrice.ReservedPrice = reservedPrice
+		}
+		duplicateComputeInstanceChecker[key] = true
+		nodePrices = append(nodePrices, nodePrice)
 	}
 	return nodePrices
 }
@@ -132,35 +323,57 @@ func updateStorageInstancePrices(product Product, priceInFloat64 float64, unit s
 	storagePrice := &models.StoragePrice{
 		ID:             dgraph.ID{Xid: productXID},
 		IsStoragePrice: true,
-		VolumeType:     product.Attributes.VolumeType,
+		VolumeType:     getVolumeType(product),
 		UsageType:      product.Attributes.UsageType,
 		Price:          priceInFloat64,
 	}
-	uid := models.StoreStoragePrice(storagePrice, productXID)
-	if uid != "" {
-		storagePrice.ID = dgraph.ID{UID: uid, Xid: productXID}
-		storagePrices = append(storagePrices, storagePrice)
+	return append(storagePrices, storagePrice)
+}
+
+// getVolumeType returns EBS volume type used by storage classes(ex: gp2, io1) of storage and IOPS products
+// ex: volumeApiName: gp2 or usagetype: EBS:VolumeUsage.gp2
+func getVolumeType(product Product) string {
+	if product.Attributes.VolumeAPIName != "" {
+		return product.Attributes.VolumeAPIName
 	}
-	return storagePrices
+	usageType := product.Attributes.UsageType
+	return usageType[strings.LastIndex(usageType, ".")+1:]
 }
 
-func getPriceForUnitResource(product Product, priceInFloat64 float64) (float64, float64) {
-	pricePerCPU := models.DefaultCPUCostInFloat64
-	pricePerGB := models.DefaultMemCostInFloat64
+// getIOPSPrice returns price per IOPS per hour of the first tier of provisioned IOPS
+func getIOPSPrice(terms map[string]TermAttributes) float64 {
+	for _, term := range terms {
+		for _, pricingData := range term.PriceDimensions {
+			if pricingData.BeginRange != "" && pricingData.BeginRange != "0" {
+				continue
+			}
+			price, err := strconv.ParseFloat(pricingData.PricePerUnit["USD"], 64)
+			if err != nil {
+				logrus.Errorf("unable to parse IOPS price: %v, err: %v", pricingData.PricePerUnit, err)
+				return models.PriceError
+			}
+			if pricingData.Unit == iopsMonth {
+				price = price / models.HoursInMonth
+			}
+			return price
+		}
+	}
+	return models.PriceError
+}
 
+// getPriceForUnitResource returns per vCPU, per GiB and per gpu prices of an instance along with its number of gpus
+func getPriceForUnitResource(product Product, priceInFloat64 float64, split priceSplit) (float64, float64, float64, float64) {
 	// priceInFloat64 should be greater than 0 otherwise this function returns default pricing
 	if priceInFloat64 != models.PriceError && priceInFloat64 != 0 {
-		cpu, err := strconv.ParseFloat(product.Attributes.Vcpu, 64)
+		capacity, err := getInstanceCapacity(product)
 		if err == nil {
-			pricePerCPU = priceSplitRatio * priceInFloat64 / cpu
-		}
-
-		memWithUnits := product.Attributes.Memory
-		// memWithUnits format: "3,126 GiB"
-		mem, err := strconv.ParseFloat(strings.Join(strings.Split(strings.Split(memWithUnits, " GiB")[0], ","), ""), 64)
-		if err == nil {
-			pricePerGB = (1 - priceSplitRatio) * priceInFloat64 / mem
+			if capacity.gpu > 0 {
+				pricePerCPU, pricePerGB, pricePerGPU := split.splitWithGPUs(priceInFloat64, capacity)
+				return pricePerCPU, pricePerGB, pricePerGPU, capacity.gpu
+			}
+			pricePerCPU, pricePerGB := split.split(priceInFloat64, capacity)
+			return pricePerCPU, pricePerGB, 0, 0
 		}
 	}
-	return pricePerCPU, pricePerGB
+	return models.DefaultCPUCostInFloat64, models.DefaultMemCostInFloat64, 0, 0
 }
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+func getTestPricingWithIOPS() *Pricing {
+	onDemand := func(unit, price string) map[string]TermAttributes {
+		return map[string]TermAttributes{
+			"term": {PriceDimensions: map[string]PricingData{
+				"dimension": {Unit: unit, BeginRange: "0", PricePerUnit: map[string]string{"USD": price}},
+			}},
+		}
+	}
+	return &Pricing{
+		Products: map[string]Product{
+			"IO1STORAGE": {Sku: "IO1STORAGE", ProductFamily: storageInstance, Attributes: ProductAttributes{
+				VolumeType: "Provisioned IOPS", VolumeAPIName: "io1", UsageType: "EBS:VolumeUsage.piops"}},
+			"IO1IOPS": {Sku: "IO1IOPS", ProductFamily: systemOperation, Attributes: ProductAttributes{
+				Group: ebsIOPSGroup, VolumeAPIName: "io1", UsageType: "EBS:VolumeP-IOPS.piops"}},
+			"GP3STORAGE": {Sku: "GP3STORAGE", ProductFamily: storageInstance, Attributes: ProductAttributes{
+				VolumeType: "General Purpose", UsageType: "EBS:VolumeUsage.gp3"}},
+			"GP3IOPS": {Sku: "GP3IOPS", ProductFamily: systemOperation, Attributes: ProductAttributes{
+				Group: ebsIOPSGroup, VolumeAPIName: "gp3", UsageType: "EBS:VolumeP-IOPS.gp3"}},
+		},
+		Terms: PlanList{OnDemand: map[string]map[string]TermAttributes{
+			"IO1STORAGE": onDemand(gbMonth, "0.125"),
+			"IO1IOPS":    onDemand(iopsMonth, "0.065"),
+			"GP3STORAGE": onDemand(gbMonth, "0.08"),
+			"GP3IOPS":    onDemand(iopsMonth, "0.005"),
+		}},
+	}
+}
+
+// TestGetResourcePricesWithIOPS ...
+func TestGetResourcePricesWithIOPS(t *testing.T) {
+	_, storagePrices, _ := getResourcePricesFromAWSPricing(getTestPricingWithIOPS())
+
+	assert.Len(t, storagePrices, 2)
+	for _, storagePrice := range storagePrices {
+		switch storagePrice.VolumeType {
+		case "io1":
+			assert.InDelta(t, 0.125/models.HoursInMonth, storagePrice.Price, 1e-12)
+			assert.InDelta(t, 0.065/models.HoursInMonth, storagePrice.IOPSPrice, 1e-12)
+		case "gp3":
+			// volume type is taken from usage type, IOPS within baseline aren't charged
+			assert.InDelta(t, 0.08/models.HoursInMonth, storagePrice.Price, 1e-12)
+			assert.Equal(t, 0.0, storagePrice.IOPSPrice)
+		default:
+			t.Errorf("unexpected volume type: %s", storagePrice.VolumeType)
+		}
+	}
+}
+
+// TestGetNetworkPrices ...
+func TestGetNetworkPrices(t *testing.T) {
+	onDemand := func(unit, price string) map[string]TermAttributes {
+		return map[string]TermAttributes{
+			"term": {PriceDimensions: map[string]PricingData{
+				"dimension": {Unit: unit, PricePerUnit: map[string]string{"USD": price}},
+			}},
+		}
+	}
+	pricing := &Pricing{
+		Products: map[string]Product{
+			"ELBHOURS":    {Sku: "ELBHOURS", ProductFamily: loadBalancer, Attributes: ProductAttributes{UsageType: "LoadBalancerUsage"}},
+			"ELBBYTES":    {Sku: "ELBBYTES", ProductFamily: loadBalancer, Attributes: ProductAttributes{UsageType: "DataProcessing-Bytes"}},
+			"ELBOUTPOSTS": {Sku: "ELBOUTPOSTS", ProductFamily: loadBalancer, Attributes: ProductAttributes{UsageType: "LoadBalancerUsage-Outposts"}},
+			"NLBHOURS":    {Sku: "NLBHOURS", ProductFamily: networkLB, Attributes: ProductAttributes{UsageType: "LoadBalancerUsage"}},
+			"NLBLCU":      {Sku: "NLBLCU", ProductFamily: networkLB, Attributes: ProductAttributes{UsageType: "LCUUsage"}},
+			"ALBHOURS":    {Sku: "ALBHOURS", ProductFamily: "Load Balancer-Application", Attributes: ProductAttributes{UsageType: "LoadBalancerUsage"}},
+			"NATHOURS":    {Sku: "NATHOURS", ProductFamily: natGateway, Attributes: ProductAttributes{UsageType: "NatGateway-Hours"}},
+			"IPV4":        {Sku: "IPV4", ProductFamily: ipAddress, Attributes: ProductAttributes{UsageType: "PublicIPv4:InUseAddress"}},
+		},
+		Terms: PlanList{OnDemand: map[string]map[string]TermAttributes{
+			"ELBHOURS":    onDemand(hours, "0.025"),
+			"ELBBYTES":    onDemand(gb, "0.008"),
+			"ELBOUTPOSTS": onDemand(hours, "0.01"),
+			"NLBHOURS":    onDemand(hours, "0.0225"),
+			"NLBLCU":      onDemand("LCU-Hrs", "0.006"),
+			"ALBHOURS":    onDemand(hours, "0.0226"),
+			"NATHOURS":    onDemand(hours, "0.045"),
+			"IPV4":        onDemand(hours, "0.005"),
+		}},
+	}
+	_, _, networkPrices := getResourcePricesFromAWSPricing(pricing)
+
+	// application load balancers back ingresses, not services, they aren't priced
+	assert.Len(t, networkPrices, 4)
+	// the highest hourly price is kept whatever the order of products
+	assert.Equal(t, models.LoadBalancerNetworkResource, networkPrices[0].NetworkResource)
+	assert.Equal(t, "purser-networkPrice-loadBalancer", networkPrices[0].Xid)
+	assert.Equal(t, 0.025, networkPrices[0].Price)
+	assert.Equal(t, 0.008, networkPrices[0].PricePerGB)
+	assert.Equal(t, models.NATGatewayNetworkResource, networkPrices[1].NetworkResource)
+	assert.Equal(t, 0.045, networkPrices[1].Price)
+	// capacity units aren't priced
+	assert.Equal(t, models.NetworkLoadBalancerNetworkResource, networkPrices[2].NetworkResource)
+	assert.Equal(t, 0.0225, networkPrices[2].Price)
+	assert.Equal(t, 0.0, networkPrices[2].PricePerGB)
+	assert.Equal(t, models.PublicIPNetworkResource, networkPrices[3].NetworkResource)
+	assert.Equal(t, 0.005, networkPrices[3].Price)
+}
+
+// TestGetTransferPrices ...
+func TestGetTransferPrices(t *testing.T) {
+	tiered := func(prices ...string) map[string]TermAttributes {
+		dimensions := map[string]PricingData{}
+		for index, price := range prices {
+			dimensions["dimension"+strconv.Itoa(index)] = PricingData{Unit: gb, PricePerUnit: map[string]string{"USD": price}}
+		}
+		return map[string]TermAttributes{"term": {PriceDimensions: dimensions}}
+	}
+	pricing := &Pricing{
+		Products: map[string]Product{
+			"AZ":       {Sku: "AZ", ProductFamily: dataTransfer, Attributes: ProductAttributes{TransferType: "IntraRegion"}},
+			"EUWEST":   {Sku: "EUWEST", ProductFamily: dataTransfer, Attributes: ProductAttributes{TransferType: "InterRegion Outbound"}},
+			"USWEST":   {Sku: "USWEST", ProductFamily: dataTransfer, Attributes: ProductAttributes{TransferType: "InterRegion Outbound"}},
+			"INTERNET": {Sku: "INTERNET", ProductFamily: dataTransfer, Attributes: ProductAttributes{TransferType: "AWS Outbound"}},
+		},
+		Terms: PlanList{OnDemand: map[string]map[string]TermAttributes{
+			"AZ":       tiered("0.01"),
+			"EUWEST":   tiered("0.02"),
+			"USWEST":   tiered("0.01"),
+			"INTERNET": tiered("0.0", "0.09", "0.085", "0.07"),
+		}},
+	}
+	_, _, networkPrices := getResourcePricesFromAWSPricing(pricing)
+
+	assert.Len(t, networkPrices, 3)
+	assert.Equal(t, models.CrossRegionTraffic, networkPrices[0].NetworkResource)
+	assert.Equal(t, 0.02, networkPrices[0].PricePerGB)
+	assert.Equal(t, models.CrossZoneTraffic, networkPrices[1].NetworkResource)
+	assert.Equal(t, 0.01, networkPrices[1].PricePerGB)
+	assert.Equal(t, models.InternetTraffic, networkPrices[2].NetworkResource)
+	assert.Equal(t, 0.09, networkPrices[2].PricePerGB)
+}
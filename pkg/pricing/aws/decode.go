@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Offer file keys and attribute values used while streaming
+const (
+	productsKey   = "products"
+	termsKey      = "terms"
+	versionKey    = "version"
+	onDemandKey   = "OnDemand"
+	reservedKey   = "Reserved"
+	sharedTenancy = "Shared"
+	capacityInUse = "Used"
+	linuxOS       = "Linux"
+	windowsOS     = "Windows"
+)
+
+// supportedOperatingSystems are the operating systems reported by kubernetes nodes(kubernetes.io/os label)
+var supportedOperatingSystems = map[string]bool{
+	linuxOS:   true,
+	windowsOS: true,
+}
+
+// decodeAWSPricing reads an offer file token by token keeping only the products(and their terms) of the region
+// which purser prices, so memory used doesn't grow with the size of the offer file.
+func decodeAWSPricing(reader io.Reader, region string) (*Pricing, error) {
+	decoder := json.NewDecoder(reader)
+	pricing := &Pricing{
+		Products: make(map[string]Product),
+		Terms: PlanList{
+			OnDemand: make(map[string]map[string]TermAttributes),
+			Reserved: make(map[string]map[string]TermAttributes),
+		},
+	}
+	productsRead := false
+
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return nil, err
+	}
+	for decoder.More() {
+		key, err := readKey(decoder)
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case versionKey:
+			err = decoder.Decode(&pricing.Version)
+		case productsKey:
+			err = decodeProducts(decoder, region, pricing.Products)
+			productsRead = true
+		case termsKey:
+			err = decodeTerms(decoder, pricing.Products, productsRead, &pricing.Terms)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// terms listed before products couldn't be filtered while reading
+	for _, terms := range []map[string]map[string]TermAttributes{pricing.Terms.OnDemand, pricing.Terms.Reserved} {
+		for sku := range terms {
+			if _, isPresent := pricing.Products[sku]; !isPresent {
+				delete(terms, sku)
+			}
+		}
+	}
+	return pricing, nil
+}
+
+func decodeProducts(decoder *json.Decoder, region string, products map[string]Product) error {
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+	for decoder.More() {
+		sku, err := readKey(decoder)
+		if err != nil {
+			return err
+		}
+		product := Product{}
+		err = decoder.Decode(&product)
+		if err != nil {
+			return err
+		}
+		if isProductRequired(product, region) {
+			products[sku] = product
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+func decodeTerms(decoder *json.Decoder, products map[string]Product, productsRead bool, planList *PlanList) error {
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+	for decoder.More() {
+		termType, err := readKey(decoder)
+		if err != nil {
+			return err
+		}
+		switch termType {
+		case onDemandKey:
+			err = decodeTermsOfProducts(decoder, products, productsRead, planList.OnDemand)
+		case reservedKey:
+			err = decodeTermsOfProducts(decoder, products, productsRead, planList.Reserved)
+		default:
+			err = skipValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+func decodeTermsOfProducts(decoder *json.Decoder, products map[string]Product, productsRead bool, terms map[string]map[string]TermAttributes) error {
+	err := expectDelim(decoder, '{')
+	if err != nil {
+		return err
+	}
+	for decoder.More() {
+		sku, err := readKey(decoder)
+		if err != nil {
+			return err
+		}
+		if _, isPresent := products[sku]; productsRead && !isPresent {
+			err = skipValue(decoder)
+		} else {
+			productTerms := make(map[string]TermAttributes)
+			err = decoder.Decode(&productTerms)
+			terms[sku] = productTerms
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, '}')
+}
+
+// isProductRequired reports whether the product is priced by purser: on-demand shared compute instances without
+// pre installed software running an operating system supported by kubernetes, storage, classic and network load
+// balancers, NAT gateways, in use public IPs and data transfer out of the region.
+func isProductRequired(product Product, region string) bool {
+	attributes := product.Attributes
+	if attributes.RegionCode != "" && attributes.RegionCode != region {
+		return false
+	}
+	switch product.ProductFamily {
+	case computeInstance:
+		return attributes.PreInstalledSW == na &&
+			supportedOperatingSystems[attributes.OperatingSystem] &&
+			(attributes.Tenancy == "" || attributes.Tenancy == sharedTenancy) &&
+			(attributes.CapacityStatus == "" || attributes.CapacityStatus == capacityInUse)
+	case storageInstance:
+		return true
+	case systemOperation:
+		return attributes.Group == ebsIOPSGroup
+	case loadBalancer, networkLB, natGateway:
+		return true
+	case ipAddress:
+		return strings.Contains(attributes.UsageType, inUseAddressUsage)
+	case dataTransfer:
+		_, isPriced := transferTrafficClasses[attributes.TransferType]
+		return attributes.FromRegionCode == region && isPriced
+	}
+	return false
+}
+
+func readKey(decoder *json.Decoder) (string, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return "", err
+	}
+	key, isString := token.(string)
+	if !isString {
+		return "", fmt.Errorf("expected object key in offer file, found: %v", token)
+	}
+	return key, nil
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v in offer file, found: %v", delim, token)
+	}
+	return nil
+}
+
+// skipValue reads the next value without keeping it in memory
+func skipValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// TestDecodeAWSPricingKeepsRequiredProducts ...
+func TestDecodeAWSPricingKeepsRequiredProducts(t *testing.T) {
+	pricing, err := GetAWSPricingFromFile(testPriceFile, "us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "20190603213414", pricing.Version)
+
+	var skus []string
+	for sku := range pricing.Products {
+		skus = append(skus, sku)
+	}
+	assert.ElementsMatch(t, []string{"M5LARGELINUX", "M5LARGEWINDOWS", "GP2STORAGE"}, skus)
+
+	var termSkus []string
+	for sku := range pricing.Terms.OnDemand {
+		termSkus = append(termSkus, sku)
+	}
+	assert.ElementsMatch(t, []string{"M5LARGELINUX", "M5LARGEWINDOWS", "GP2STORAGE"}, termSkus)
+	assert.Len(t, pricing.Terms.Reserved, 1)
+	assert.Contains(t, pricing.Terms.Reserved, "M5LARGELINUX")
+}
+
+// TestDecodeAWSPricingWithTermsBeforeProducts ...
+func TestDecodeAWSPricingWithTermsBeforeProducts(t *testing.T) {
+	offer := `{
+		"terms": {"OnDemand": {
+			"A": {"A.1": {"priceDimensions": {"A.1.1": {"unit": "Hrs", "pricePerUnit": {"USD": "0.1"}}}}},
+			"B": {"B.1": {"priceDimensions": {"B.1.1": {"unit": "Hrs", "pricePerUnit": {"USD": "0.2"}}}}}
+		}},
+		"products": {
+			"A": {"sku": "A", "productFamily": "Compute Instance", "attributes": {"operatingSystem": "Linux", "preInstalledSw": "NA"}},
+			"B": {"sku": "B", "productFamily": "Compute Instance", "attributes": {"operatingSystem": "SUSE", "preInstalledSw": "NA"}}
+		}
+	}`
+	pricing, err := decodeAWSPricing(strings.NewReader(offer), "us-east-1")
+	assert.NoError(t, err)
+	assert.Len(t, pricing.Products, 1)
+	assert.Len(t, pricing.Terms.OnDemand, 1)
+	assert.Contains(t, pricing.Terms.OnDemand, "A")
+}
+
+// TestDecodeAWSPricingWithInvalidOffer ...
+func TestDecodeAWSPricingWithInvalidOffer(t *testing.T) {
+	_, err := decodeAWSPricing(strings.NewReader(`["products"]`), "us-east-1")
+	assert.Error(t, err)
+
+	_, err = decodeAWSPricing(strings.NewReader(`{"products": {"A": `), "us-east-1")
+	assert.Error(t, err)
+}
+
+// TestGetReservedPrice ...
+func TestGetReservedPrice(t *testing.T) {
+	hourly := func(price string) map[string]PricingData {
+		return map[string]PricingData{"hrs": {Unit: hours, PricePerUnit: map[string]string{"USD": price}}}
+	}
+	reservations := map[string]TermAttributes{
+		"allUpfront": {
+			PriceDimensions: map[string]PricingData{
+				"hrs":     {Unit: hours, PricePerUnit: map[string]string{"USD": "0"}},
+				"upfront": {Unit: quantity, PricePerUnit: map[string]string{"USD": "432"}},
+			},
+			TermAttributes: map[string]string{leaseContractLength: oneYear, offeringClass: standardOffering, purchaseOption: "All Upfront"},
+		},
+		"convertible": {
+			PriceDimensions: hourly("0.07"),
+			TermAttributes:  map[string]string{leaseContractLength: oneYear, offeringClass: "convertible", purchaseOption: noUpfront},
+		},
+	}
+	// cheapest effective price when there is no 1 year standard reservation without upfront payment
+	assert.InDelta(t, 0.05, getReservedPrice(reservations), 1e-9)
+
+	reservations["noUpfront"] = TermAttributes{
+		PriceDimensions: hourly("0.06"),
+		TermAttributes:  map[string]string{leaseContractLength: oneYear, offeringClass: standardOffering, purchaseOption: noUpfront},
+	}
+	assert.Equal(t, 0.06, getReservedPrice(reservations))
+
+	assert.Equal(t, models.PriceError, getReservedPrice(nil))
+}
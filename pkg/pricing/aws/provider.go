@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"fmt"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Provider of EC2 prices, prices are read from priceFile instead of the price list API when it is given
+type Provider struct {
+	priceFile string
+	pricing   *Pricing
+}
+
+// NewProvider returns aws pricing provider
+func NewProvider(priceFile string) *Provider {
+	return &Provider{priceFile: priceFile}
+}
+
+// Fetch retrieves the offer file of the region
+func (p *Provider) Fetch(region string) error {
+	var err error
+	if p.priceFile != "" {
+		p.pricing, err = GetAWSPricingFromFile(p.priceFile, region)
+	} else {
+		p.pricing, err = GetAWSPricing(region)
+	}
+	return err
+}
+
+// RateCard converts the fetched offer file to purser's rate card
+func (p *Provider) RateCard(region string) (*models.RateCard, error) {
+	if p.pricing == nil {
+		return nil, fmt.Errorf("aws prices of region: %s are not fetched", region)
+	}
+	return convertAWSPricingToPurserRateCard(region, p.pricing), nil
+}
+
+// Version returns version of the fetched offer file(ex: 20190603213414)
+func (p *Provider) Version() string {
+	if p.pricing == nil {
+		return ""
+	}
+	return p.pricing.Version
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+const testPriceFile = "testdata/offer.json"
+
+// TestGetAWSPricingFromFileWithMissingFile ...
+func TestGetAWSPricingFromFileWithMissingFile(t *testing.T) {
+	_, err := GetAWSPricingFromFile("testdata/missing.json", "us-east-1")
+	assert.Error(t, err)
+}
+
+// TestProviderBeforeFetch ...
+func TestProviderBeforeFetch(t *testing.T) {
+	provider := NewProvider(testPriceFile)
+	_, err := provider.RateCard("us-east-1")
+	assert.Error(t, err)
+	assert.Equal(t, "", provider.Version())
+}
+
+// TestProviderFromPriceFile ...
+func TestProviderFromPriceFile(t *testing.T) {
+	provider := NewProvider(testPriceFile)
+	assert.NoError(t, provider.Fetch("us-east-1"))
+	assert.Equal(t, "20190603213414", provider.Version())
+
+	rateCard, err := provider.RateCard("us-east-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.AWS, rateCard.CloudProvider)
+	assert.Equal(t, "us-east-1", rateCard.Region)
+
+	assert.Len(t, rateCard.NodePrices, 2)
+	for _, nodePrice := range rateCard.NodePrices {
+		if nodePrice.Xid == "m5.large-linux" {
+			assert.Equal(t, 0.096, nodePrice.Price)
+			// a single linux instance type can't be fitted, reference ratio splits the price
+			assert.InDelta(t, 0.096, 2*nodePrice.PricePerCPU+8*nodePrice.PricePerMemory, 1e-9)
+			assert.InDelta(t, referenceCPUToMemoryRatio, nodePrice.PricePerCPU/nodePrice.PricePerMemory, 1e-9)
+			assert.Contains(t, nodePrice.PriceSplit, "reference ratio")
+			assert.Equal(t, 0.06, nodePrice.ReservedPrice)
+			assert.Equal(t, 0.0, nodePrice.SpotPrice)
+			assert.Equal(t, 2.0, nodePrice.CPUCapacity)
+			assert.Equal(t, 8.0, nodePrice.MemoryCapacity)
+		} else {
+			assert.Equal(t, "m5.large-windows", nodePrice.Xid)
+			assert.Equal(t, 0.188, nodePrice.Price)
+		}
+	}
+
+	assert.Len(t, rateCard.StoragePrices, 1)
+	storagePrice := rateCard.StoragePrices[0]
+	assert.Equal(t, "General Purpose-EBS:VolumeUsage.gp2", storagePrice.Xid)
+	assert.Equal(t, "gp2", storagePrice.VolumeType)
+	assert.InDelta(t, 0.1/models.HoursInMonth, storagePrice.Price, 1e-12)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minimum number of instance types needed to fit per vCPU and per GiB prices of a region
+	minRegressionSamples = 3
+
+	// a vCPU is priced as much as referenceCPUToMemoryRatio GiB of memory when prices of the region
+	// can't be fitted, ratio of per vCPU and per GiB prices of gcp n1 custom machine types
+	referenceCPUToMemoryRatio = 7.46
+
+	// share of gpus in the price of an instance when vCPUs and memory can't be priced as on instance types
+	// without gpus, gpus make up most of the price of gpu instance types
+	referenceGPUPriceShare = 0.8
+)
+
+// instanceCapacity number of vCPUs, GiB of memory and gpus of an instance type
+type instanceCapacity struct {
+	cpu    float64
+	memory float64
+	gpu    float64
+}
+
+// priceSplit splits hourly price of an instance between its vCPUs and memory in proportion of cpuWeight
+// per vCPU to memoryWeight per GiB, note tells how the weights were derived. Weights are per vCPU and per GiB prices
+// when they were fitted.
+type priceSplit struct {
+	cpuWeight    float64
+	memoryWeight float64
+	fitted       bool
+	note         string
+}
+
+// getPriceSplit fits on-demand prices of linux instance types without gpus of the region to
+// price = cpuPrice * vCPUs + memoryPrice * GiB, reference ratio is used when the fit fails
+func getPriceSplit(products map[string]Product, planList PlanList) priceSplit {
+	samples := make(map[string]instanceCapacity)
+	prices := make(map[string]float64)
+	for _, product := range products {
+		attributes := product.Attributes
+		if product.ProductFamily != computeInstance || attributes.OperatingSystem != linuxOS || attributes.PreInstalledSW != na {
+			continue
+		}
+		capacity, err := getInstanceCapacity(product)
+		if err != nil || capacity.gpu > 0 {
+			continue
+		}
+		price, _ := getResourcePrice(product, planList)
+		if price <= 0 {
+			continue
+		}
+		samples[attributes.InstanceType] = capacity
+		prices[attributes.InstanceType] = price
+	}
+
+	cpuPrice, memoryPrice, err := fitUnitPrices(samples, prices)
+	if err != nil {
+		return priceSplit{
+			cpuWeight:    referenceCPUToMemoryRatio,
+			memoryWeight: 1,
+			note:         fmt.Sprintf("reference ratio, a vCPU is priced as %.2f GiB of memory (%v)", referenceCPUToMemoryRatio, err),
+		}
+	}
+	return priceSplit{
+		cpuWeight:    cpuPrice,
+		memoryWeight: memoryPrice,
+		fitted:       true,
+		note: fmt.Sprintf("regression over %d instance types, %.6f per vCPU hour and %.6f per GiB hour",
+			len(samples), cpuPrice, memoryPrice),
+	}
+}
+
+// fitUnitPrices solves least squares fit of price = cpuPrice * vCPUs + memoryPrice * GiB
+func fitUnitPrices(samples map[string]instanceCapacity, prices map[string]float64) (float64, float64, error) {
+	if len(samples) < minRegressionSamples {
+		return 0, 0, fmt.Errorf("only %d instance types to fit", len(samples))
+	}
+	var cpuCPU, cpuMemory, memoryMemory, cpuPrice, memoryPrice float64
+	for instanceType, capacity := range samples {
+		price := prices[instanceType]
+		cpuCPU += capacity.cpu * capacity.cpu
+		cpuMemory += capacity.cpu * capacity.memory
+		memoryMemory += capacity.memory * capacity.memory
+		cpuPrice += capacity.cpu * price
+		memoryPrice += capacity.memory * price
+	}
+
+	determinant := cpuCPU*memoryMemory - cpuMemory*cpuMemory
+	if math.Abs(determinant) <= 1e-9*cpuCPU*memoryMemory {
+		return 0, 0, fmt.Errorf("memory per vCPU is same for every instance type")
+	}
+	fittedCPUPrice := (cpuPrice*memoryMemory - memoryPrice*cpuMemory) / determinant
+	fittedMemoryPrice := (memoryPrice*cpuCPU - cpuPrice*cpuMemory) / determinant
+	if fittedCPUPrice <= 0 || fittedMemoryPrice <= 0 {
+		return 0, 0, fmt.Errorf("fitted prices aren't positive")
+	}
+	return fittedCPUPrice, fittedMemoryPrice, nil
+}
+
+// split returns per vCPU and per GiB prices of an instance, together they add up to the price of instance
+func (s priceSplit) split(price float64, capacity instanceCapacity) (float64, float64) {
+	cpuShare := s.cpuWeight * capacity.cpu / (s.cpuWeight*capacity.cpu + s.memoryWeight*capacity.memory)
+	return cpuShare * price / capacity.cpu, (1 - cpuShare) * price / capacity.memory
+}
+
+// splitWithGPUs returns per vCPU, per GiB and per gpu prices of an instance with gpus, together they add up to the
+// price of instance. vCPUs and memory are priced as on instance types without gpus and gpus take the rest of the price,
+// gpus take referenceGPUPriceShare of the price when unit prices weren't fitted or leave nothing for gpus.
+func (s priceSplit) splitWithGPUs(price float64, capacity instanceCapacity) (float64, float64, float64) {
+	if s.fitted {
+		cpuAndMemoryPrice := s.cpuWeight*capacity.cpu + s.memoryWeight*capacity.memory
+		if cpuAndMemoryPrice < price {
+			return s.cpuWeight, s.memoryWeight, (price - cpuAndMemoryPrice) / capacity.gpu
+		}
+	}
+	gpuPrice := referenceGPUPriceShare * price
+	pricePerCPU, pricePerGB := s.split(price-gpuPrice, capacity)
+	return pricePerCPU, pricePerGB, gpuPrice / capacity.gpu
+}
+
+// getInstanceCapacity parses vCPUs, memory and gpus of an instance type, memory format: "3,126 GiB". Instance types
+// without gpus have no(or NA) gpu attribute.
+func getInstanceCapacity(product Product) (instanceCapacity, error) {
+	cpu, err := strconv.ParseFloat(product.Attributes.Vcpu, 64)
+	if err != nil {
+		return instanceCapacity{}, err
+	}
+	memory, err := strconv.ParseFloat(strings.Replace(strings.Split(product.Attributes.Memory, " GiB")[0], ",", "", -1), 64)
+	if err != nil {
+		return instanceCapacity{}, err
+	}
+	if cpu <= 0 || memory <= 0 {
+		return instanceCapacity{}, fmt.Errorf("instance type: %s has no capacity", product.Attributes.InstanceType)
+	}
+	gpu, err := strconv.ParseFloat(product.Attributes.GPU, 64)
+	if err != nil || gpu < 0 {
+		gpu = 0
+	}
+	return instanceCapacity{cpu: cpu, memory: memory, gpu: gpu}, nil
+}
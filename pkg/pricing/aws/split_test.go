@@ -0,0 +1,124 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestComputeProduct(instanceType, vcpu, memory, gpu string) Product {
+	return Product{
+		Sku:           instanceType,
+		ProductFamily: computeInstance,
+		Attributes: ProductAttributes{
+			InstanceType:    instanceType,
+			OperatingSystem: linuxOS,
+			PreInstalledSW:  na,
+			Vcpu:            vcpu,
+			Memory:          memory,
+			GPU:             gpu,
+		},
+	}
+}
+
+func getTestOnDemandTerms(prices map[string]string) PlanList {
+	planList := PlanList{OnDemand: make(map[string]map[string]TermAttributes)}
+	for sku, price := range prices {
+		planList.OnDemand[sku] = map[string]TermAttributes{
+			sku + ".term": {PriceDimensions: map[string]PricingData{
+				sku + ".term.dimension": {Unit: hours, PricePerUnit: map[string]string{"USD": price}},
+			}},
+		}
+	}
+	return planList
+}
+
+// TestGetPriceSplitByRegression ...
+func TestGetPriceSplitByRegression(t *testing.T) {
+	// prices are 0.04 per vCPU hour and 0.005 per GiB hour, gpu instance doesn't follow them
+	products := map[string]Product{
+		"c5.large":    getTestComputeProduct("c5.large", "2", "4 GiB", ""),
+		"m5.large":    getTestComputeProduct("m5.large", "2", "8 GiB", ""),
+		"r5.large":    getTestComputeProduct("r5.large", "2", "16 GiB", ""),
+		"x1.16xlarge": getTestComputeProduct("x1.16xlarge", "64", "976 GiB", ""),
+		"p3.2xlarge":  getTestComputeProduct("p3.2xlarge", "8", "61 GiB", "1"),
+	}
+	planList := getTestOnDemandTerms(map[string]string{
+		"c5.large":    "0.1",
+		"m5.large":    "0.12",
+		"r5.large":    "0.16",
+		"x1.16xlarge": "7.44",
+		"p3.2xlarge":  "3.06",
+	})
+
+	split := getPriceSplit(products, planList)
+	assert.InDelta(t, 0.04, split.cpuWeight, 1e-9)
+	assert.InDelta(t, 0.005, split.memoryWeight, 1e-9)
+	assert.Contains(t, split.note, "regression over 4 instance types")
+
+	pricePerCPU, pricePerGB := split.split(0.16, instanceCapacity{cpu: 2, memory: 16})
+	assert.InDelta(t, 0.04, pricePerCPU, 1e-9)
+	assert.InDelta(t, 0.005, pricePerGB, 1e-9)
+
+	// vCPUs and memory of gpu instance are priced as on other instances, gpu takes the rest
+	capacity, err := getInstanceCapacity(products["p3.2xlarge"])
+	assert.NoError(t, err)
+	pricePerCPU, pricePerGB, pricePerGPU := split.splitWithGPUs(3.06, capacity)
+	assert.InDelta(t, 0.04, pricePerCPU, 1e-9)
+	assert.InDelta(t, 0.005, pricePerGB, 1e-9)
+	assert.InDelta(t, 2.435, pricePerGPU, 1e-9)
+}
+
+// TestGetPriceSplitWithReferenceRatio ...
+func TestGetPriceSplitWithReferenceRatio(t *testing.T) {
+	// memory per vCPU is same for every instance type, prices can't be fitted
+	products := map[string]Product{
+		"m5.large":   getTestComputeProduct("m5.large", "2", "8 GiB", ""),
+		"m5.xlarge":  getTestComputeProduct("m5.xlarge", "4", "16 GiB", ""),
+		"m5.2xlarge": getTestComputeProduct("m5.2xlarge", "8", "32 GiB", ""),
+	}
+	planList := getTestOnDemandTerms(map[string]string{"m5.large": "0.096", "m5.xlarge": "0.192", "m5.2xlarge": "0.384"})
+
+	split := getPriceSplit(products, planList)
+	assert.Equal(t, referenceCPUToMemoryRatio, split.cpuWeight)
+	assert.Contains(t, split.note, "reference ratio")
+
+	pricePerCPU, pricePerGB := split.split(0.096, instanceCapacity{cpu: 2, memory: 8})
+	assert.InDelta(t, 0.096, 2*pricePerCPU+8*pricePerGB, 1e-9)
+
+	// gpus take the reference share of price of gpu instance
+	pricePerCPU, pricePerGB, pricePerGPU := split.splitWithGPUs(12.24, instanceCapacity{cpu: 32, memory: 244, gpu: 4})
+	assert.InDelta(t, 2.448, pricePerGPU, 1e-9)
+	assert.InDelta(t, 12.24, 32*pricePerCPU+244*pricePerGB+4*pricePerGPU, 1e-9)
+}
+
+// TestGetInstanceCapacity ...
+func TestGetInstanceCapacity(t *testing.T) {
+	capacity, err := getInstanceCapacity(getTestComputeProduct("x1e.32xlarge", "128", "3,904 GiB", ""))
+	assert.NoError(t, err)
+	assert.Equal(t, instanceCapacity{cpu: 128, memory: 3904}, capacity)
+
+	capacity, err = getInstanceCapacity(getTestComputeProduct("p3.8xlarge", "32", "244 GiB", "4"))
+	assert.NoError(t, err)
+	assert.Equal(t, instanceCapacity{cpu: 32, memory: 244, gpu: 4}, capacity)
+
+	_, err = getInstanceCapacity(getTestComputeProduct("m5.large", "2", "NA", ""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/utils"
+)
+
+const (
+	httpTimeout     = 100 * time.Second
+	retailPricesURL = "https://prices.azure.com/api/retail/prices"
+)
+
+// Pricing structure of a page of Azure Retail Prices API response
+type Pricing struct {
+	Items        []Item `json:"Items"`
+	NextPageLink string `json:"NextPageLink"`
+}
+
+// Item structure
+type Item struct {
+	RetailPrice        float64 `json:"retailPrice"`
+	ArmRegionName      string  `json:"armRegionName"`
+	EffectiveStartDate string  `json:"effectiveStartDate"`
+	MeterName          string  `json:"meterName"`
+	ProductName        string  `json:"productName"`
+	SkuName            string  `json:"skuName"`
+	ServiceName        string  `json:"serviceName"`
+	UnitOfMeasure      string  `json:"unitOfMeasure"`
+	Type               string  `json:"type"`
+	ArmSkuName         string  `json:"armSkuName"`
+}
+
+// GetAzurePricing function details
+// input: region
+// retrieves virtual machine and storage prices of the region following every page of the response
+func GetAzurePricing(region string) (*Pricing, error) {
+	var myClient = &http.Client{Timeout: httpTimeout}
+	pricing := Pricing{}
+	nextPageLink := getURLForRegion(region)
+	for nextPageLink != "" {
+		page := Pricing{}
+		err := utils.GetJSONResponse(myClient, nextPageLink, &page)
+		if err != nil {
+			logrus.Errorf("Unable to get azure pricing. Reason: %v", err)
+			return nil, err
+		}
+		pricing.Items = append(pricing.Items, page.Items...)
+		nextPageLink = page.NextPageLink
+	}
+	return &pricing, nil
+}
+
+// GetAzurePricingFromFile reads a previously downloaded Retail Prices API response
+func GetAzurePricingFromFile(path string) (*Pricing, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		logrus.Errorf("Unable to read azure pricing file: %s. Reason: %v", path, err)
+		return nil, err
+	}
+
+	pricing := Pricing{}
+	err = json.Unmarshal(data, &pricing)
+	if err != nil {
+		logrus.Errorf("Unable to parse azure pricing file: %s. Reason: %v", path, err)
+		return nil, err
+	}
+	return &pricing, nil
+}
+
+func getURLForRegion(region string) string {
+	filter := "armRegionName eq '" + region + "' and priceType eq 'Consumption'" +
+		" and (serviceName eq 'Virtual Machines' or serviceName eq 'Storage')"
+	return retailPricesURL + "?$filter=" + url.QueryEscape(filter)
+}
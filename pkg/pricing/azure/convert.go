@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Azure specific constants
+const (
+	deliminator     = "-"
+	consumption     = "Consumption"
+	virtualMachines = "Virtual Machines"
+	storage         = "Storage"
+	diskMeterSuffix = " Disk"
+	windowsSuffix   = " Windows"
+	linux           = "linux"
+	windows         = "windows"
+	perGiB          = "per-GiB"
+
+	perHour     = "1 Hour"
+	perMonth    = "1/Month"
+	perGBMonth  = "1 GB/Month"
+	perGiBMonth = "1 GiB/Month"
+	perGiBHour  = "1 GiB/Hour"
+)
+
+// spotMeter is the suffix of virtual machine meters(ex: "D2s v3 Spot") of spot prices
+const spotMeter = " Spot"
+
+// interruptible virtual machine meters which are neither on-demand nor spot prices
+var skippedVMMeters = []string{"Low Priority"}
+
+// diskSkus maps managed disk products to the prefix of disk sku name used by storage classes(ex: Premium_LRS)
+var diskSkus = map[string]string{
+	"Premium SSD Managed Disks":   "Premium",
+	"Premium SSD v2 Managed Disk": "PremiumV2",
+	"Standard SSD Managed Disks":  "StandardSSD",
+	"Standard HDD Managed Disks":  "Standard",
+	"Ultra Disks":                 "UltraSSD",
+}
+
+// diskTierSizes maps managed disk tier numbers(ex: 10 of P10, E10 and S10) to their size in GiB
+var diskTierSizes = map[string]float64{
+	"1": 4, "2": 8, "3": 16, "4": 32, "6": 64, "10": 128, "15": 256, "20": 512,
+	"30": 1024, "40": 2048, "50": 4096, "60": 8192, "70": 16384, "80": 32767,
+}
+
+func convertAzurePricingToPurserRateCard(region string, azurePricing *Pricing) *models.RateCard {
+	nodePrices, storagePrices := getResourcePricesFromAzurePricing(region, azurePricing)
+	return &models.RateCard{
+		ID:            dgraph.ID{Xid: models.RateCardXID},
+		IsRateCard:    true,
+		CloudProvider: models.Azure,
+		Region:        region,
+		NodePrices:    nodePrices,
+		StoragePrices: storagePrices,
+	}
+}
+
+func getResourcePricesFromAzurePricing(region string, azurePricing *Pricing) ([]*models.NodePrice, []*models.StoragePrice) {
+	// latest effective item is used when an item is listed more than once
+	latestItems := make(map[string]Item)
+	for _, item := range azurePricing.Items {
+		if item.Type != consumption || item.ArmRegionName != region {
+			continue
+		}
+		key := getItemKey(item)
+		if key == "" {
+			continue
+		}
+		if existing, isPresent := latestItems[key]; !isPresent || existing.EffectiveStartDate < item.EffectiveStartDate {
+			latestItems[key] = item
+		}
+	}
+
+	var nodePrices []*models.NodePrice
+	var storagePrices []*models.StoragePrice
+	spotPrices := make(map[string]float64)
+	for key, item := range latestItems {
+		switch item.ServiceName {
+		case virtualMachines:
+			if isSpotItem(item) {
+				spotPrices[strings.TrimSuffix(key, deliminator+models.SpotPricingTerm)] = item.RetailPrice
+				continue
+			}
+			nodePrices = append(nodePrices, getNodePrice(item))
+		case storage:
+			if storagePrice := getStoragePrice(item); storagePrice != nil {
+				storagePrices = append(storagePrices, storagePrice)
+			}
+		}
+	}
+	for _, nodePrice := range nodePrices {
+		nodePrice.SpotPrice = spotPrices[nodePrice.Xid]
+	}
+	return nodePrices, storagePrices
+}
+
+// getItemKey returns the XID of the node or storage price an item maps to, empty for unsupported items
+func getItemKey(item Item) string {
+	switch item.ServiceName {
+	case virtualMachines:
+		if item.ArmSkuName == "" || item.UnitOfMeasure != perHour {
+			return ""
+		}
+		for _, skipped := range skippedVMMeters {
+			if strings.Contains(item.MeterName, skipped) || strings.Contains(item.SkuName, skipped) {
+				return ""
+			}
+		}
+		key := item.ArmSkuName + deliminator + getOS(item)
+		if isSpotItem(item) {
+			return key + deliminator + models.SpotPricingTerm
+		}
+		return key
+	case storage:
+		volumeType, usageType := getDiskSkuAndTier(item)
+		if volumeType == "" {
+			return ""
+		}
+		return volumeType + deliminator + usageType
+	}
+	return ""
+}
+
+// isSpotItem tells whether a virtual machine item is the spot price of its sku
+func isSpotItem(item Item) bool {
+	return strings.HasSuffix(item.MeterName, spotMeter) || strings.HasSuffix(item.SkuName, spotMeter)
+}
+
+func getNodePrice(item Item) *models.NodePrice {
+	os := getOS(item)
+	// Unit of Compute price USD-perHour, per unit resource prices are derived from node capacity
+	return &models.NodePrice{
+		ID:              dgraph.ID{Xid: item.ArmSkuName + deliminator + os},
+		IsNodePrice:     true,
+		InstanceType:    item.ArmSkuName,
+		InstanceFamily:  strings.TrimSuffix(strings.TrimPrefix(item.ProductName, virtualMachines+" "), windowsSuffix),
+		OperatingSystem: os,
+		Price:           item.RetailPrice,
+	}
+}
+
+func getStoragePrice(item Item) *models.StoragePrice {
+	volumeType, usageType := getDiskSkuAndTier(item)
+
+	// convert to GBHour
+	var price float64
+	switch item.UnitOfMeasure {
+	case perMonth:
+		size, isPresent := diskTierSizes[strings.TrimLeft(usageType, "PES")]
+		if !isPresent {
+			logrus.Debugf("unknown size of azure disk tier: %s", usageType)
+			return nil
+		}
+		price = item.RetailPrice / size / models.HoursInMonth
+	case perGBMonth, perGiBMonth:
+		price = item.RetailPrice / models.HoursInMonth
+	case perGiBHour:
+		price = item.RetailPrice
+	default:
+		return nil
+	}
+
+	return &models.StoragePrice{
+		ID:             dgraph.ID{Xid: volumeType + deliminator + usageType},
+		IsStoragePrice: true,
+		VolumeType:     volumeType,
+		UsageType:      usageType,
+		Price:          price,
+	}
+}
+
+// getDiskSkuAndTier returns managed disk sku(ex: Premium_LRS) and tier(ex: P10) of a storage item
+// tier is per-GiB for disks which are priced by provisioned capacity
+func getDiskSkuAndTier(item Item) (string, string) {
+	skuPrefix, isPresent := diskSkus[item.ProductName]
+	if !isPresent {
+		return "", ""
+	}
+
+	// ex: skuName "P10 LRS" for Premium SSD, "Premium LRS" for Premium SSD v2
+	skuNameParts := strings.Fields(item.SkuName)
+	if len(skuNameParts) != 2 {
+		return "", ""
+	}
+	volumeType := skuPrefix + "_" + skuNameParts[1]
+
+	if item.UnitOfMeasure == perMonth {
+		// only disk capacity meters are priced, ex: "P10 LRS Disk" but not "P10 LRS Disk Mount"
+		if !strings.HasSuffix(item.MeterName, diskMeterSuffix) {
+			return "", ""
+		}
+		return volumeType, skuNameParts[0]
+	}
+	if !strings.Contains(item.MeterName, "Capacity") {
+		return "", ""
+	}
+	return volumeType, perGiB
+}
+
+func getOS(item Item) string {
+	if strings.HasSuffix(item.ProductName, windowsSuffix) {
+		return windows
+	}
+	return linux
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+const testPriceFile = "testdata/prices.json"
+
+func getTestRateCard(t *testing.T, region string) *models.RateCard {
+	azurePricing, err := GetAzurePricingFromFile(testPriceFile)
+	assert.NoError(t, err)
+	return convertAzurePricingToPurserRateCard(region, azurePricing)
+}
+
+func findNodePrice(rateCard *models.RateCard, xid string) *models.NodePrice {
+	for _, nodePrice := range rateCard.NodePrices {
+		if nodePrice.Xid == xid {
+			return nodePrice
+		}
+	}
+	return nil
+}
+
+func findStoragePrice(rateCard *models.RateCard, xid string) *models.StoragePrice {
+	for _, storagePrice := range rateCard.StoragePrices {
+		if storagePrice.Xid == xid {
+			return storagePrice
+		}
+	}
+	return nil
+}
+
+// TestGetAzurePricingFromFileWithMissingFile ...
+func TestGetAzurePricingFromFileWithMissingFile(t *testing.T) {
+	_, err := GetAzurePricingFromFile("testdata/missing.json")
+	assert.Error(t, err)
+}
+
+// TestConvertAzurePricingForVirtualMachines ...
+func TestConvertAzurePricingForVirtualMachines(t *testing.T) {
+	rateCard := getTestRateCard(t, "eastus")
+	assert.Equal(t, models.Azure, rateCard.CloudProvider)
+	assert.Len(t, rateCard.NodePrices, 2)
+
+	linuxPrice := findNodePrice(rateCard, "Standard_D2s_v3-linux")
+	assert.NotNil(t, linuxPrice)
+	assert.Equal(t, "Standard_D2s_v3", linuxPrice.InstanceType)
+	assert.Equal(t, "DSv3 Series", linuxPrice.InstanceFamily)
+	assert.Equal(t, "linux", linuxPrice.OperatingSystem)
+	// latest effective price is used
+	assert.Equal(t, 0.096, linuxPrice.Price)
+	// spot price is taken from the spot meter of the sku, low priority meters are skipped
+	assert.Equal(t, 0.0192, linuxPrice.SpotPrice)
+
+	windowsPrice := findNodePrice(rateCard, "Standard_D2s_v3-windows")
+	assert.NotNil(t, windowsPrice)
+	assert.Equal(t, "DSv3 Series", windowsPrice.InstanceFamily)
+	assert.Equal(t, 0.188, windowsPrice.Price)
+	assert.Equal(t, 0.0, windowsPrice.SpotPrice)
+}
+
+// TestConvertAzurePricingForManagedDisks ...
+func TestConvertAzurePricingForManagedDisks(t *testing.T) {
+	rateCard := getTestRateCard(t, "eastus")
+	assert.Len(t, rateCard.StoragePrices, 4)
+
+	premium := findStoragePrice(rateCard, "Premium_LRS-P10")
+	assert.NotNil(t, premium)
+	assert.Equal(t, "Premium_LRS", premium.VolumeType)
+	assert.Equal(t, "P10", premium.UsageType)
+	assert.InDelta(t, 19.71/128/models.HoursInMonth, premium.Price, 1e-12)
+
+	standardSSD := findStoragePrice(rateCard, "StandardSSD_LRS-E10")
+	assert.NotNil(t, standardSSD)
+	assert.InDelta(t, 9.6/128/models.HoursInMonth, standardSSD.Price, 1e-12)
+
+	standardHDD := findStoragePrice(rateCard, "Standard_LRS-S10")
+	assert.NotNil(t, standardHDD)
+	assert.InDelta(t, 5.89/128/models.HoursInMonth, standardHDD.Price, 1e-12)
+
+	ultra := findStoragePrice(rateCard, "UltraSSD_LRS-per-GiB")
+	assert.NotNil(t, ultra)
+	assert.Equal(t, 0.000164, ultra.Price)
+}
+
+// TestConvertAzurePricingForOtherRegion ...
+func TestConvertAzurePricingForOtherRegion(t *testing.T) {
+	rateCard := getTestRateCard(t, "westeurope")
+	assert.Len(t, rateCard.NodePrices, 1)
+	assert.Equal(t, 0.107, rateCard.NodePrices[0].Price)
+	assert.Empty(t, rateCard.StoragePrices)
+}
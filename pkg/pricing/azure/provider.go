@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"fmt"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Provider of virtual machine and managed disk prices, prices are read from priceFile instead of
+// the Retail Prices API when it is given
+type Provider struct {
+	priceFile string
+	pricing   *Pricing
+}
+
+// NewProvider returns azure pricing provider
+func NewProvider(priceFile string) *Provider {
+	return &Provider{priceFile: priceFile}
+}
+
+// Fetch retrieves the prices of the region
+func (p *Provider) Fetch(region string) error {
+	var err error
+	if p.priceFile != "" {
+		p.pricing, err = GetAzurePricingFromFile(p.priceFile)
+	} else {
+		p.pricing, err = GetAzurePricing(region)
+	}
+	return err
+}
+
+// RateCard converts the fetched prices of the region to purser's rate card
+func (p *Provider) RateCard(region string) (*models.RateCard, error) {
+	if p.pricing == nil {
+		return nil, fmt.Errorf("azure prices of region: %s are not fetched", region)
+	}
+	return convertAzurePricingToPurserRateCard(region, p.pricing), nil
+}
+
+// Version returns the latest effective start date among the fetched prices
+func (p *Provider) Version() string {
+	version := ""
+	if p.pricing == nil {
+		return version
+	}
+	for _, item := range p.pricing.Items {
+		if item.EffectiveStartDate > version {
+			version = item.EffectiveStartDate
+		}
+	}
+	return version
+}
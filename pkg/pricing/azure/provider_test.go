@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProviderBeforeFetch ...
+func TestProviderBeforeFetch(t *testing.T) {
+	provider := NewProvider(testPriceFile)
+	_, err := provider.RateCard("eastus")
+	assert.Error(t, err)
+	assert.Equal(t, "", provider.Version())
+}
+
+// TestProviderFromPriceFile ...
+func TestProviderFromPriceFile(t *testing.T) {
+	provider := NewProvider(testPriceFile)
+	assert.NoError(t, provider.Fetch("eastus"))
+	rateCard, err := provider.RateCard("eastus")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rateCard.NodePrices)
+	assert.Equal(t, "2021-01-01T00:00:00Z", provider.Version())
+}
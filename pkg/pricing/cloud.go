@@ -19,32 +19,195 @@ package pricing
 
 import (
 	"github.com/Sirupsen/logrus"
+	ratecard_client "github.com/vmware/purser/pkg/client/clientset/typed/ratecard/v1"
+	"github.com/vmware/purser/pkg/controller"
+	"github.com/vmware/purser/pkg/controller/dgraph"
 	"github.com/vmware/purser/pkg/controller/dgraph/models"
-	"github.com/vmware/purser/pkg/pricing/aws"
+	"github.com/vmware/purser/pkg/controller/utils"
+	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
 // Cloud structure used for pricing
 type Cloud struct {
-	CloudProvider string
-	Region        string
-	Kubeclient    *kubernetes.Clientset
+	Location
+	// Override is used when the location can't be detected from nodes
+	Override Location
+	// PriceFile is a downloaded price list of the cloud provider(ex: mounted from a volume), when it is
+	// given prices are read from it instead of the network. Required for gcp.
+	PriceFile string
+	// SpotDiscounts are comma separated instanceFamily=discount used to price spot nodes of price lists without
+	// spot prices(ex: aws)
+	SpotDiscounts  string
+	Kubeclient     *kubernetes.Clientset
+	RateCardclient *ratecard_client.RateCardClient
 }
 
-// GetClusterProviderAndRegion returns cluster provider(ex: aws) and region(ex: us-east-1)
-func GetClusterProviderAndRegion() (string, string) {
-	// TODO: https://github.com/vmware/purser/issues/143
-	cloudProvider := models.AWS
-	region := "us-east-1"
-	logrus.Infof("CloudProvider: %s, Region: %s", cloudProvider, region)
-	return cloudProvider, region
+// NewCloud returns cloud used for pricing with clients, price file and location override of the controller's config
+func NewCloud(conf *controller.Config) *Cloud {
+	return &Cloud{
+		Kubeclient:     conf.Kubeclient,
+		RateCardclient: conf.RateCardclient,
+		PriceFile:      conf.Cloud.PriceFile,
+		SpotDiscounts:  conf.Cloud.SpotDiscounts,
+		Override: Location{
+			CloudProvider: conf.Cloud.CloudProvider,
+			Region:        conf.Cloud.Region,
+			Zone:          conf.Cloud.Zone,
+		},
+	}
 }
 
-// PopulateRateCard given a cloud (cloudProvider and region) it populates corresponding rate card in dgraph
+// GetClusterProviderAndRegion returns cluster provider(ex: aws), region(ex: us-east-1) and zone
+// detected from node metadata, override is returned when nodes disagree or say nothing.
+func GetClusterProviderAndRegion(kubeclient *kubernetes.Clientset, override Location) Location {
+	var nodes []api_v1.Node
+	if nodeList := utils.RetrieveNodeList(kubeclient, meta_v1.ListOptions{}); nodeList != nil {
+		nodes = nodeList.Items
+	}
+	location := DetectLocation(nodes, override)
+	logrus.Infof("CloudProvider: %s, Region: %s, Zone: %s, Source: %s",
+		location.CloudProvider, location.Region, location.Zone, location.Source)
+	return location
+}
+
+// PopulateRateCard detects the cloud (cloudProvider and region) and populates corresponding rate card in dgraph.
+// Nothing is fetched when prices are defined by a RateCard custom resource.
 func (c *Cloud) PopulateRateCard() {
-	switch c.CloudProvider {
-	case models.AWS:
-		rateCard := aws.GetRateCardForAWS(c.Region)
-		models.StoreRateCard(rateCard)
+	if c.isRateCardDefinedByCRD() {
+		logrus.Infof("rate card is defined by RateCard custom resource, skipping cloud pricing")
+		return
+	}
+	c.storeRateCard()
+}
+
+// RestoreRateCard populates rate card of the cloud once prices of RateCard custom resources are deleted, nodes,
+// volumes and load balancers are repriced even when prices of the cloud can't be fetched so that deleted prices
+// aren't applied anymore
+func (c *Cloud) RestoreRateCard() {
+	if !c.storeRateCard() {
+		UpdateNodePrices(c.Kubeclient)
+		UpdateStoragePrices(c.Kubeclient)
+		UpdateServicePrices(c.Kubeclient)
+	}
+}
+
+// storeRateCard stores rate card of the detected cloud and reprices nodes, volumes and load balancers, returns false
+// if there is no rate card to store
+func (c *Cloud) storeRateCard() bool {
+	c.Location = GetClusterProviderAndRegion(c.Kubeclient, c.Override)
+
+	rateCard := c.getRateCard()
+	if rateCard == nil {
+		return false
+	}
+	rateCard.Zone = c.Zone
+	rateCard.LocationSource = c.Source
+	models.StoreRateCard(rateCard)
+	UpdateNodePrices(c.Kubeclient)
+	UpdateStoragePrices(c.Kubeclient)
+	UpdateServicePrices(c.Kubeclient)
+	return true
+}
+
+// getRateCard returns rate card from the provider registered for the cloud provider, a rate card without
+// prices is returned when there is no such provider so that default prices are used
+func (c *Cloud) getRateCard() *models.RateCard {
+	provider, err := GetProvider(c.CloudProvider, c.PriceFile)
+	if err != nil {
+		logrus.Warnf("%v, registered providers: %v, default prices will be used", err, RegisteredProviders())
+		return &models.RateCard{
+			ID:            dgraph.ID{Xid: models.RateCardXID},
+			IsRateCard:    true,
+			CloudProvider: c.CloudProvider,
+			Region:        c.Region,
+		}
+	}
+
+	err = provider.Fetch(c.Region)
+	if err != nil {
+		logrus.Errorf("unable to fetch prices of cloud provider: %s, region: %s, err: %v", c.CloudProvider, c.Region, err)
+		return nil
+	}
+	rateCard, err := provider.RateCard(c.Region)
+	if err != nil {
+		logrus.Errorf("unable to convert prices of cloud provider: %s, region: %s, err: %v", c.CloudProvider, c.Region, err)
+		return nil
+	}
+	rateCard.Version = provider.Version()
+	c.setSpotPrices(rateCard)
+	return rateCard
+}
+
+// setSpotPrices prices spot instances missing from the price list with spot discounts of the cloud
+func (c *Cloud) setSpotPrices(rateCard *models.RateCard) {
+	discounts, err := parseSpotDiscounts(c.SpotDiscounts)
+	if err != nil {
+		logrus.Errorf("unable to parse spot discounts, spot nodes without spot prices are priced on-demand: %v", err)
+		return
+	}
+	applySpotDiscounts(rateCard, discounts)
+}
+
+func (c *Cloud) isRateCardDefinedByCRD() bool {
+	if c.RateCardclient == nil {
+		return false
+	}
+	rateCards, err := c.RateCardclient.List(meta_v1.ListOptions{})
+	if err != nil {
+		logrus.Errorf("unable to list RateCard custom resources: %v", err)
+		return false
+	}
+	return len(rateCards.Items) > 0
+}
+
+// UpdateNodePrices stores live nodes again so that their per unit resource prices are taken from the latest rate card,
+// pods running on them are repriced after that
+func UpdateNodePrices(kubeclient *kubernetes.Clientset) {
+	nodeList := utils.RetrieveNodeList(kubeclient, meta_v1.ListOptions{})
+	if nodeList == nil {
+		return
+	}
+	for _, node := range nodeList.Items {
+		_, err := models.StoreNode(node)
+		if err != nil {
+			logrus.Errorf("unable to update prices of node: %s, err: %v", node.Name, err)
+		}
+	}
+	models.UpdatePodPrices()
+}
+
+// UpdateStoragePrices stores persistent volumes again so that they are priced with the latest rate card,
+// storage of their claims and pods is repriced after that
+func UpdateStoragePrices(kubeclient *kubernetes.Clientset) {
+	pvList := utils.RetrievePersistentVolumeList(kubeclient, meta_v1.ListOptions{})
+	if pvList == nil {
+		return
+	}
+	for _, pv := range pvList.Items {
+		_, err := models.StorePersistentVolume(pv, kubeclient)
+		if err != nil {
+			logrus.Errorf("unable to update storage price of persistent volume: %s, err: %v", pv.Name, err)
+		}
+	}
+	models.UpdatePodStoragePrices()
+}
+
+// UpdateServicePrices stores LoadBalancer services again so that their load balancers are priced with the latest
+// rate card
+func UpdateServicePrices(kubeclient *kubernetes.Clientset) {
+	serviceList := utils.RetrieveServiceList(kubeclient, meta_v1.ListOptions{})
+	if serviceList == nil {
+		return
+	}
+	for _, service := range serviceList.Items {
+		if service.Spec.Type != api_v1.ServiceTypeLoadBalancer {
+			continue
+		}
+		err := models.StoreService(service)
+		if err != nil {
+			logrus.Errorf("unable to update price of load balancer of service: %s, err: %v", service.Name, err)
+		}
 	}
 }
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	api_v1 "k8s.io/api/core/v1"
+)
+
+// Well known node labels carrying topology information
+const (
+	RegionLabelKey     = "topology.kubernetes.io/region"
+	ZoneLabelKey       = "topology.kubernetes.io/zone"
+	BetaRegionLabelKey = "failure-domain.beta.kubernetes.io/region"
+	BetaZoneLabelKey   = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// Sources of a cluster location
+const (
+	SourceNodes   = "nodes"
+	SourceConfig  = "config"
+	SourceDefault = "default"
+)
+
+// Location used when neither the nodes nor the controller config tell where the cluster runs
+const (
+	defaultCloudProvider = models.AWS
+	defaultRegion        = "us-east-1"
+)
+
+// providerIDSchemes maps node spec.providerID schemes to purser's cloud provider names
+var providerIDSchemes = map[string]string{
+	"aws":     models.AWS,
+	"gce":     models.GCP,
+	"azure":   models.Azure,
+	"vsphere": models.VSphere,
+}
+
+// Location of a cluster: cloud provider, region and zone along with where these values came from
+type Location struct {
+	CloudProvider string
+	Region        string
+	Zone          string
+	Source        string
+}
+
+// DetectLocation infers cloud provider, region and zone from node metadata.
+// The override is used for cloud provider and region when the nodes disagree or say nothing.
+// Zones of a multi zone cluster are reported as a comma separated list.
+func DetectLocation(nodes []api_v1.Node, override Location) Location {
+	providers := map[string]bool{}
+	regions := map[string]bool{}
+	zones := map[string]bool{}
+	for _, node := range nodes {
+		nodeLocation := GetNodeLocation(node)
+		if nodeLocation.CloudProvider != "" {
+			providers[nodeLocation.CloudProvider] = true
+		}
+		if nodeLocation.Region != "" {
+			regions[nodeLocation.Region] = true
+		}
+		if nodeLocation.Zone != "" {
+			zones[nodeLocation.Zone] = true
+		}
+	}
+
+	if len(providers) == 1 && len(regions) == 1 {
+		return Location{
+			CloudProvider: onlyKey(providers),
+			Region:        onlyKey(regions),
+			Zone:          strings.Join(sortedKeys(zones), ","),
+			Source:        SourceNodes,
+		}
+	}
+
+	location := override
+	if location.CloudProvider == "" || location.Region == "" {
+		// partially detected values are still better than the default
+		if location.CloudProvider == "" && len(providers) == 1 {
+			location.CloudProvider = onlyKey(providers)
+		}
+		if location.Region == "" && len(regions) == 1 {
+			location.Region = onlyKey(regions)
+		}
+	}
+	if location.CloudProvider == "" || location.Region == "" {
+		return Location{CloudProvider: defaultCloudProvider, Region: defaultRegion, Source: SourceDefault}
+	}
+	if location.Zone == "" && len(zones) == 1 {
+		location.Zone = onlyKey(zones)
+	}
+	location.Source = SourceConfig
+	return location
+}
+
+// GetNodeLocation returns the location of a single node, labels take precedence over spec.providerID
+func GetNodeLocation(node api_v1.Node) Location {
+	location := parseProviderID(node.Spec.ProviderID)
+	labels := node.GetLabels()
+
+	if region := getFirstLabel(labels, RegionLabelKey, BetaRegionLabelKey); region != "" {
+		location.Region = region
+	}
+	if zone := getFirstLabel(labels, ZoneLabelKey, BetaZoneLabelKey); zone != "" {
+		location.Zone = zone
+	}
+	if location.CloudProvider == "" {
+		instanceType := getFirstLabel(labels, models.StableInstanceTypeLabelKey, models.InstanceTypeLabelKey)
+		location.CloudProvider = getProviderFromInstanceType(instanceType)
+	}
+	return location
+}
+
+// parseProviderID extracts cloud provider and zone(region for aws and gce) from a node's spec.providerID
+// ex: aws:///us-east-1a/i-0123456789, gce://project/us-central1-a/instance, azure:///subscriptions/...
+func parseProviderID(providerID string) Location {
+	location := Location{}
+	parts := strings.SplitN(providerID, "://", 2)
+	if len(parts) != 2 {
+		return location
+	}
+	location.CloudProvider = providerIDSchemes[parts[0]]
+
+	segments := strings.Split(strings.Trim(parts[1], "/"), "/")
+	switch location.CloudProvider {
+	case models.AWS:
+		// aws:///<zone>/<instance-id>
+		if len(segments) == 2 {
+			location.Zone = segments[0]
+			location.Region = strings.TrimRight(location.Zone, "abcdefghijklmnopqrstuvwxyz")
+		}
+	case models.GCP:
+		// gce://<project>/<zone>/<instance-name>
+		if len(segments) == 3 {
+			location.Zone = segments[1]
+			if index := strings.LastIndex(location.Zone, "-"); index > 0 {
+				location.Region = location.Zone[:index]
+			}
+		}
+	}
+	return location
+}
+
+// getProviderFromInstanceType guesses the cloud provider from the naming scheme of its instance types
+// ex: m5.large(aws), n1-standard-4(gcp), Standard_D2s_v3(azure)
+func getProviderFromInstanceType(instanceType string) string {
+	switch {
+	case instanceType == "":
+		return ""
+	case strings.HasPrefix(instanceType, "Standard_") || strings.HasPrefix(instanceType, "Basic_"):
+		return models.Azure
+	case strings.Count(instanceType, ".") == 1:
+		return models.AWS
+	case strings.Count(instanceType, "-") >= 2:
+		return models.GCP
+	}
+	return ""
+}
+
+func getFirstLabel(labels map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func onlyKey(set map[string]bool) string {
+	for key := range set {
+		return key
+	}
+	return ""
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := []string{}
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	api_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestNode(providerID string, labels map[string]string) api_v1.Node {
+	return api_v1.Node{
+		ObjectMeta: meta_v1.ObjectMeta{Labels: labels},
+		Spec:       api_v1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+// TestDetectLocationFromProviderID ...
+func TestDetectLocationFromProviderID(t *testing.T) {
+	nodes := []api_v1.Node{
+		getTestNode("aws:///us-west-2a/i-0a1b2c3d", nil),
+		getTestNode("aws:///us-west-2b/i-0e1f2a3b", nil),
+	}
+	got := DetectLocation(nodes, Location{})
+	expected := Location{CloudProvider: models.AWS, Region: "us-west-2", Zone: "us-west-2a,us-west-2b", Source: SourceNodes}
+	assert.Equal(t, expected, got)
+}
+
+// TestDetectLocationFromLabels ...
+func TestDetectLocationFromLabels(t *testing.T) {
+	nodes := []api_v1.Node{
+		getTestNode("azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-0", map[string]string{
+			RegionLabelKey: "westeurope",
+			ZoneLabelKey:   "westeurope-1",
+		}),
+		getTestNode("", map[string]string{
+			BetaRegionLabelKey:                "westeurope",
+			BetaZoneLabelKey:                  "westeurope-1",
+			models.StableInstanceTypeLabelKey: "Standard_D2s_v3",
+		}),
+	}
+	got := DetectLocation(nodes, Location{})
+	expected := Location{CloudProvider: models.Azure, Region: "westeurope", Zone: "westeurope-1", Source: SourceNodes}
+	assert.Equal(t, expected, got)
+}
+
+// TestDetectLocationWithDisagreeingNodes ...
+func TestDetectLocationWithDisagreeingNodes(t *testing.T) {
+	nodes := []api_v1.Node{
+		getTestNode("gce://purser/us-central1-a/node-1", nil),
+		getTestNode("gce://purser/europe-west1-b/node-2", nil),
+	}
+	override := Location{CloudProvider: models.GCP, Region: "us-central1"}
+	got := DetectLocation(nodes, override)
+	expected := Location{CloudProvider: models.GCP, Region: "us-central1", Source: SourceConfig}
+	assert.Equal(t, expected, got)
+}
+
+// TestDetectLocationWithPartialOverride ...
+func TestDetectLocationWithPartialOverride(t *testing.T) {
+	nodes := []api_v1.Node{
+		getTestNode("vsphere://4201b4c1-7e2a-11e8", nil),
+	}
+	got := DetectLocation(nodes, Location{Region: "datacenter-1"})
+	expected := Location{CloudProvider: models.VSphere, Region: "datacenter-1", Source: SourceConfig}
+	assert.Equal(t, expected, got)
+}
+
+// TestDetectLocationWithoutInformation ...
+func TestDetectLocationWithoutInformation(t *testing.T) {
+	got := DetectLocation([]api_v1.Node{getTestNode("", nil)}, Location{})
+	expected := Location{CloudProvider: models.AWS, Region: "us-east-1", Source: SourceDefault}
+	assert.Equal(t, expected, got)
+}
+
+// TestParseProviderID ...
+func TestParseProviderID(t *testing.T) {
+	assert.Equal(t, Location{CloudProvider: models.AWS, Region: "ap-south-1", Zone: "ap-south-1a"}, parseProviderID("aws:///ap-south-1a/i-0a1b2c3d"))
+	assert.Equal(t, Location{CloudProvider: models.GCP, Region: "us-east4", Zone: "us-east4-c"}, parseProviderID("gce://purser/us-east4-c/node-1"))
+	assert.Equal(t, Location{}, parseProviderID("kind://docker/kind/kind-control-plane"))
+	assert.Equal(t, Location{}, parseProviderID(""))
+}
+
+// TestGetProviderFromInstanceType ...
+func TestGetProviderFromInstanceType(t *testing.T) {
+	assert.Equal(t, models.AWS, getProviderFromInstanceType("m5.large"))
+	assert.Equal(t, models.GCP, getProviderFromInstanceType("n1-standard-4"))
+	assert.Equal(t, models.Azure, getProviderFromInstanceType("Standard_D2s_v3"))
+	assert.Equal(t, "", getProviderFromInstanceType("purser-default"))
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// GCP specific constants
+const (
+	deliminator   = "-"
+	onDemand      = "OnDemand"
+	preemptible   = "Preemptible"
+	computeFamily = "Compute"
+	storageFamily = "Storage"
+	instanceCore  = "Instance Core"
+	instanceRAM   = "Instance Ram"
+	custom        = "custom"
+	predefined    = "predefined"
+	linux         = "linux"
+	defaultFamily = "n1"
+	regionalDisk  = "Regional "
+	zonal         = "zonal"
+	regional      = "regional"
+	perHour       = "h"
+	perGiBHour    = "GiBy.h"
+	perGiBMonth   = "GiBy.mo"
+	nanosPerUnit  = 1e9
+)
+
+// ex: N1, E2, N2D, C2
+var machineFamilyRegex = regexp.MustCompile(`^[A-Z][0-9][A-Z]?$`)
+
+// unsupported compute SKUs which share the description format of regular machine types
+var skippedComputeSkus = []string{"Extended", "Sole Tenancy", "Commitment", "Preemptible", "Spot", "Premium"}
+
+// diskTypes maps description of persistent disk capacity SKUs to their disk type
+var diskTypes = map[string]string{
+	"Storage PD Capacity":    "pd-standard",
+	"SSD backed PD Capacity": "pd-ssd",
+	"Balanced PD Capacity":   "pd-balanced",
+}
+
+// predefinedShapes memory(GiB) per vCPU for predefined machine types of a family
+var predefinedShapes = map[string]map[string]float64{
+	"n1":  {"standard": 3.75, "highmem": 6.5, "highcpu": 0.9},
+	"n2":  {"standard": 4, "highmem": 8, "highcpu": 1},
+	"n2d": {"standard": 4, "highmem": 8, "highcpu": 1},
+	"e2":  {"standard": 4, "highmem": 8, "highcpu": 1},
+	"c2":  {"standard": 4},
+}
+
+// predefinedCPUs vCPU counts offered by predefined machine types of a family
+var predefinedCPUs = map[string][]int{
+	"n1":  {1, 2, 4, 8, 16, 32, 64, 96},
+	"n2":  {2, 4, 8, 16, 32, 48, 64, 80, 96, 128},
+	"n2d": {2, 4, 8, 16, 32, 48, 64, 80, 96, 128, 224},
+	"e2":  {2, 4, 8, 16, 32},
+	"c2":  {4, 8, 16, 30, 60},
+}
+
+// unitPrices per vCPU and per GiB on-demand and spot prices of a machine family
+type unitPrices struct {
+	cpu        float64
+	memory     float64
+	spotCPU    float64
+	spotMemory float64
+}
+
+func convertGCPPricingToPurserRateCard(region string, catalog *Catalog) *models.RateCard {
+	nodePrices, storagePrices := getResourcePricesFromGCPPricing(region, catalog)
+	return &models.RateCard{
+		ID:            dgraph.ID{Xid: models.RateCardXID},
+		IsRateCard:    true,
+		CloudProvider: models.GCP,
+		Region:        region,
+		NodePrices:    nodePrices,
+		StoragePrices: storagePrices,
+	}
+}
+
+func getResourcePricesFromGCPPricing(region string, catalog *Catalog) ([]*models.NodePrice, []*models.StoragePrice) {
+	familyPrices := make(map[string]*unitPrices)
+	var storagePrices []*models.StoragePrice
+	for _, sku := range catalog.Skus {
+		// spot prices of compute are listed under preemptible usage type
+		isSpot := sku.Category.UsageType == preemptible
+		if (sku.Category.UsageType != onDemand && !isSpot) || !isOfferedInRegion(sku, region) {
+			continue
+		}
+		price, err := getPricePerHour(sku)
+		if err != nil {
+			logrus.Debugf("skipping gcp sku: %s, reason: %v", sku.SkuID, err)
+			continue
+		}
+
+		switch {
+		case sku.Category.ResourceFamily == computeFamily:
+			updateFamilyPrices(sku, price, isSpot, familyPrices)
+		case sku.Category.ResourceFamily == storageFamily && !isSpot:
+			storagePrices = updateStoragePrices(sku, price, storagePrices)
+		}
+	}
+	return getNodePricesFromFamilyPrices(familyPrices), storagePrices
+}
+
+// updateFamilyPrices records per vCPU or per GiB price of a machine family from a compute SKU
+// ex: "N1 Predefined Instance Core running in Americas", "N2 Custom Instance Ram running in EMEA",
+// "Spot Preemptible N1 Predefined Instance Core running in Americas"
+func updateFamilyPrices(sku Sku, price float64, isSpot bool, familyPrices map[string]*unitPrices) {
+	description := sku.Description
+	if isSpot {
+		description = strings.TrimPrefix(strings.TrimPrefix(description, "Spot "), preemptible+" ")
+	}
+	isCore := strings.Contains(description, instanceCore)
+	if !isCore && !strings.Contains(description, instanceRAM) {
+		return
+	}
+	for _, skipped := range skippedComputeSkus {
+		if strings.Contains(description, skipped) {
+			return
+		}
+	}
+
+	family := defaultFamily
+	if words := strings.Fields(description); len(words) > 0 && machineFamilyRegex.MatchString(words[0]) {
+		family = strings.ToLower(words[0])
+	}
+	class := predefined
+	if strings.Contains(description, "Custom") {
+		class = custom
+	}
+
+	key := family + deliminator + class
+	if _, isPresent := familyPrices[key]; !isPresent {
+		familyPrices[key] = &unitPrices{}
+	}
+	prices := familyPrices[key]
+	switch {
+	case isCore && isSpot:
+		prices.spotCPU = price
+	case isCore:
+		prices.cpu = price
+	case isSpot:
+		prices.spotMemory = price
+	default:
+		prices.memory = price
+	}
+}
+
+// getNodePricesFromFamilyPrices returns per unit prices of every machine family(ex: n1-custom, n1-predefined)
+// along with prices of predefined machine types(ex: n1-standard-4) of the family
+func getNodePricesFromFamilyPrices(familyPrices map[string]*unitPrices) []*models.NodePrice {
+	var nodePrices []*models.NodePrice
+	for key, prices := range familyPrices {
+		if prices.cpu == 0 || prices.memory == 0 {
+			logrus.Debugf("incomplete gcp prices for machine family: %s", key)
+			continue
+		}
+		family := strings.Split(key, deliminator)[0]
+		nodePrices = append(nodePrices, newNodePrice(key, family, 0, prices))
+
+		if !strings.HasSuffix(key, predefined) {
+			continue
+		}
+		for shape, memoryPerCPU := range predefinedShapes[family] {
+			for _, cpus := range predefinedCPUs[family] {
+				instanceType := fmt.Sprintf("%s-%s-%d", family, shape, cpus)
+				price := float64(cpus)*prices.cpu + float64(cpus)*memoryPerCPU*prices.memory
+				nodePrice := newNodePrice(instanceType, family, price, prices)
+				nodePrice.CPUCapacity = float64(cpus)
+				nodePrice.MemoryCapacity = float64(cpus) * memoryPerCPU
+				if prices.spotCPU > 0 && prices.spotMemory > 0 {
+					nodePrice.SpotPrice = nodePrice.CPUCapacity*prices.spotCPU + nodePrice.MemoryCapacity*prices.spotMemory
+				}
+				nodePrices = append(nodePrices, nodePrice)
+			}
+		}
+	}
+	return nodePrices
+}
+
+func newNodePrice(instanceType, family string, price float64, prices *unitPrices) *models.NodePrice {
+	return &models.NodePrice{
+		ID:              dgraph.ID{Xid: instanceType + deliminator + linux},
+		IsNodePrice:     true,
+		InstanceType:    instanceType,
+		InstanceFamily:  family,
+		OperatingSystem: linux,
+		Price:           price,
+		PricePerCPU:     prices.cpu,
+		PricePerMemory:  prices.memory,
+	}
+}
+
+// updateStoragePrices appends price of a persistent disk SKU
+// ex: "SSD backed PD Capacity", "Regional Balanced PD Capacity in Sydney"
+func updateStoragePrices(sku Sku, price float64, storagePrices []*models.StoragePrice) []*models.StoragePrice {
+	description := sku.Description
+	usageType := zonal
+	if strings.HasPrefix(description, regionalDisk) {
+		description = strings.TrimPrefix(description, regionalDisk)
+		usageType = regional
+	}
+
+	for diskDescription, volumeType := range diskTypes {
+		if strings.HasPrefix(description, diskDescription) {
+			return append(storagePrices, &models.StoragePrice{
+				ID:             dgraph.ID{Xid: volumeType + deliminator + usageType},
+				IsStoragePrice: true,
+				VolumeType:     volumeType,
+				UsageType:      usageType,
+				Price:          price,
+			})
+		}
+	}
+	return storagePrices
+}
+
+func isOfferedInRegion(sku Sku, region string) bool {
+	for _, serviceRegion := range sku.ServiceRegions {
+		if serviceRegion == region {
+			return true
+		}
+	}
+	return false
+}
+
+// getPricePerHour returns the first non zero tier price of a SKU converted to USD-perHour(per vCPU or per GiB)
+func getPricePerHour(sku Sku) (float64, error) {
+	if len(sku.PricingInfo) < 1 {
+		return models.PriceError, fmt.Errorf("no pricing info")
+	}
+	expression := sku.PricingInfo[0].PricingExpression
+
+	price := 0.0
+	for _, tier := range expression.TieredRates {
+		tierPrice, err := tier.UnitPrice.toFloat64()
+		if err != nil {
+			return models.PriceError, err
+		}
+		if tierPrice > 0 {
+			price = tierPrice
+			break
+		}
+	}
+
+	switch expression.UsageUnit {
+	case perHour, perGiBHour:
+		return price, nil
+	case perGiBMonth:
+		// convert to GBHour
+		return price / models.HoursInMonth, nil
+	}
+	return models.PriceError, fmt.Errorf("unsupported usage unit: %s", expression.UsageUnit)
+}
+
+func (m Money) toFloat64() (float64, error) {
+	units := 0.0
+	if m.Units != "" {
+		var err error
+		units, err = strconv.ParseFloat(m.Units, 64)
+		if err != nil {
+			return models.PriceError, err
+		}
+	}
+	return units + float64(m.Nanos)/nanosPerUnit, nil
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+const testPriceFile = "testdata/skus.json"
+
+func getTestRateCard(t *testing.T, region string) *models.RateCard {
+	catalog, err := GetGCPPricingFromFile(testPriceFile)
+	assert.NoError(t, err)
+	return convertGCPPricingToPurserRateCard(region, catalog)
+}
+
+func getNodePrice(rateCard *models.RateCard, instanceType string) *models.NodePrice {
+	for _, nodePrice := range rateCard.NodePrices {
+		if nodePrice.InstanceType == instanceType {
+			return nodePrice
+		}
+	}
+	return nil
+}
+
+func getStoragePrice(rateCard *models.RateCard, volumeType, usageType string) *models.StoragePrice {
+	for _, storagePrice := range rateCard.StoragePrices {
+		if storagePrice.VolumeType == volumeType && storagePrice.UsageType == usageType {
+			return storagePrice
+		}
+	}
+	return nil
+}
+
+// TestGetGCPPricingFromFileWithMissingFile ...
+func TestGetGCPPricingFromFileWithMissingFile(t *testing.T) {
+	_, err := GetGCPPricingFromFile("testdata/missing.json")
+	assert.Error(t, err)
+}
+
+// TestConvertGCPPricingForMachineFamilies ...
+func TestConvertGCPPricingForMachineFamilies(t *testing.T) {
+	rateCard := getTestRateCard(t, "us-central1")
+	assert.Equal(t, models.GCP, rateCard.CloudProvider)
+	assert.Equal(t, "us-central1", rateCard.Region)
+
+	predefinedN1 := getNodePrice(rateCard, "n1-predefined")
+	assert.NotNil(t, predefinedN1)
+	assert.Equal(t, "n1-predefined-linux", predefinedN1.Xid)
+	assert.InDelta(t, 0.031611, predefinedN1.PricePerCPU, 1e-9)
+	assert.InDelta(t, 0.004237, predefinedN1.PricePerMemory, 1e-9)
+
+	customN1 := getNodePrice(rateCard, "n1-custom")
+	assert.NotNil(t, customN1)
+	assert.InDelta(t, 0.033174, customN1.PricePerCPU, 1e-9)
+	assert.InDelta(t, 0.004446, customN1.PricePerMemory, 1e-9)
+
+	e2 := getNodePrice(rateCard, "e2-predefined")
+	assert.NotNil(t, e2)
+	assert.InDelta(t, 0.021811, e2.PricePerCPU, 1e-9)
+	assert.Nil(t, getNodePrice(rateCard, "e2-custom"))
+}
+
+// TestConvertGCPPricingForPredefinedMachineTypes ...
+func TestConvertGCPPricingForPredefinedMachineTypes(t *testing.T) {
+	rateCard := getTestRateCard(t, "us-central1")
+
+	n1Standard4 := getNodePrice(rateCard, "n1-standard-4")
+	assert.NotNil(t, n1Standard4)
+	assert.Equal(t, "n1", n1Standard4.InstanceFamily)
+	assert.Equal(t, "linux", n1Standard4.OperatingSystem)
+	assert.InDelta(t, 4*0.031611+15*0.004237, n1Standard4.Price, 1e-9)
+	assert.Equal(t, 4.0, n1Standard4.CPUCapacity)
+	assert.Equal(t, 15.0, n1Standard4.MemoryCapacity)
+	// spot price is taken from preemptible skus of the family
+	assert.InDelta(t, 4*0.00668+15*0.000892, n1Standard4.SpotPrice, 1e-9)
+
+	e2Highmem8 := getNodePrice(rateCard, "e2-highmem-8")
+	assert.NotNil(t, e2Highmem8)
+	assert.InDelta(t, 8*0.021811+64*0.002923, e2Highmem8.Price, 1e-9)
+	assert.Equal(t, 0.0, e2Highmem8.SpotPrice)
+}
+
+// TestConvertGCPPricingForOtherRegion ...
+func TestConvertGCPPricingForOtherRegion(t *testing.T) {
+	rateCard := getTestRateCard(t, "europe-west1")
+	// ram price isn't available for europe-west1 in test data
+	assert.Empty(t, rateCard.NodePrices)
+	assert.Empty(t, rateCard.StoragePrices)
+}
+
+// TestConvertGCPPricingForDisks ...
+func TestConvertGCPPricingForDisks(t *testing.T) {
+	rateCard := getTestRateCard(t, "us-central1")
+	assert.Len(t, rateCard.StoragePrices, 4)
+
+	standard := getStoragePrice(rateCard, "pd-standard", "zonal")
+	assert.NotNil(t, standard)
+	assert.Equal(t, "pd-standard-zonal", standard.Xid)
+	assert.InDelta(t, 0.04/models.HoursInMonth, standard.Price, 1e-12)
+
+	ssd := getStoragePrice(rateCard, "pd-ssd", "zonal")
+	assert.NotNil(t, ssd)
+	assert.InDelta(t, 0.17/models.HoursInMonth, ssd.Price, 1e-12)
+
+	balanced := getStoragePrice(rateCard, "pd-balanced", "zonal")
+	assert.NotNil(t, balanced)
+	assert.InDelta(t, 0.1/models.HoursInMonth, balanced.Price, 1e-12)
+
+	regionalSSD := getStoragePrice(rateCard, "pd-ssd", "regional")
+	assert.NotNil(t, regionalSSD)
+	assert.InDelta(t, 0.34/models.HoursInMonth, regionalSSD.Price, 1e-12)
+}
+
+// TestGetPricePerHourSkipsFreeTier ...
+func TestGetPricePerHourSkipsFreeTier(t *testing.T) {
+	sku := Sku{
+		PricingInfo: []PricingInfo{{
+			PricingExpression: PricingExpression{
+				UsageUnit: perGiBMonth,
+				TieredRates: []TieredRate{
+					{StartUsageAmount: 0, UnitPrice: Money{Units: "0"}},
+					{StartUsageAmount: 30, UnitPrice: Money{Units: "1", Nanos: 440000000}},
+				},
+			},
+		}},
+	}
+	price, err := getPricePerHour(sku)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.44/models.HoursInMonth, price, 1e-12)
+
+	sku.PricingInfo[0].PricingExpression.UsageUnit = "GiBy.d"
+	_, err = getPricePerHour(sku)
+	assert.Error(t, err)
+}
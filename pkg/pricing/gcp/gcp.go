@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// Catalog structure of a Cloud Billing catalog SKU document
+// ex: https://cloudbilling.googleapis.com/v1/services/6F81-5844-456A/skus (Compute Engine)
+type Catalog struct {
+	Skus []Sku `json:"skus"`
+}
+
+// Sku structure
+type Sku struct {
+	SkuID          string        `json:"skuId"`
+	Description    string        `json:"description"`
+	Category       Category      `json:"category"`
+	ServiceRegions []string      `json:"serviceRegions"`
+	PricingInfo    []PricingInfo `json:"pricingInfo"`
+}
+
+// Category structure
+type Category struct {
+	ResourceFamily string `json:"resourceFamily"`
+	ResourceGroup  string `json:"resourceGroup"`
+	UsageType      string `json:"usageType"`
+}
+
+// PricingInfo structure
+type PricingInfo struct {
+	EffectiveTime     string            `json:"effectiveTime"`
+	PricingExpression PricingExpression `json:"pricingExpression"`
+}
+
+// PricingExpression structure
+type PricingExpression struct {
+	UsageUnit   string       `json:"usageUnit"`
+	TieredRates []TieredRate `json:"tieredRates"`
+}
+
+// TieredRate structure
+type TieredRate struct {
+	StartUsageAmount float64 `json:"startUsageAmount"`
+	UnitPrice        Money   `json:"unitPrice"`
+}
+
+// Money structure, amount is units + nanos * 10^-9
+type Money struct {
+	CurrencyCode string `json:"currencyCode"`
+	Units        string `json:"units"`
+	Nanos        int64  `json:"nanos"`
+}
+
+// GetGCPPricingFromFile reads a previously downloaded Cloud Billing catalog SKU document
+func GetGCPPricingFromFile(path string) (*Catalog, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		logrus.Errorf("Unable to read gcp pricing file: %s. Reason: %v", path, err)
+		return nil, err
+	}
+
+	catalog := Catalog{}
+	err = json.Unmarshal(data, &catalog)
+	if err != nil {
+		logrus.Errorf("Unable to parse gcp pricing file: %s. Reason: %v", path, err)
+		return nil, err
+	}
+	return &catalog, nil
+}
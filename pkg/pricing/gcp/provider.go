@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"fmt"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// Provider of Compute Engine prices, prices are read from priceFile as the catalog API needs an api key
+type Provider struct {
+	priceFile string
+	catalog   *Catalog
+}
+
+// NewProvider returns gcp pricing provider
+func NewProvider(priceFile string) *Provider {
+	return &Provider{priceFile: priceFile}
+}
+
+// Fetch reads the Cloud Billing catalog SKU document from priceFile
+func (p *Provider) Fetch(region string) error {
+	if p.priceFile == "" {
+		return fmt.Errorf("gcp prices of region: %s can only be read from a price file", region)
+	}
+	var err error
+	p.catalog, err = GetGCPPricingFromFile(p.priceFile)
+	return err
+}
+
+// RateCard converts the fetched SKUs of the region to purser's rate card
+func (p *Provider) RateCard(region string) (*models.RateCard, error) {
+	if p.catalog == nil {
+		return nil, fmt.Errorf("gcp prices of region: %s are not fetched", region)
+	}
+	return convertGCPPricingToPurserRateCard(region, p.catalog), nil
+}
+
+// Version returns the latest effective time among the fetched SKU prices
+func (p *Provider) Version() string {
+	version := ""
+	if p.catalog == nil {
+		return version
+	}
+	for _, sku := range p.catalog.Skus {
+		for _, pricingInfo := range sku.PricingInfo {
+			if pricingInfo.EffectiveTime > version {
+				version = pricingInfo.EffectiveTime
+			}
+		}
+	}
+	return version
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestProviderWithoutPriceFile ...
+func TestProviderWithoutPriceFile(t *testing.T) {
+	provider := NewProvider("")
+	assert.Error(t, provider.Fetch("us-central1"))
+	_, err := provider.RateCard("us-central1")
+	assert.Error(t, err)
+	assert.Equal(t, "", provider.Version())
+}
+
+// TestProviderFromPriceFile ...
+func TestProviderFromPriceFile(t *testing.T) {
+	provider := NewProvider(testPriceFile)
+	assert.NoError(t, provider.Fetch("us-central1"))
+	rateCard, err := provider.RateCard("us-central1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rateCard.NodePrices)
+	assert.Equal(t, "2019-01-01T00:00:00Z", provider.Version())
+}
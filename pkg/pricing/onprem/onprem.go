@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package onprem
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Sirupsen/logrus"
+	ratecard_v1 "github.com/vmware/purser/pkg/apis/ratecard/v1"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// On-prem specific constants
+const (
+	deliminator               = "-"
+	overrideXIDPrefix         = "purser-override-nodePrice-"
+	provisionerXIDPrefix      = "purser-provisioner-storagePrice-"
+	extendedResourceXIDPrefix = "purser-extendedResourcePrice-"
+)
+
+// StoreRateCard converts RateCard custom resource to purser's rate card and stores it in dgraph
+func StoreRateCard(rateCardCRD *ratecard_v1.RateCard) {
+	rateCard, overrides := convertRateCardCRDToPurserRateCard(rateCardCRD)
+	// prices removed from the custom resource shouldn't select nodes or volumes anymore
+	deleteSelectablePrices()
+	// instance prices of the cloud would be selected before the default node price of the custom resource
+	models.DeleteInstanceNodePrices()
+	rateCard.NodePrices = append(rateCard.NodePrices, overrides...)
+	models.StoreRateCard(rateCard)
+}
+
+// DeleteRateCard deletes prices of the RateCard custom resource from dgraph so that nodes and volumes aren't priced by
+// them anymore, nodes matching no other price fall back to default prices
+func DeleteRateCard() {
+	deleteSelectablePrices()
+	models.DeleteDefaultNodePrice()
+}
+
+// deleteSelectablePrices deletes prices which are selected by node labels, storage class, provisioner, extended
+// resource or network resource
+func deleteSelectablePrices() {
+	models.DeleteNodePriceOverrides()
+	models.DeleteStorageClassStoragePrices()
+	models.DeleteProvisionerStoragePrices()
+	models.DeleteExtendedResourcePrices()
+	models.DeleteNetworkPrices()
+}
+
+// SelectRateCard returns the RateCard custom resource whose prices are applied, it is the oldest one so that rate cards
+// created later don't overwrite its prices. Nil is returned when there are none.
+func SelectRateCard(rateCards []ratecard_v1.RateCard) *ratecard_v1.RateCard {
+	if len(rateCards) == 0 {
+		return nil
+	}
+	sorted := append([]ratecard_v1.RateCard{}, rateCards...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(&sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return &sorted[0]
+}
+
+// convertRateCardCRDToPurserRateCard returns rate card with default node price, storage class, provisioner, extended
+// resource and network prices along with node prices which are selected by node labels
+func convertRateCardCRDToPurserRateCard(rateCardCRD *ratecard_v1.RateCard) (*models.RateCard, []*models.NodePrice) {
+	spec := rateCardCRD.Spec
+	defaultNodePrice := &models.NodePrice{
+		ID:             dgraph.ID{Xid: models.DefaultNodePriceXID},
+		IsNodePrice:    true,
+		InstanceType:   models.DefaultNodeInstance,
+		PricePerCPU:    spec.CPUPricePerHour,
+		PricePerMemory: spec.MemoryPricePerGBHour,
+		PricePerGPU:    spec.GPUPricePerHour,
+	}
+
+	var overrides []*models.NodePrice
+	for index, override := range spec.NodeLabelOverrides {
+		if len(override.Labels) == 0 {
+			logrus.Warnf("skipping node label override: %d of rate card: %s without labels", index, rateCardCRD.Name)
+			continue
+		}
+		overrides = append(overrides, &models.NodePrice{
+			ID:             dgraph.ID{Xid: fmt.Sprintf("%s%d", overrideXIDPrefix, index)},
+			IsNodePrice:    true,
+			InstanceType:   models.DefaultNodeInstance,
+			PricePerCPU:    override.CPUPricePerHour,
+			PricePerMemory: override.MemoryPricePerGBHour,
+			PricePerGPU:    override.GPUPricePerHour,
+			LabelSelector:  labels.SelectorFromSet(labels.Set(override.Labels)).String(),
+			Priority:       index,
+		})
+	}
+
+	var storagePrices []*models.StoragePrice
+	for _, storageClassPrice := range spec.StorageClassPrices {
+		storagePrices = append(storagePrices, &models.StoragePrice{
+			ID:             dgraph.ID{Xid: storageClassPrice.StorageClass + deliminator + models.StorageClassUsageType},
+			IsStoragePrice: true,
+			VolumeType:     storageClassPrice.StorageClass,
+			UsageType:      models.StorageClassUsageType,
+			Price:          storageClassPrice.PricePerGBHour,
+		})
+	}
+	for index, provisionerPrice := range spec.ProvisionerPrices {
+		storagePrices = append(storagePrices, &models.StoragePrice{
+			ID:                dgraph.ID{Xid: fmt.Sprintf("%s%d", provisionerXIDPrefix, index)},
+			IsStoragePrice:    true,
+			VolumeType:        provisionerPrice.Provisioner,
+			UsageType:         models.ProvisionerUsageType,
+			Price:             provisionerPrice.PricePerGBHour,
+			ParameterSelector: labels.SelectorFromSet(labels.Set(provisionerPrice.Parameters)).String(),
+			Priority:          index,
+		})
+	}
+
+	var extendedResourcePrices []*models.ExtendedResourcePrice
+	for _, extendedResourcePrice := range spec.ExtendedResourcePrices {
+		extendedResourcePrices = append(extendedResourcePrices, &models.ExtendedResourcePrice{
+			ID:                      dgraph.ID{Xid: extendedResourceXIDPrefix + extendedResourcePrice.ResourceName},
+			IsExtendedResourcePrice: true,
+			ResourceName:            extendedResourcePrice.ResourceName,
+			Price:                   extendedResourcePrice.PricePerHour,
+		})
+	}
+
+	var networkPrices []*models.NetworkPrice
+	for _, networkPrice := range spec.NetworkPrices {
+		networkPrices = append(networkPrices, &models.NetworkPrice{
+			ID:              dgraph.ID{Xid: models.NetworkPriceXIDPrefix + networkPrice.Resource},
+			IsNetworkPrice:  true,
+			NetworkResource: networkPrice.Resource,
+			Price:           networkPrice.PricePerHour,
+			PricePerGB:      networkPrice.PricePerGB,
+		})
+	}
+
+	rateCard := &models.RateCard{
+		ID:             dgraph.ID{Xid: models.RateCardXID},
+		IsRateCard:     true,
+		CloudProvider:  models.OnPrem,
+		Region:         spec.Region,
+		LocationSource: ratecard_v1.RateCardPlural + "/" + rateCardCRD.Name,
+		NodePrices:     []*models.NodePrice{defaultNodePrice},
+		StoragePrices:  storagePrices,
+
+		ExtendedResourcePrices: extendedResourcePrices,
+		NetworkPrices:          networkPrices,
+	}
+	return rateCard, overrides
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package onprem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ratecard_v1 "github.com/vmware/purser/pkg/apis/ratecard/v1"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestRateCardCRD() *ratecard_v1.RateCard {
+	return &ratecard_v1.RateCard{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "datacenter-prices"},
+		Spec: ratecard_v1.RateCardSpec{
+			Region:               "datacenter-1",
+			CPUPricePerHour:      0.03,
+			MemoryPricePerGBHour: 0.004,
+			StorageClassPrices: []ratecard_v1.StorageClassPrice{
+				{StorageClass: "fast", PricePerGBHour: 0.0003},
+			},
+			NodeLabelOverrides: []ratecard_v1.NodeLabelOverride{
+				{Labels: map[string]string{"hardware-generation": "gen10", "rack": "r1"}, CPUPricePerHour: 0.045, MemoryPricePerGBHour: 0.006},
+				{Labels: map[string]string{}, CPUPricePerHour: 0.01},
+				{Labels: map[string]string{"hardware-generation": "gen9"}, CPUPricePerHour: 0.02, MemoryPricePerGBHour: 0.002},
+			},
+		},
+	}
+}
+
+// TestConvertRateCardCRDToPurserRateCard ...
+func TestConvertRateCardCRDToPurserRateCard(t *testing.T) {
+	rateCard, overrides := convertRateCardCRDToPurserRateCard(getTestRateCardCRD())
+
+	assert.Equal(t, models.OnPrem, rateCard.CloudProvider)
+	assert.Equal(t, "datacenter-1", rateCard.Region)
+	assert.Equal(t, "ratecards/datacenter-prices", rateCard.LocationSource)
+
+	assert.Len(t, rateCard.NodePrices, 1)
+	assert.Equal(t, models.DefaultNodePriceXID, rateCard.NodePrices[0].Xid)
+	assert.Equal(t, 0.03, rateCard.NodePrices[0].PricePerCPU)
+	assert.Equal(t, 0.004, rateCard.NodePrices[0].PricePerMemory)
+
+	assert.Len(t, rateCard.StoragePrices, 1)
+	assert.Equal(t, "fast", rateCard.StoragePrices[0].VolumeType)
+	assert.Equal(t, "fast-storageClass", rateCard.StoragePrices[0].Xid)
+	assert.Equal(t, 0.0003, rateCard.StoragePrices[0].Price)
+
+	// override without labels is skipped
+	assert.Len(t, overrides, 2)
+	assert.Equal(t, "hardware-generation=gen10,rack=r1", overrides[0].LabelSelector)
+	assert.Equal(t, 0, overrides[0].Priority)
+	assert.Equal(t, 0.045, overrides[0].PricePerCPU)
+	assert.Equal(t, "hardware-generation=gen9", overrides[1].LabelSelector)
+	assert.Equal(t, 2, overrides[1].Priority)
+	assert.Equal(t, "purser-override-nodePrice-2", overrides[1].Xid)
+}
+
+// TestConvertProvisionerPrices ...
+func TestConvertProvisionerPrices(t *testing.T) {
+	rateCardCRD := getTestRateCardCRD()
+	rateCardCRD.Spec.ProvisionerPrices = []ratecard_v1.ProvisionerPrice{
+		{Provisioner: "csi.vsphere.vmware.com", Parameters: map[string]string{"storagepolicyname": "vsan-gold"}, PricePerGBHour: 0.0002},
+		{Provisioner: "csi.vsphere.vmware.com", PricePerGBHour: 0.0001},
+	}
+	rateCard, _ := convertRateCardCRDToPurserRateCard(rateCardCRD)
+
+	assert.Len(t, rateCard.StoragePrices, 3)
+	gold := rateCard.StoragePrices[1]
+	assert.Equal(t, "purser-provisioner-storagePrice-0", gold.Xid)
+	assert.Equal(t, "csi.vsphere.vmware.com", gold.VolumeType)
+	assert.Equal(t, models.ProvisionerUsageType, gold.UsageType)
+	assert.Equal(t, "storagepolicyname=vsan-gold", gold.ParameterSelector)
+	assert.Equal(t, 0.0002, gold.Price)
+	assert.Equal(t, "", rateCard.StoragePrices[2].ParameterSelector)
+	assert.Equal(t, 1, rateCard.StoragePrices[2].Priority)
+}
+
+// TestConvertGPUAndExtendedResourcePrices ...
+func TestConvertGPUAndExtendedResourcePrices(t *testing.T) {
+	rateCardCRD := getTestRateCardCRD()
+	rateCardCRD.Spec.GPUPricePerHour = 0.8
+	rateCardCRD.Spec.NodeLabelOverrides[0].GPUPricePerHour = 1.2
+	rateCardCRD.Spec.ExtendedResourcePrices = []ratecard_v1.ExtendedResourcePrice{
+		{ResourceName: "xilinx.com/fpga-xilinx_u200", PricePerHour: 0.5},
+	}
+	rateCard, overrides := convertRateCardCRDToPurserRateCard(rateCardCRD)
+
+	assert.Equal(t, 0.8, rateCard.NodePrices[0].PricePerGPU)
+	assert.Equal(t, 1.2, overrides[0].PricePerGPU)
+	assert.Equal(t, 0.0, overrides[1].PricePerGPU)
+
+	assert.Len(t, rateCard.ExtendedResourcePrices, 1)
+	fpga := rateCard.ExtendedResourcePrices[0]
+	assert.Equal(t, "purser-extendedResourcePrice-xilinx.com/fpga-xilinx_u200", fpga.Xid)
+	assert.Equal(t, "xilinx.com/fpga-xilinx_u200", fpga.ResourceName)
+	assert.Equal(t, 0.5, fpga.Price)
+}
+
+// TestConvertNetworkPrices ...
+func TestConvertNetworkPrices(t *testing.T) {
+	rateCardCRD := getTestRateCardCRD()
+	rateCardCRD.Spec.NetworkPrices = []ratecard_v1.NetworkPrice{
+		{Resource: models.LoadBalancerNetworkResource, PricePerHour: 0.02, PricePerGB: 0.008},
+		{Resource: models.PublicIPNetworkResource, PricePerHour: 0.005},
+	}
+	rateCard, _ := convertRateCardCRDToPurserRateCard(rateCardCRD)
+
+	assert.Len(t, rateCard.NetworkPrices, 2)
+	loadBalancer := rateCard.NetworkPrices[0]
+	assert.Equal(t, "purser-networkPrice-loadBalancer", loadBalancer.Xid)
+	assert.Equal(t, models.LoadBalancerNetworkResource, loadBalancer.NetworkResource)
+	assert.Equal(t, 0.02, loadBalancer.Price)
+	assert.Equal(t, 0.008, loadBalancer.PricePerGB)
+	assert.Equal(t, 0.005, rateCard.NetworkPrices[1].Price)
+}
+
+// TestSelectRateCard ...
+func TestSelectRateCard(t *testing.T) {
+	assert.Nil(t, SelectRateCard(nil))
+
+	created := meta_v1.NewTime(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC))
+	rateCards := []ratecard_v1.RateCard{
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "later", CreationTimestamp: meta_v1.NewTime(created.Add(time.Hour))}},
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "datacenter-b", CreationTimestamp: created}},
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "datacenter-a", CreationTimestamp: created}},
+	}
+	assert.Equal(t, "datacenter-a", SelectRateCard(rateCards).Name)
+	// order of the given rate cards is kept
+	assert.Equal(t, "later", rateCards[0].Name)
+}
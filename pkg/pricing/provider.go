@@ -0,0 +1,84 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+	"github.com/vmware/purser/pkg/pricing/aws"
+	"github.com/vmware/purser/pkg/pricing/azure"
+	"github.com/vmware/purser/pkg/pricing/gcp"
+)
+
+// Provider fetches price list of a cloud provider and converts it to purser's rate card
+type Provider interface {
+	// Fetch retrieves prices of the region
+	Fetch(region string) error
+	// RateCard converts the fetched prices of the region to purser's rate card
+	RateCard(region string) (*models.RateCard, error)
+	// Version of the fetched price list, empty if the price list has none
+	Version() string
+}
+
+// NewProviderFunc returns a provider which reads prices from priceFile instead of the network when it is given
+type NewProviderFunc func(priceFile string) Provider
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]NewProviderFunc)
+)
+
+func init() {
+	RegisterProvider(models.AWS, func(priceFile string) Provider { return aws.NewProvider(priceFile) })
+	RegisterProvider(models.GCP, func(priceFile string) Provider { return gcp.NewProvider(priceFile) })
+	RegisterProvider(models.Azure, func(priceFile string) Provider { return azure.NewProvider(priceFile) })
+}
+
+// RegisterProvider makes a provider available by cloud provider name(ex: aws), an existing
+// provider of the same name is replaced
+func RegisterProvider(name string, newProvider NewProviderFunc) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = newProvider
+}
+
+// GetProvider returns the provider registered with the name
+func GetProvider(name, priceFile string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	newProvider, isPresent := providers[name]
+	if !isPresent {
+		return nil, fmt.Errorf("no pricing provider is registered for cloud provider: %s", name)
+	}
+	return newProvider(priceFile), nil
+}
+
+// RegisteredProviders returns sorted names of the registered providers
+func RegisteredProviders() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	var names []string
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
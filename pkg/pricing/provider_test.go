@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+type testProvider struct {
+	priceFile string
+}
+
+func (p *testProvider) Fetch(region string) error {
+	return nil
+}
+
+func (p *testProvider) RateCard(region string) (*models.RateCard, error) {
+	return &models.RateCard{ID: dgraph.ID{Xid: models.RateCardXID}, IsRateCard: true, Region: region}, nil
+}
+
+func (p *testProvider) Version() string {
+	return p.priceFile
+}
+
+// TestBuiltinProvidersAreRegistered ...
+func TestBuiltinProvidersAreRegistered(t *testing.T) {
+	for _, name := range []string{models.AWS, models.GCP, models.Azure} {
+		provider, err := GetProvider(name, "")
+		assert.NoError(t, err)
+		assert.NotNil(t, provider)
+	}
+}
+
+// TestGetProviderNotRegistered ...
+func TestGetProviderNotRegistered(t *testing.T) {
+	_, err := GetProvider(models.VSphere, "")
+	assert.Error(t, err)
+}
+
+// TestRegisterProvider ...
+func TestRegisterProvider(t *testing.T) {
+	RegisterProvider("test", func(priceFile string) Provider { return &testProvider{priceFile: priceFile} })
+	assert.Contains(t, RegisteredProviders(), "test")
+
+	provider, err := GetProvider("test", "/prices/test.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "/prices/test.json", provider.Version())
+}
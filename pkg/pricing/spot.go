@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// anyInstanceFamily is the key of the spot discount applied to instance families without one
+const anyInstanceFamily = "*"
+
+// parseSpotDiscounts parses comma separated instanceFamily=discount(ex: m5=0.7,*=0.6) where discount is the fraction
+// of on-demand price saved by spot instances, instance types(ex: m5.large) can be given instead of families
+func parseSpotDiscounts(rawSpotDiscounts string) (map[string]float64, error) {
+	discounts := make(map[string]float64)
+	for _, rawSpotDiscount := range strings.Split(rawSpotDiscounts, ",") {
+		rawSpotDiscount = strings.TrimSpace(rawSpotDiscount)
+		if rawSpotDiscount == "" {
+			continue
+		}
+		parts := strings.SplitN(rawSpotDiscount, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid spot discount: %s, expected instanceFamily=discount", rawSpotDiscount)
+		}
+		discount, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || discount < 0 || discount >= 1 {
+			return nil, fmt.Errorf("invalid spot discount: %s, discount must be at least 0 and less than 1", rawSpotDiscount)
+		}
+		discounts[parts[0]] = discount
+	}
+	return discounts, nil
+}
+
+// applySpotDiscounts sets spot prices of node prices which have none(ex: aws, whose offer file has no spot prices)
+// from on-demand prices discounted by the discount of their instance type or family
+func applySpotDiscounts(rateCard *models.RateCard, discounts map[string]float64) {
+	for _, nodePrice := range rateCard.NodePrices {
+		if nodePrice.SpotPrice > 0 || nodePrice.Price <= 0 {
+			continue
+		}
+		if discount, isPresent := getSpotDiscount(nodePrice.InstanceType, discounts); isPresent {
+			nodePrice.SpotPrice = nodePrice.Price * (1 - discount)
+		}
+	}
+}
+
+// getSpotDiscount returns discount of the instance type, of its family(ex: m5 of m5.large) or of any family in that order
+func getSpotDiscount(instanceType string, discounts map[string]float64) (float64, bool) {
+	family := instanceType
+	if index := strings.Index(instanceType, "."); index > 0 {
+		family = instanceType[:index]
+	}
+	for _, key := range []string{instanceType, family, anyInstanceFamily} {
+		if discount, isPresent := discounts[key]; isPresent {
+			return discount, true
+		}
+	}
+	return 0, false
+}
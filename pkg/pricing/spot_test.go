@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2018 VMware Inc. All Rights Reserved.
+ * SPDX-License-Identifier: Apache-2.0
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/purser/pkg/controller/dgraph/models"
+)
+
+// TestParseSpotDiscounts ...
+func TestParseSpotDiscounts(t *testing.T) {
+	discounts, err := parseSpotDiscounts(" m5=0.7, c5.large=0.6,*=0.5,")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]float64{"m5": 0.7, "c5.large": 0.6, "*": 0.5}, discounts)
+
+	discounts, err = parseSpotDiscounts("")
+	assert.Nil(t, err)
+	assert.Empty(t, discounts)
+
+	for _, invalid := range []string{"m5", "=0.7", "m5=cheap", "m5=1", "m5=-0.1"} {
+		_, err = parseSpotDiscounts(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+// TestApplySpotDiscounts ...
+func TestApplySpotDiscounts(t *testing.T) {
+	rateCard := &models.RateCard{NodePrices: []*models.NodePrice{
+		{InstanceType: "m5.large", Price: 0.1},
+		{InstanceType: "c5.large", Price: 0.1},
+		{InstanceType: "r5.large", Price: 0.1},
+		{InstanceType: "t3.large", Price: 0.1, SpotPrice: 0.02},
+	}}
+	applySpotDiscounts(rateCard, map[string]float64{"m5": 0.7, "c5.large": 0.6})
+	assert.InDelta(t, 0.03, rateCard.NodePrices[0].SpotPrice, 1e-9)
+	assert.InDelta(t, 0.04, rateCard.NodePrices[1].SpotPrice, 1e-9)
+	// no discount for the family, node is priced on-demand
+	assert.Equal(t, 0.0, rateCard.NodePrices[2].SpotPrice)
+	// spot prices of the price list are kept
+	assert.Equal(t, 0.02, rateCard.NodePrices[3].SpotPrice)
+
+	applySpotDiscounts(rateCard, map[string]float64{"*": 0.5})
+	assert.InDelta(t, 0.05, rateCard.NodePrices[2].SpotPrice, 1e-9)
+}